@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -92,7 +91,7 @@ func main() {
 	}
 
 	// Initialize database connection
-	database, err := db.New(cfg)
+	database, err := db.New(cfg, zapLogger)
 	if err != nil {
 		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -106,16 +105,10 @@ func main() {
 	zapLogger.Info("Successfully connected to database")
 
 	// Create router
-	handler := router.New(cfg, zapLogger, database)
+	handler, shutdownRouter := router.New(cfg, zapLogger, database)
 
 	// Configure HTTP server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      handler,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
-	}
+	srv := router.NewServer(cfg, handler)
 
 	// Start the server in a goroutine
 	go func() {
@@ -146,6 +139,9 @@ func main() {
 		zapLogger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Save what only lives in memory while the database is still open
+	shutdownRouter(ctx)
+
 	// Close database connection
 	zapLogger.Info("Closing database connection...")
 	if err := database.Close(); err != nil {
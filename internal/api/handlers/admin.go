@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// ClickRetention defines the interface for purging expired click data
+type ClickRetention interface {
+	Run(ctx context.Context) (int64, error)
+}
+
+// SystemStatsProvider defines the interface for aggregate system statistics
+type SystemStatsProvider interface {
+	GetSystemStats(ctx context.Context) (*domain.SystemStats, error)
+}
+
+// LinkHistory defines the interface for reading a link's audit log
+type LinkHistory interface {
+	GetLinkHistory(ctx context.Context, code string) ([]*domain.AuditEntry, error)
+}
+
+// LinkImporter defines the interface for creating links with a given code
+type LinkImporter interface {
+	ImportShortLink(ctx context.Context, req *domain.ImportShortLinkRequest) (*domain.ShortLink, error)
+}
+
+// LinkExporter defines the interface for streaming the whole link catalog
+type LinkExporter interface {
+	ExportShortLinks(ctx context.Context, fn func(link *domain.ShortLink) error) (bool, error)
+}
+
+// LinkHealthChecker defines the interface for checking link destinations
+type LinkHealthChecker interface {
+	CheckLinkHealth(ctx context.Context, codes []string) (*domain.LinkHealthReport, error)
+}
+
+// LinkCacheInvalidator defines the interface for dropping a link's cached entries
+type LinkCacheInvalidator interface {
+	InvalidateLink(ctx context.Context, code string) error
+}
+
+// LinkDestinationSearcher defines the interface for finding links by the
+// host they point at
+type LinkDestinationSearcher interface {
+	ListShortLinksByDestinationHost(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error)
+}
+
+// AdminHandler handles administrative routes
+type AdminHandler struct {
+	retention ClickRetention
+	stats     SystemStatsProvider
+	history   LinkHistory
+	importer  LinkImporter
+	exporter  LinkExporter
+	health    LinkHealthChecker
+	cache     LinkCacheInvalidator
+
+	destinations LinkDestinationSearcher
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(
+	retention ClickRetention,
+	stats SystemStatsProvider,
+	history LinkHistory,
+	importer LinkImporter,
+	exporter LinkExporter,
+	health LinkHealthChecker,
+	cache LinkCacheInvalidator,
+	destinations LinkDestinationSearcher,
+) *AdminHandler {
+	return &AdminHandler{
+		retention: retention,
+		stats:     stats,
+		history:   history,
+		importer:  importer,
+		exporter:  exporter,
+		health:    health,
+		cache:     cache,
+
+		destinations: destinations,
+	}
+}
+
+// PurgeClicks handles a manual run of the click retention job
+// @Summary Purge expired clicks
+// @Description Delete raw click records older than the configured retention period
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]int64 "Number of clicks deleted"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /admin/clicks/purge [post]
+func (h *AdminHandler) PurgeClicks(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	deleted, err := h.retention.Run(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to purge expired clicks", zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to purge clicks")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// GetStats handles retrieving aggregate system statistics
+// @Summary Get system statistics
+// @Description Get total links, total clicks, links created today and active vs expired counts
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.SystemStats "System statistics"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /admin/stats [get]
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	stats, err := h.stats.GetSystemStats(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to get system stats", zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to get system stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetLinkHistory handles retrieving the audit log of a short link
+// @Summary Get link history
+// @Description Get the create, update and delete events of a short link, newest first
+// @Tags admin
+// @Produce json
+// @Param code path string true "Short link code"
+// @Success 200 {array} domain.AuditEntry "Audit entries"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Link not found"
+// @Security BearerAuth
+// @Router /admin/links/{code}/history [get]
+func (h *AdminHandler) GetLinkHistory(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	code := c.Param("code")
+	entries, err := h.history.GetLinkHistory(c.Request.Context(), code)
+	if err != nil {
+		logger.Info("Failed to get link history", zap.String("code", code), zap.Error(err))
+		respondError(c, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
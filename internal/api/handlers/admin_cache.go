@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// InvalidateLinkCache handles dropping the cached entries of a link
+// @Summary Invalidate a link's cache
+// @Description Drop every cached entry of the link, keyed by its ID, code or alias, so the next read comes from the database. Use after editing a link directly in the database.
+// @Tags admin
+// @Produce json
+// @Param code path string true "Short link code or alias"
+// @Success 204 "Cache entries dropped"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Link not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 501 {object} map[string]string "Link cache not enabled"
+// @Security BearerAuth
+// @Router /admin/links/{code}/invalidate-cache [post]
+func (h *AdminHandler) InvalidateLinkCache(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	if h.cache == nil {
+		respondError(c, http.StatusNotImplemented, "Link cache is not enabled")
+		return
+	}
+
+	code := c.Param("code")
+	if err := h.cache.InvalidateLink(c.Request.Context(), code); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "Link not found")
+			return
+		}
+		logger.Error("Failed to invalidate link cache", zap.String("code", code), zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to invalidate link cache")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
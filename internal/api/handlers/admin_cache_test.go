@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler cache invalidation", func() {
+	var (
+		recorder    *httptest.ResponseRecorder
+		invalidated []string
+		invalidErr  error
+	)
+
+	serve := func(cache handlers.LinkCacheInvalidator) {
+		router := gin.New()
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, &MockLinkImporter{}, &MockLinkExporter{}, nil, cache, nil)
+		router.POST("/api/admin/links/:code/invalidate-cache", handler.InvalidateLinkCache)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/admin/links/abc123/invalidate-cache", nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	cache := func() *MockLinkCacheInvalidator {
+		return &MockLinkCacheInvalidator{
+			InvalidateLinkFunc: func(ctx context.Context, code string) error {
+				invalidated = append(invalidated, code)
+				return invalidErr
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		invalidated = nil
+		invalidErr = nil
+	})
+
+	It("should invalidate the named link", func() {
+		serve(cache())
+
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(invalidated).To(Equal([]string{"abc123"}))
+	})
+
+	It("should answer 404 for an unknown link", func() {
+		invalidErr = fmt.Errorf("short link abc123: %w", domain.ErrNotFound)
+
+		serve(cache())
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should answer 500 when the link can't be read", func() {
+		invalidErr = errors.New("connection refused")
+
+		serve(cache())
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("connection refused"))
+	})
+
+	It("should answer 501 when the cache is off", func() {
+		serve(nil)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+	})
+})
+
+// MockLinkCacheInvalidator is a mock implementation of LinkCacheInvalidator
+type MockLinkCacheInvalidator struct {
+	InvalidateLinkFunc func(ctx context.Context, code string) error
+}
+
+func (m *MockLinkCacheInvalidator) InvalidateLink(ctx context.Context, code string) error {
+	if m.InvalidateLinkFunc != nil {
+		return m.InvalidateLinkFunc(ctx, code)
+	}
+	return nil
+}
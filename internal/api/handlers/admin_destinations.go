@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+)
+
+// ListLinksByDestinationHost handles listing the links pointing at a domain
+// @Summary List links by destination host
+// @Description List every link whose destination host is the given host or one of its subdomains, newest first, e.g. to take down links to a malicious domain
+// @Tags admin
+// @Produce json
+// @Param host query string true "Destination host, e.g. example.com"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Links per page"
+// @Success 200 {object} ListResponse "Matching links"
+// @Failure 400 {object} map[string]string "Missing host or page size too large"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 501 {object} map[string]string "Destination search not configured"
+// @Security BearerAuth
+// @Router /admin/links/by-host [get]
+func (h *AdminHandler) ListLinksByDestinationHost(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	if h.destinations == nil {
+		respondError(c, http.StatusNotImplemented, "Destination search is not configured")
+		return
+	}
+
+	host := c.Query("host")
+	if host == "" {
+		respondError(c, http.StatusBadRequest, "host is required")
+		return
+	}
+
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := parsePageSize(c.Query("page_size"), DefaultMaxPageSize)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	links, total, err := h.destinations.ListShortLinksByDestinationHost(c.Request.Context(), host, page, pageSize)
+	if err != nil {
+		logger.Info("Failed to list links by destination host", zap.String("host", host), zap.Error(err))
+		respondServiceError(c, err, "Failed to list links")
+		return
+	}
+
+	logger.Info("Listed links by destination host", zap.String("host", host), zap.Int("total", total))
+
+	meta := newPageMeta(c, "", total, page, pageSize)
+	setLinkHeader(c, meta.Next, meta.Prev)
+
+	respondList(c, links, meta)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler links by destination host", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		searcher *MockLinkDestinationSearcher
+	)
+
+	newRouter := func(destinations handlers.LinkDestinationSearcher) {
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, &MockLinkImporter{}, &MockLinkExporter{}, nil, nil, destinations)
+		router = gin.New()
+		router.GET("/api/admin/links/by-host", handler.ListLinksByDestinationHost)
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		searcher = &MockLinkDestinationSearcher{
+			ListFunc: func(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return []*domain.ShortLink{{ID: "link-1", Code: "abc123"}}, 1, nil
+			},
+		}
+		newRouter(searcher)
+	})
+
+	request := func(target string) map[string]interface{} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(recorder, req)
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body
+	}
+
+	It("lists the matching links in the list envelope", func() {
+		body := request("/api/admin/links/by-host?host=example.com&page=2&page_size=5")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(searcher.Host).To(Equal("example.com"))
+		Expect(searcher.Page).To(Equal(2))
+		Expect(searcher.PageSize).To(Equal(5))
+		Expect(body["data"]).To(HaveLen(1))
+		Expect(body["meta"]).To(HaveKeyWithValue("total", BeEquivalentTo(1)))
+	})
+
+	It("requires a host", func() {
+		request("/api/admin/links/by-host")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects an invalid host", func() {
+		searcher.ListFunc = func(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error) {
+			verr := &domain.ValidationError{}
+			verr.Add("host", "host must be a domain name such as example.com")
+			return nil, 0, verr
+		}
+
+		body := request("/api/admin/links/by-host?host=a/b")
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(body).To(HaveKey("fields"))
+	})
+
+	It("answers 501 without a searcher", func() {
+		newRouter(nil)
+
+		request("/api/admin/links/by-host?host=example.com")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+	})
+})
+
+// MockLinkDestinationSearcher is a mock implementation of LinkDestinationSearcher
+type MockLinkDestinationSearcher struct {
+	ListFunc func(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error)
+
+	Host     string
+	Page     int
+	PageSize int
+}
+
+func (m *MockLinkDestinationSearcher) ListShortLinksByDestinationHost(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	m.Host, m.Page, m.PageSize = host, page, pageSize
+	return m.ListFunc(ctx, host, page, pageSize)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// exportFlushEvery is how many rows are buffered before flushing to the client
+const exportFlushEvery = 500
+
+// exportCSVHeader lists the CSV export columns
+var exportCSVHeader = []string{"code", "original_url", "created_at", "expiration", "active", "click_count"}
+
+// ExportedLink is one link in a JSON export
+type ExportedLink struct {
+	Code           string     `json:"code"`
+	OriginalURL    string     `json:"original_url"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpirationDate *time.Time `json:"expiration_date"`
+	IsActive       bool       `json:"is_active"`
+	ClickCount     int64      `json:"click_count"`
+}
+
+// linkExportEncoder writes links in one export format
+type linkExportEncoder interface {
+	begin() error
+	encode(link *domain.ShortLink) error
+	end(truncated bool) error
+}
+
+// ExportLinks handles streaming the link catalog as a backup
+// @Summary Export links
+// @Description Stream every link, oldest first, as CSV or JSON up to the server-side row cap. JSON exports report whether the cap cut the export short.
+// @Tags admin
+// @Produce json,text/csv
+// @Param format query string false "csv or json (default)"
+// @Success 200 {string} string "Exported links"
+// @Failure 400 {object} map[string]string "Unknown format"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /admin/export [get]
+func (h *AdminHandler) ExportLinks(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	var encoder linkExportEncoder
+	var contentType, extension string
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "csv":
+		encoder = &csvLinkEncoder{w: csv.NewWriter(c.Writer)}
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	case "json":
+		encoder = &jsonLinkEncoder{w: c.Writer, links: newJSONArrayStream(c.Writer)}
+		contentType, extension = "application/json; charset=utf-8", "json"
+	default:
+		respondError(c, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	// The response starts with the first row, so a failure before any
+	// output can still be reported with a proper status
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="links-%s.%s"`, time.Now().UTC().Format("20060102"), extension))
+		c.Status(http.StatusOK)
+		return encoder.begin()
+	}
+
+	rows := 0
+	truncated, err := h.exporter.ExportShortLinks(c.Request.Context(), func(link *domain.ShortLink) error {
+		if err := start(); err != nil {
+			return err
+		}
+		if err := encoder.encode(link); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = start()
+	}
+	if err != nil {
+		logger.Error("Failed to export links", zap.Int("rows", rows), zap.Error(err))
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			respondError(c, http.StatusInternalServerError, "Failed to export links")
+			return
+		}
+		// Headers are gone; cutting the body short is the only signal left
+		c.Abort()
+		return
+	}
+
+	if err := encoder.end(truncated); err != nil {
+		logger.Error("Failed to finish link export", zap.Error(err))
+		return
+	}
+
+	if truncated {
+		logger.Warn("Link export reached the row cap", zap.Int("rows", rows))
+	}
+	c.Writer.Flush()
+}
+
+// csvLinkEncoder writes a header row followed by one row per link
+type csvLinkEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvLinkEncoder) begin() error {
+	return e.w.Write(exportCSVHeader)
+}
+
+func (e *csvLinkEncoder) encode(link *domain.ShortLink) error {
+	var originalURL, expiration string
+	if link.URL != nil {
+		originalURL = link.URL.OriginalURL
+	}
+	if link.ExpirationDate != nil {
+		expiration = link.ExpirationDate.UTC().Format(time.RFC3339)
+	}
+
+	return e.w.Write([]string{
+		link.Code,
+		originalURL,
+		link.CreatedAt.UTC().Format(time.RFC3339),
+		expiration,
+		strconv.FormatBool(link.IsActive),
+		strconv.FormatInt(clickCount(link), 10),
+	})
+}
+
+func (e *csvLinkEncoder) end(truncated bool) error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonLinkEncoder writes {"links":[...],"truncated":bool} one link at a time
+type jsonLinkEncoder struct {
+	w     io.Writer
+	links *jsonArrayStream
+}
+
+func (e *jsonLinkEncoder) begin() error {
+	_, err := io.WriteString(e.w, `{"links":[`)
+	return err
+}
+
+func (e *jsonLinkEncoder) encode(link *domain.ShortLink) error {
+	exported := ExportedLink{
+		Code:           link.Code,
+		CreatedAt:      link.CreatedAt,
+		ExpirationDate: link.ExpirationDate,
+		IsActive:       link.IsActive,
+		ClickCount:     clickCount(link),
+	}
+	if link.URL != nil {
+		exported.OriginalURL = link.URL.OriginalURL
+	}
+
+	return e.links.write(exported)
+}
+
+func (e *jsonLinkEncoder) end(truncated bool) error {
+	_, err := fmt.Fprintf(e.w, `],"truncated":%t}`, truncated)
+	return err
+}
+
+// clickCount returns the link's click count, or zero when it was not loaded
+func clickCount(link *domain.ShortLink) int64 {
+	if link.ClickCount == nil {
+		return 0
+	}
+	return *link.ClickCount
+}
@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler export", func() {
+	var (
+		router    *gin.Engine
+		recorder  *httptest.ResponseRecorder
+		exporter  *MockLinkExporter
+		links     []*domain.ShortLink
+		truncated bool
+	)
+
+	createdAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	clicks := func(n int64) *int64 { return &n }
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		truncated = false
+		links = []*domain.ShortLink{
+			{
+				Code:       "abc123",
+				URL:        &domain.URL{OriginalURL: "https://example.com/a"},
+				CreatedAt:  createdAt,
+				IsActive:   true,
+				ClickCount: clicks(7),
+			},
+			{
+				Code:           "promo",
+				URL:            &domain.URL{OriginalURL: "https://example.com/b?x=1,2"},
+				CreatedAt:      createdAt,
+				ExpirationDate: &expiresAt,
+				ClickCount:     clicks(0),
+			},
+		}
+
+		exporter = &MockLinkExporter{
+			ExportShortLinksFunc: func(ctx context.Context, fn func(link *domain.ShortLink) error) (bool, error) {
+				for _, link := range links {
+					if err := fn(link); err != nil {
+						return false, err
+					}
+				}
+				return truncated, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, &MockLinkImporter{}, exporter, nil, nil, nil)
+		router.GET("/api/admin/export", handler.ExportLinks)
+	})
+
+	request := func(query string) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/admin/export"+query, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	Context("as CSV", func() {
+		It("writes the header followed by one row per link", func() {
+			request("?format=csv")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/csv"))
+			Expect(recorder.Header().Get("Content-Disposition")).To(MatchRegexp(`^attachment; filename="links-\d{8}\.csv"$`))
+
+			records, err := csv.NewReader(recorder.Body).ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(Equal([][]string{
+				{"code", "original_url", "created_at", "expiration", "active", "click_count"},
+				{"abc123", "https://example.com/a", "2024-03-01T09:00:00Z", "", "true", "7"},
+				{"promo", "https://example.com/b?x=1,2", "2024-03-01T09:00:00Z", "2025-03-01T00:00:00Z", "false", "0"},
+			}))
+		})
+
+		It("writes only the header when there are no links", func() {
+			links = nil
+
+			request("?format=csv")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("code,original_url,created_at,expiration,active,click_count\n"))
+		})
+	})
+
+	Context("as JSON", func() {
+		type export struct {
+			Links     []handlers.ExportedLink `json:"links"`
+			Truncated bool                    `json:"truncated"`
+		}
+
+		decode := func() export {
+			var body export
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+			return body
+		}
+
+		It("streams a valid document by default", func() {
+			request("")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+
+			body := decode()
+			Expect(body.Truncated).To(BeFalse())
+			Expect(body.Links).To(HaveLen(2))
+			Expect(body.Links[0]).To(Equal(handlers.ExportedLink{
+				Code:        "abc123",
+				OriginalURL: "https://example.com/a",
+				CreatedAt:   createdAt,
+				IsActive:    true,
+				ClickCount:  7,
+			}))
+			Expect(body.Links[1].ExpirationDate).To(HaveValue(BeTemporally("==", expiresAt)))
+		})
+
+		It("stays valid across many flushed rows", func() {
+			links = nil
+			for i := 0; i < 1234; i++ {
+				links = append(links, &domain.ShortLink{
+					Code:      fmt.Sprintf("code%d", i),
+					URL:       &domain.URL{OriginalURL: "https://example.com"},
+					CreatedAt: createdAt,
+				})
+			}
+
+			request("?format=json")
+
+			body := decode()
+			Expect(body.Links).To(HaveLen(1234))
+			Expect(body.Links[1233].Code).To(Equal("code1233"))
+		})
+
+		It("produces an empty list when there are no links", func() {
+			links = nil
+
+			request("?format=json")
+
+			Expect(recorder.Body.String()).To(MatchJSON(`{"links":[],"truncated":false}`))
+		})
+
+		It("reports when the row cap cut the export short", func() {
+			truncated = true
+
+			request("?format=json")
+
+			Expect(decode().Truncated).To(BeTrue())
+		})
+	})
+
+	It("rejects unknown formats", func() {
+		request("?format=xml")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 500 when the export fails before any output", func() {
+		exporter.ExportShortLinksFunc = func(ctx context.Context, fn func(link *domain.ShortLink) error) (bool, error) {
+			return false, errors.New("database error")
+		}
+
+		request("?format=csv")
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Header().Get("Content-Disposition")).To(BeEmpty())
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+	})
+})
@@ -0,0 +1,225 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler", func() {
+	var (
+		router    *gin.Engine
+		recorder  *httptest.ResponseRecorder
+		retention *MockClickRetention
+		stats     *MockSystemStats
+		history   *MockLinkHistory
+		importer  *MockLinkImporter
+		handler   *handlers.AdminHandler
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		retention = &MockClickRetention{}
+		stats = &MockSystemStats{}
+		history = &MockLinkHistory{}
+		importer = &MockLinkImporter{}
+		handler = handlers.NewAdminHandler(retention, stats, history, importer, &MockLinkExporter{}, nil, nil, nil)
+		router.POST("/api/admin/clicks/purge", handler.PurgeClicks)
+		router.GET("/api/admin/stats", handler.GetStats)
+		router.GET("/api/admin/links/:code/history", handler.GetLinkHistory)
+		router.POST("/api/admin/import", handler.ImportLinks)
+	})
+
+	Describe("PurgeClicks", func() {
+		It("runs the retention job and reports the deleted count", func() {
+			retention.RunFunc = func(ctx context.Context) (int64, error) {
+				return 42, nil
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/admin/clicks/purge", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var respBody map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &respBody)).To(Succeed())
+			Expect(respBody["deleted"]).To(Equal(float64(42)))
+		})
+
+		It("returns 500 when the job fails", func() {
+			retention.RunFunc = func(ctx context.Context) (int64, error) {
+				return 0, errors.New("database error")
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/admin/clicks/purge", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("GetStats", func() {
+		It("returns the aggregate counts", func() {
+			stats.GetSystemStatsFunc = func(ctx context.Context) (*domain.SystemStats, error) {
+				return &domain.SystemStats{
+					TotalLinks:        10,
+					TotalClicks:       250,
+					LinksCreatedToday: 2,
+					ActiveLinks:       7,
+					ExpiredLinks:      2,
+					InactiveLinks:     1,
+				}, nil
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var respBody map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &respBody)).To(Succeed())
+			Expect(respBody["total_links"]).To(Equal(float64(10)))
+			Expect(respBody["total_clicks"]).To(Equal(float64(250)))
+			Expect(respBody["links_created_today"]).To(Equal(float64(2)))
+			Expect(respBody["active_links"]).To(Equal(float64(7)))
+			Expect(respBody["expired_links"]).To(Equal(float64(2)))
+		})
+
+		It("keeps a click total past 2^53 exact", func() {
+			const clicks int64 = 1<<53 + 1
+			stats.GetSystemStatsFunc = func(ctx context.Context) (*domain.SystemStats, error) {
+				return &domain.SystemStats{TotalClicks: clicks}, nil
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var respBody domain.SystemStats
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &respBody)).To(Succeed())
+			Expect(respBody.TotalClicks).To(Equal(clicks))
+		})
+
+		It("returns 500 when the stats cannot be computed", func() {
+			stats.GetSystemStatsFunc = func(ctx context.Context) (*domain.SystemStats, error) {
+				return nil, errors.New("database error")
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("GetLinkHistory", func() {
+		It("returns the audit entries of the link", func() {
+			userID := "alice"
+			history.GetLinkHistoryFunc = func(ctx context.Context, code string) ([]*domain.AuditEntry, error) {
+				Expect(code).To(Equal("abc123"))
+				return []*domain.AuditEntry{
+					{
+						ID:       "entry-1",
+						EntityID: "link-1",
+						Action:   domain.AuditActionUpdate,
+						UserID:   &userID,
+						Before:   &domain.ShortLink{ID: "link-1", IsActive: true},
+						After:    &domain.ShortLink{ID: "link-1", IsActive: false},
+					},
+				}, nil
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/links/abc123/history", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var respBody []map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &respBody)).To(Succeed())
+			Expect(respBody).To(HaveLen(1))
+			Expect(respBody[0]["action"]).To(Equal("update"))
+			Expect(respBody[0]["user_id"]).To(Equal("alice"))
+		})
+
+		It("returns 404 for unknown links", func() {
+			history.GetLinkHistoryFunc = func(ctx context.Context, code string) ([]*domain.AuditEntry, error) {
+				return nil, errors.New("short link not found")
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/links/missing/history", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
+
+// MockClickRetention mocks the ClickRetention interface
+type MockClickRetention struct {
+	RunFunc func(ctx context.Context) (int64, error)
+}
+
+func (m *MockClickRetention) Run(ctx context.Context) (int64, error) {
+	if m.RunFunc != nil {
+		return m.RunFunc(ctx)
+	}
+	return 0, nil
+}
+
+// MockSystemStats mocks the SystemStatsProvider interface
+type MockSystemStats struct {
+	GetSystemStatsFunc func(ctx context.Context) (*domain.SystemStats, error)
+}
+
+func (m *MockSystemStats) GetSystemStats(ctx context.Context) (*domain.SystemStats, error) {
+	if m.GetSystemStatsFunc != nil {
+		return m.GetSystemStatsFunc(ctx)
+	}
+	return &domain.SystemStats{}, nil
+}
+
+// MockLinkHistory mocks the LinkHistory interface
+type MockLinkHistory struct {
+	GetLinkHistoryFunc func(ctx context.Context, code string) ([]*domain.AuditEntry, error)
+}
+
+func (m *MockLinkHistory) GetLinkHistory(ctx context.Context, code string) ([]*domain.AuditEntry, error) {
+	if m.GetLinkHistoryFunc != nil {
+		return m.GetLinkHistoryFunc(ctx, code)
+	}
+	return []*domain.AuditEntry{}, nil
+}
+
+type MockLinkImporter struct {
+	ImportShortLinkFunc func(ctx context.Context, req *domain.ImportShortLinkRequest) (*domain.ShortLink, error)
+}
+
+func (m *MockLinkImporter) ImportShortLink(ctx context.Context, req *domain.ImportShortLinkRequest) (*domain.ShortLink, error) {
+	if m.ImportShortLinkFunc != nil {
+		return m.ImportShortLinkFunc(ctx, req)
+	}
+	return &domain.ShortLink{Code: req.Code}, nil
+}
+
+type MockLinkExporter struct {
+	ExportShortLinksFunc func(ctx context.Context, fn func(link *domain.ShortLink) error) (bool, error)
+}
+
+func (m *MockLinkExporter) ExportShortLinks(ctx context.Context, fn func(link *domain.ShortLink) error) (bool, error) {
+	if m.ExportShortLinksFunc != nil {
+		return m.ExportShortLinksFunc(ctx, fn)
+	}
+	return false, nil
+}
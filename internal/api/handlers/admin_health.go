@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// MaxHealthCheckCodes bounds how many codes a single health check may name
+const MaxHealthCheckCodes = 1000
+
+// CheckLinkHealth handles checking that link destinations still resolve
+// @Summary Check link destinations
+// @Description Probe the destinations of the named links, or of every link when no codes are given, and report each one's status code or error. Results are stored on the links as their last health check. Checking every link stops at the export row cap; truncated reports whether links were left out.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.LinkHealthCheckRequest false "Codes to check; omit to check every link"
+// @Success 200 {object} domain.LinkHealthReport "Per-link results"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 501 {object} map[string]string "Health checks not configured"
+// @Security BearerAuth
+// @Router /admin/links/health-check [post]
+func (h *AdminHandler) CheckLinkHealth(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	if h.health == nil {
+		respondError(c, http.StatusNotImplemented, "Link health checks are not configured")
+		return
+	}
+
+	// An empty body checks every link
+	var req domain.LinkHealthCheckRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSONFields(c, &req); err != nil {
+			logger.Info("Failed to decode request body", zap.Error(err))
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	if len(req.Codes) > MaxHealthCheckCodes {
+		verr := &domain.ValidationError{}
+		verr.Add("codes", fmt.Sprintf("must not name more than %d codes", MaxHealthCheckCodes))
+		respondValidationError(c, verr)
+		return
+	}
+
+	report, err := h.health.CheckLinkHealth(c.Request.Context(), req.Codes)
+	if err != nil {
+		logger.Error("Failed to check link health", zap.Int("codes", len(req.Codes)), zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to check link health")
+		return
+	}
+
+	logger.Info("Checked link health",
+		zap.Int("healthy", report.Healthy),
+		zap.Int("unhealthy", report.Unhealthy),
+		zap.Int("not_found", len(report.NotFound)),
+	)
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler health check", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		checker  *MockLinkHealthChecker
+		checked  [][]string
+	)
+
+	checkedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		checked = nil
+
+		checker = &MockLinkHealthChecker{
+			CheckLinkHealthFunc: func(ctx context.Context, codes []string) (*domain.LinkHealthReport, error) {
+				checked = append(checked, codes)
+				return &domain.LinkHealthReport{
+					Results: []*domain.LinkHealthResult{
+						{Code: "ok", URL: "https://example.com/a", LinkHealth: domain.LinkHealth{StatusCode: 200, CheckedAt: checkedAt}},
+						{Code: "gone", URL: "https://example.com/b", LinkHealth: domain.LinkHealth{
+							StatusCode: 404, Error: "destination responded with status 404", CheckedAt: checkedAt,
+						}},
+					},
+					Healthy:   1,
+					Unhealthy: 1,
+					NotFound:  []string{"missing"},
+				}, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, &MockLinkImporter{}, &MockLinkExporter{}, checker, nil, nil)
+		router.POST("/api/admin/links/health-check", handler.CheckLinkHealth)
+	})
+
+	request := func(body string) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/admin/links/health-check", strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("checks the named codes and reports each link's result", func() {
+		request(`{"codes": ["ok", "gone", "missing"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(checked).To(Equal([][]string{{"ok", "gone", "missing"}}))
+
+		var report domain.LinkHealthReport
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &report)).To(Succeed())
+		Expect(report.Healthy).To(Equal(1))
+		Expect(report.Unhealthy).To(Equal(1))
+		Expect(report.NotFound).To(Equal([]string{"missing"}))
+		Expect(report.Results).To(HaveLen(2))
+		Expect(report.Results[1].Code).To(Equal("gone"))
+		Expect(report.Results[1].StatusCode).To(Equal(404))
+		Expect(report.Results[1].Error).To(Equal("destination responded with status 404"))
+		Expect(report.Results[1].CheckedAt).To(Equal(checkedAt))
+	})
+
+	It("checks every link when the body is empty", func() {
+		request("")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(checked).To(HaveLen(1))
+		Expect(checked[0]).To(BeEmpty())
+	})
+
+	It("rejects too many codes", func() {
+		codes, _ := json.Marshal(map[string][]string{"codes": make([]string, handlers.MaxHealthCheckCodes+1)})
+		request(string(codes))
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(checked).To(BeEmpty())
+	})
+
+	It("rejects a body that is not JSON", func() {
+		request(`codes=ok`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(checked).To(BeEmpty())
+	})
+
+	It("returns 500 when the check fails", func() {
+		checker.CheckLinkHealthFunc = func(ctx context.Context, codes []string) (*domain.LinkHealthReport, error) {
+			return nil, errors.New("database error")
+		}
+
+		request("")
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("returns 501 when no checker is configured", func() {
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, &MockLinkImporter{}, &MockLinkExporter{}, nil, nil, nil)
+		router = gin.New()
+		router.POST("/api/admin/links/health-check", handler.CheckLinkHealth)
+
+		request("")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+	})
+})
+
+// MockLinkHealthChecker mocks the LinkHealthChecker interface
+type MockLinkHealthChecker struct {
+	CheckLinkHealthFunc func(ctx context.Context, codes []string) (*domain.LinkHealthReport, error)
+}
+
+func (m *MockLinkHealthChecker) CheckLinkHealth(ctx context.Context, codes []string) (*domain.LinkHealthReport, error) {
+	if m.CheckLinkHealthFunc != nil {
+		return m.CheckLinkHealthFunc(ctx, codes)
+	}
+	return &domain.LinkHealthReport{}, nil
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// MaxImportRows caps the data rows accepted by a single import
+const MaxImportRows = 10000
+
+// errImportTooLarge is returned for a CSV with more than MaxImportRows data rows
+var errImportTooLarge = errors.New("too many import rows")
+
+// Per-row import outcomes
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+// ImportRowResult reports what happened to one CSV row
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Code   string `json:"code,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ImportLinks handles bulk-loading links from another shortener
+// @Summary Import links from CSV
+// @Description Create links that keep their original codes from a CSV with the columns code, original_url and optionally expiration (RFC 3339 or YYYY-MM-DD) and custom_alias. A header row is optional. Rows whose code is taken are skipped; each row's outcome is reported.
+// @Tags admin
+// @Accept text/csv,multipart/form-data
+// @Produce json
+// @Param file formData file false "CSV file, when uploading as multipart/form-data"
+// @Success 200 {object} handlers.ImportResult "Per-row results"
+// @Failure 400 {object} map[string]string "Unreadable CSV or more than 10000 rows; nothing is imported"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Security BearerAuth
+// @Router /admin/import [post]
+func (h *AdminHandler) ImportLinks(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	body, err := importBody(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer body.Close()
+
+	records, err := readImportRecords(body)
+	if errors.Is(err, errImportTooLarge) {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("Import must not exceed %d rows", MaxImportRows))
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("Invalid CSV: %v", err))
+		return
+	}
+
+	result := ImportResult{Rows: []ImportRowResult{}}
+	for _, record := range records {
+		row := h.importRow(c, record.line, record.fields)
+		switch row.Status {
+		case ImportCreated:
+			result.Created++
+		case ImportSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	logger.Info("Imported links",
+		zap.Int("created", result.Created),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", result.Failed))
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importRecord is a CSV data row and the line it was read from
+type importRecord struct {
+	line   int
+	fields []string
+}
+
+// readImportRecords reads every data row of an import before any link is
+// created, so an unreadable or oversized CSV is refused without leaving a
+// partial import behind
+func readImportRecords(body io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []importRecord
+	for line := 1; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && isImportHeader(fields) {
+			continue
+		}
+
+		if len(records) == MaxImportRows {
+			return nil, errImportTooLarge
+		}
+		records = append(records, importRecord{line: line, fields: fields})
+	}
+}
+
+// importRow creates the link described by one CSV record
+func (h *AdminHandler) importRow(c *gin.Context, line int, record []string) ImportRowResult {
+	row := ImportRowResult{Row: line, Status: ImportFailed}
+
+	if len(record) < 2 || len(record) > 4 {
+		row.Error = "expected code, original_url and optionally expiration and custom_alias"
+		return row
+	}
+
+	req := &domain.ImportShortLinkRequest{
+		Code: strings.TrimSpace(record[0]),
+		URL:  strings.TrimSpace(record[1]),
+	}
+	row.Code = req.Code
+
+	if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+		expiration, err := parseFilterTime(strings.TrimSpace(record[2]))
+		if err != nil {
+			row.Error = "expiration must be an RFC 3339 timestamp or a YYYY-MM-DD date"
+			return row
+		}
+		req.ExpirationDate = &expiration
+	}
+
+	if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+		alias := strings.TrimSpace(record[3])
+		req.CustomAlias = &alias
+	}
+
+	if _, err := h.importer.ImportShortLink(c.Request.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrConflict):
+			row.Status = ImportSkipped
+			row.Error = "code or alias already in use"
+		case errors.Is(err, domain.ErrValidation):
+			row.Error = strings.TrimSuffix(err.Error(), ": "+domain.ErrValidation.Error())
+		default:
+			middleware.GetLogger(c).Error("Failed to import link", zap.String("code", req.Code), zap.Error(err))
+			row.Error = "failed to create link"
+		}
+		return row
+	}
+
+	row.Status = ImportCreated
+	return row
+}
+
+// importBody returns the uploaded CSV, sent either as the "file" field of a
+// multipart form or as the raw request body
+func importBody(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return c.Request.Body, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("a CSV file is required in the file field")
+	}
+
+	return header.Open()
+}
+
+// isImportHeader reports whether the first record is a header row, ignoring
+// the byte order mark spreadsheet exports often start with
+func isImportHeader(record []string) bool {
+	return len(record) > 0 && strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff")), "code")
+}
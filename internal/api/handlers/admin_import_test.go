@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("AdminHandler import", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		imported []*domain.ImportShortLinkRequest
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		imported = nil
+
+		importer := &MockLinkImporter{
+			ImportShortLinkFunc: func(ctx context.Context, req *domain.ImportShortLinkRequest) (*domain.ShortLink, error) {
+				if req.Code == "taken" {
+					return nil, fmt.Errorf("code already in use: %w", domain.ErrConflict)
+				}
+				if !strings.HasPrefix(req.URL, "http") {
+					return nil, fmt.Errorf("invalid URL: URL must use HTTP or HTTPS scheme: %w", domain.ErrValidation)
+				}
+				imported = append(imported, req)
+				return &domain.ShortLink{Code: req.Code}, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(&MockClickRetention{}, &MockSystemStats{}, &MockLinkHistory{}, importer, &MockLinkExporter{}, nil, nil, nil)
+		router.POST("/api/admin/import", handler.ImportLinks)
+	})
+
+	importCSV := func(body string) handlers.ImportResult {
+		req, _ := http.NewRequest(http.MethodPost, "/api/admin/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		router.ServeHTTP(recorder, req)
+
+		var result handlers.ImportResult
+		if recorder.Code == http.StatusOK {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &result)).To(Succeed())
+		}
+		return result
+	}
+
+	It("creates every row of a clean import with the original codes", func() {
+		result := importCSV("code,original_url,expiration,custom_alias\n" +
+			"abc123,https://example.com/a\n" +
+			"promo,https://example.com/b,2030-01-31,summer-sale\n" +
+			"old1,https://example.com/c,2030-06-01T12:00:00Z,\n")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(result.Created).To(Equal(3))
+		Expect(result.Skipped).To(BeZero())
+		Expect(result.Failed).To(BeZero())
+		Expect(result.Rows).To(HaveLen(3))
+		Expect(result.Rows[0]).To(Equal(handlers.ImportRowResult{Row: 2, Code: "abc123", Status: handlers.ImportCreated}))
+
+		Expect(imported).To(HaveLen(3))
+		Expect(imported[0].Code).To(Equal("abc123"))
+		Expect(imported[0].URL).To(Equal("https://example.com/a"))
+		Expect(imported[0].ExpirationDate).To(BeNil())
+		Expect(imported[0].CustomAlias).To(BeNil())
+		Expect(imported[1].ExpirationDate).To(HaveValue(BeTemporally("==", time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC))))
+		Expect(imported[1].CustomAlias).To(HaveValue(Equal("summer-sale")))
+		Expect(imported[2].ExpirationDate).To(HaveValue(BeTemporally("==", time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC))))
+		Expect(imported[2].CustomAlias).To(BeNil())
+	})
+
+	It("skips rows whose code is already taken and imports the rest", func() {
+		result := importCSV("abc123,https://example.com/a\n" +
+			"taken,https://example.com/b\n" +
+			"xyz789,https://example.com/c\n")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(result.Created).To(Equal(2))
+		Expect(result.Skipped).To(Equal(1))
+		Expect(result.Rows[1]).To(Equal(handlers.ImportRowResult{
+			Row:    2,
+			Code:   "taken",
+			Status: handlers.ImportSkipped,
+			Error:  "code or alias already in use",
+		}))
+		Expect(imported).To(HaveLen(2))
+	})
+
+	It("reports invalid rows without stopping the import", func() {
+		result := importCSV("abc123\n" +
+			"bad-url,ftp://example.com\n" +
+			"bad-date,https://example.com,next week\n" +
+			"ok,https://example.com\n")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(result.Created).To(Equal(1))
+		Expect(result.Failed).To(Equal(3))
+		Expect(result.Rows[0].Error).To(ContainSubstring("expected code, original_url"))
+		Expect(result.Rows[1].Error).To(Equal("invalid URL: URL must use HTTP or HTTPS scheme"))
+		Expect(result.Rows[2].Error).To(ContainSubstring("expiration must be"))
+	})
+
+	It("accepts the CSV as a multipart upload", func() {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "links.csv")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = part.Write([]byte("abc123,https://example.com/a\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(form.Close()).To(Succeed())
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/admin/import", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(imported).To(HaveLen(1))
+	})
+
+	It("refuses an import over the row limit without creating any link", func() {
+		var body strings.Builder
+		body.WriteString("code,original_url\n")
+		for i := 0; i <= handlers.MaxImportRows; i++ {
+			fmt.Fprintf(&body, "code%d,https://example.com/%d\n", i, i)
+		}
+
+		importCSV(body.String())
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("must not exceed 10000 rows"))
+		Expect(imported).To(BeEmpty())
+	})
+
+	It("imports exactly as many rows as the limit allows", func() {
+		var body strings.Builder
+		for i := 0; i < handlers.MaxImportRows; i++ {
+			fmt.Fprintf(&body, "code%d,https://example.com/%d\n", i, i)
+		}
+
+		result := importCSV(body.String())
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(result.Created).To(Equal(handlers.MaxImportRows))
+	})
+
+	It("refuses CSV that turns malformed after valid rows without creating any link", func() {
+		importCSV("abc123,https://example.com/a\ndef456,\"https://example.com\n")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(imported).To(BeEmpty())
+	})
+
+	It("rejects malformed CSV", func() {
+		importCSV("abc123,\"https://example.com\n")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("Invalid CSV"))
+	})
+})
@@ -10,7 +10,7 @@ import (
 // AuthService defines the interface for authentication operations
 type AuthService interface {
 	ValidateMasterPassword(password string) bool
-	GenerateToken() (string, error)
+	GenerateToken(userID string) (string, error)
 }
 
 // AuthHandler handles authentication-related routes
@@ -28,6 +28,7 @@ func NewAuthHandler(authService AuthService) *AuthHandler {
 // TokenRequest represents the token request payload
 type TokenRequest struct {
 	MasterPassword string `json:"master_password" binding:"required" example:"your_master_password"`
+	UserID         string `json:"user_id,omitempty" example:"alice"`
 }
 
 // TokenResponse represents the token response
@@ -54,22 +55,22 @@ func (h *AuthHandler) GenerateToken(c *gin.Context) {
 	var req TokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Info("Failed to decode request body", zap.Error(err))
-		c.JSON(400, gin.H{"error": "Invalid request"})
+		respondError(c, 400, "Invalid request")
 		return
 	}
 
 	// Validate master password
 	if !h.authService.ValidateMasterPassword(req.MasterPassword) {
 		logger.Info("Invalid master password")
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+		respondError(c, 401, "Unauthorized")
 		return
 	}
 
 	// Generate token
-	token, err := h.authService.GenerateToken()
+	token, err := h.authService.GenerateToken(req.UserID)
 	if err != nil {
 		logger.Error("Failed to generate token", zap.Error(err))
-		c.JSON(500, gin.H{"error": "Internal server error"})
+		respondError(c, 500, "Internal server error")
 		return
 	}
 
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// CollectionService defines the interface for managing the caller's link collections
+type CollectionService interface {
+	CreateCollection(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error)
+	GetCollection(ctx context.Context, id string) (*domain.Collection, error)
+	ListCollections(ctx context.Context) ([]*domain.Collection, error)
+	UpdateCollection(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error)
+	DeleteCollection(ctx context.Context, id string) error
+}
+
+// CollectionHandler handles link collection routes
+type CollectionHandler struct {
+	collections CollectionService
+}
+
+// NewCollectionHandler creates a new collection handler
+func NewCollectionHandler(collections CollectionService) *CollectionHandler {
+	return &CollectionHandler{
+		collections: collections,
+	}
+}
+
+// respondCollectionError maps a collection service error to a response
+func respondCollectionError(c *gin.Context, err error, message string) {
+	var verr *domain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		respondValidationError(c, verr)
+	case errors.Is(err, domain.ErrNotFound):
+		respondError(c, http.StatusNotFound, "Collection not found")
+	case errors.Is(err, domain.ErrConflict):
+		respondError(c, http.StatusConflict, "Collection name already in use")
+	default:
+		middleware.GetLogger(c).Error(message, zap.Error(err))
+		respondError(c, http.StatusInternalServerError, message)
+	}
+}
+
+// CreateCollection handles creating a collection
+// @Summary Create a collection
+// @Description Create a named folder for grouping links, owned by the token's user
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param request body domain.CollectionRequest true "Collection name"
+// @Success 201 {object} domain.Collection "Collection created"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Collection name already in use"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /collections [post]
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	var req domain.CollectionRequest
+	if err := bindJSONFields(c, &req); err != nil {
+		logger.Info("Failed to decode request body", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	collection, err := h.collections.CreateCollection(c.Request.Context(), &req)
+	if err != nil {
+		respondCollectionError(c, err, "Failed to create collection")
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// ListCollections handles listing the caller's collections
+// @Summary List collections
+// @Description List the collections owned by the token's user, ordered by name
+// @Tags collections
+// @Produce json
+// @Success 200 {object} ListResponse "Collections"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /collections [get]
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	collections, err := h.collections.ListCollections(c.Request.Context())
+	if err != nil {
+		respondCollectionError(c, err, "Failed to list collections")
+		return
+	}
+
+	// Collections aren't paginated, so the whole list is a single page
+	respondList(c, collections, newPageMeta(c, "", len(collections), 1, len(collections)))
+}
+
+// GetCollection handles retrieving one of the caller's collections
+// @Summary Get a collection
+// @Description Get a collection owned by the token's user; list its links with GET /links?collection={id}
+// @Tags collections
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} domain.Collection "Collection"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Collection not found"
+// @Security BearerAuth
+// @Router /collections/{id} [get]
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	collection, err := h.collections.GetCollection(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCollectionError(c, err, "Failed to get collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection handles renaming one of the caller's collections
+// @Summary Rename a collection
+// @Description Rename a collection owned by the token's user
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body domain.CollectionRequest true "New collection name"
+// @Success 200 {object} domain.Collection "Collection renamed"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Collection not found"
+// @Failure 409 {object} map[string]string "Collection name already in use"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
+// @Security BearerAuth
+// @Router /collections/{id} [put]
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	var req domain.CollectionRequest
+	if err := bindJSONFields(c, &req); err != nil {
+		logger.Info("Failed to decode request body", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	collection, err := h.collections.UpdateCollection(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondCollectionError(c, err, "Failed to update collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection handles deleting one of the caller's collections
+// @Summary Delete a collection
+// @Description Delete a collection owned by the token's user; its links are kept outside any collection
+// @Tags collections
+// @Param id path string true "Collection ID"
+// @Success 204 "Collection deleted"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Collection not found"
+// @Security BearerAuth
+// @Router /collections/{id} [delete]
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	if err := h.collections.DeleteCollection(c.Request.Context(), c.Param("id")); err != nil {
+		respondCollectionError(c, err, "Failed to delete collection")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("CollectionHandler", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		svc      *MockCollectionService
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		svc = &MockCollectionService{}
+
+		handler := handlers.NewCollectionHandler(svc)
+		router.GET("/api/collections", handler.ListCollections)
+		router.POST("/api/collections", handler.CreateCollection)
+		router.GET("/api/collections/:id", handler.GetCollection)
+		router.PUT("/api/collections/:id", handler.UpdateCollection)
+		router.DELETE("/api/collections/:id", handler.DeleteCollection)
+	})
+
+	request := func(method, path, body string) {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("creates a collection", func() {
+		svc.CreateCollectionFunc = func(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error) {
+			return &domain.Collection{ID: "col-1", OwnerID: "alice", Name: req.Name}, nil
+		}
+
+		request(http.MethodPost, "/api/collections", `{"name": "Campaigns"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		var collection domain.Collection
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &collection)).To(Succeed())
+		Expect(collection.ID).To(Equal("col-1"))
+		Expect(collection.Name).To(Equal("Campaigns"))
+	})
+
+	It("reports rejected names and taken names", func() {
+		svc.CreateCollectionFunc = func(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error) {
+			if req.Name == "" {
+				verr := &domain.ValidationError{}
+				verr.Add("name", "name is required")
+				return nil, verr.Err()
+			}
+			return nil, fmt.Errorf("collection name already in use: %w", domain.ErrConflict)
+		}
+
+		request(http.MethodPost, "/api/collections", `{"name": ""}`)
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+
+		recorder = httptest.NewRecorder()
+		request(http.MethodPost, "/api/collections", `{"name": "Campaigns"}`)
+		Expect(recorder.Code).To(Equal(http.StatusConflict))
+	})
+
+	It("lists the caller's collections", func() {
+		svc.ListCollectionsFunc = func(ctx context.Context) ([]*domain.Collection, error) {
+			return []*domain.Collection{{ID: "col-1", Name: "Campaigns"}}, nil
+		}
+
+		request(http.MethodGet, "/api/collections", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		var body struct {
+			Collections []*domain.Collection `json:"data"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Collections).To(HaveLen(1))
+	})
+
+	It("renames a collection", func() {
+		svc.UpdateCollectionFunc = func(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error) {
+			return &domain.Collection{ID: id, Name: req.Name}, nil
+		}
+
+		request(http.MethodPut, "/api/collections/col-1", `{"name": "Archive"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"name":"Archive"`))
+	})
+
+	It("deletes a collection", func() {
+		var deleted string
+		svc.DeleteCollectionFunc = func(ctx context.Context, id string) error {
+			deleted = id
+			return nil
+		}
+
+		request(http.MethodDelete, "/api/collections/col-1", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(deleted).To(Equal("col-1"))
+	})
+
+	DescribeTable("answers 404 for collections the caller doesn't own",
+		func(method, body string) {
+			notFound := fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+			svc.GetCollectionFunc = func(ctx context.Context, id string) (*domain.Collection, error) { return nil, notFound }
+			svc.UpdateCollectionFunc = func(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error) {
+				return nil, notFound
+			}
+			svc.DeleteCollectionFunc = func(ctx context.Context, id string) error { return notFound }
+
+			request(method, "/api/collections/col-2", body)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		},
+		Entry("get", http.MethodGet, ""),
+		Entry("rename", http.MethodPut, `{"name": "Mine"}`),
+		Entry("delete", http.MethodDelete, ""),
+	)
+})
+
+// MockCollectionService mocks the CollectionService interface
+type MockCollectionService struct {
+	CreateCollectionFunc func(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error)
+	GetCollectionFunc    func(ctx context.Context, id string) (*domain.Collection, error)
+	ListCollectionsFunc  func(ctx context.Context) ([]*domain.Collection, error)
+	UpdateCollectionFunc func(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error)
+	DeleteCollectionFunc func(ctx context.Context, id string) error
+}
+
+func (m *MockCollectionService) CreateCollection(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error) {
+	if m.CreateCollectionFunc != nil {
+		return m.CreateCollectionFunc(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *MockCollectionService) GetCollection(ctx context.Context, id string) (*domain.Collection, error) {
+	if m.GetCollectionFunc != nil {
+		return m.GetCollectionFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCollectionService) ListCollections(ctx context.Context) ([]*domain.Collection, error) {
+	if m.ListCollectionsFunc != nil {
+		return m.ListCollectionsFunc(ctx)
+	}
+	return []*domain.Collection{}, nil
+}
+
+func (m *MockCollectionService) UpdateCollection(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error) {
+	if m.UpdateCollectionFunc != nil {
+		return m.UpdateCollectionFunc(ctx, id, req)
+	}
+	return nil, nil
+}
+
+func (m *MockCollectionService) DeleteCollection(ctx context.Context, id string) error {
+	if m.DeleteCollectionFunc != nil {
+		return m.DeleteCollectionFunc(ctx, id)
+	}
+	return nil
+}
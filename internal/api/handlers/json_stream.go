@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonArrayStream writes the elements of a JSON array one at a time, so
+// large lists are sent as they are produced instead of being marshaled as
+// a whole. The caller writes the opening and closing brackets around them.
+type jsonArrayStream struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int
+}
+
+// newJSONArrayStream creates a stream writing array elements to w
+func newJSONArrayStream(w io.Writer) *jsonArrayStream {
+	return &jsonArrayStream{w: w, enc: json.NewEncoder(w)}
+}
+
+// write appends v to the array
+func (s *jsonArrayStream) write(v interface{}) error {
+	if s.count > 0 {
+		if _, err := io.WriteString(s.w, ","); err != nil {
+			return err
+		}
+	}
+	s.count++
+
+	return s.enc.Encode(v)
+}
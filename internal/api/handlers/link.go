@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,8 +15,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/api/pages"
+	"github.com/menezmethod/ref_go/internal/config"
 	"github.com/menezmethod/ref_go/internal/domain"
-	"github.com/menezmethod/ref_go/internal/metrics"
 )
 
 // LinkService defines the interface for link-related operations
@@ -22,23 +28,176 @@ type LinkService interface {
 	UpdateShortLink(ctx context.Context, id string, req *domain.UpdateShortLinkRequest) (*domain.ShortLink, error)
 	DeleteShortLink(ctx context.Context, id string) error
 	ListShortLinks(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error)
-	RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress string) error
-	GetLinkStats(ctx context.Context, shortLinkID string) (*domain.LinkStats, error)
+	ListShortLinksFiltered(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error)
+	ListShortLinksAfter(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error)
+	RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error
+	GetLinkStats(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error)
+	GetLinkStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error)
+	GetLinkClick(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error)
+}
+
+// LinkListStreamer hands over a page of links as they are read rather than
+// as a slice, e.g. *service.URLShortenerService. begin receives the total
+// number of links before the first one.
+type LinkListStreamer interface {
+	StreamShortLinks(ctx context.Context, page, pageSize int, begin func(total int) error, fn func(link *domain.ShortLink) error) error
+}
+
+// MetricsRecorder receives the metrics the link handler observes, e.g.
+// *metrics.Metrics or an adapter for another metrics backend
+type MetricsRecorder interface {
+	RecordRedirect(linkID string)
+}
+
+// NopMetricsRecorder discards every metric
+type NopMetricsRecorder struct{}
+
+// RecordRedirect does nothing
+func (NopMetricsRecorder) RecordRedirect(linkID string) {}
+
+// Not found behaviors for unknown, inactive or expired redirect codes
+const (
+	NotFoundPage     = "page"
+	NotFoundRedirect = "redirect"
+)
+
+// Root path behaviors
+const (
+	RootNotFound = "not_found"
+	RootRedirect = "redirect"
+	RootStatus   = "status"
+)
+
+// How destination URLs appear in redirect logs
+const (
+	LogDestinationsFull = "full"
+	LogDestinationsHash = "hash"
+	LogDestinationsOmit = "omit"
+)
+
+// DefaultMaxCodeLength is the longest redirect code looked up when none is
+// configured, matching the longest code an import accepts
+const DefaultMaxCodeLength = 64
+
+// DefaultAPIPrefix is the path the API routes are mounted under when none
+// is configured
+const DefaultAPIPrefix = "/api"
+
+// DefaultPreviewCacheMaxAge bounds how long a preview page is cached. Links
+// can be edited or deactivated, so previews are only cached briefly.
+const DefaultPreviewCacheMaxAge = 5 * time.Minute
+
+// LinkHandlerOptions configures optional link handler behavior
+type LinkHandlerOptions struct {
+	// DefaultLocale is used for HTML pages when the Accept-Language header
+	// names no supported locale
+	DefaultLocale string
+
+	// BrandName is shown on the HTML pages
+	BrandName string
+
+	// NotFoundMode is NotFoundPage (default) or NotFoundRedirect, which sends
+	// visitors of dead links to NotFoundRedirectURL instead
+	NotFoundMode        string
+	NotFoundRedirectURL string
+
+	// ExpiredStatus is the status expired links answer with. Zero or 404
+	// treats them like any other dead link unless ExpiredMessage is set;
+	// 410 always renders the expired page so clients can tell them apart.
+	ExpiredStatus  int
+	ExpiredMessage string
+
+	// RootMode is what GET / answers with: RootNotFound (default) renders
+	// the not found page, RootRedirect sends visitors to RootRedirectURL and
+	// RootStatus renders a short status page
+	RootMode        string
+	RootRedirectURL string
+
+	// MaxPageSize caps page_size on list endpoints; zero uses DefaultMaxPageSize
+	MaxPageSize int
+
+	// MaxCodeLength is the longest code redirect and preview requests look
+	// up; longer ones are answered as not found. Zero uses DefaultMaxCodeLength.
+	MaxCodeLength int
+
+	// PreviewCacheMaxAge is how long shared caches may keep a preview page;
+	// zero uses DefaultPreviewCacheMaxAge
+	PreviewCacheMaxAge time.Duration
+
+	// Features gates optional endpoints; disabled ones answer 404
+	Features config.FeaturesConfig
+
+	// AllowedSchemes lists the lowercase destination schemes visitors may be
+	// sent to; empty allows only http and https
+	AllowedSchemes []string
+
+	// ForwardQueryParams names the query parameters of a redirect request
+	// passed on to web destinations; ForwardAllQueryParams passes all of
+	// them and empty passes none
+	ForwardQueryParams []string
+
+	// StatsLocation is the time zone link stats are bucketed by when the
+	// request names none with ?tz=; nil means UTC
+	StatsLocation *time.Location
+
+	// LogDestinations is how destination URLs appear in redirect and
+	// preview logs: LogDestinationsFull (default), LogDestinationsHash or
+	// LogDestinationsOmit
+	LogDestinations string
+
+	// APIPrefix is the path the API routes are mounted under; pattern links
+	// never resolve below it and stats URLs point into it. Empty uses
+	// DefaultAPIPrefix.
+	APIPrefix string
+
+	// DisplayCode formats a link's code for showing in responses, such as
+	// abc-123 for abc123; the formatted code must resolve to the same link.
+	// Nil shows codes as stored.
+	DisplayCode func(*domain.ShortLink) string
+
+	// ListStreamer serves unfiltered offset pages of the link list, writing
+	// each link as it is read so large pages don't build up in memory; nil
+	// lists them through the link service
+	ListStreamer LinkListStreamer
 }
 
 // LinkHandler handles link-related routes
 type LinkHandler struct {
 	linkService LinkService
 	baseURL     string
-	metrics     *metrics.Metrics
+	metrics     MetricsRecorder
+	pages       *pages.Renderer
+	opts        LinkHandlerOptions
 }
 
-// NewLinkHandler creates a new link handler
-func NewLinkHandler(linkService LinkService, baseURL string, metrics *metrics.Metrics) *LinkHandler {
+// NewLinkHandler creates a new link handler; a nil metrics recorder
+// discards metrics
+func NewLinkHandler(linkService LinkService, baseURL string, metrics MetricsRecorder) *LinkHandler {
+	return NewLinkHandlerWithOptions(linkService, baseURL, metrics, LinkHandlerOptions{
+		Features: config.DefaultFeatures(),
+	})
+}
+
+// NewLinkHandlerWithOptions creates a new link handler with optional behavior
+func NewLinkHandlerWithOptions(
+	linkService LinkService,
+	baseURL string,
+	metrics MetricsRecorder,
+	opts LinkHandlerOptions,
+) *LinkHandler {
+	if opts.DefaultLocale == "" {
+		opts.DefaultLocale = pages.DefaultLocale
+	}
+	if metrics == nil {
+		metrics = NopMetricsRecorder{}
+	}
+
 	return &LinkHandler{
 		linkService: linkService,
 		baseURL:     baseURL,
 		metrics:     metrics,
+		pages:       pages.MustNew(opts.DefaultLocale),
+		opts:        opts,
 	}
 }
 
@@ -50,9 +209,14 @@ func NewLinkHandler(linkService LinkService, baseURL string, metrics *metrics.Me
 // @Produce json
 // @Param request body domain.CreateShortLinkRequest true "Link creation request"
 // @Success 201 {object} domain.ShortLink "Link created successfully"
+// @Success 200 {object} domain.ShortLink "Existing link returned for reuse_existing"
 // @Failure 400 {object} map[string]string "Invalid request or URL"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 402 {object} map[string]string "Link quota reached"
+// @Failure 409 {object} map[string]string "Custom alias already in use"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
 // @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "No unique code could be generated"
 // @Security BearerAuth
 // @Router /links [post]
 func (h *LinkHandler) CreateLink(c *gin.Context) {
@@ -60,9 +224,9 @@ func (h *LinkHandler) CreateLink(c *gin.Context) {
 
 	// Parse request body
 	var req domain.CreateShortLinkRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSONFields(c, &req); err != nil {
 		logger.Info("Failed to decode request body", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondBindError(c, err)
 		return
 	}
 
@@ -70,12 +234,16 @@ func (h *LinkHandler) CreateLink(c *gin.Context) {
 	link, err := h.linkService.CreateShortLink(c.Request.Context(), &req)
 	if err != nil {
 		logger.Info("Failed to create short link", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, "Failed to create link")
 		return
 	}
 
 	// Return response
-	c.JSON(http.StatusCreated, link)
+	status := http.StatusCreated
+	if link.Reused {
+		status = http.StatusOK
+	}
+	c.JSON(status, h.linkResponse(link))
 }
 
 // GetLink handles link retrieval
@@ -97,7 +265,7 @@ func (h *LinkHandler) GetLink(c *gin.Context) {
 	// Extract code from URL
 	code := c.Param("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Link code is required"})
+		respondError(c, http.StatusBadRequest, "Link code is required")
 		return
 	}
 
@@ -105,12 +273,12 @@ func (h *LinkHandler) GetLink(c *gin.Context) {
 	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
 	if err != nil {
 		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		respondError(c, http.StatusNotFound, "Link not found")
 		return
 	}
 
 	// Return response
-	c.JSON(http.StatusOK, link)
+	c.JSON(http.StatusOK, h.linkResponse(link))
 }
 
 // UpdateLink handles link updates
@@ -123,8 +291,11 @@ func (h *LinkHandler) GetLink(c *gin.Context) {
 // @Param request body domain.UpdateShortLinkRequest true "Update request"
 // @Success 200 {object} domain.ShortLink "Updated link"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Link not found"
+// @Failure 409 {object} map[string]string "Custom alias already in use"
+// @Failure 500 {object} map[string]string "Internal server error"
 // @Security BearerAuth
 // @Router /links/{code} [put]
 func (h *LinkHandler) UpdateLink(c *gin.Context) {
@@ -133,7 +304,7 @@ func (h *LinkHandler) UpdateLink(c *gin.Context) {
 	// Extract code from URL
 	code := c.Param("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Link code is required"})
+		respondError(c, http.StatusBadRequest, "Link code is required")
 		return
 	}
 
@@ -141,15 +312,15 @@ func (h *LinkHandler) UpdateLink(c *gin.Context) {
 	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
 	if err != nil {
 		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		respondError(c, http.StatusNotFound, "Link not found")
 		return
 	}
 
 	// Parse request body
 	var req domain.UpdateShortLinkRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSONFields(c, &req); err != nil {
 		logger.Info("Failed to decode request body", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondBindError(c, err)
 		return
 	}
 
@@ -157,7 +328,7 @@ func (h *LinkHandler) UpdateLink(c *gin.Context) {
 	updatedLink, err := h.linkService.UpdateShortLink(c.Request.Context(), link.ID, &req)
 	if err != nil {
 		logger.Info("Failed to update short link", zap.String("id", link.ID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, "Failed to update link")
 		return
 	}
 
@@ -184,7 +355,7 @@ func (h *LinkHandler) DeleteLink(c *gin.Context) {
 	// Extract code from URL
 	code := c.Param("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Link code is required"})
+		respondError(c, http.StatusBadRequest, "Link code is required")
 		return
 	}
 
@@ -192,14 +363,14 @@ func (h *LinkHandler) DeleteLink(c *gin.Context) {
 	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
 	if err != nil {
 		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		respondError(c, http.StatusNotFound, "Link not found")
 		return
 	}
 
 	// Delete link using its ID
 	if err := h.linkService.DeleteShortLink(c.Request.Context(), link.ID); err != nil {
 		logger.Info("Failed to delete short link", zap.String("id", link.ID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete link"})
+		respondError(c, http.StatusInternalServerError, "Failed to delete link")
 		return
 	}
 
@@ -223,46 +394,89 @@ func (h *LinkHandler) ListLinks(c *gin.Context) {
 		}
 	}
 
-	pageSize := 10
-	if pageSizeStr != "" {
-		var err error
-		pageSize, err = strconv.Atoi(pageSizeStr)
-		if err != nil || pageSize < 1 || pageSize > 100 {
-			pageSize = 10
+	pageSize, err := parsePageSize(pageSizeStr, h.opts.MaxPageSize)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseLinkFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Keyset pagination when a cursor is passed, even an empty one for the first page
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if !filter.IsZero() {
+			respondError(c, http.StatusBadRequest, "Filters cannot be combined with cursor pagination")
+			return
 		}
+		h.listLinksByCursor(c, cursor, pageSize)
+		return
+	}
+
+	if filter.IsZero() && h.opts.ListStreamer != nil {
+		h.streamLinks(c, page, pageSize)
+		return
 	}
 
 	// Get links
-	links, total, err := h.linkService.ListShortLinks(c.Request.Context(), page, pageSize)
+	var links []*domain.ShortLink
+	var total int
+	if filter.IsZero() {
+		links, total, err = h.linkService.ListShortLinks(c.Request.Context(), page, pageSize)
+	} else {
+		links, total, err = h.linkService.ListShortLinksFiltered(c.Request.Context(), filter, page, pageSize)
+	}
 	if err != nil {
+		if errors.Is(err, domain.ErrValidation) {
+			respondError(c, http.StatusBadRequest, "Invalid filter")
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "Collection not found")
+			return
+		}
 		logger.Error("Failed to list short links", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list links"})
-		return
-	}
-
-	// Prepare response
-	response := struct {
-		Links []*domain.ShortLink `json:"links"`
-		Meta  struct {
-			Total   int `json:"total"`
-			Page    int `json:"page"`
-			PerPage int `json:"per_page"`
-		} `json:"meta"`
-	}{
-		Links: links,
-		Meta: struct {
-			Total   int `json:"total"`
-			Page    int `json:"page"`
-			PerPage int `json:"per_page"`
-		}{
-			Total:   total,
-			Page:    page,
-			PerPage: pageSize,
-		},
+		respondError(c, http.StatusInternalServerError, "Failed to list links")
+		return
 	}
 
-	// Return response
-	c.JSON(http.StatusOK, response)
+	meta := newPageMeta(c, h.baseURL, total, page, pageSize)
+	setLinkHeader(c, meta.Next, meta.Prev)
+
+	respondList(c, links, meta)
+}
+
+// listLinksByCursor responds with one page of links after cursor
+func (h *LinkHandler) listLinksByCursor(c *gin.Context, cursor string, pageSize int) {
+	logger := middleware.GetLogger(c)
+
+	links, nextCursor, err := h.linkService.ListShortLinksAfter(c.Request.Context(), cursor, pageSize)
+	if err != nil {
+		if errors.Is(err, domain.ErrValidation) {
+			respondError(c, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		logger.Error("Failed to list short links", zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to list links")
+		return
+	}
+
+	meta := CursorMeta{
+		PerPage:    pageSize,
+		HasNext:    nextCursor != "",
+		NextCursor: nextCursor,
+	}
+
+	// Cursor walks only go forward
+	if meta.HasNext {
+		meta.Next = requestURLWith(c, h.baseURL, "cursor", nextCursor)
+		setLinkHeader(c, meta.Next, "")
+	}
+
+	respondList(c, links, meta)
 }
 
 // GetLinkStats handles retrieving link statistics
@@ -272,8 +486,9 @@ func (h *LinkHandler) ListLinks(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param code path string true "Short link code"
+// @Param tz query string false "IANA time zone clicks are bucketed by day in, e.g. Europe/Berlin"
 // @Success 200 {object} domain.LinkStats "Link statistics"
-// @Failure 400 {object} map[string]string "Invalid code"
+// @Failure 400 {object} map[string]string "Invalid code or time zone"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Link not found"
 // @Security BearerAuth
@@ -284,23 +499,34 @@ func (h *LinkHandler) GetLinkStats(c *gin.Context) {
 	// Extract code from URL
 	code := c.Param("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Link code is required"})
+		respondError(c, http.StatusBadRequest, "Link code is required")
 		return
 	}
 
+	// Day buckets follow the requested zone, falling back to the configured one
+	loc := h.opts.StatsLocation
+	if tz := c.Query("tz"); tz != "" {
+		requested, err := time.LoadLocation(tz)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid tz, expected an IANA time zone such as Europe/Berlin")
+			return
+		}
+		loc = requested
+	}
+
 	// Get link by code first to get its ID
 	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
 	if err != nil {
 		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		respondError(c, http.StatusNotFound, "Link not found")
 		return
 	}
 
 	// Get link stats using its ID
-	stats, err := h.linkService.GetLinkStats(c.Request.Context(), link.ID)
+	stats, err := h.linkService.GetLinkStats(c.Request.Context(), link.ID, loc)
 	if err != nil {
 		logger.Error("Failed to get link stats", zap.String("id", link.ID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get link statistics"})
+		respondError(c, http.StatusInternalServerError, "Failed to get link statistics")
 		return
 	}
 
@@ -308,21 +534,82 @@ func (h *LinkHandler) GetLinkStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// RedirectLink handles redirection for short links
+// GetLinkClick handles retrieving a single click of a short link
+// @Summary Get a link click
+// @Description Get one recorded click of a short link, e.g. to investigate an abuse report
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param code path string true "Short link code"
+// @Param clickID path string true "Click ID"
+// @Success 200 {object} domain.LinkClick "Click details"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Link or click not found"
+// @Security BearerAuth
+// @Router /links/{code}/clicks/{clickID} [get]
+func (h *LinkHandler) GetLinkClick(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	code := c.Param("code")
+	clickID := c.Param("clickID")
+
+	// Resolve the link first; the click must belong to it
+	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
+	if err != nil {
+		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
+		respondError(c, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	// Another user's link answers like a missing one, so its clicks stay
+	// private and its existence is not revealed
+	if !ownsLink(c, link) {
+		logger.Info("Refused click of another user's link", zap.String("code", code))
+		respondError(c, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	click, err := h.linkService.GetLinkClick(c.Request.Context(), link.ID, clickID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Info("Link click not found", zap.String("code", code), zap.String("click_id", clickID), zap.Error(err))
+			respondError(c, http.StatusNotFound, "Click not found")
+			return
+		}
+		logger.Error("Failed to get link click", zap.String("click_id", clickID), zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to get click")
+		return
+	}
+
+	c.JSON(http.StatusOK, click)
+}
+
+// RedirectLink handles redirection for short links. API clients sending
+// Accept: application/json receive the destination as JSON instead.
 func (h *LinkHandler) RedirectLink(c *gin.Context) {
+	h.redirect(c, c.Param("code"), "")
+}
+
+// redirect sends the visitor of code to its destination. A non-empty suffix
+// is the rest of the request path and only resolves for pattern links.
+func (h *LinkHandler) redirect(c *gin.Context, code, suffix string) {
 	logger := middleware.GetLogger(c)
 
 	logger.Debug("Starting redirect process")
 
-	// Extract code from URL
-	code := c.Param("code")
 	if code == "" {
 		logger.Info("Empty code parameter received")
-		c.Status(http.StatusNotFound)
+		h.notFound(c)
+		return
+	}
+
+	if h.codeTooLong(code) {
+		logger.Info("Redirect code exceeds the maximum length", zap.Int("length", len(code)))
+		h.notFound(c)
 		return
 	}
 
-	logger.Info("Redirect request received",
+	logger.Debug("Redirect request received",
 		zap.String("code", code))
 
 	// Get link by code
@@ -332,18 +619,18 @@ func (h *LinkHandler) RedirectLink(c *gin.Context) {
 			zap.String("code", code),
 			zap.Error(err),
 		)
-		c.Status(http.StatusNotFound)
+		h.notFound(c)
 		return
 	}
 
-	logger.Info("Link found for redirect",
+	logger.Debug("Link found for redirect",
 		zap.String("link_id", link.ID),
-		zap.String("original_url", link.URL.OriginalURL))
+		h.destinationField("original_url", link.URL.OriginalURL))
 
 	// Check if link is active
 	if !link.IsActive {
 		logger.Info("Attempt to access inactive link", zap.String("code", code))
-		c.Status(http.StatusNotFound)
+		h.notFound(c)
 		return
 	}
 
@@ -353,50 +640,309 @@ func (h *LinkHandler) RedirectLink(c *gin.Context) {
 			zap.String("code", code),
 			zap.Time("expiration", *link.ExpirationDate),
 		)
-		c.Status(http.StatusNotFound)
+		h.expired(c)
 		return
 	}
 
+	if !allowVisitor(c, link) {
+		return
+	}
+
+	// Only pattern links accept a path after the code
+	destination := link.URL.OriginalURL
+	if suffix != "" {
+		if !link.IsPattern {
+			logger.Info("Path suffix on a non-pattern link", zap.String("code", code))
+			h.notFound(c)
+			return
+		}
+
+		destination, err = patternDestination(destination, suffix)
+		if err != nil {
+			logger.Info("Rejected pattern link suffix",
+				zap.String("code", code),
+				zap.String("suffix", suffix),
+				zap.Error(err),
+			)
+			respondError(c, http.StatusBadRequest, "Invalid path")
+			return
+		}
+	}
+
+	destination = forwardQuery(destination, c.Request.URL.Query(), h.opts.ForwardQueryParams)
+
+	// Stored destinations are not trusted blindly, see checkRedirectTarget
+	if err := checkRedirectTarget(destination, h.opts.AllowedSchemes); err != nil {
+		logger.Warn("Refused redirect to unsafe destination",
+			zap.String("link_id", link.ID),
+			zap.String("code", code),
+			h.destinationField("destination", destination),
+			zap.Error(err),
+		)
+		h.notFound(c)
+		return
+	}
+
+	// Links with click tracking off skip recording altogether; the redirect
+	// metric below is all that counts them
+	if link.TracksClicks() {
+		h.recordClick(c, link.ID)
+	}
+
+	// Record redirect in metrics
+	h.metrics.RecordRedirect(link.ID)
+
+	// The response depends on the Accept header, so caches must key on it
+	c.Header("Vary", "Accept")
+
+	// Browsers get the redirect, API clients asking for JSON get the target
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, gin.H{
+			"original_url": destination,
+			"code":         link.Code,
+		})
+	} else {
+		c.Redirect(http.StatusMovedPermanently, destination)
+	}
+
+	// The one info line of a successful redirect; the steps above log at debug
+	logger.Info("Redirect",
+		zap.String("code", code),
+		zap.String("link_id", link.ID),
+		h.destinationField("destination", destination),
+		zap.Int("status", c.Writer.Status()),
+	)
+}
+
+// recordClick records a click on the link in the background with the
+// details of the current request
+func (h *LinkHandler) recordClick(c *gin.Context, linkID string) {
+	logger := middleware.GetLogger(c)
+
+	// Capture request details before handing off, the gin context must not
+	// be used once the handler has returned
+	referrer := c.GetHeader("Referer")
+	userAgent := c.GetHeader("User-Agent")
+	ipAddress := middleware.ClientIP(c)
+	rawQuery := c.Request.URL.RawQuery
+
 	// Record click asynchronously
 	go func() {
-		referrer := c.GetHeader("Referer")
-		userAgent := c.GetHeader("User-Agent")
-		ipAddress := c.ClientIP()
-
 		// Create a new context with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := h.linkService.RecordClick(ctx, link.ID, referrer, userAgent, ipAddress); err != nil {
+		if err := h.linkService.RecordClick(ctx, linkID, referrer, userAgent, ipAddress, rawQuery); err != nil {
 			logger.Error("Failed to record click",
-				zap.String("link_id", link.ID),
+				zap.String("link_id", linkID),
 				zap.Error(err),
 			)
 		} else {
-			logger.Info("Click recorded successfully",
-				zap.String("link_id", link.ID))
+			logger.Debug("Click recorded successfully",
+				zap.String("link_id", linkID))
 		}
 	}()
+}
 
-	// Log before redirect
-	logger.Info("About to perform redirect",
-		zap.String("link_id", link.ID),
-		zap.String("original_url", link.URL.OriginalURL),
-		zap.String("code", code))
+// PreviewLink handles the interstitial page showing where a short link leads
+// @Summary Preview a short link
+// @Description Show an HTML page with the destination of a short link instead of redirecting
+// @Tags links
+// @Produce html
+// @Param code path string true "Short link code"
+// @Success 200 {string} string "Preview page"
+// @Failure 404 {string} string "Not found page"
+// @Failure 410 {string} string "Expired page, when EXPIRED_LINK_STATUS is 410"
+// @Router /{code}/preview [get]
+func (h *LinkHandler) PreviewLink(c *gin.Context) {
+	logger := middleware.GetLogger(c)
 
-	// Record redirect in metrics
-	if h.metrics != nil {
-		logger.Info("Recording redirect in metrics", zap.String("link_id", link.ID))
-		h.metrics.RecordRedirect(link.ID)
-	} else {
-		logger.Error("Metrics collector is nil, cannot record redirect")
+	if !h.opts.Features.PreviewPages {
+		h.renderPage(c, http.StatusNotFound, pages.NotFound, pages.Data{}, 0)
+		return
 	}
 
-	// Redirect to original URL
-	c.Redirect(http.StatusMovedPermanently, link.URL.OriginalURL)
+	code := c.Param("code")
+	if h.codeTooLong(code) {
+		logger.Info("Preview code exceeds the maximum length", zap.Int("length", len(code)))
+		h.notFound(c)
+		return
+	}
 
-	// Log after redirect
-	logger.Info("Redirect completed",
-		zap.String("link_id", link.ID),
-		zap.String("destination", link.URL.OriginalURL))
+	link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
+	if err != nil {
+		logger.Info("Failed to get short link for preview", zap.String("code", code), zap.Error(err))
+		h.notFound(c)
+		return
+	}
+
+	if !link.IsActive {
+		h.notFound(c)
+		return
+	}
+
+	if link.ExpirationDate != nil && time.Now().UTC().After(*link.ExpirationDate) {
+		h.expired(c)
+		return
+	}
+
+	if !allowVisitor(c, link) {
+		return
+	}
+
+	// The page links to the destination, so it gets the same check as a redirect
+	if err := checkRedirectTarget(link.URL.OriginalURL, h.opts.AllowedSchemes); err != nil {
+		logger.Warn("Refused preview of unsafe destination",
+			zap.String("link_id", link.ID),
+			zap.String("code", code),
+			h.destinationField("destination", link.URL.OriginalURL),
+			zap.Error(err),
+		)
+		h.notFound(c)
+		return
+	}
+
+	// Never let a cache keep serving the page past the link's expiry, or
+	// serve a restricted link's page at all
+	maxAge := h.previewMaxAge()
+	if len(link.AllowedUsers) > 0 {
+		maxAge = 0
+	}
+	if link.ExpirationDate != nil {
+		if remaining := time.Until(*link.ExpirationDate); remaining < maxAge {
+			maxAge = remaining
+		}
+	}
+
+	h.renderPage(c, http.StatusOK, pages.Preview, pages.Data{
+		Code:        code,
+		OriginalURL: link.URL.OriginalURL,
+	}, maxAge)
+}
+
+// previewMaxAge returns how long shared caches may keep a preview page
+func (h *LinkHandler) previewMaxAge() time.Duration {
+	if h.opts.PreviewCacheMaxAge > 0 {
+		return h.opts.PreviewCacheMaxAge
+	}
+	return DefaultPreviewCacheMaxAge
+}
+
+// apiPrefix returns the path the API routes are mounted under
+func (h *LinkHandler) apiPrefix() string {
+	if h.opts.APIPrefix != "" {
+		return h.opts.APIPrefix
+	}
+	return DefaultAPIPrefix
+}
+
+// codeTooLong reports whether code is longer than any code worth looking up
+func (h *LinkHandler) codeTooLong(code string) bool {
+	maxLength := h.opts.MaxCodeLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxCodeLength
+	}
+	return len(code) > maxLength
+}
+
+// destinationField logs a destination URL under key as configured by
+// LogDestinations: in full, as its SHA-256 under key_hash, or not at all
+func (h *LinkHandler) destinationField(key, destination string) zap.Field {
+	switch h.opts.LogDestinations {
+	case LogDestinationsHash:
+		sum := sha256.Sum256([]byte(destination))
+		return zap.String(key+"_hash", hex.EncodeToString(sum[:]))
+	case LogDestinationsOmit:
+		return zap.Skip()
+	default:
+		return zap.String(key, destination)
+	}
+}
+
+// notFound responds to a dead redirect code with the configured not found
+// behavior. API clients asking for JSON get a JSON error instead.
+func (h *LinkHandler) notFound(c *gin.Context) {
+	if wantsJSON(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return
+	}
+
+	if h.opts.NotFoundMode == NotFoundRedirect && h.opts.NotFoundRedirectURL != "" {
+		c.Redirect(http.StatusFound, h.opts.NotFoundRedirectURL)
+		return
+	}
+
+	h.renderPage(c, http.StatusNotFound, pages.NotFound, pages.Data{}, 0)
+}
+
+// expired answers a request for an expired link with the configured status
+func (h *LinkHandler) expired(c *gin.Context) {
+	status := h.opts.ExpiredStatus
+	if status == 0 {
+		status = http.StatusNotFound
+	}
+
+	if status == http.StatusNotFound && h.opts.ExpiredMessage == "" {
+		h.notFound(c)
+		return
+	}
+
+	if wantsJSON(c) {
+		message := h.opts.ExpiredMessage
+		if message == "" {
+			message = "Link expired"
+		}
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	h.renderPage(c, status, pages.Expired, pages.Data{Message: h.opts.ExpiredMessage}, 0)
+}
+
+// renderPage writes an HTML page localized for the request's Accept-Language
+// header. Pages with a positive maxAge are publicly cacheable and carry an
+// ETag so conditional requests can be answered with 304; all others are
+// marked no-store.
+func (h *LinkHandler) renderPage(c *gin.Context, status int, page string, data pages.Data, maxAge time.Duration) {
+	data.Brand = h.opts.BrandName
+	writePage(c, h.pages, status, page, data, maxAge)
+}
+
+// writePage renders page with renderer for the request's Accept-Language
+// header and writes it, see renderPage
+func writePage(c *gin.Context, renderer *pages.Renderer, status int, page string, data pages.Data, maxAge time.Duration) {
+	locale := renderer.Negotiate(c.GetHeader("Accept-Language"))
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, page, locale, data); err != nil {
+		middleware.GetLogger(c).Error("Failed to render page", zap.String("page", page), zap.Error(err))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the Accept set by callers that negotiated the format
+	vary := "Accept-Language, Accept-Encoding"
+	if prior := c.Writer.Header().Get("Vary"); prior != "" {
+		vary = prior + ", " + vary
+	}
+
+	c.Header("Content-Language", locale)
+	c.Header("Vary", vary)
+
+	if maxAge <= 0 {
+		c.Header("Cache-Control", "no-store")
+		c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	etag := contentETag(buf.Bytes())
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	c.Header("ETag", etag)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// allowVisitor checks the authenticated visitor against a restricted link's
+// allowed users, answering 401 to anonymous visitors and 403 to anyone not
+// listed. Links without allowed users are open to everyone.
+func allowVisitor(c *gin.Context, link *domain.ShortLink) bool {
+	if len(link.AllowedUsers) == 0 {
+		return true
+	}
+
+	// Whether the visitor may follow the link depends on who they are
+	c.Header("Cache-Control", "private, no-store")
+
+	userID := auth.UserIDFromContext(c.Request.Context())
+	if userID == "" {
+		respondError(c, http.StatusUnauthorized, "Authentication required")
+		return false
+	}
+
+	if !slices.ContainsFunc(link.AllowedUsers, func(allowed string) bool { return strings.EqualFold(allowed, userID) }) {
+		middleware.GetLogger(c).Info("Refused restricted link to user",
+			zap.String("link_id", link.ID),
+			zap.String("user_id", userID),
+		)
+		respondError(c, http.StatusForbidden, "Access to this link is not allowed")
+		return false
+	}
+
+	return true
+}
+
+// ownsLink reports whether the link belongs to the request's caller. Links
+// created without a user belong to callers without one, and to no user.
+func ownsLink(c *gin.Context, link *domain.ShortLink) bool {
+	owner := ""
+	if link.OwnerID != nil {
+		owner = *link.OwnerID
+	}
+	return owner == auth.UserIDFromContext(c.Request.Context())
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler restricted links", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		recorded chan string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		recorded = make(chan string, 1)
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				link := &domain.ShortLink{
+					ID:       "link-" + code,
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://intranet.example.com/roadmap"},
+				}
+				if code == "team" {
+					link.AllowedUsers = []string{"alice@example.com", "bob"}
+				}
+				return link, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID, referrer, userAgent, ipAddress, rawQuery string) error {
+				recorded <- shortLinkID
+				return nil
+			},
+		}
+
+		router = gin.New()
+		// Stands in for the optional authentication on the redirect routes
+		router.Use(func(c *gin.Context) {
+			if user := c.GetHeader("X-Test-User"); user != "" {
+				c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), user))
+			}
+		})
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features: config.DefaultFeatures(),
+		})
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+	})
+
+	request := func(path, user string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("redirects an allowed user", func() {
+		request("/team", "ALICE@example.com")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://intranet.example.com/roadmap"))
+		Expect(recorder.Header().Get("Cache-Control")).To(Equal("private, no-store"))
+		Eventually(recorded).Should(Receive(Equal("link-team")))
+	})
+
+	It("refuses a user who is not allowed", func() {
+		request("/team", "mallory")
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		Expect(recorder.Header().Get("Location")).To(BeEmpty())
+		Consistently(recorded, "50ms").ShouldNot(Receive())
+	})
+
+	It("asks anonymous visitors to authenticate", func() {
+		request("/team", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Header().Get("Location")).To(BeEmpty())
+		Consistently(recorded, "50ms").ShouldNot(Receive())
+	})
+
+	It("applies the same check to the preview page", func() {
+		request("/team/preview", "")
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+
+		recorder = httptest.NewRecorder()
+		request("/team/preview", "bob")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Cache-Control")).To(Equal("no-store"))
+	})
+
+	It("leaves unrestricted links open to anonymous visitors", func() {
+		request("/open", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+	})
+})
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("LinkHandler redirects with click beacons", func() {
+	var (
+		router   *gin.Engine
+		received chan struct{}
+		recorded chan string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		received = make(chan struct{}, 10)
+		recorded = make(chan string, 10)
+
+		// An analytics endpoint that hangs until the beacon gives up
+		release := make(chan struct{})
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		DeferCleanup(func() {
+			close(release)
+			receiver.Close()
+		})
+
+		svc := service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://app.example.com/#/welcome"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: "link-1", Code: alias, URLID: "url-1", IsActive: true}, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					recorded <- click.ShortLinkID
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				ClickBeacon:        service.ClickBeaconWebhook(receiver.URL),
+				ClickBeaconTimeout: 100 * time.Millisecond,
+			},
+		)
+
+		router = gin.New()
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	It("should redirect and record the click while the beacon endpoint hangs", func() {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/welcome", nil)
+
+		start := time.Now()
+		router.ServeHTTP(recorder, req)
+
+		Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond))
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://app.example.com/#/welcome"))
+		Eventually(received).Should(Receive())
+		Eventually(recorded).Should(Receive(Equal("link-1")))
+	})
+})
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler click lookup", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		svc      *MockShortenerService
+		owner    *string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		owner = nil
+
+		svc = &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code != "abc123" {
+					return nil, errors.New("short link not found")
+				}
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true, OwnerID: owner}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/api/links/:code/clicks/:clickID", handler.GetLinkClick)
+	})
+
+	requestAs := func(userID, path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if userID != "" {
+			req = req.WithContext(auth.WithUserID(req.Context(), userID))
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	request := func(path string) {
+		requestAs("", path)
+	}
+
+	It("should return the click when it belongs to the link", func() {
+		ip := "203.0.113.7"
+		var gotLinkID, gotClickID string
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			gotLinkID, gotClickID = shortLinkID, clickID
+			return &domain.LinkClick{ID: clickID, ShortLinkID: shortLinkID, IPAddress: &ip}, nil
+		}
+
+		request("/api/links/abc123/clicks/click-1")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(gotLinkID).To(Equal("link-1"))
+		Expect(gotClickID).To(Equal("click-1"))
+
+		var click domain.LinkClick
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &click)).To(Succeed())
+		Expect(click.ID).To(Equal("click-1"))
+		Expect(click.ShortLinkID).To(Equal("link-1"))
+		Expect(click.IPAddress).To(HaveValue(Equal(ip)))
+	})
+
+	It("should return 404 when the click does not exist", func() {
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			return nil, fmt.Errorf("link click not found: %w", domain.ErrNotFound)
+		}
+
+		request("/api/links/abc123/clicks/missing")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Click not found"))
+	})
+
+	It("should return 404 when the click belongs to another link", func() {
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			return nil, fmt.Errorf("click %s does not belong to short link %s: %w", clickID, shortLinkID, domain.ErrNotFound)
+		}
+
+		request("/api/links/abc123/clicks/click-of-other-link")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should return the click of the caller's own link", func() {
+		alice := "alice"
+		owner = &alice
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			return &domain.LinkClick{ID: clickID, ShortLinkID: shortLinkID}, nil
+		}
+
+		requestAs("alice", "/api/links/abc123/clicks/click-1")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should return 404 for the click of another user's link", func() {
+		bob := "bob"
+		owner = &bob
+		looked := false
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			looked = true
+			return &domain.LinkClick{ID: clickID, ShortLinkID: shortLinkID}, nil
+		}
+
+		requestAs("alice", "/api/links/abc123/clicks/click-1")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+		Expect(looked).To(BeFalse())
+	})
+
+	It("should return 404 when the link does not exist", func() {
+		request("/api/links/unknown/clicks/click-1")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+	})
+
+	It("should return 500 when the lookup fails", func() {
+		svc.GetLinkClickFunc = func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+			return nil, errors.New("connection refused")
+		}
+
+		request("/api/links/abc123/clicks/click-1")
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler code length limit", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		lookups  []string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		lookups = nil
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				lookups = append(lookups, code)
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features:      config.DefaultFeatures(),
+			MaxCodeLength: 8,
+		})
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+	})
+
+	request := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("answers 404 for an over-length code without a lookup", func() {
+		request("/" + strings.Repeat("a", 5000))
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(lookups).To(BeEmpty())
+	})
+
+	It("redirects a code at the maximum length", func() {
+		request("/abcd1234")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(lookups).To(Equal([]string{"abcd1234"}))
+	})
+
+	It("skips the lookup for over-length preview codes too", func() {
+		request("/abcd12345/preview")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(lookups).To(BeEmpty())
+	})
+})
@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("LinkHandler display codes", func() {
+	var router *gin.Engine
+
+	newRouter := func(displayCode bool) *gin.Engine {
+		stored := &domain.ShortLink{ID: "link-1", Code: "abc123", URLID: "url-1", IsActive: true}
+		svc := service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com/landing"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if code == stored.Code {
+						link := *stored
+						return &link, nil
+					}
+					return nil, errors.New("short link not found")
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("short link not found")
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{CodeCase: service.CodeCaseLower, CodeDisplayGroup: 3},
+		)
+
+		features := config.DefaultFeatures()
+		features.LinkURLs = true
+		opts := handlers.LinkHandlerOptions{Features: features}
+		if displayCode {
+			opts.DisplayCode = svc.DisplayCode
+		}
+
+		engine := gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, opts)
+		engine.GET("/api/links/:code", handler.GetLink)
+		engine.GET("/:code", handler.RedirectLink)
+		return engine
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = newRouter(true)
+	})
+
+	It("should redirect a formatted code in the URL to the stored link", func() {
+		for _, path := range []string{"/abc-123", "/ABC-123", "/abc123"} {
+			recorder := get(path)
+
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently), path)
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/landing"), path)
+		}
+	})
+
+	It("should show the display code next to the stored one", func() {
+		recorder := get("/api/links/ABC-123")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp).To(HaveKeyWithValue("code", "abc123"))
+		Expect(resp).To(HaveKeyWithValue("display_code", "abc-123"))
+		Expect(resp).To(HaveKeyWithValue("short_url", "http://localhost:8081/abc-123"))
+		Expect(resp).To(HaveKeyWithValue("stats_url", "http://localhost:8081/api/links/abc123/stats"))
+	})
+
+	It("should leave display_code out without a display hook", func() {
+		router = newRouter(false)
+
+		recorder := get("/api/links/abc123")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp).NotTo(HaveKey("display_code"))
+		Expect(resp).To(HaveKeyWithValue("short_url", "http://localhost:8081/abc123"))
+	})
+})
@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler expired links", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	newRouter := func(opts handlers.LinkHandlerOptions) {
+		expired := time.Now().Add(-time.Hour)
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code != "old" {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{
+					ID:             "link-1",
+					Code:           code,
+					IsActive:       true,
+					ExpirationDate: &expired,
+					URL:            &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+		}
+
+		opts.Features = config.DefaultFeatures()
+		router = gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, opts)
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+	}
+
+	request := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("when expired links answer 410", func() {
+		BeforeEach(func() {
+			newRouter(handlers.LinkHandlerOptions{ExpiredStatus: http.StatusGone})
+		})
+
+		It("returns 410 with the expired page for an expired link", func() {
+			request("/old")
+
+			Expect(recorder.Code).To(Equal(http.StatusGone))
+			Expect(recorder.Header().Get("Location")).To(BeEmpty())
+			Expect(recorder.Body.String()).To(ContainSubstring("Link expired"))
+		})
+
+		It("still returns 404 for an unknown code", func() {
+			request("/missing")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+		})
+
+		It("returns 410 from the preview page too", func() {
+			request("/old/preview")
+
+			Expect(recorder.Code).To(Equal(http.StatusGone))
+		})
+
+		It("is not overridden by the not found redirect", func() {
+			newRouter(handlers.LinkHandlerOptions{
+				ExpiredStatus:       http.StatusGone,
+				NotFoundMode:        handlers.NotFoundRedirect,
+				NotFoundRedirectURL: "https://example.com/not-found",
+			})
+
+			request("/old")
+
+			Expect(recorder.Code).To(Equal(http.StatusGone))
+		})
+	})
+
+	It("shows the configured message on the expired page", func() {
+		newRouter(handlers.LinkHandlerOptions{
+			ExpiredStatus:  http.StatusGone,
+			ExpiredMessage: "This campaign has ended.",
+		})
+
+		request("/old")
+
+		Expect(recorder.Code).To(Equal(http.StatusGone))
+		Expect(recorder.Body.String()).To(ContainSubstring("This campaign has ended."))
+	})
+
+	It("keeps a 404 with a message distinct from the not found page", func() {
+		newRouter(handlers.LinkHandlerOptions{
+			ExpiredStatus:  http.StatusNotFound,
+			ExpiredMessage: "This campaign has ended.",
+		})
+
+		request("/old")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Link expired"))
+		Expect(recorder.Body.String()).To(ContainSubstring("This campaign has ended."))
+	})
+
+	It("treats expired links as not found by default", func() {
+		newRouter(handlers.LinkHandlerOptions{})
+
+		request("/old")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+	})
+})
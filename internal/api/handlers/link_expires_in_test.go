@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler expiry countdown", func() {
+	var (
+		router *gin.Engine
+		expiry *time.Time
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		expiry = nil
+
+		svc := &MockShortenerService{
+			CreateShortLinkFunc: func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: "abc123", IsActive: true, ExpirationDate: expiry}, nil
+			},
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true, ExpirationDate: expiry}, nil
+			},
+		}
+
+		router = gin.New()
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.POST("/api/links", handler.CreateLink)
+		router.GET("/api/links/:code", handler.GetLink)
+	})
+
+	request := func(method, target, body string) map[string]interface{} {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	It("should count down to a future expiry on create and get", func() {
+		in3Days := time.Now().Add(72 * time.Hour)
+		expiry = &in3Days
+
+		created := request(http.MethodPost, "/api/links", `{"url":"https://example.com"}`)
+		fetched := request(http.MethodGet, "/api/links/abc123", "")
+
+		for _, resp := range []map[string]interface{}{created, fetched} {
+			Expect(resp).To(HaveKeyWithValue("expires_in_seconds", BeNumerically("~", 72*60*60, 5)))
+		}
+	})
+
+	It("should be null for a link without expiry", func() {
+		resp := request(http.MethodGet, "/api/links/abc123", "")
+
+		Expect(resp).To(HaveKeyWithValue("expires_in_seconds", BeNil()))
+	})
+
+	It("should be 0 rather than negative for an expired link", func() {
+		yesterday := time.Now().Add(-24 * time.Hour)
+		expiry = &yesterday
+
+		resp := request(http.MethodGet, "/api/links/abc123", "")
+
+		Expect(resp).To(HaveKeyWithValue("expires_in_seconds", BeEquivalentTo(0)))
+	})
+})
@@ -225,6 +225,33 @@ var _ = Describe("LinkHandler", func() {
 				Expect(respBody["short_url"]).To(Equal("abc123"))
 				Expect(respBody["visits"]).To(Equal(float64(10)))
 			})
+
+			It("keeps a visit count past 2^53 exact", func() {
+				const visits int64 = 1<<53 + 1
+				linkSvc.GetLinkFunc = func(id string) (*domain.Link, error) {
+					return &domain.Link{ID: "link-123", UserID: "user-123", Visits: visits}, nil
+				}
+
+				req, _ := http.NewRequest(http.MethodGet, "/api/links/link-123", nil)
+				router.GET("/api/links/:id", func(c *gin.Context) {
+					c.Set("user_id", "user-123")
+					handler.GetLinkForTest(c)
+				})
+				router.ServeHTTP(recorder, req)
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(ContainSubstring(`"visits":9007199254740993`))
+
+				var link domain.Link
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &link)).To(Succeed())
+				Expect(link.Visits).To(Equal(visits))
+
+				var respBody map[string]interface{}
+				decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+				decoder.UseNumber()
+				Expect(decoder.Decode(&respBody)).To(Succeed())
+				Expect(respBody["visits"]).To(Equal(json.Number("9007199254740993")))
+			})
 		})
 
 		Context("when the link doesn't exist", func() {
@@ -575,7 +602,7 @@ var _ = Describe("LinkHandler", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				// Check response data
-				links, ok := respBody["links"].([]interface{})
+				links, ok := respBody["data"].([]interface{})
 				Expect(ok).To(BeTrue())
 				Expect(len(links)).To(Equal(2))
 
@@ -674,9 +701,7 @@ var _ = Describe("LinkHandler", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				// Check response data
-				Expect(respBody["link_id"]).To(Equal("link-123"))
-
-				clicks, ok := respBody["clicks"].([]interface{})
+				clicks, ok := respBody["data"].([]interface{})
 				Expect(ok).To(BeTrue())
 				Expect(len(clicks)).To(Equal(1))
 
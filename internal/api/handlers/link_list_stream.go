@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// streamLinks responds with one page of links in the list envelope, like
+// respondList, but writes each link as the streamer hands it over
+func (h *LinkHandler) streamLinks(c *gin.Context, page, pageSize int) {
+	logger := middleware.GetLogger(c)
+
+	var meta PageMeta
+	links := newJSONArrayStream(c.Writer)
+
+	// The meta needs the total, which is known before the first link, but
+	// is written after the data since JSON doesn't care about key order
+	begin := func(total int) error {
+		meta = newPageMeta(c, h.baseURL, total, page, pageSize)
+		setLinkHeader(c, meta.Next, meta.Prev)
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		_, err := io.WriteString(c.Writer, `{"data":[`)
+		return err
+	}
+
+	rows := 0
+	err := h.opts.ListStreamer.StreamShortLinks(c.Request.Context(), page, pageSize, begin, func(link *domain.ShortLink) error {
+		if err := links.write(link); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = endLinkStream(c.Writer, meta)
+	}
+	if err != nil {
+		logger.Error("Failed to list short links", zap.Int("rows", rows), zap.Error(err))
+		if !c.Writer.Written() {
+			respondError(c, http.StatusInternalServerError, "Failed to list links")
+			return
+		}
+		// Headers are gone; cutting the body short is the only signal left
+		c.Abort()
+		return
+	}
+}
+
+// endLinkStream closes the data array and the envelope around it
+func endLinkStream(w io.Writer, meta PageMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `],"meta":%s}`, data)
+	return err
+}
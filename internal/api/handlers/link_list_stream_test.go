@@ -0,0 +1,247 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// fakeLinkStreamer hands over total generated links, making each one only
+// when it is due like rows read from a database cursor
+type fakeLinkStreamer struct {
+	total    int
+	failAt   int // the row whose handling fails; 0 never fails
+	countErr error
+
+	// onRow is called after every row handed over
+	onRow func(row int)
+}
+
+func (s *fakeLinkStreamer) StreamShortLinks(ctx context.Context, page, pageSize int, begin func(total int) error, fn func(link *domain.ShortLink) error) error {
+	if s.countErr != nil {
+		return s.countErr
+	}
+	if err := begin(s.total); err != nil {
+		return err
+	}
+
+	offset := (page - 1) * pageSize
+	for row := offset + 1; row <= min(offset+pageSize, s.total); row++ {
+		if row == s.failAt {
+			return errors.New("connection reset")
+		}
+
+		clicks := int64(row)
+		link := &domain.ShortLink{
+			ID:         fmt.Sprintf("link-%d", row),
+			Code:       fmt.Sprintf("c%06d", row),
+			URLID:      fmt.Sprintf("url-%d", row),
+			IsActive:   true,
+			CreatedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(row) * time.Minute),
+			ClickCount: &clicks,
+			URL:        &domain.URL{ID: fmt.Sprintf("url-%d", row), OriginalURL: fmt.Sprintf("https://example.com/articles/%d?ref=newsletter", row)},
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
+		if s.onRow != nil {
+			s.onRow(row)
+		}
+	}
+
+	return nil
+}
+
+// pipeResponseWriter hands the response body to a reader as it is written,
+// without keeping it
+type pipeResponseWriter struct {
+	header http.Header
+	status int
+	pipe   *io.PipeWriter
+}
+
+func (w *pipeResponseWriter) Header() http.Header         { return w.header }
+func (w *pipeResponseWriter) WriteHeader(status int)      { w.status = status }
+func (w *pipeResponseWriter) Write(p []byte) (int, error) { return w.pipe.Write(p) }
+func (w *pipeResponseWriter) Flush()                      {}
+
+var _ = Describe("LinkHandler streamed link list", func() {
+	var streamer *fakeLinkStreamer
+
+	newRouter := func(linkService handlers.LinkService, listStreamer handlers.LinkListStreamer) *gin.Engine {
+		router := gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(linkService, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			MaxPageSize:  1000000,
+			ListStreamer: listStreamer,
+		})
+		router.GET("/api/links", handler.ListLinks)
+		return router
+	}
+
+	get := func(router *gin.Engine, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		streamer = &fakeLinkStreamer{total: 25}
+	})
+
+	It("should respond exactly like the list built in memory", func() {
+		// Collect the same page the streamer hands over for the slice based list
+		var links []*domain.ShortLink
+		collect := &fakeLinkStreamer{total: streamer.total}
+		Expect(collect.StreamShortLinks(context.Background(), 2, 10,
+			func(int) error { return nil },
+			func(link *domain.ShortLink) error {
+				links = append(links, link)
+				return nil
+			},
+		)).To(Succeed())
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return links, streamer.total, nil
+			},
+		}
+
+		streamed := get(newRouter(svc, streamer), "/api/links?page=2&page_size=10")
+		built := get(newRouter(svc, nil), "/api/links?page=2&page_size=10")
+
+		Expect(streamed.Code).To(Equal(http.StatusOK))
+		Expect(streamed.Header().Get("Content-Type")).To(Equal("application/json; charset=utf-8"))
+		Expect(streamed.Header().Get("Link")).To(Equal(built.Header().Get("Link")))
+		Expect(streamed.Body.String()).To(MatchJSON(built.Body.String()))
+	})
+
+	It("should send an empty page as an empty list", func() {
+		streamer.total = 0
+
+		recorder := get(newRouter(&MockShortenerService{}, streamer), "/api/links")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"data": [],
+			"meta": {"total": 0, "page": 1, "per_page": 10, "total_pages": 0, "has_next": false, "has_prev": false}
+		}`))
+	})
+
+	It("should leave filtered lists to the link service", func() {
+		listed := false
+		svc := &MockShortenerService{
+			ListShortLinksFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				listed = true
+				return nil, 0, nil
+			},
+		}
+
+		recorder := get(newRouter(svc, streamer), "/api/links?status=active")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(listed).To(BeTrue())
+	})
+
+	It("should answer 500 when the list fails before any output", func() {
+		streamer.countErr = errors.New("connection refused")
+
+		recorder := get(newRouter(&MockShortenerService{}, streamer), "/api/links")
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).To(ContainSubstring("Failed to list links"))
+	})
+
+	It("should cut the body short when the list fails midway", func() {
+		streamer.failAt = 5
+
+		recorder := get(newRouter(&MockShortenerService{}, streamer), "/api/links")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(json.Valid(recorder.Body.Bytes())).To(BeFalse())
+	})
+
+	It("should stream a large page as valid JSON in bounded memory", func() {
+		const rows = 100000
+		streamer.total = rows
+
+		var baseline, peak uint64
+		heap := func() uint64 {
+			var stats runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			return stats.HeapAlloc
+		}
+		streamer.onRow = func(row int) {
+			if row%10000 == 0 {
+				peak = max(peak, heap())
+			}
+		}
+
+		reader, pipe := io.Pipe()
+		writer := &pipeResponseWriter{header: http.Header{}, pipe: pipe}
+		router := newRouter(&MockShortenerService{}, streamer)
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/links?page_size=%d", rows), nil)
+
+		baseline = heap()
+		go func() {
+			defer GinkgoRecover()
+			router.ServeHTTP(writer, req)
+			pipe.Close()
+		}()
+
+		// Read the body as it arrives, one element at a time
+		decoder := json.NewDecoder(reader)
+		expectDelim := func(delim json.Delim) {
+			token, err := decoder.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(delim))
+		}
+		expectKey := func(key string) {
+			token, err := decoder.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(key))
+		}
+
+		expectDelim('{')
+		expectKey("data")
+		expectDelim('[')
+		count := 0
+		for decoder.More() {
+			var link struct {
+				Code string `json:"code"`
+			}
+			Expect(decoder.Decode(&link)).To(Succeed())
+			count++
+			Expect(link.Code).To(Equal(fmt.Sprintf("c%06d", count)))
+		}
+		expectDelim(']')
+		expectKey("meta")
+		var meta handlers.PageMeta
+		Expect(decoder.Decode(&meta)).To(Succeed())
+		expectDelim('}')
+		_, err := decoder.Token()
+		Expect(err).To(Equal(io.EOF))
+
+		Expect(writer.status).To(Equal(http.StatusOK))
+		Expect(count).To(Equal(rows))
+		Expect(meta.Total).To(Equal(rows))
+		Expect(meta.PerPage).To(Equal(rows))
+
+		// Holding the page would take tens of megabytes
+		Expect(peak).To(BeNumerically("<", baseline+8<<20))
+	})
+})
@@ -0,0 +1,533 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler page size", func() {
+	var (
+		router        *gin.Engine
+		recorder      *httptest.ResponseRecorder
+		requestedSize int
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		requestedSize = 0
+
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				requestedSize = pageSize
+				return []*domain.ShortLink{}, 0, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			MaxPageSize: 50,
+		})
+		router.GET("/api/links", handler.ListLinks)
+	})
+
+	request := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should use the default page size when none is given", func() {
+		request("/api/links")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(requestedSize).To(Equal(handlers.DefaultPageSize))
+	})
+
+	It("should accept a page size equal to the cap", func() {
+		request("/api/links?page_size=50")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(requestedSize).To(Equal(50))
+	})
+
+	It("should reject a page size over the cap and report the cap", func() {
+		request("/api/links?page_size=51")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		var body map[string]string
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body["error"]).To(ContainSubstring("50"))
+		Expect(requestedSize).To(BeZero())
+	})
+})
+
+var _ = Describe("LinkHandler cursor pagination", func() {
+	var (
+		router          *gin.Engine
+		recorder        *httptest.ResponseRecorder
+		requestedCursor string
+		offsetUsed      bool
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		requestedCursor = "unset"
+		offsetUsed = false
+
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				offsetUsed = true
+				return []*domain.ShortLink{}, 0, nil
+			},
+			ListShortLinksAfterFunc: func(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error) {
+				requestedCursor = cursor
+				if cursor == "bad" {
+					return nil, "", fmt.Errorf("invalid cursor: %w", domain.ErrValidation)
+				}
+				return []*domain.ShortLink{{ID: "link-1", Code: "abc123"}}, "next-token", nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/api/links", handler.ListLinks)
+	})
+
+	request := func(path string) map[string]interface{} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body
+	}
+
+	It("should start a cursor walk with an empty cursor and return the next cursor", func() {
+		body := request("/api/links?cursor=&page_size=1")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(requestedCursor).To(BeEmpty())
+		Expect(body["meta"]).To(HaveKeyWithValue("next_cursor", "next-token"))
+		Expect(body["data"]).To(HaveLen(1))
+		Expect(offsetUsed).To(BeFalse())
+	})
+
+	It("should point to the next cursor page in the Link header", func() {
+		request("/api/links?cursor=&page_size=1")
+
+		Expect(recorder.Header().Get("Link")).To(Equal(
+			`<http://localhost:8081/api/links?cursor=next-token&page_size=1>; rel="next"`))
+	})
+
+	It("should pass the cursor through", func() {
+		request("/api/links?cursor=next-token")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(requestedCursor).To(Equal("next-token"))
+	})
+
+	It("should reject an invalid cursor", func() {
+		request("/api/links?cursor=bad")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should keep offset pagination without a cursor", func() {
+		request("/api/links?page=2")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(offsetUsed).To(BeTrue())
+		Expect(requestedCursor).To(Equal("unset"))
+	})
+})
+
+var _ = Describe("LinkHandler click counts", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	clicks := func(n int64) *int64 { return &n }
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+
+		links := []*domain.ShortLink{
+			{ID: "link-1", Code: "popular", ClickCount: clicks(42)},
+			{ID: "link-2", Code: "unvisited", ClickCount: clicks(0)},
+		}
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return links, len(links), nil
+			},
+			ListShortLinksAfterFunc: func(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error) {
+				return links, "", nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/api/links", handler.ListLinks)
+	})
+
+	// listedCounts returns the click_count of each listed link by code
+	listedCounts := func(path string) map[string]interface{} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var body struct {
+			Links []map[string]interface{} `json:"data"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+
+		counts := map[string]interface{}{}
+		for _, link := range body.Links {
+			counts[link["code"].(string)] = link["click_count"]
+		}
+		return counts
+	}
+
+	It("should include each link's click count in page listings", func() {
+		Expect(listedCounts("/api/links")).To(Equal(map[string]interface{}{
+			"popular":   float64(42),
+			"unvisited": float64(0),
+		}))
+	})
+
+	It("should include each link's click count in cursor listings", func() {
+		Expect(listedCounts("/api/links?cursor=")).To(Equal(map[string]interface{}{
+			"popular":   float64(42),
+			"unvisited": float64(0),
+		}))
+	})
+})
+
+var _ = Describe("LinkHandler list filters", func() {
+	var (
+		router         *gin.Engine
+		recorder       *httptest.ResponseRecorder
+		filter         *domain.LinkFilter
+		unfilteredUsed bool
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		filter = nil
+		unfilteredUsed = false
+
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				unfilteredUsed = true
+				return []*domain.ShortLink{}, 0, nil
+			},
+			ListShortLinksFilteredFunc: func(ctx context.Context, f domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				filter = &f
+				if f.CollectionID == "someone-elses" {
+					return nil, 0, fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+				}
+				return []*domain.ShortLink{{ID: "link-1", Code: "abc123"}}, 1, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/api/links", handler.ListLinks)
+	})
+
+	request := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should list without filtering when no filter is given", func() {
+		request("/api/links")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(unfilteredUsed).To(BeTrue())
+		Expect(filter).To(BeNil())
+	})
+
+	It("should filter on creation time", func() {
+		request("/api/links?created_after=2024-05-01T08:30:00Z")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(filter.CreatedAfter).To(HaveValue(BeTemporally("==", time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC))))
+		Expect(filter.ExpiresBefore).To(BeNil())
+		Expect(filter.Status).To(BeEmpty())
+	})
+
+	It("should accept a date for expires_before", func() {
+		request("/api/links?expires_before=2024-06-08")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(filter.ExpiresBefore).To(HaveValue(BeTemporally("==", time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC))))
+	})
+
+	DescribeTable("should filter on status",
+		func(status string) {
+			request("/api/links?status=" + status)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(filter.Status).To(Equal(status))
+		},
+		Entry("active", domain.LinkStatusActive),
+		Entry("expired", domain.LinkStatusExpired),
+		Entry("inactive", domain.LinkStatusInactive),
+	)
+
+	It("should filter on collection", func() {
+		request("/api/links?collection=col-1&status=active")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(filter.CollectionID).To(Equal("col-1"))
+		Expect(filter.Status).To(Equal(domain.LinkStatusActive))
+		Expect(unfilteredUsed).To(BeFalse())
+	})
+
+	It("should answer 404 for a collection the caller can't see", func() {
+		request("/api/links?collection=someone-elses")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Collection not found"))
+	})
+
+	It("should combine filters", func() {
+		request("/api/links?status=active&expires_before=2024-06-08&created_after=2024-01-01")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(filter.Status).To(Equal(domain.LinkStatusActive))
+		Expect(filter.ExpiresBefore).NotTo(BeNil())
+		Expect(filter.CreatedAfter).NotTo(BeNil())
+		Expect(unfilteredUsed).To(BeFalse())
+	})
+
+	DescribeTable("should reject invalid filters",
+		func(query, message string) {
+			request("/api/links?" + query)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring(message))
+			Expect(filter).To(BeNil())
+		},
+		Entry("unknown status", "status=archived", "status must be active, expired or inactive"),
+		Entry("malformed time", "created_after=yesterday", "created_after must be"),
+		Entry("with a cursor", "status=active&cursor=", "cannot be combined with cursor"),
+	)
+})
+
+var _ = Describe("LinkHandler page metadata", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		total    int
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+
+		svc := &MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return []*domain.ShortLink{}, total, nil
+			},
+			ListShortLinksFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return []*domain.ShortLink{}, total, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "https://sho.rt/", nil)
+		router.GET("/api/links", handler.ListLinks)
+	})
+
+	meta := func(path string) handlers.PageMeta {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var body struct {
+			Meta handlers.PageMeta `json:"meta"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body.Meta
+	}
+
+	It("should link only forward from the first page", func() {
+		total = 25
+
+		Expect(meta("/api/links?page_size=10")).To(Equal(handlers.PageMeta{
+			Total:      25,
+			Page:       1,
+			PerPage:    10,
+			TotalPages: 3,
+			HasNext:    true,
+			Next:       "https://sho.rt/api/links?page=2&page_size=10",
+		}))
+	})
+
+	It("should link only back from the last page", func() {
+		total = 25
+
+		Expect(meta("/api/links?page=3&page_size=10")).To(Equal(handlers.PageMeta{
+			Total:      25,
+			Page:       3,
+			PerPage:    10,
+			TotalPages: 3,
+			HasPrev:    true,
+			Prev:       "https://sho.rt/api/links?page=2&page_size=10",
+		}))
+	})
+
+	It("should link both ways from a middle page and keep the filters", func() {
+		total = 25
+
+		m := meta("/api/links?page=2&page_size=10&status=active")
+
+		Expect(m.Next).To(Equal("https://sho.rt/api/links?page=3&page_size=10&status=active"))
+		Expect(m.Prev).To(Equal("https://sho.rt/api/links?page=1&page_size=10&status=active"))
+	})
+
+	It("should report no pages for an empty result set", func() {
+		total = 0
+
+		Expect(meta("/api/links")).To(Equal(handlers.PageMeta{
+			Total:   0,
+			Page:    1,
+			PerPage: handlers.DefaultPageSize,
+		}))
+	})
+
+	It("should link back to the last page from past the end", func() {
+		total = 25
+
+		m := meta("/api/links?page=7&page_size=10")
+
+		Expect(m.HasNext).To(BeFalse())
+		Expect(m.HasPrev).To(BeTrue())
+		Expect(m.Prev).To(Equal("https://sho.rt/api/links?page=3&page_size=10"))
+	})
+
+	Context("in the Link header", func() {
+		BeforeEach(func() {
+			total = 25
+		})
+
+		It("should only point forward from the first page", func() {
+			meta("/api/links?page_size=10")
+
+			Expect(recorder.Header().Get("Link")).To(Equal(
+				`<https://sho.rt/api/links?page=2&page_size=10>; rel="next"`))
+		})
+
+		It("should only point back from the last page", func() {
+			meta("/api/links?page=3&page_size=10")
+
+			Expect(recorder.Header().Get("Link")).To(Equal(
+				`<https://sho.rt/api/links?page=2&page_size=10>; rel="prev"`))
+		})
+
+		It("should point both ways from a middle page", func() {
+			meta("/api/links?page=2&page_size=10")
+
+			Expect(recorder.Header().Get("Link")).To(Equal(
+				`<https://sho.rt/api/links?page=3&page_size=10>; rel="next", ` +
+					`<https://sho.rt/api/links?page=1&page_size=10>; rel="prev"`))
+		})
+
+		It("should be left out when everything fits on one page", func() {
+			total = 5
+
+			meta("/api/links?page_size=10")
+
+			Expect(recorder.Header().Values("Link")).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("List envelope", func() {
+	// envelopeKeys requests path from handler and returns the top-level keys
+	// of the body along with the keys of its meta
+	envelopeKeys := func(handler gin.HandlerFunc, path string) ([]string, []string) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/links", func(c *gin.Context) {
+			c.Set("user_id", "user-123")
+			handler(c)
+		})
+
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		meta, ok := body["meta"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+
+		keys := func(m map[string]interface{}) []string {
+			var out []string
+			for k := range m {
+				out = append(out, k)
+			}
+			return out
+		}
+		return keys(body), keys(meta)
+	}
+
+	It("should be the same for short links and legacy links", func() {
+		shortLinks := handlers.NewLinkHandler(&MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return []*domain.ShortLink{{ID: "link-1", Code: "abc123"}}, 25, nil
+			},
+		}, "http://localhost:8081", nil)
+		legacyLinks := handlers.NewMockLinkHandler(nil, zap.NewNop(), &MockLinkService{
+			ListLinksFunc: func(userID string, page, perPage int) ([]*domain.Link, int, error) {
+				return []*domain.Link{{ID: "link-1", ShortURL: "abc123"}}, 25, nil
+			},
+		})
+
+		shortKeys, shortMeta := envelopeKeys(shortLinks.ListLinks, "/api/links?page=2")
+		legacyKeys, legacyMeta := envelopeKeys(legacyLinks.ListLinksForTest, "/api/links?page=2")
+
+		Expect(shortKeys).To(ConsistOf("data", "meta"))
+		Expect(legacyKeys).To(ConsistOf(shortKeys))
+		Expect(shortMeta).To(ConsistOf("total", "page", "per_page", "total_pages", "has_next", "has_prev", "next", "prev"))
+		Expect(legacyMeta).To(ConsistOf(shortMeta))
+	})
+
+	It("should send an empty list rather than null", func() {
+		handler := handlers.NewLinkHandler(&MockShortenerService{
+			ListShortLinksFunc: func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+				return nil, 0, nil
+			},
+		}, "http://localhost:8081", nil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/links", handler.ListLinks)
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/links", nil)
+		router.ServeHTTP(recorder, req)
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("data", BeEmpty()))
+		Expect(body["data"]).NotTo(BeNil())
+	})
+})
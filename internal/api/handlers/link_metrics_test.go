@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/metrics"
+)
+
+// The built-in collector is one MetricsRecorder implementation
+var _ handlers.MetricsRecorder = (*metrics.Metrics)(nil)
+
+var _ = Describe("LinkHandler metrics", func() {
+	var (
+		router   *gin.Engine
+		recorder *MockMetricsRecorder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = &MockMetricsRecorder{}
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code == "missing" {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{
+					ID:       "link-" + code,
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/" + code},
+				}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", recorder)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	visit := func(path string) int {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(response, req)
+		return response.Code
+	}
+
+	It("should record each redirect with its link ID", func() {
+		Expect(visit("/docs")).To(Equal(http.StatusMovedPermanently))
+		Expect(visit("/docs")).To(Equal(http.StatusMovedPermanently))
+		Expect(visit("/team")).To(Equal(http.StatusMovedPermanently))
+
+		Expect(recorder.Redirects).To(Equal([]string{"link-docs", "link-docs", "link-team"}))
+	})
+
+	It("should not record a redirect for an unknown code", func() {
+		Expect(visit("/missing")).To(Equal(http.StatusNotFound))
+
+		Expect(recorder.Redirects).To(BeEmpty())
+	})
+
+	It("should redirect without a recorder", func() {
+		handler := handlers.NewLinkHandler(&MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true, URL: &domain.URL{OriginalURL: "https://example.com"}}, nil
+			},
+		}, "http://localhost:8081", nil)
+		router = gin.New()
+		router.GET("/:code", handler.RedirectLink)
+
+		Expect(visit("/docs")).To(Equal(http.StatusMovedPermanently))
+	})
+})
+
+// MockMetricsRecorder is a mock implementation of MetricsRecorder
+type MockMetricsRecorder struct {
+	Redirects []string
+}
+
+func (m *MockMetricsRecorder) RecordRedirect(linkID string) {
+	m.Redirects = append(m.Redirects, linkID)
+}
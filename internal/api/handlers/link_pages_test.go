@@ -0,0 +1,278 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler pages", func() {
+	var (
+		router   *gin.Engine
+		svc      *MockShortenerService
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		svc = &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code != "abc123" {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+	})
+
+	request := func(path, acceptLanguage string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	Describe("not found page", func() {
+		It("renders in French for fr", func() {
+			request("/missing", "fr-FR,fr;q=0.9")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(ContainSubstring("text/html"))
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("fr"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`lang="fr"`))
+			Expect(recorder.Body.String()).To(ContainSubstring("Lien introuvable"))
+		})
+
+		It("renders in Spanish for es", func() {
+			request("/missing", "es")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("es"))
+			Expect(recorder.Body.String()).To(ContainSubstring("Enlace no encontrado"))
+		})
+
+		It("falls back to the default locale for an unknown language", func() {
+			request("/missing", "de-DE,de;q=0.9")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("en"))
+			Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+		})
+
+		It("prefers the supported language with the highest quality", func() {
+			request("/missing", "de;q=1.0, es;q=0.5, fr;q=0.8")
+
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("fr"))
+		})
+
+		It("uses the configured fallback locale", func() {
+			router = gin.New()
+			handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+				DefaultLocale: "es",
+			})
+			router.GET("/:code", handler.RedirectLink)
+
+			request("/missing", "ja")
+
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("es"))
+		})
+	})
+
+	Describe("preview page", func() {
+		It("renders the destination in the requested language", func() {
+			request("/abc123/preview", "fr")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Language")).To(Equal("fr"))
+			Expect(recorder.Body.String()).To(ContainSubstring("Vous quittez ce site"))
+			Expect(recorder.Body.String()).To(ContainSubstring("https://example.com/destination"))
+		})
+
+		It("renders the localized not found page for unknown codes", func() {
+			request("/nope/preview", "es")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Enlace no encontrado"))
+		})
+
+		Context("caching", func() {
+			conditional := func(etag string) {
+				recorder = httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, "/abc123/preview", nil)
+				req.Header.Set("If-None-Match", etag)
+				router.ServeHTTP(recorder, req)
+			}
+
+			It("marks the page as publicly cacheable with an ETag", func() {
+				request("/abc123/preview", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Header().Get("Cache-Control")).To(Equal("public, max-age=300"))
+				Expect(recorder.Header().Get("ETag")).To(MatchRegexp(`^"[0-9a-f]+"$`))
+				Expect(recorder.Header().Get("Vary")).To(ContainSubstring("Accept-Encoding"))
+			})
+
+			It("returns 304 when the ETag matches", func() {
+				request("/abc123/preview", "")
+				etag := recorder.Header().Get("ETag")
+
+				conditional(etag)
+
+				Expect(recorder.Code).To(Equal(http.StatusNotModified))
+				Expect(recorder.Body.Len()).To(BeZero())
+				Expect(recorder.Header().Get("ETag")).To(Equal(etag))
+			})
+
+			It("accepts a weak validator in a list", func() {
+				request("/abc123/preview", "")
+				etag := recorder.Header().Get("ETag")
+
+				conditional(`"stale", W/` + etag)
+
+				Expect(recorder.Code).To(Equal(http.StatusNotModified))
+			})
+
+			It("returns the full page when the ETag is stale", func() {
+				conditional(`"stale"`)
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(ContainSubstring("https://example.com/destination"))
+			})
+
+			It("does not cache a preview past the link's expiry", func() {
+				expiresAt := time.Now().Add(90 * time.Second)
+				svc.GetShortLinkByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{
+						Code:           code,
+						IsActive:       true,
+						ExpirationDate: &expiresAt,
+						URL:            &domain.URL{OriginalURL: "https://example.com/destination"},
+					}, nil
+				}
+
+				request("/abc123/preview", "")
+
+				Expect(recorder.Header().Get("Cache-Control")).To(MatchRegexp(`^public, max-age=(8\d|90)$`))
+			})
+
+			It("keeps the not found page out of caches", func() {
+				request("/nope/preview", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Header().Get("Cache-Control")).To(Equal("no-store"))
+				Expect(recorder.Header().Get("ETag")).To(BeEmpty())
+			})
+		})
+
+		Context("behind the preview pages feature flag", func() {
+			newRouter := func(features config.FeaturesConfig) {
+				router = gin.New()
+				handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+					Features: features,
+				})
+				router.GET("/:code/preview", handler.PreviewLink)
+			}
+
+			It("returns 404 when the feature is disabled", func() {
+				newRouter(config.FeaturesConfig{PreviewPages: false})
+
+				request("/abc123/preview", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).NotTo(ContainSubstring("https://example.com/destination"))
+			})
+
+			It("renders the page when the feature is enabled", func() {
+				newRouter(config.FeaturesConfig{PreviewPages: true})
+
+				request("/abc123/preview", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(ContainSubstring("https://example.com/destination"))
+			})
+		})
+	})
+
+	Describe("not found handling", func() {
+		newRouter := func(opts handlers.LinkHandlerOptions) {
+			router = gin.New()
+			handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, opts)
+			router.GET("/:code", handler.RedirectLink)
+		}
+
+		It("renders a branded HTML page by default", func() {
+			newRouter(handlers.LinkHandlerOptions{BrandName: "Acme Links"})
+
+			request("/missing", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(ContainSubstring("text/html"))
+			Expect(recorder.Body.String()).To(ContainSubstring("<title>Link not found | Acme Links</title>"))
+			Expect(recorder.Body.String()).To(ContainSubstring("<header>Acme Links</header>"))
+		})
+
+		It("redirects to the fallback URL when configured", func() {
+			newRouter(handlers.LinkHandlerOptions{
+				NotFoundMode:        handlers.NotFoundRedirect,
+				NotFoundRedirectURL: "https://example.com/not-found",
+			})
+
+			request("/missing", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/not-found"))
+		})
+
+		It("redirects expired links to the fallback URL", func() {
+			expired := time.Now().Add(-time.Hour)
+			svc.GetShortLinkByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{
+					ID:             "link-1",
+					Code:           code,
+					IsActive:       true,
+					ExpirationDate: &expired,
+					URL:            &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			}
+			newRouter(handlers.LinkHandlerOptions{
+				NotFoundMode:        handlers.NotFoundRedirect,
+				NotFoundRedirectURL: "https://example.com/not-found",
+			})
+
+			request("/abc123", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/not-found"))
+		})
+
+		It("falls back to the page when redirect mode has no URL", func() {
+			newRouter(handlers.LinkHandlerOptions{NotFoundMode: handlers.NotFoundRedirect})
+
+			request("/missing", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidSuffix is returned for pattern link suffixes that could leave
+// the destination's path
+var errInvalidSuffix = errors.New("path suffix must not contain empty, dot or dot-dot segments or backslashes")
+
+// RedirectPattern resolves request paths with more than one segment, such
+// as /docs/guides/setup, against pattern links: the first segment is the
+// code and the rest is appended to the destination. It is meant as the
+// router's NoRoute handler, since /:code/*suffix would clash with the
+// /:code/... routes; requests it doesn't handle keep the default 404.
+func (h *LinkHandler) RedirectPattern(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return
+	}
+
+	// API routes keep their plain 404
+	path := c.Request.URL.Path
+	if strings.HasPrefix(path, h.apiPrefix()+"/") {
+		return
+	}
+
+	code, suffix, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !found || code == "" || suffix == "" {
+		return
+	}
+
+	h.redirect(c, code, suffix)
+}
+
+// patternDestination appends the path suffix of a pattern link visit to
+// its destination. Each segment is escaped on its own and dot segments are
+// refused, so the result always stays below the destination's path.
+func patternDestination(destination, suffix string) (string, error) {
+	dest, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(suffix, "/")
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		// A trailing slash is kept, any other empty segment is refused
+		if segment == "" && i == len(segments)-1 {
+			continue
+		}
+		if segment == "" || segment == "." || segment == ".." || strings.Contains(segment, `\`) {
+			return "", errInvalidSuffix
+		}
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	rawPath := strings.TrimSuffix(dest.EscapedPath(), "/") + "/" + strings.Join(escaped, "/")
+	unescaped, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", err
+	}
+	dest.Path = unescaped
+	dest.RawPath = rawPath
+
+	return dest.String(), nil
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler pattern links", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		lookups  []string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		lookups = nil
+
+		links := map[string]*domain.ShortLink{
+			"docs": {
+				ID:        "link-docs",
+				Code:      "docs",
+				IsActive:  true,
+				IsPattern: true,
+				URL:       &domain.URL{OriginalURL: "https://intranet.example.com/docs"},
+			},
+			"plain": {
+				ID:       "link-plain",
+				Code:     "plain",
+				IsActive: true,
+				URL:      &domain.URL{OriginalURL: "https://example.com/landing"},
+			},
+		}
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				lookups = append(lookups, code)
+				if link, ok := links[code]; ok {
+					return link, nil
+				}
+				return nil, errors.New("short link not found")
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+				return nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+		router.NoRoute(handler.RedirectPattern)
+	})
+
+	request := func(target string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should append the rest of the path to the destination", func() {
+		request("/docs/guides/setup")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://intranet.example.com/docs/guides/setup"))
+	})
+
+	It("should keep a trailing slash and escape each segment", func() {
+		request("/docs/release%20notes/2024/")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://intranet.example.com/docs/release%20notes/2024/"))
+	})
+
+	It("should redirect the bare code to the destination itself", func() {
+		request("/docs")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://intranet.example.com/docs"))
+	})
+
+	DescribeTable("should reject suffixes that could leave the destination path",
+		func(target string) {
+			request(target)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Header().Get("Location")).To(BeEmpty())
+		},
+		Entry("dot-dot segment", "/docs/../../admin"),
+		Entry("encoded dot-dot segment", "/docs/%2e%2e/admin"),
+		Entry("encoded slash hiding dot-dot", "/docs/a%2F..%2F..%2Fadmin"),
+		Entry("dot segment", "/docs/./a"),
+		Entry("empty segment", "/docs/a//b"),
+		Entry("backslash", "/docs/a%5C..%5Cadmin"),
+	)
+
+	It("should not forward a suffix for links that are not patterns", func() {
+		request("/plain/extra")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Location")).To(BeEmpty())
+	})
+
+	It("should leave unknown API paths alone", func() {
+		request("/api/unknown/path")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(lookups).To(BeEmpty())
+	})
+})
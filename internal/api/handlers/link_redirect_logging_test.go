@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler redirect logging", func() {
+	var (
+		router *gin.Engine
+		logs   *observer.ObservedLogs
+		clicks chan string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		clicks = make(chan string, 1)
+
+		// Without a request logger in the context the handler uses the global one
+		var core zapcore.Core
+		core, logs = observer.New(zapcore.InfoLevel)
+		DeferCleanup(zap.ReplaceGlobals(zap.New(core)))
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+				clicks <- shortLinkID
+				return nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	It("should write a single info line for a successful redirect", func() {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/abc123", nil)
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Eventually(clicks).Should(Receive())
+		Consistently(logs.Len).Should(BeNumerically("<=", 1))
+
+		entries := logs.All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Message).To(Equal("Redirect"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("code", "abc123"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("status", int64(http.StatusMovedPermanently)))
+	})
+})
+
+var _ = Describe("LinkHandler destination logging", func() {
+	const destination = "https://example.com/reset?token=s3cret"
+
+	var (
+		router *gin.Engine
+		logs   *observer.ObservedLogs
+	)
+
+	serve := func(mode string) {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+
+		var core zapcore.Core
+		core, logs = observer.New(zapcore.DebugLevel)
+		DeferCleanup(zap.ReplaceGlobals(zap.New(core)))
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: destination},
+				}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features:        config.DefaultFeatures(),
+			LogDestinations: mode,
+		})
+		router.GET("/:code", handler.RedirectLink)
+	}
+
+	// redirectLog follows a short link and returns the fields of its info line
+	redirectLog := func() map[string]interface{} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/abc123", nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Header().Get("Location")).To(Equal(destination))
+
+		entries := logs.FilterMessage("Redirect").All()
+		Expect(entries).To(HaveLen(1))
+		return entries[0].ContextMap()
+	}
+
+	It("should log the full destination by default", func() {
+		serve("")
+
+		Expect(redirectLog()).To(HaveKeyWithValue("destination", destination))
+	})
+
+	It("should log only a hash of the destination when hashing", func() {
+		serve(handlers.LogDestinationsHash)
+		sum := sha256.Sum256([]byte(destination))
+
+		fields := redirectLog()
+
+		Expect(fields).To(HaveKeyWithValue("destination_hash", hex.EncodeToString(sum[:])))
+		Expect(fields).NotTo(HaveKey("destination"))
+		Expect(fields).To(HaveKeyWithValue("code", "abc123"))
+		Expect(fields).To(HaveKeyWithValue("link_id", "link-1"))
+		for _, entry := range logs.All() {
+			Expect(fmt.Sprint(entry.ContextMap())).NotTo(ContainSubstring("s3cret"))
+		}
+	})
+
+	It("should leave the destination out when omitting", func() {
+		serve(handlers.LogDestinationsOmit)
+
+		fields := redirectLog()
+
+		Expect(fields).NotTo(HaveKey("destination"))
+		Expect(fields).NotTo(HaveKey("destination_hash"))
+		Expect(fields).To(HaveKeyWithValue("code", "abc123"))
+		Expect(fields).To(HaveKeyWithValue("link_id", "link-1"))
+		for _, entry := range logs.All() {
+			Expect(fmt.Sprint(entry.ContextMap())).NotTo(ContainSubstring("example.com"))
+		}
+	})
+})
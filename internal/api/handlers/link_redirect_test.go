@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler redirect", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		clicks   chan string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		clicks = make(chan string, 1)
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+				clicks <- shortLinkID
+				return nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	request := func(accept string) {
+		req, _ := http.NewRequest(http.MethodGet, "/abc123", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should return the destination as JSON for API clients", func() {
+		request("application/json")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(ContainSubstring("application/json"))
+		Expect(recorder.Header().Get("Vary")).To(Equal("Accept"))
+
+		var body map[string]string
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(map[string]string{
+			"original_url": "https://example.com/destination",
+			"code":         "abc123",
+		}))
+		Eventually(clicks).Should(Receive(Equal("link-1")))
+	})
+
+	It("should redirect when no Accept header is sent", func() {
+		request("")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/destination"))
+		Eventually(clicks).Should(Receive(Equal("link-1")))
+	})
+
+	It("should redirect browsers", func() {
+		request("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/destination"))
+		Eventually(clicks).Should(Receive(Equal("link-1")))
+	})
+})
@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler reuse_existing", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		received *domain.CreateShortLinkRequest
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		received = nil
+
+		svc := &MockShortenerService{
+			CreateShortLinkFunc: func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+				received = req
+				if req.ReuseExisting {
+					return &domain.ShortLink{ID: "link-1", Code: "old123", IsActive: true, Reused: true}, nil
+				}
+				return &domain.ShortLink{ID: "link-2", Code: "new456", IsActive: true}, nil
+			},
+		}
+
+		router = gin.New()
+		handler := handlers.NewLinkHandler(svc, "https://sho.rt", nil)
+		router.POST("/api/links", handler.CreateLink)
+	})
+
+	create := func(body string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should answer 200 with the existing link when it is reused", func() {
+		create(`{"url":"https://example.com","reuse_existing":true}`)
+
+		Expect(received.ReuseExisting).To(BeTrue())
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"code":"old123"`))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"reused":true`))
+	})
+
+	It("should answer 201 for a new link by default", func() {
+		create(`{"url":"https://example.com"}`)
+
+		Expect(received.ReuseExisting).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring(`"reused"`))
+	})
+})
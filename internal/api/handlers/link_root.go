@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/api/pages"
+)
+
+// Root handles requests for the root path
+// @Summary Root landing
+// @Description Answers GET / with the configured root behavior: the not found page, a redirect to a landing site or a short status page
+// @Tags redirect
+// @Produce html
+// @Success 200 {string} string "Status page"
+// @Success 302 {string} string "Redirect to the landing site"
+// @Failure 404 {string} string "Not found page"
+// @Router / [get]
+func (h *LinkHandler) Root(c *gin.Context) {
+	switch h.opts.RootMode {
+	case RootRedirect:
+		if h.opts.RootRedirectURL != "" {
+			c.Redirect(http.StatusFound, h.opts.RootRedirectURL)
+			return
+		}
+	case RootStatus:
+		h.renderPage(c, http.StatusOK, pages.Status, pages.Data{}, 0)
+		return
+	}
+
+	h.renderPage(c, http.StatusNotFound, pages.NotFound, pages.Data{}, 0)
+}
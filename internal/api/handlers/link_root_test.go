@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler root path", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	newRouter := func(opts handlers.LinkHandlerOptions) {
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code != "a" {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/destination"},
+				}, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID, referrer, userAgent, ipAddress, rawQuery string) error {
+				return nil
+			},
+		}
+
+		opts.Features = config.DefaultFeatures()
+		router = gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, opts)
+		router.GET("/", handler.Root)
+		router.GET("/:code", handler.RedirectLink)
+	}
+
+	request := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+	})
+
+	It("renders the not found page by default", func() {
+		newRouter(handlers.LinkHandlerOptions{})
+
+		request("/")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+	})
+
+	It("redirects to the landing site in redirect mode", func() {
+		newRouter(handlers.LinkHandlerOptions{
+			RootMode:        handlers.RootRedirect,
+			RootRedirectURL: "https://www.example.com/",
+		})
+
+		request("/")
+
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://www.example.com/"))
+	})
+
+	It("falls back to the not found page in redirect mode without a URL", func() {
+		newRouter(handlers.LinkHandlerOptions{RootMode: handlers.RootRedirect})
+
+		request("/")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("renders the status page in status mode", func() {
+		newRouter(handlers.LinkHandlerOptions{RootMode: handlers.RootStatus, BrandName: "Acme Links"})
+
+		request("/")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(ContainSubstring("text/html"))
+		Expect(recorder.Body.String()).To(ContainSubstring("Service is running"))
+		Expect(recorder.Body.String()).To(ContainSubstring("Acme Links"))
+	})
+
+	It("still redirects single-character codes", func() {
+		newRouter(handlers.LinkHandlerOptions{
+			RootMode:        handlers.RootRedirect,
+			RootRedirectURL: "https://www.example.com/",
+		})
+
+		request("/a")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/destination"))
+	})
+})
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// MaxStatsBatchCodes bounds how many codes a single batched stats request may name
+const MaxStatsBatchCodes = 100
+
+// GetLinkStatsBatch handles retrieving summary statistics for several links
+// @Summary Get statistics for several links
+// @Description Get total clicks, unique visitors and last click time for up to 100 links in one request. Codes that do not resolve to a link of the caller are listed in not_found.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param request body domain.LinkStatsBatchRequest true "Codes to summarize"
+// @Success 200 {object} domain.LinkStatsBatch "Summaries keyed by code"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 422 {object} map[string]interface{} "Rejected fields"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /links/stats [post]
+func (h *LinkHandler) GetLinkStatsBatch(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	var req domain.LinkStatsBatchRequest
+	if err := bindJSONFields(c, &req); err != nil {
+		logger.Info("Failed to decode request body", zap.Error(err))
+		respondBindError(c, err)
+		return
+	}
+
+	verr := &domain.ValidationError{}
+	switch {
+	case len(req.Codes) == 0:
+		verr.Add("codes", "must name at least one code")
+	case len(req.Codes) > MaxStatsBatchCodes:
+		verr.Add("codes", fmt.Sprintf("must not name more than %d codes", MaxStatsBatchCodes))
+	}
+	if verr.Err() != nil {
+		respondValidationError(c, verr)
+		return
+	}
+
+	// Resolve codes to links; a code and an alias of the same link share its ID
+	batch := &domain.LinkStatsBatch{
+		Stats:    make(map[string]*domain.LinkStatsSummary, len(req.Codes)),
+		NotFound: []string{},
+	}
+	codesByID := make(map[string][]string, len(req.Codes))
+	var ids []string
+	seen := make(map[string]bool, len(req.Codes))
+	for _, code := range req.Codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		if code == "" || h.codeTooLong(code) {
+			batch.NotFound = append(batch.NotFound, code)
+			continue
+		}
+
+		link, err := h.linkService.GetShortLinkByCode(c.Request.Context(), code)
+		if err != nil {
+			logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
+			batch.NotFound = append(batch.NotFound, code)
+			continue
+		}
+
+		// Someone else's link is reported like a missing one, so the
+		// response does not reveal which codes exist
+		if !ownsLink(c, link) {
+			logger.Info("Refused stats of another user's link", zap.String("code", code))
+			batch.NotFound = append(batch.NotFound, code)
+			continue
+		}
+
+		if _, ok := codesByID[link.ID]; !ok {
+			ids = append(ids, link.ID)
+		}
+		codesByID[link.ID] = append(codesByID[link.ID], code)
+	}
+
+	if len(ids) > 0 {
+		summaries, err := h.linkService.GetLinkStatsSummaries(c.Request.Context(), ids)
+		if err != nil {
+			logger.Error("Failed to get link stats summaries", zap.Int("links", len(ids)), zap.Error(err))
+			respondError(c, http.StatusInternalServerError, "Failed to get link statistics")
+			return
+		}
+
+		for _, id := range ids {
+			summary, ok := summaries[id]
+			if !ok {
+				summary = &domain.LinkStatsSummary{}
+			}
+			for _, code := range codesByID[id] {
+				batch.Stats[code] = summary
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
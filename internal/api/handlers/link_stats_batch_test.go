@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler batched stats", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		svc      *MockShortenerService
+		batches  [][]string
+		owners   map[string]*string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		batches = nil
+		owners = map[string]*string{}
+
+		links := map[string]string{"abc123": "link-1", "def456": "link-2", "promo": "link-1"}
+		svc = &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				id, ok := links[code]
+				if !ok {
+					return nil, fmt.Errorf("short link not found: %w", domain.ErrNotFound)
+				}
+				return &domain.ShortLink{ID: id, Code: code, OwnerID: owners[id]}, nil
+			},
+			GetLinkStatsSummariesFunc: func(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+				batches = append(batches, shortLinkIDs)
+				return map[string]*domain.LinkStatsSummary{
+					"link-1": {TotalClicks: 12, UniqueVisitors: 5},
+				}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.POST("/api/links/stats", handler.GetLinkStatsBatch)
+	})
+
+	requestAs := func(userID, body string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodPost, "/api/links/stats", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if userID != "" {
+			req = req.WithContext(auth.WithUserID(req.Context(), userID))
+		}
+		router.ServeHTTP(recorder, req)
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	request := func(body string) map[string]interface{} {
+		return requestAs("", body)
+	}
+
+	It("should summarize existing codes and list missing ones", func() {
+		resp := request(`{"codes":["abc123","missing","def456"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp["stats"]).To(Equal(map[string]interface{}{
+			"abc123": map[string]interface{}{"total_clicks": 12.0, "unique_visitors": 5.0},
+			"def456": map[string]interface{}{"total_clicks": 0.0, "unique_visitors": 0.0},
+		}))
+		Expect(resp["not_found"]).To(ConsistOf("missing"))
+	})
+
+	It("should fetch every summary in one call", func() {
+		request(`{"codes":["abc123","def456","promo","abc123"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(batches).To(HaveLen(1))
+		Expect(batches[0]).To(ConsistOf("link-1", "link-2"))
+	})
+
+	It("should report a code and an alias of the same link under each name", func() {
+		resp := request(`{"codes":["abc123","promo"]}`)
+
+		stats := resp["stats"].(map[string]interface{})
+		Expect(stats).To(HaveKey("abc123"))
+		Expect(stats["promo"]).To(Equal(stats["abc123"]))
+	})
+
+	It("should skip the stats query when no code resolves", func() {
+		resp := request(`{"codes":["missing"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp["stats"]).To(BeEmpty())
+		Expect(resp["not_found"]).To(ConsistOf("missing"))
+		Expect(batches).To(BeEmpty())
+	})
+
+	It("should summarize the caller's own links", func() {
+		alice := "alice"
+		owners["link-1"] = &alice
+		owners["link-2"] = &alice
+
+		resp := requestAs("alice", `{"codes":["abc123","def456"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp["stats"]).To(HaveLen(2))
+		Expect(resp["not_found"]).To(BeEmpty())
+	})
+
+	It("should report another user's links as not found", func() {
+		alice, bob := "alice", "bob"
+		owners["link-1"] = &alice
+		owners["link-2"] = &bob
+
+		resp := requestAs("alice", `{"codes":["abc123","def456","promo","missing"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp["stats"]).To(Equal(map[string]interface{}{
+			"abc123": map[string]interface{}{"total_clicks": 12.0, "unique_visitors": 5.0},
+			"promo":  map[string]interface{}{"total_clicks": 12.0, "unique_visitors": 5.0},
+		}))
+		Expect(resp["not_found"]).To(ConsistOf("def456", "missing"))
+		Expect(batches).To(Equal([][]string{{"link-1"}}))
+	})
+
+	It("should not summarize a user's links for a caller without one", func() {
+		alice := "alice"
+		owners["link-1"] = &alice
+
+		resp := request(`{"codes":["abc123","def456"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp["stats"]).To(HaveKey("def456"))
+		Expect(resp["not_found"]).To(ConsistOf("abc123"))
+	})
+
+	It("should reject an empty list of codes", func() {
+		resp := request(`{"codes":[]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(resp["fields"]).To(ConsistOf(HaveKeyWithValue("field", "codes")))
+	})
+
+	It("should reject more codes than one batch allows", func() {
+		codes := make([]string, handlers.MaxStatsBatchCodes+1)
+		for i := range codes {
+			codes[i] = fmt.Sprintf("code%d", i)
+		}
+		body, _ := json.Marshal(domain.LinkStatsBatchRequest{Codes: codes})
+
+		request(string(body))
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(batches).To(BeEmpty())
+	})
+
+	It("should answer 500 when the summaries cannot be read", func() {
+		svc.GetLinkStatsSummariesFunc = func(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+			return nil, errors.New("connection refused")
+		}
+
+		request(`{"codes":["abc123"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
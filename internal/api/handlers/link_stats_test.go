@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler stats time zone", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		gotLoc   *time.Location
+		called   bool
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		gotLoc, called = nil, false
+
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code}, nil
+			},
+			GetLinkStatsFunc: func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+				gotLoc, called = loc, true
+				return &domain.LinkStats{Timezone: loc.String()}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features:      config.DefaultFeatures(),
+			StatsLocation: berlin,
+		})
+		router.GET("/api/links/:code/stats", handler.GetLinkStats)
+	})
+
+	request := func(target string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	It("should use the configured time zone by default", func() {
+		request("/api/links/abc123/stats")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(gotLoc.String()).To(Equal("Europe/Berlin"))
+	})
+
+	It("should let the request pick another time zone", func() {
+		request("/api/links/abc123/stats?tz=America/New_York")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(gotLoc.String()).To(Equal("America/New_York"))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"timezone":"America/New_York"`))
+	})
+
+	It("should reject an unknown time zone", func() {
+		request("/api/links/abc123/stats?tz=Nowhere/Special")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(called).To(BeFalse())
+	})
+})
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler click tracking", func() {
+	var (
+		router   *gin.Engine
+		metrics  *MockMetricsRecorder
+		recorded chan string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		metrics = &MockMetricsRecorder{}
+		recorded = make(chan string, 10)
+
+		tracked, untracked := true, false
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				link := &domain.ShortLink{
+					ID:       "link-" + code,
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: "https://example.com/" + code},
+				}
+				switch code {
+				case "quiet":
+					link.TrackClicks = &untracked
+				case "loud":
+					link.TrackClicks = &tracked
+				}
+				return link, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID, referrer, userAgent, ipAddress, rawQuery string) error {
+				recorded <- shortLinkID
+				return nil
+			},
+		}
+
+		router = gin.New()
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", metrics)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	visit := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should redirect without recording a click when tracking is off", func() {
+		resp := visit("/quiet")
+
+		Expect(resp.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(resp.Header().Get("Location")).To(Equal("https://example.com/quiet"))
+		Consistently(recorded, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should still count redirects of untracked links", func() {
+		visit("/quiet")
+		visit("/quiet")
+
+		Expect(metrics.Redirects).To(Equal([]string{"link-quiet", "link-quiet"}))
+	})
+
+	It("should record clicks when tracking is on or unset", func() {
+		visit("/loud")
+		visit("/other")
+
+		// Clicks are recorded in the background, in no particular order
+		var ids []string
+		for range 2 {
+			var id string
+			Eventually(recorded).Should(Receive(&id))
+			ids = append(ids, id)
+		}
+		Expect(ids).To(ConsistOf("link-loud", "link-other"))
+	})
+})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// linkBody is the response for a single link. ExpiresInSeconds saves
+// clients from computing the countdown themselves and is null for links
+// that never expire. ShortURL and StatsURL are ready-to-use absolute URLs,
+// so clients don't have to know the public base URL or API layout; they
+// are only set when the LinkURLs feature is on. DisplayCode is the code
+// formatted for people and is only set when it differs from the stored one.
+type linkBody struct {
+	*domain.ShortLink
+	DisplayCode      string `json:"display_code,omitempty"`
+	ExpiresInSeconds *int64 `json:"expires_in_seconds"`
+	ShortURL         string `json:"short_url,omitempty"`
+	StatsURL         string `json:"stats_url,omitempty"`
+}
+
+// linkResponse returns the body for a single link response
+func (h *LinkHandler) linkResponse(link *domain.ShortLink) linkBody {
+	body := linkBody{
+		ShortLink:        link,
+		ExpiresInSeconds: expiresInSeconds(link.ExpirationDate, time.Now()),
+	}
+
+	if h.opts.DisplayCode != nil {
+		if display := h.opts.DisplayCode(link); display != link.Code {
+			body.DisplayCode = display
+		}
+	}
+
+	if h.opts.Features.LinkURLs {
+		base := strings.TrimRight(h.baseURL, "/")
+		code := url.PathEscape(link.Code)
+
+		// The short URL is the one people see and type, so it uses the display code
+		body.ShortURL = base + "/" + code
+		if body.DisplayCode != "" {
+			body.ShortURL = base + "/" + url.PathEscape(body.DisplayCode)
+		}
+		body.StatsURL = base + h.apiPrefix() + "/links/" + code + "/stats"
+	}
+
+	return body
+}
+
+// expiresInSeconds returns the whole seconds left until expiry as of now,
+// 0 once it has passed and nil without an expiry
+func expiresInSeconds(expiry *time.Time, now time.Time) *int64 {
+	if expiry == nil {
+		return nil
+	}
+
+	seconds := max(int64(expiry.Sub(now)/time.Second), 0)
+	return &seconds
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler link URLs", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	newRouter := func(baseURL string, linkURLs bool) {
+		svc := &MockShortenerService{
+			CreateShortLinkFunc: func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: "abc123", IsActive: true}, nil
+			},
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true}, nil
+			},
+		}
+
+		features := config.DefaultFeatures()
+		features.LinkURLs = linkURLs
+
+		router = gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, baseURL, nil, handlers.LinkHandlerOptions{Features: features})
+		router.POST("/api/links", handler.CreateLink)
+		router.GET("/api/links/:code", handler.GetLink)
+	}
+
+	request := func(method, target, body string) map[string]interface{} {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+	})
+
+	It("should add absolute URLs to the create response", func() {
+		newRouter("https://sho.rt", true)
+
+		resp := request(http.MethodPost, "/api/links", `{"url":"https://example.com"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(resp).To(HaveKeyWithValue("code", "abc123"))
+		Expect(resp).To(HaveKeyWithValue("short_url", "https://sho.rt/abc123"))
+		Expect(resp).To(HaveKeyWithValue("stats_url", "https://sho.rt/api/links/abc123/stats"))
+	})
+
+	It("should add absolute URLs to the get response", func() {
+		newRouter("https://sho.rt", true)
+
+		resp := request(http.MethodGet, "/api/links/abc123", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(resp).To(HaveKeyWithValue("short_url", "https://sho.rt/abc123"))
+		Expect(resp).To(HaveKeyWithValue("stats_url", "https://sho.rt/api/links/abc123/stats"))
+	})
+
+	It("should join a base URL with a path and trailing slash", func() {
+		newRouter("https://example.com/s/", true)
+
+		resp := request(http.MethodGet, "/api/links/abc123", "")
+
+		Expect(resp).To(HaveKeyWithValue("short_url", "https://example.com/s/abc123"))
+		Expect(resp).To(HaveKeyWithValue("stats_url", "https://example.com/s/api/links/abc123/stats"))
+	})
+
+	It("should leave the URLs out when the feature is off", func() {
+		newRouter("https://sho.rt", false)
+
+		resp := request(http.MethodGet, "/api/links/abc123", "")
+
+		Expect(resp).To(HaveKey("code"))
+		Expect(resp).NotTo(HaveKey("short_url"))
+		Expect(resp).NotTo(HaveKey("stats_url"))
+	})
+})
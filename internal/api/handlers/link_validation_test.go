@@ -0,0 +1,216 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler validation errors", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		svc      *MockShortenerService
+		called   bool
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+		called = false
+
+		svc = &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true}, nil
+			},
+			CreateShortLinkFunc: func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+				called = true
+				return &domain.ShortLink{ID: "link-1", Code: "abc123"}, nil
+			},
+			UpdateShortLinkFunc: func(ctx context.Context, id string, req *domain.UpdateShortLinkRequest) (*domain.ShortLink, error) {
+				called = true
+				return &domain.ShortLink{ID: id, Code: "abc123"}, nil
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.POST("/api/links", handler.CreateLink)
+		router.PUT("/api/links/:code", handler.UpdateLink)
+	})
+
+	request := func(method, path, body string) {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+	}
+
+	fields := func() []domain.FieldError {
+		var body struct {
+			Error  string              `json:"error"`
+			Fields []domain.FieldError `json:"fields"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body.Fields
+	}
+
+	It("should report every mistyped create field at once", func() {
+		request(http.MethodPost, "/api/links", `{"url": 42, "custom_alias": true, "expiration_date": "tomorrow"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(fields()).To(ConsistOf(
+			domain.FieldError{Field: "url", Message: "must be a string"},
+			domain.FieldError{Field: "custom_alias", Message: "must be a string"},
+			domain.FieldError{Field: "expiration_date", Message: "must be an RFC 3339 timestamp"},
+		))
+		Expect(called).To(BeFalse())
+	})
+
+	It("should report every field the service rejects", func() {
+		svc.CreateShortLinkFunc = func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+			verr := &domain.ValidationError{}
+			verr.Add("url", "URL must use HTTP or HTTPS protocol")
+			verr.Add("custom_alias", "custom alias 'api' is reserved and cannot be used")
+			return nil, verr
+		}
+
+		request(http.MethodPost, "/api/links", `{"url": "ftp://example.com", "custom_alias": "api"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(fields()).To(Equal([]domain.FieldError{
+			{Field: "url", Message: "URL must use HTTP or HTTPS protocol"},
+			{Field: "custom_alias", Message: "custom alias 'api' is reserved and cannot be used"},
+		}))
+	})
+
+	It("should report every mistyped update field at once", func() {
+		request(http.MethodPut, "/api/links/abc123", `{"is_active": "yes", "expiration_date": 12}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(fields()).To(ConsistOf(
+			HaveField("Field", "is_active"),
+			HaveField("Field", "expiration_date"),
+		))
+		Expect(called).To(BeFalse())
+	})
+
+	It("should still reject a body that is not JSON with 400", func() {
+		request(http.MethodPost, "/api/links", `{"url":`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(called).To(BeFalse())
+	})
+
+	It("should create the link when every field is valid", func() {
+		request(http.MethodPost, "/api/links", `{"url": "https://example.com", "expiration_date": "2030-01-01T00:00:00Z"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(called).To(BeTrue())
+	})
+})
+
+var _ = Describe("LinkHandler service errors", func() {
+	var (
+		router    *gin.Engine
+		recorder  *httptest.ResponseRecorder
+		createErr error
+		updateErr error
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true}, nil
+			},
+			CreateShortLinkFunc: func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+				return nil, createErr
+			},
+			UpdateShortLinkFunc: func(ctx context.Context, id string, req *domain.UpdateShortLinkRequest) (*domain.ShortLink, error) {
+				return nil, updateErr
+			},
+		}
+
+		handler := handlers.NewLinkHandler(svc, "http://localhost:8081", nil)
+		router.POST("/api/links", handler.CreateLink)
+		router.PUT("/api/links/:code", handler.UpdateLink)
+	})
+
+	request := func(method, path, body string) map[string]interface{} {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+
+		var resp map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	It("should answer a wrapped ErrValidation with 422", func() {
+		createErr = fmt.Errorf("destination is unreachable: timeout: %w", domain.ErrValidation)
+
+		body := request(http.MethodPost, "/api/links", `{"url": "https://example.com"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(body).To(HaveKeyWithValue("error", "destination is unreachable: timeout"))
+	})
+
+	It("should answer a field validation error with 422 and its fields", func() {
+		verr := &domain.ValidationError{}
+		verr.Add("url", "URL must have a host")
+		createErr = verr.Err()
+
+		body := request(http.MethodPost, "/api/links", `{"url": "https://"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(body).To(HaveKey("fields"))
+	})
+
+	It("should answer a reached link quota with 402", func() {
+		createErr = &domain.QuotaError{Limit: 5}
+
+		body := request(http.MethodPost, "/api/links", `{"url": "https://example.com"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusPaymentRequired))
+		Expect(body).To(HaveKeyWithValue("error", "link quota of 5 active links reached"))
+	})
+
+	It("should answer an internal failure with 500 without leaking it", func() {
+		createErr = errors.New("checking existing URL: pq: connection refused")
+
+		body := request(http.MethodPost, "/api/links", `{"url": "https://example.com"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(body).To(HaveKeyWithValue("error", "Failed to create link"))
+	})
+
+	It("should keep conflicts and missing links apart from validation", func() {
+		updateErr = fmt.Errorf("custom alias already in use: %w", domain.ErrConflict)
+		request(http.MethodPut, "/api/links/abc123", `{"is_active": false}`)
+		Expect(recorder.Code).To(Equal(http.StatusConflict))
+
+		recorder = httptest.NewRecorder()
+		updateErr = fmt.Errorf("retrieving short link: %w", domain.ErrNotFound)
+		request(http.MethodPut, "/api/links/abc123", `{"is_active": false}`)
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		recorder = httptest.NewRecorder()
+		updateErr = errors.New("updating short link: pq: deadlock detected")
+		request(http.MethodPut, "/api/links/abc123", `{"is_active": false}`)
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/menezmethod/ref_go/internal/api/middleware"
 	"github.com/menezmethod/ref_go/internal/config"
 	"github.com/menezmethod/ref_go/internal/domain"
 	"github.com/menezmethod/ref_go/internal/service"
@@ -212,7 +213,6 @@ func (h *MockLinkHandler) ListLinksForTest(c *gin.Context) {
 
 	// Parse pagination parameters
 	page := 1
-	perPage := 10
 
 	// Get page from query parameters
 	pageStr := c.Query("page")
@@ -223,11 +223,10 @@ func (h *MockLinkHandler) ListLinksForTest(c *gin.Context) {
 	}
 
 	// Get per_page from query parameters
-	perPageStr := c.Query("per_page")
-	if perPageStr != "" {
-		if perPageInt, err := parseInt(perPageStr); err == nil && perPageInt > 0 && perPageInt <= 100 {
-			perPage = perPageInt
-		}
+	perPage, err := parsePageSize(c.Query("per_page"), h.maxPageSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// Get links
@@ -238,17 +237,7 @@ func (h *MockLinkHandler) ListLinksForTest(c *gin.Context) {
 		return
 	}
 
-	// Prepare response
-	response := gin.H{
-		"links": links,
-		"meta": gin.H{
-			"total":    total,
-			"page":     page,
-			"per_page": perPage,
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
+	respondList(c, links, newPageMeta(c, h.baseURL(), total, page, perPage))
 }
 
 // GetLinkStatsForTest handles the retrieval of link statistics for testing
@@ -280,7 +269,6 @@ func (h *MockLinkHandler) GetLinkStatsForTest(c *gin.Context) {
 
 	// Get clicks with pagination
 	page := 1
-	perPage := 10
 
 	// Get page from query parameters
 	pageStr := c.Query("page")
@@ -291,11 +279,10 @@ func (h *MockLinkHandler) GetLinkStatsForTest(c *gin.Context) {
 	}
 
 	// Get per_page from query parameters
-	perPageStr := c.Query("per_page")
-	if perPageStr != "" {
-		if perPageInt, err := parseInt(perPageStr); err == nil && perPageInt > 0 && perPageInt <= 100 {
-			perPage = perPageInt
-		}
+	perPage, err := parsePageSize(c.Query("per_page"), h.maxPageSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// Get clicks
@@ -306,18 +293,7 @@ func (h *MockLinkHandler) GetLinkStatsForTest(c *gin.Context) {
 		return
 	}
 
-	// Prepare response
-	response := gin.H{
-		"link_id": id,
-		"clicks":  clicks,
-		"meta": gin.H{
-			"total":    total,
-			"page":     page,
-			"per_page": perPage,
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
+	respondList(c, clicks, newPageMeta(c, h.baseURL(), total, page, perPage))
 }
 
 // RedirectLinkForTest handles the redirection of a link for testing
@@ -339,7 +315,7 @@ func (h *MockLinkHandler) RedirectLinkForTest(c *gin.Context) {
 	// Record click
 	userAgent := c.Request.UserAgent()
 	referer := c.Request.Referer()
-	ipAddress := c.ClientIP()
+	ipAddress := middleware.ClientIP(c)
 
 	// Record click asynchronously to not block the redirect
 	go func() {
@@ -352,6 +328,22 @@ func (h *MockLinkHandler) RedirectLinkForTest(c *gin.Context) {
 	c.Redirect(http.StatusMovedPermanently, link.OriginalURL)
 }
 
+// maxPageSize returns the configured page size cap
+func (h *MockLinkHandler) maxPageSize() int {
+	if h.cfg == nil {
+		return DefaultMaxPageSize
+	}
+	return h.cfg.Server.MaxPageSize
+}
+
+// baseURL returns the configured public base URL used in page links
+func (h *MockLinkHandler) baseURL() string {
+	if h.cfg == nil {
+		return ""
+	}
+	return h.cfg.Server.BaseURL
+}
+
 // Helper function to parse integers from query parameters
 func parseInt(s string) (int, error) {
 	return strconv.Atoi(s)
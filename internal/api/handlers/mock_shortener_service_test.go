@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// MockShortenerService mocks the handlers.LinkService interface
+type MockShortenerService struct {
+	CreateShortLinkFunc        func(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error)
+	GetShortLinkFunc           func(ctx context.Context, id string) (*domain.ShortLink, error)
+	GetShortLinkByCodeFunc     func(ctx context.Context, code string) (*domain.ShortLink, error)
+	UpdateShortLinkFunc        func(ctx context.Context, id string, req *domain.UpdateShortLinkRequest) (*domain.ShortLink, error)
+	DeleteShortLinkFunc        func(ctx context.Context, id string) error
+	ListShortLinksFunc         func(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error)
+	ListShortLinksFilteredFunc func(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error)
+	ListShortLinksAfterFunc    func(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error)
+	RecordClickFunc            func(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error
+	GetLinkStatsFunc           func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error)
+	GetLinkClickFunc           func(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error)
+	GetLinkStatsSummariesFunc  func(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error)
+}
+
+func (m *MockShortenerService) CreateShortLink(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+	if m.CreateShortLinkFunc != nil {
+		return m.CreateShortLinkFunc(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *MockShortenerService) GetShortLink(ctx context.Context, id string) (*domain.ShortLink, error) {
+	if m.GetShortLinkFunc != nil {
+		return m.GetShortLinkFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockShortenerService) GetShortLinkByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	if m.GetShortLinkByCodeFunc != nil {
+		return m.GetShortLinkByCodeFunc(ctx, code)
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockShortenerService) UpdateShortLink(ctx context.Context, id string, req *domain.UpdateShortLinkRequest) (*domain.ShortLink, error) {
+	if m.UpdateShortLinkFunc != nil {
+		return m.UpdateShortLinkFunc(ctx, id, req)
+	}
+	return nil, nil
+}
+
+func (m *MockShortenerService) DeleteShortLink(ctx context.Context, id string) error {
+	if m.DeleteShortLinkFunc != nil {
+		return m.DeleteShortLinkFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockShortenerService) ListShortLinks(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	if m.ListShortLinksFunc != nil {
+		return m.ListShortLinksFunc(ctx, page, pageSize)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockShortenerService) ListShortLinksFiltered(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	if m.ListShortLinksFilteredFunc != nil {
+		return m.ListShortLinksFilteredFunc(ctx, filter, page, pageSize)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockShortenerService) ListShortLinksAfter(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error) {
+	if m.ListShortLinksAfterFunc != nil {
+		return m.ListShortLinksAfterFunc(ctx, cursor, pageSize)
+	}
+	return nil, "", nil
+}
+
+func (m *MockShortenerService) RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+	if m.RecordClickFunc != nil {
+		return m.RecordClickFunc(ctx, shortLinkID, referrer, userAgent, ipAddress, rawQuery)
+	}
+	return nil
+}
+
+func (m *MockShortenerService) GetLinkStats(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+	if m.GetLinkStatsFunc != nil {
+		return m.GetLinkStatsFunc(ctx, shortLinkID, loc)
+	}
+	return nil, nil
+}
+
+func (m *MockShortenerService) GetLinkStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+	if m.GetLinkStatsSummariesFunc != nil {
+		return m.GetLinkStatsSummariesFunc(ctx, shortLinkIDs)
+	}
+	return map[string]*domain.LinkStatsSummary{}, nil
+}
+
+func (m *MockShortenerService) GetLinkClick(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+	if m.GetLinkClickFunc != nil {
+		return m.GetLinkClickFunc(ctx, shortLinkID, clickID)
+	}
+	return nil, domain.ErrNotFound
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// Page size bounds used when none are configured
+const (
+	DefaultPageSize    = 10
+	DefaultMaxPageSize = 100
+)
+
+// parsePageSize reads a page size query value. Missing or malformed values
+// fall back to DefaultPageSize; values above maxPageSize are an error rather
+// than being clamped, so clients learn the cap instead of silently getting
+// fewer results than they asked for.
+func parsePageSize(raw string, maxPageSize int) (int, error) {
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	if raw == "" {
+		return DefaultPageSize, nil
+	}
+
+	pageSize, err := strconv.Atoi(raw)
+	if err != nil || pageSize < 1 {
+		return DefaultPageSize, nil
+	}
+
+	if pageSize > maxPageSize {
+		return 0, fmt.Errorf("page size must not exceed %d", maxPageSize)
+	}
+
+	return pageSize, nil
+}
+
+// PageMeta describes one page of an offset paginated list. Next and Prev
+// are absolute URLs of the neighbouring pages, keeping every other query
+// parameter, and are left out when there is no such page.
+type PageMeta struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// CursorMeta describes one page of a cursor paginated list. Next is the
+// absolute URL of the following page and NextCursor its cursor; both are
+// left out on the last page.
+type CursorMeta struct {
+	PerPage    int    `json:"per_page"`
+	HasNext    bool   `json:"has_next"`
+	Next       string `json:"next,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListResponse is the envelope every list endpoint responds with: the items
+// under data and a PageMeta or CursorMeta under meta, so generic clients can
+// read any list the same way.
+type ListResponse struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta"`
+}
+
+// respondList writes items and their meta in the list envelope. items must
+// be a slice; a nil one is sent as an empty list rather than null.
+func respondList(c *gin.Context, items interface{}, meta interface{}) {
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	c.JSON(http.StatusOK, ListResponse{Data: items, Meta: meta})
+}
+
+// newPageMeta builds the metadata for page of a list of total items split
+// into pages of perPage, linking neighbours under baseURL. A page past the
+// end links back to the last page, or the first when the list is empty.
+func newPageMeta(c *gin.Context, baseURL string, total, page, perPage int) PageMeta {
+	meta := PageMeta{
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}
+	if perPage > 0 {
+		meta.TotalPages = (total + perPage - 1) / perPage
+	}
+
+	meta.HasNext = page < meta.TotalPages
+	meta.HasPrev = page > 1
+
+	if meta.HasNext {
+		meta.Next = pageURL(c, baseURL, page+1)
+	}
+	if meta.HasPrev {
+		meta.Prev = pageURL(c, baseURL, max(min(page-1, meta.TotalPages), 1))
+	}
+
+	return meta
+}
+
+// pageURL returns the absolute URL of the current request with its page
+// query parameter set to page
+func pageURL(c *gin.Context, baseURL string, page int) string {
+	return requestURLWith(c, baseURL, "page", strconv.Itoa(page))
+}
+
+// requestURLWith returns the absolute URL of the current request with the
+// query parameter key set to value
+func requestURLWith(c *gin.Context, baseURL, key, value string) string {
+	query := c.Request.URL.Query()
+	query.Set(key, value)
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return strings.TrimSuffix(baseURL, "/") + u.String()
+}
+
+// setLinkHeader sets an RFC 8288 Link header with the given next and prev
+// page URLs, for clients that paginate by header rather than by the body
+// meta. Empty URLs are left out, and so is the header when both are.
+func setLinkHeader(c *gin.Context, next, prev string) {
+	var links []string
+	if next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	if prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, prev))
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// parseLinkFilter reads the created_after, expires_before and status list
+// filters. Times are RFC 3339 timestamps or dates, which mean midnight UTC.
+func parseLinkFilter(c *gin.Context) (domain.LinkFilter, error) {
+	var filter domain.LinkFilter
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"expires_before", &filter.ExpiresBefore},
+	} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+
+		t, err := parseFilterTime(raw)
+		if err != nil {
+			return domain.LinkFilter{}, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", param.name)
+		}
+		*param.dest = &t
+	}
+
+	switch status := c.Query("status"); status {
+	case "", domain.LinkStatusActive, domain.LinkStatusExpired, domain.LinkStatusInactive:
+		filter.Status = status
+	default:
+		return domain.LinkFilter{}, fmt.Errorf("status must be active, expired or inactive")
+	}
+
+	filter.CollectionID = c.Query("collection")
+
+	return filter, nil
+}
+
+// parseFilterTime parses an RFC 3339 timestamp or a date
+func parseFilterTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
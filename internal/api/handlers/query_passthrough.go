@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ForwardAllQueryParams in LinkHandlerOptions.ForwardQueryParams forwards
+// every query parameter of the short link request
+const ForwardAllQueryParams = "*"
+
+// forwardQuery appends the query parameters of a short link request named in
+// allowed to a web destination, e.g. ?ref=newsletter on /abc reaching
+// https://example.com/page?ref=newsletter. Parameters the destination already
+// sets are left as stored, and anything not allowed is dropped.
+func forwardQuery(destination string, query url.Values, allowed []string) string {
+	if len(allowed) == 0 || len(query) == 0 {
+		return destination
+	}
+
+	dest, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	scheme := strings.ToLower(dest.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return destination
+	}
+
+	existing := dest.Query()
+	forwarded := url.Values{}
+	for name, values := range query {
+		if existing.Has(name) {
+			continue
+		}
+		if slices.Contains(allowed, name) || slices.Contains(allowed, ForwardAllQueryParams) {
+			forwarded[name] = values
+		}
+	}
+	if len(forwarded) == 0 {
+		return destination
+	}
+
+	// Append rather than re-encode so the stored query keeps its exact form
+	if dest.RawQuery == "" {
+		dest.RawQuery = forwarded.Encode()
+	} else {
+		dest.RawQuery += "&" + forwarded.Encode()
+	}
+	dest.ForceQuery = false
+
+	return dest.String()
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler query passthrough", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		recorded chan string
+	)
+
+	newRouter := func(forward ...string) {
+		destinations := map[string]string{
+			"plain":  "https://example.com/page",
+			"tagged": "https://example.com/page?utm_source=link&ref=stored",
+			"mail":   "mailto:team@example.com",
+		}
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				destination, ok := destinations[code]
+				if !ok {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{
+					ID:       "link-" + code,
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: destination},
+				}, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID, referrer, userAgent, ipAddress, rawQuery string) error {
+				recorded <- rawQuery
+				return nil
+			},
+		}
+
+		router = gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features:           config.DefaultFeatures(),
+			AllowedSchemes:     []string{"http", "https", "mailto"},
+			ForwardQueryParams: forward,
+		})
+		router.GET("/:code", handler.RedirectLink)
+	}
+
+	location := func(path string) string {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		return recorder.Header().Get("Location")
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		recorded = make(chan string, 1)
+	})
+
+	It("forwards nothing by default", func() {
+		newRouter()
+
+		Expect(location("/plain?ref=newsletter&session=123")).To(Equal("https://example.com/page"))
+	})
+
+	It("forwards allowed params and strips the rest", func() {
+		newRouter("ref", "utm_campaign")
+
+		Expect(location("/plain?ref=newsletter&session=123&utm_campaign=spring")).
+			To(Equal("https://example.com/page?ref=newsletter&utm_campaign=spring"))
+	})
+
+	It("keeps params the destination already sets", func() {
+		newRouter("ref", "utm_campaign")
+
+		Expect(location("/tagged?ref=newsletter&utm_campaign=spring")).
+			To(Equal("https://example.com/page?utm_source=link&ref=stored&utm_campaign=spring"))
+	})
+
+	It("matches param names exactly", func() {
+		newRouter("ref")
+
+		Expect(location("/plain?REF=newsletter")).To(Equal("https://example.com/page"))
+	})
+
+	It("forwards every param with the wildcard", func() {
+		newRouter(handlers.ForwardAllQueryParams)
+
+		Expect(location("/plain?ref=newsletter&session=123")).
+			To(Equal("https://example.com/page?ref=newsletter&session=123"))
+	})
+
+	It("hands the request's query to click recording even when nothing is forwarded", func() {
+		newRouter()
+
+		location("/plain?utm_source=newsletter&utm_campaign=spring")
+
+		Eventually(recorded).Should(Receive(Equal("utm_source=newsletter&utm_campaign=spring")))
+	})
+
+	It("leaves non-web destinations alone", func() {
+		newRouter("ref")
+
+		Expect(location("/mail?ref=newsletter")).To(Equal("mailto:team@example.com"))
+	})
+})
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// defaultRedirectSchemes are the destination schemes redirected to when no
+// AllowedSchemes are configured
+var defaultRedirectSchemes = []string{"http", "https"}
+
+// scriptSchemes run content in the visitor's browser instead of navigating,
+// so they are never redirected to, whatever the configuration says
+var scriptSchemes = []string{"javascript", "vbscript", "data", "file"}
+
+// checkRedirectTarget re-validates a stored destination right before it is
+// handed to a browser. Destinations are checked on create, but rows can be
+// written by imports, older releases or by hand, so the redirect must not
+// trust them: the scheme has to be allowed and web URLs need a host, which
+// also rules out protocol-relative and relative targets.
+func checkRedirectTarget(destination string, allowedSchemes []string) error {
+	if strings.TrimSpace(destination) != destination {
+		return fmt.Errorf("destination has surrounding whitespace")
+	}
+
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Errorf("parsing destination: %w", err)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme == "" {
+		return fmt.Errorf("destination has no scheme")
+	}
+	if slices.Contains(scriptSchemes, scheme) {
+		return fmt.Errorf("scheme %q is never redirected to", scheme)
+	}
+
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = defaultRedirectSchemes
+	}
+	if !slices.Contains(allowedSchemes, scheme) {
+		return fmt.Errorf("scheme %q is not allowed", scheme)
+	}
+
+	if (scheme == "http" || scheme == "https") && parsed.Host == "" {
+		return fmt.Errorf("destination has no host")
+	}
+
+	return nil
+}
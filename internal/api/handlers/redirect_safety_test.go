@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("LinkHandler redirect safety", func() {
+	var (
+		recorder    *httptest.ResponseRecorder
+		destination string
+		clicks      chan string
+	)
+
+	serve := func(target string, allowedSchemes ...string) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{
+					ID:       "link-1",
+					Code:     code,
+					IsActive: true,
+					URL:      &domain.URL{OriginalURL: destination},
+				}, nil
+			},
+			RecordClickFunc: func(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+				clicks <- shortLinkID
+				return nil
+			},
+		}
+
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features:       config.DefaultFeatures(),
+			AllowedSchemes: allowedSchemes,
+		})
+		router.GET("/:code", handler.RedirectLink)
+		router.GET("/:code/preview", handler.PreviewLink)
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(recorder, req)
+	}
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		clicks = make(chan string, 1)
+	})
+
+	DescribeTable("should refuse dangerous stored destinations",
+		func(stored string) {
+			destination = stored
+
+			serve("/abc123")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Location")).To(BeEmpty())
+			Consistently(clicks, "50ms").ShouldNot(Receive())
+		},
+		Entry("protocol-relative URL", "//evil.example.com/login"),
+		Entry("relative path", "/admin"),
+		Entry("javascript scheme", "javascript:alert(document.cookie)"),
+		Entry("data scheme", "data:text/html,<script>alert(1)</script>"),
+		Entry("scheme not allowed", "ftp://files.example.com/"),
+		Entry("web URL without host", "https:///evil.example.com"),
+		Entry("leading whitespace", " https://example.com"),
+	)
+
+	It("should refuse script schemes even when configured as allowed", func() {
+		destination = "javascript:alert(1)"
+
+		serve("/abc123", "http", "https", "javascript")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Location")).To(BeEmpty())
+	})
+
+	It("should redirect to configured non-web schemes", func() {
+		destination = "mailto:team@example.com"
+
+		serve("/abc123", "http", "https", "mailto")
+
+		Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+		Expect(recorder.Header().Get("Location")).To(Equal("mailto:team@example.com"))
+	})
+
+	It("should not reveal a refused destination to API clients", func() {
+		destination = "//evil.example.com"
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true, URL: &domain.URL{OriginalURL: destination}}, nil
+			},
+		}
+		router.GET("/:code", handlers.NewLinkHandler(svc, "http://localhost:8081", nil).RedirectLink)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+		req.Header.Set("Accept", "application/json")
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("evil.example.com"))
+	})
+
+	It("should refuse to preview a dangerous destination", func() {
+		destination = "javascript:alert(1)"
+
+		serve("/abc123/preview")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("javascript:"))
+	})
+})
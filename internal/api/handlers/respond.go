@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/api/pages"
+)
+
+// errorPages renders the HTML error page for handlers without a renderer
+// of their own
+var errorPages = pages.MustNew(pages.DefaultLocale)
+
+// respondError answers an API request with an error in the format the client
+// negotiated: {"error": message} by default, or an HTML error page when the
+// Accept header lists text/html ahead of JSON, as browsers do
+func respondError(c *gin.Context, status int, message string) {
+	c.Header("Vary", "Accept")
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	writePage(c, errorPages, status, pages.Error, pages.Data{Status: status, Message: message}, 0)
+}
+
+// wantsJSON reports whether a request for a browser facing page asked for
+// JSON ahead of HTML. It marks the response as varying on Accept.
+func wantsJSON(c *gin.Context) bool {
+	c.Header("Vary", "Accept")
+	return c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+}
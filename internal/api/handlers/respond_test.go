@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("Error content negotiation", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	request := func(path, accept string) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(recorder, req)
+	}
+
+	expectJSONError := func(message string) {
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+		var body map[string]string
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("error", message))
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+
+		svc := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return nil, domain.ErrNotFound
+			},
+		}
+
+		router = gin.New()
+		handler := handlers.NewLinkHandlerWithOptions(svc, "http://localhost:8081", nil, handlers.LinkHandlerOptions{
+			Features: config.DefaultFeatures(),
+		})
+		router.GET("/api/links/:code", handler.GetLink)
+		router.GET("/:code", handler.RedirectLink)
+	})
+
+	Context("for an API 404", func() {
+		It("returns an HTML error page to browsers", func() {
+			request("/api/links/missing", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+			Expect(recorder.Header().Values("Vary")).To(ContainElement(ContainSubstring("Accept")))
+			Expect(recorder.Body.String()).To(ContainSubstring("Link not found"))
+			Expect(recorder.Body.String()).To(ContainSubstring("404"))
+		})
+
+		It("returns JSON to API clients", func() {
+			request("/api/links/missing", "application/json")
+
+			expectJSONError("Link not found")
+		})
+
+		It("returns JSON without an Accept header", func() {
+			request("/api/links/missing", "")
+
+			expectJSONError("Link not found")
+		})
+	})
+
+	Context("for a redirect 404", func() {
+		It("returns the not found page to browsers", func() {
+			request("/missing", "text/html")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		})
+
+		It("returns JSON to API clients", func() {
+			request("/missing", "application/json")
+
+			expectJSONError("Link not found")
+		})
+	})
+})
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// StatsShareService defines the interface for sharing a link's stats by token
+type StatsShareService interface {
+	CreateToken(ctx context.Context, shortLinkID string, ttl time.Duration) (*domain.StatsShare, error)
+	GetLinkStats(ctx context.Context, token string, loc *time.Location) (*domain.LinkStats, error)
+}
+
+// SharedStatsHandler handles minting stats share tokens and serving the
+// stats they grant access to
+type SharedStatsHandler struct {
+	links  LinkService
+	shares StatsShareService
+	loc    *time.Location
+}
+
+// NewSharedStatsHandler creates a new shared stats handler bucketing days
+// in loc unless a request asks for another zone; nil means UTC
+func NewSharedStatsHandler(links LinkService, shares StatsShareService, loc *time.Location) *SharedStatsHandler {
+	return &SharedStatsHandler{
+		links:  links,
+		shares: shares,
+		loc:    loc,
+	}
+}
+
+// ShareLinkStats handles minting a share token for a link's stats
+// @Summary Share a link's stats
+// @Description Mint a signed token that lets anyone read this link's stats, and nothing else, through GET /shared/stats until it expires
+// @Tags links
+// @Produce json
+// @Param code path string true "Short link code"
+// @Param ttl query string false "Token lifetime, e.g. 1h; defaults to and may not exceed STATS_SHARE_TTL"
+// @Success 201 {object} domain.StatsShare "Share token"
+// @Failure 422 {object} map[string]interface{} "Invalid ttl"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Link not found"
+// @Security BearerAuth
+// @Router /links/{code}/stats/share [post]
+func (h *SharedStatsHandler) ShareLinkStats(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	var ttl time.Duration
+	if raw := c.Query("ttl"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			verr := &domain.ValidationError{}
+			verr.Add("ttl", "ttl must be a duration such as 30m or 24h")
+			respondValidationError(c, verr)
+			return
+		}
+		ttl = parsed
+	}
+
+	code := c.Param("code")
+	link, err := h.links.GetShortLinkByCode(c.Request.Context(), code)
+	if err != nil {
+		logger.Info("Failed to get short link", zap.String("code", code), zap.Error(err))
+		respondError(c, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	share, err := h.shares.CreateToken(c.Request.Context(), link.ID, ttl)
+	if err != nil {
+		var verr *domain.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			respondValidationError(c, verr)
+		case errors.Is(err, domain.ErrNotFound):
+			respondError(c, http.StatusNotFound, "Link not found")
+		default:
+			logger.Error("Failed to create stats share token", zap.String("id", link.ID), zap.Error(err))
+			respondError(c, http.StatusInternalServerError, "Failed to share link statistics")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+// GetSharedStats handles reading a link's stats with a share token
+// @Summary Get shared link stats
+// @Description Get the stats of the link a share token was minted for. No account is needed; the token is the credential.
+// @Tags links
+// @Produce json
+// @Param token query string true "Share token"
+// @Param tz query string false "IANA time zone to bucket days in"
+// @Success 200 {object} domain.LinkStats "Link statistics"
+// @Failure 400 {object} map[string]string "Missing token or invalid tz"
+// @Failure 403 {object} map[string]string "Invalid or expired token"
+// @Router /shared/stats [get]
+func (h *SharedStatsHandler) GetSharedStats(c *gin.Context) {
+	logger := middleware.GetLogger(c)
+
+	token := c.Query("token")
+	if token == "" {
+		respondError(c, http.StatusBadRequest, "Share token is required")
+		return
+	}
+
+	loc := h.loc
+	if tz := c.Query("tz"); tz != "" {
+		requested, err := time.LoadLocation(tz)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid tz, expected an IANA time zone such as Europe/Berlin")
+			return
+		}
+		loc = requested
+	}
+
+	stats, err := h.shares.GetLinkStats(c.Request.Context(), token, loc)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			logger.Info("Rejected stats share token", zap.Error(err))
+			respondError(c, http.StatusForbidden, "Share token is invalid or expired")
+			return
+		}
+		logger.Error("Failed to get shared link stats", zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "Failed to get link statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
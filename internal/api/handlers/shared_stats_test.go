@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("SharedStatsHandler", func() {
+	var router *gin.Engine
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	// share mints a token for the link behind code through the API
+	share := func(code, ttl string) string {
+		recorder := request(http.MethodPost, "/api/links/"+code+"/stats/share?ttl="+ttl)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+		var body domain.StatsShare
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body.Token
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+
+		links := &MockShortenerService{
+			GetShortLinkByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code == "missing" {
+					return nil, domain.ErrNotFound
+				}
+				return &domain.ShortLink{ID: "id-" + code, Code: code}, nil
+			},
+		}
+		shares := service.NewStatsShareService(
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				GetStatsByShortLinkIDFunc: func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+					clicks := map[string]int64{"id-docs": 42, "id-team": 7}
+					return &domain.LinkStats{TotalClicks: clicks[shortLinkID]}, nil
+				},
+			},
+			"shared-stats-secret",
+			time.Hour,
+		)
+
+		handler := handlers.NewSharedStatsHandler(links, shares, nil)
+		router.POST("/api/links/:code/stats/share", handler.ShareLinkStats)
+		router.GET("/api/shared/stats", handler.GetSharedStats)
+	})
+
+	It("should serve the shared link's stats for a valid token", func() {
+		token := share("docs", "30m")
+
+		recorder := request(http.MethodGet, "/api/shared/stats?token="+url.QueryEscape(token))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"total_clicks":42`))
+	})
+
+	It("should refuse an expired token", func() {
+		token := share("docs", "1ms")
+		time.Sleep(5 * time.Millisecond)
+
+		recorder := request(http.MethodGet, "/api/shared/stats?token="+url.QueryEscape(token))
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("total_clicks"))
+	})
+
+	It("should keep each token to its own link", func() {
+		docs := share("docs", "")
+		team := share("team", "")
+
+		Expect(request(http.MethodGet, "/api/shared/stats?token="+url.QueryEscape(docs)).Body.String()).
+			To(ContainSubstring(`"total_clicks":42`))
+		Expect(request(http.MethodGet, "/api/shared/stats?token="+url.QueryEscape(team)).Body.String()).
+			To(ContainSubstring(`"total_clicks":7`))
+	})
+
+	It("should refuse a tampered token", func() {
+		token := share("docs", "")
+
+		recorder := request(http.MethodGet, "/api/shared/stats?token="+url.QueryEscape(token+"x"))
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require a token", func() {
+		Expect(request(http.MethodGet, "/api/shared/stats").Code).To(Equal(http.StatusBadRequest))
+	})
+
+	DescribeTable("should not mint tokens for bad requests",
+		func(target string, status int) {
+			Expect(request(http.MethodPost, target).Code).To(Equal(status))
+		},
+		Entry("unknown link", "/api/links/missing/stats/share", http.StatusNotFound),
+		Entry("unparsable ttl", "/api/links/docs/stats/share?ttl=soon", http.StatusUnprocessableEntity),
+		Entry("ttl beyond the limit", "/api/links/docs/stats/share?ttl=2h", http.StatusUnprocessableEntity),
+	)
+})
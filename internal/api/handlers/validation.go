@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// errInvalidJSON is returned by bindJSONFields when the body is not a JSON object
+var errInvalidJSON = errors.New("request body must be a JSON object")
+
+// bindJSONFields decodes the JSON object in the request body into dst one
+// field at a time, so a value of the wrong type doesn't hide problems with
+// the fields after it. Rejected fields are returned together as a
+// *domain.ValidationError; a body that is not a JSON object is errInvalidJSON.
+func bindJSONFields(c *gin.Context, dst interface{}) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return fmt.Errorf("reading request body: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return errInvalidJSON
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	verr := &domain.ValidationError{}
+	for _, name := range names {
+		single, _ := json.Marshal(map[string]json.RawMessage{name: fields[name]})
+		if err := json.Unmarshal(single, dst); err != nil {
+			verr.Add(name, describeJSONError(err))
+		}
+	}
+
+	return verr.Err()
+}
+
+// describeJSONError turns a decoding error into a message for API clients
+func describeJSONError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return "must be " + describeJSONType(typeErr.Type)
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return "must be an RFC 3339 timestamp"
+	}
+
+	return "has an invalid value"
+}
+
+// describeJSONType names the JSON value expected for a Go type
+func describeJSONType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "an RFC 3339 timestamp"
+	case t.Kind() == reflect.String:
+		return "a string"
+	case t.Kind() == reflect.Bool:
+		return "a boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+		return "a number"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// respondBindError writes the response for a bindJSONFields failure
+func respondBindError(c *gin.Context, err error) {
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		respondValidationError(c, verr)
+		return
+	}
+	respondError(c, http.StatusBadRequest, "Invalid request body")
+}
+
+// respondServiceError maps an error from a link service call: validation
+// failures are a 422, unknown links a 404, conflicts a 409 and running out
+// of codes a 503. Anything else is an internal failure, logged and reported
+// as a 500 with message rather than blamed on the request.
+func respondServiceError(c *gin.Context, err error, message string) {
+	var verr *domain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		respondValidationError(c, verr)
+	case errors.Is(err, domain.ErrValidation):
+		respondError(c, http.StatusUnprocessableEntity, strings.TrimSuffix(err.Error(), ": "+domain.ErrValidation.Error()))
+	case errors.Is(err, domain.ErrNotFound):
+		respondError(c, http.StatusNotFound, "Link not found")
+	case errors.Is(err, domain.ErrConflict):
+		respondError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrCodeGenerationExhausted):
+		// Running out of free codes is transient, not the client's fault
+		respondError(c, http.StatusServiceUnavailable, "Unable to generate a unique code, please retry")
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		respondError(c, http.StatusPaymentRequired, err.Error())
+	default:
+		middleware.GetLogger(c).Error(message, zap.Error(err))
+		respondError(c, http.StatusInternalServerError, message)
+	}
+}
+
+// respondValidationError writes a 422 listing every rejected field
+func respondValidationError(c *gin.Context, verr *domain.ValidationError) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  "Validation failed",
+		"fields": verr.Fields,
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/buildinfo"
+)
+
+// VersionHandler reports which build is running
+type VersionHandler struct {
+	info buildinfo.Info
+}
+
+// NewVersionHandler creates a new version handler serving info
+func NewVersionHandler(info buildinfo.Info) *VersionHandler {
+	return &VersionHandler{
+		info: info,
+	}
+}
+
+// GetVersion handles retrieving the build info. It is public and only
+// serves values fixed at startup, so it is safe to poll.
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, h.info)
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/buildinfo"
+)
+
+var _ = Describe("VersionHandler", func() {
+	var original buildinfo.Info
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		original = buildinfo.Get()
+
+		// Stand in for the values -ldflags "-X" injects at build time
+		buildinfo.Version = "v1.4.2"
+		buildinfo.Commit = "5ef3e86"
+		buildinfo.BuildTime = "2024-03-15T10:00:00Z"
+	})
+
+	AfterEach(func() {
+		buildinfo.Version = original.Version
+		buildinfo.Commit = original.Commit
+		buildinfo.BuildTime = original.BuildTime
+	})
+
+	It("should return the injected build info as JSON", func() {
+		router := gin.New()
+		router.GET("/version", handlers.NewVersionHandler(buildinfo.Get()).GetVersion)
+
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/version", nil)
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+
+		var body map[string]string
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(map[string]string{
+			"version":    "v1.4.2",
+			"commit":     "5ef3e86",
+			"build_time": "2024-03-15T10:00:00Z",
+		}))
+	})
+
+	It("should default to a dev build without ldflags", func() {
+		Expect(original).To(Equal(buildinfo.Info{Version: "dev", Commit: "unknown", BuildTime: "unknown"}))
+	})
+})
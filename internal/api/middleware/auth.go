@@ -17,41 +17,66 @@ type AuthService interface {
 // Authentication middleware checks for valid JWT token
 func Authentication(authService AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get logger from context
-		logger := GetLogger(c)
-
-		// Extract token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			logger.Info("Missing Authorization header")
+		if c.GetHeader("Authorization") == "" {
+			GetLogger(c).Info("Missing Authorization header")
 			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
 			return
 		}
 
-		// Check token format
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			logger.Info("Invalid Authorization header format")
+		if !authenticate(c, authService) {
 			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
 			return
 		}
 
-		tokenString := parts[1]
+		// Continue to the next handler
+		c.Next()
+	}
+}
 
-		// Validate token
-		claims, err := authService.ValidateToken(tokenString)
-		if err != nil {
-			logger.Info("Invalid token", zap.Error(err))
-			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
-			return
+// OptionalAuthentication identifies the user of requests carrying a valid
+// bearer token like Authentication, but lets every request through so
+// handlers can decide what anonymous visitors may see
+func OptionalAuthentication(authService AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c, authService)
 		}
+		c.Next()
+	}
+}
 
-		// Store claims in context
-		c.Set("claims", claims)
+// authenticate validates the request's bearer token and stores its claims
+// and subject in the context, reporting whether the token was accepted
+func authenticate(c *gin.Context, authService AuthService) bool {
+	// Get logger from context
+	logger := GetLogger(c)
 
-		// Continue to the next handler
-		c.Next()
+	// Check token format
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		logger.Info("Invalid Authorization header format")
+		return false
+	}
+
+	tokenString := parts[1]
+
+	// Validate token
+	claims, err := authService.ValidateToken(tokenString)
+	if err != nil {
+		logger.Info("Invalid token", zap.Error(err))
+		return false
 	}
+
+	// Store claims in context
+	c.Set("claims", claims)
+
+	// Make the token subject available to handlers and services
+	if claims.Subject != "" {
+		c.Set("user_id", claims.Subject)
+		c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), claims.Subject))
+	}
+
+	return true
 }
 
 // GetTokenClaims retrieves token claims from context
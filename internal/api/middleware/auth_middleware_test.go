@@ -481,6 +481,38 @@ var _ = Describe("Authentication Middleware (Direct)", func() {
 			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
 		})
 	})
+
+	Context("with a token issued for a user", func() {
+		BeforeEach(func() {
+			mockValidator := &mockTokenValidator{
+				validateFunc: func(token string) (*auth.TokenClaims, error) {
+					claims := &auth.TokenClaims{}
+					claims.Subject = "alice"
+					return claims, nil
+				},
+			}
+
+			router.Use(middleware.Authentication(mockValidator))
+			router.GET("/whoami", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{
+					"user_id":     c.GetString("user_id"),
+					"ctx_user_id": auth.UserIDFromContext(c.Request.Context()),
+				})
+			})
+		})
+
+		It("exposes the token subject as the user ID", func() {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.Header.Set("Authorization", "Bearer user-token")
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var response map[string]string
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["user_id"]).To(Equal("alice"))
+			Expect(response["ctx_user_id"]).To(Equal("alice"))
+		})
+	})
 })
 
 // Mock services
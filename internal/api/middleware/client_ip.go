@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clientIPKey contextKey = "clientIP"
+
+// ClientIP resolves the client IP address for a request.
+// Forwarding headers are only honored when the immediate peer is one of the
+// engine's trusted proxies (see gin.Engine.SetTrustedProxies), so a spoofed
+// X-Forwarded-For from an untrusted client is ignored. An address taken
+// from a Forwarded header by ForwardedClientIP wins over X-Forwarded-For,
+// and one resolved by HeaderClientIP wins over both.
+func ClientIP(c *gin.Context) string {
+	if ip, exists := c.Get(string(clientIPKey)); exists {
+		if ip, ok := ip.(string); ok {
+			return ip
+		}
+	}
+	return c.ClientIP()
+}
+
+// ForwardedClientIP resolves the client IP from RFC 7239 Forwarded headers
+// for ClientIP. The header is only read when the peer is one of
+// trustedProxies (IPs or CIDRs; invalid entries are skipped), and hops are
+// walked from the nearest proxy back, stopping at the first address that
+// isn't itself trusted. Requests whose header is missing or holds a node
+// that isn't an IP address ("unknown", obfuscated names) fall back to
+// X-Forwarded-For and the peer address.
+func ForwardedClientIP(trustedProxies []string) gin.HandlerFunc {
+	trusted := parseProxies(trustedProxies)
+
+	return func(c *gin.Context) {
+		if values := c.Request.Header.Values("Forwarded"); len(values) > 0 && trusted.containsPeer(c.Request.RemoteAddr) {
+			if ip, ok := forwardedClient(values, trusted); ok {
+				c.Set(string(clientIPKey), ip)
+			}
+		}
+		c.Next()
+	}
+}
+
+// HeaderClientIP resolves the client IP for ClientIP from the first of
+// headers, in order, that holds an IP address, e.g. CF-Connecting-IP behind
+// Cloudflare. Headers are only read when the peer is one of trustedProxies;
+// list-valued headers are walked from the right past trusted hops like
+// Forwarded. Requests from other peers, or without any usable header, use
+// the peer address. It overrides ForwardedClientIP and X-Forwarded-For.
+func HeaderClientIP(headers, trustedProxies []string) gin.HandlerFunc {
+	trusted := parseProxies(trustedProxies)
+
+	return func(c *gin.Context) {
+		c.Set(string(clientIPKey), headerClient(c.Request, headers, trusted))
+		c.Next()
+	}
+}
+
+// headerClient returns the client address from the first usable header,
+// falling back to the peer address
+func headerClient(r *http.Request, headers []string, trusted proxies) string {
+	if trusted.containsPeer(r.RemoteAddr) {
+		for _, header := range headers {
+			value := strings.TrimSpace(r.Header.Get(header))
+			if value == "" {
+				continue
+			}
+			if ip, ok := listClient(strings.Split(value, ","), trusted); ok {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// listClient returns the client of a comma separated address list, walking
+// it from the right past trusted proxies; any entry that isn't an address
+// makes the whole list unusable
+func listClient(nodes []string, trusted proxies) (string, bool) {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		addr, ok := parseNode(strings.TrimSpace(nodes[i]))
+		if !ok {
+			return "", false
+		}
+		if i == 0 || !trusted.contains(addr) {
+			return addr.String(), true
+		}
+	}
+	return "", false
+}
+
+// proxies is a set of trusted proxy networks
+type proxies []netip.Prefix
+
+func parseProxies(entries []string) proxies {
+	var trusted proxies
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			trusted = append(trusted, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			trusted = append(trusted, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return trusted
+}
+
+func (p proxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p proxies) containsPeer(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(remoteAddr))
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && p.contains(addr)
+}
+
+// forwardedClient returns the client address from the for= parameters of
+// Forwarded header values, walking hops from the right past trusted
+// proxies. When every hop is trusted the leftmost one is the client.
+func forwardedClient(values []string, trusted proxies) (string, bool) {
+	var nodes []string
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			node, ok := forwardedFor(element)
+			if !ok {
+				return "", false
+			}
+			nodes = append(nodes, node)
+		}
+	}
+
+	return listClient(nodes, trusted)
+}
+
+// forwardedFor returns the unquoted for= value of a single Forwarded element
+func forwardedFor(element string) (string, bool) {
+	for _, pair := range splitQuoted(element, ';') {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "for") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = strings.ReplaceAll(value[1:len(value)-1], `\`, "")
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// parseNode parses a node of the form 192.0.2.1, 192.0.2.1:8080,
+// [2001:db8::1] or [2001:db8::1]:8080
+func parseNode(node string) (netip.Addr, bool) {
+	host := node
+	if strings.HasPrefix(node, "[") {
+		end := strings.IndexByte(node, ']')
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		host = node[1:end]
+	} else if strings.Count(node, ":") == 1 {
+		host, _, _ = strings.Cut(node, ":")
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// splitQuoted splits s on sep, ignoring separators inside quoted strings
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts   []string
+		start   int
+		quoted  bool
+		escaped bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
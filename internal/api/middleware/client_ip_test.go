@@ -0,0 +1,205 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+)
+
+var _ = Describe("ClientIP", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		recorder = httptest.NewRecorder()
+
+		router.GET("/ip", func(c *gin.Context) {
+			c.String(http.StatusOK, middleware.ClientIP(c))
+		})
+	})
+
+	Context("when no proxies are trusted", func() {
+		BeforeEach(func() {
+			Expect(router.SetTrustedProxies(nil)).To(Succeed())
+		})
+
+		It("ignores a spoofed X-Forwarded-For header", func() {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = "203.0.113.7:4321"
+			req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Body.String()).To(Equal("203.0.113.7"))
+		})
+	})
+
+	Context("when the peer is not a trusted proxy", func() {
+		BeforeEach(func() {
+			Expect(router.SetTrustedProxies([]string{"10.0.0.1"})).To(Succeed())
+		})
+
+		It("uses the peer address", func() {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = "203.0.113.7:4321"
+			req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Body.String()).To(Equal("203.0.113.7"))
+		})
+	})
+
+	Context("when the peer is a trusted proxy", func() {
+		BeforeEach(func() {
+			Expect(router.SetTrustedProxies([]string{"10.0.0.0/8"})).To(Succeed())
+		})
+
+		It("uses the forwarded client address", func() {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = "10.0.0.1:4321"
+			req.Header.Set("X-Forwarded-For", "198.51.100.23")
+
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Body.String()).To(Equal("198.51.100.23"))
+		})
+	})
+
+	Context("with Forwarded headers", func() {
+		serve := func(remoteAddr string, forwarded ...string) string {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = remoteAddr
+			for _, value := range forwarded {
+				req.Header.Add("Forwarded", value)
+			}
+			req.Header.Set("X-Forwarded-For", "192.0.2.99")
+
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder.Body.String()
+		}
+
+		BeforeEach(func() {
+			trusted := []string{"10.0.0.0/8", "2001:db8:ffff::/48"}
+			router = gin.New()
+			Expect(router.SetTrustedProxies(trusted)).To(Succeed())
+			router.Use(middleware.ForwardedClientIP(trusted))
+			router.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, middleware.ClientIP(c))
+			})
+		})
+
+		It("parses an IPv4 node with a port and wins over X-Forwarded-For", func() {
+			Expect(serve("10.0.0.1:4321", "for=198.51.100.23:8080;proto=https")).To(Equal("198.51.100.23"))
+		})
+
+		It("parses a quoted IPv6 node in brackets", func() {
+			Expect(serve("10.0.0.1:4321", `For="[2001:db8:cafe::17]:4711"`)).To(Equal("2001:db8:cafe::17"))
+			Expect(serve("10.0.0.1:4321", `for="[2001:db8:cafe::18]"`)).To(Equal("2001:db8:cafe::18"))
+		})
+
+		It("skips trusted hops and stops at the first untrusted one", func() {
+			Expect(serve("10.0.0.1:4321", `for=203.0.113.5, for="[2001:db8:ffff::2]";by=10.0.0.1, for=10.0.0.9`)).To(Equal("203.0.113.5"))
+			Expect(serve("10.0.0.1:4321", "for=203.0.113.5, for=198.51.100.7", "for=10.0.0.9")).To(Equal("198.51.100.7"))
+		})
+
+		It("uses the leftmost hop when every hop is trusted", func() {
+			Expect(serve("10.0.0.1:4321", "for=10.1.1.1, for=10.2.2.2")).To(Equal("10.1.1.1"))
+		})
+
+		It("ignores the header from an untrusted peer", func() {
+			Expect(serve("203.0.113.7:4321", "for=198.51.100.23")).To(Equal("203.0.113.7"))
+		})
+
+		It("falls back to X-Forwarded-For for unknown or obfuscated nodes", func() {
+			Expect(serve("10.0.0.1:4321", "for=unknown")).To(Equal("192.0.2.99"))
+			Expect(serve("10.0.0.1:4321", "for=_hidden, for=198.51.100.23")).To(Equal("198.51.100.23"))
+			Expect(serve("10.0.0.1:4321", "for=198.51.100.23, for=_hidden")).To(Equal("192.0.2.99"))
+		})
+	})
+
+	Context("with preferred client IP headers", func() {
+		serve := func(remoteAddr string, headers map[string]string) string {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = remoteAddr
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder.Body.String()
+		}
+
+		BeforeEach(func() {
+			trusted := []string{"173.245.48.0/20", "10.0.0.0/8"}
+			router = gin.New()
+			Expect(router.SetTrustedProxies(trusted)).To(Succeed())
+			router.Use(middleware.HeaderClientIP([]string{"CF-Connecting-IP", "X-Real-IP"}, trusted))
+			router.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, middleware.ClientIP(c))
+			})
+		})
+
+		It("uses CF-Connecting-IP when Cloudflare sends it", func() {
+			Expect(serve("173.245.48.10:4321", map[string]string{
+				"CF-Connecting-IP": "198.51.100.23",
+				"X-Real-IP":        "192.0.2.1",
+				"X-Forwarded-For":  "192.0.2.99",
+			})).To(Equal("198.51.100.23"))
+		})
+
+		It("uses the next header in order when CF-Connecting-IP is absent", func() {
+			Expect(serve("173.245.48.10:4321", map[string]string{
+				"X-Real-IP":       "192.0.2.1",
+				"X-Forwarded-For": "192.0.2.99",
+			})).To(Equal("192.0.2.1"))
+		})
+
+		It("falls back to the peer address when no header is present", func() {
+			Expect(serve("173.245.48.10:4321", map[string]string{
+				"X-Forwarded-For": "192.0.2.99",
+			})).To(Equal("173.245.48.10"))
+		})
+
+		It("skips a header that doesn't hold an address", func() {
+			Expect(serve("173.245.48.10:4321", map[string]string{
+				"CF-Connecting-IP": "not-an-ip",
+				"X-Real-IP":        "192.0.2.1",
+			})).To(Equal("192.0.2.1"))
+		})
+
+		It("walks list-valued headers past trusted hops", func() {
+			Expect(serve("10.0.0.1:4321", map[string]string{
+				"X-Real-IP": "203.0.113.5, 10.0.0.9",
+			})).To(Equal("203.0.113.5"))
+		})
+
+		It("ignores the headers from an untrusted peer", func() {
+			Expect(serve("203.0.113.7:4321", map[string]string{
+				"CF-Connecting-IP": "198.51.100.23",
+			})).To(Equal("203.0.113.7"))
+		})
+	})
+
+	It("ignores Forwarded headers unless they are enabled", func() {
+		Expect(router.SetTrustedProxies([]string{"10.0.0.0/8"})).To(Succeed())
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.1:4321"
+		req.Header.Set("Forwarded", "for=198.51.100.23")
+
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Body.String()).To(Equal("10.0.0.1"))
+	})
+})
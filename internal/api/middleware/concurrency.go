@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// concurrencyRetryAfter is the Retry-After, in seconds, sent with a 503
+// when the server is saturated. Requests are short, so slots free up fast.
+const concurrencyRetryAfter = "1"
+
+// MaxConcurrentRequests limits how many requests are handled at once.
+// Requests arriving while limit are in flight are answered 503 with a
+// Retry-After header instead of queueing. Paths in exempt, such as health
+// checks, are never limited. A limit of zero or less disables the check.
+func MaxConcurrentRequests(limit int, exempt ...string) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(c *gin.Context) {
+		if slices.Contains(exempt, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			GetLogger(c).Warn("Too many concurrent requests",
+				zap.Int("limit", limit),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.Header("Retry-After", concurrencyRetryAfter)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is busy, please retry"})
+			return
+		}
+		defer func() { <-slots }()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+)
+
+var _ = Describe("MaxConcurrentRequests", func() {
+	var (
+		router  *gin.Engine
+		entered chan struct{}
+		release chan struct{}
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		entered = make(chan struct{}, 2)
+		release = make(chan struct{})
+
+		router.Use(middleware.MaxConcurrentRequests(1, "/health"))
+		router.GET("/slow", func(c *gin.Context) {
+			entered <- struct{}{}
+			<-release
+			c.Status(http.StatusOK)
+		})
+		router.GET("/fast", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.GET("/health", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// occupy holds the only slot until the returned channel is closed
+	occupy := func() chan *httptest.ResponseRecorder {
+		done := make(chan *httptest.ResponseRecorder, 1)
+		go func() {
+			defer GinkgoRecover()
+			done <- serve("/slow")
+		}()
+		Eventually(entered).Should(Receive())
+		return done
+	}
+
+	It("should answer 503 with Retry-After beyond the limit", func() {
+		done := occupy()
+
+		recorder := serve("/fast")
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(recorder.Body.String()).To(ContainSubstring("Server is busy"))
+
+		close(release)
+		Eventually(done).Should(Receive(HaveField("Code", http.StatusOK)))
+	})
+
+	It("should free the slot once a request completes", func() {
+		done := occupy()
+		close(release)
+		Eventually(done).Should(Receive(HaveField("Code", http.StatusOK)))
+
+		Expect(serve("/fast").Code).To(Equal(http.StatusOK))
+		Expect(serve("/fast").Code).To(Equal(http.StatusOK))
+	})
+
+	It("should free the slot when the request is rejected further down", func() {
+		router.GET("/abort", func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusBadRequest)
+		})
+
+		Expect(serve("/abort").Code).To(Equal(http.StatusBadRequest))
+		Expect(serve("/fast").Code).To(Equal(http.StatusOK))
+	})
+
+	It("should not limit exempt paths", func() {
+		done := occupy()
+
+		Expect(serve("/health").Code).To(Equal(http.StatusOK))
+
+		close(release)
+		Eventually(done).Should(Receive())
+	})
+
+	It("should not limit anything when disabled", func() {
+		router = gin.New()
+		router.Use(middleware.MaxConcurrentRequests(0))
+		router.GET("/slow", func(c *gin.Context) {
+			entered <- struct{}{}
+			<-release
+			c.Status(http.StatusOK)
+		})
+		router.GET("/fast", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		done := occupy()
+
+		Expect(serve("/fast").Code).To(Equal(http.StatusOK))
+
+		close(release)
+		Eventually(done).Should(Receive())
+	})
+})
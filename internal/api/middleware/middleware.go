@@ -70,13 +70,16 @@ func Logging(baseLogger *zap.Logger) gin.HandlerFunc {
 		fields := []zap.Field{
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
-			zap.String("remote_addr", c.ClientIP()),
+			zap.String("remote_addr", ClientIP(c)),
 			zap.String("user_agent", c.Request.UserAgent()),
 		}
 		if len(body) > 0 {
 			// Parse body as JSON to redact sensitive fields
+			// Keep numbers as json.Number so large counts are logged exactly
 			var jsonBody map[string]interface{}
-			if err := json.Unmarshal(body, &jsonBody); err == nil {
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			if err := decoder.Decode(&jsonBody); err == nil {
 				// Redact sensitive fields
 				sensitiveFields := []string{"password", "token", "secret", "key", "auth"}
 				for _, field := range sensitiveFields {
@@ -126,8 +129,19 @@ func GetLogger(c *gin.Context) *zap.Logger {
 	return zap.L()
 }
 
+// PanicRecorder counts recovered panics per route
+type PanicRecorder interface {
+	RecordPanic(route string)
+}
+
 // Recovery middleware handles panics
 func Recovery() gin.HandlerFunc {
+	return RecoveryWithMetrics(nil)
+}
+
+// RecoveryWithMetrics handles panics like Recovery and also reports each one
+// to recorder, labeled with the matched route template
+func RecoveryWithMetrics(recorder PanicRecorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -141,6 +155,15 @@ func Recovery() gin.HandlerFunc {
 					zap.String("stack", string(stack)),
 				)
 
+				if recorder != nil {
+					// The route template keeps the label set bounded, unlike the raw path
+					route := c.FullPath()
+					if route == "" {
+						route = "unmatched"
+					}
+					recorder.RecordPanic(route)
+				}
+
 				c.AbortWithStatusJSON(500, gin.H{"error": "Internal server error"})
 			}
 		}()
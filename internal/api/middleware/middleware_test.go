@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/metrics"
 )
 
 var _ = Describe("Middleware", func() {
@@ -170,6 +171,19 @@ var _ = Describe("Middleware", func() {
 				startLog := observedLogs.All()[0]
 				Expect(startLog.Context).To(ContainElement(zap.String("body", string(jsonBody))))
 			})
+
+			It("should log large numbers in the request body exactly", func() {
+				jsonBody := []byte(`{"visits":9007199254740993}`)
+				req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer(jsonBody))
+				req.Header.Set("Content-Type", "application/json")
+
+				router.ServeHTTP(recorder, req)
+
+				Eventually(observedLogs.All).Should(HaveLen(2))
+
+				startLog := observedLogs.All()[0]
+				Expect(startLog.Context).To(ContainElement(zap.String("body", string(jsonBody))))
+			})
 		})
 
 		Context("when handling error responses", func() {
@@ -266,6 +280,42 @@ var _ = Describe("Middleware", func() {
 		})
 	})
 
+	Describe("RecoveryWithMetrics", func() {
+		var collector *metrics.Metrics
+
+		BeforeEach(func() {
+			collector = metrics.NewMetrics()
+			router.Use(middleware.RecoveryWithMetrics(collector))
+			router.GET("/links/:code/panic", func(c *gin.Context) {
+				panic("test panic")
+			})
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+		})
+
+		It("should count panics by route template", func() {
+			for _, path := range []string{"/links/abc/panic", "/links/def/panic"} {
+				recorder = httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			}
+
+			Expect(collector.GetPanicsByRoute()).To(Equal(map[string]int64{"/links/:code/panic": 2}))
+
+			recorder = httptest.NewRecorder()
+			collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			Expect(recorder.Body.String()).To(ContainSubstring(`url_shortener_panics_total{route="/links/:code/panic"} 2`))
+		})
+
+		It("should not count requests that do not panic", func() {
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(collector.GetPanicsByRoute()).To(BeEmpty())
+		})
+	})
+
 	Describe("Timeout", func() {
 		BeforeEach(func() {
 			router.Use(middleware.Timeout(100 * time.Millisecond))
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// QueryGuard rejects requests whose raw query string is longer than
+// maxLength bytes, holds control characters once percent-decoded or has a
+// malformed percent escape. Such queries come from scanners rather than
+// real clients, so they are answered 400 before any handler work. A
+// maxLength of zero or less disables the check.
+func QueryGuard(maxLength int) gin.HandlerFunc {
+	if maxLength <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		query := c.Request.URL.RawQuery
+
+		reason := ""
+		switch {
+		case len(query) > maxLength:
+			reason = "Query string is too long"
+		case !cleanQuery(query):
+			reason = "Query string contains invalid characters"
+		}
+
+		if reason != "" {
+			GetLogger(c).Info("Rejected suspicious query string",
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("query_length", len(query)),
+				zap.String("reason", reason),
+			)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": reason})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// cleanQuery reports whether every percent escape in query is well formed
+// and no byte, decoded or not, is an ASCII control character
+func cleanQuery(query string) bool {
+	for i := 0; i < len(query); i++ {
+		b := query[i]
+		if b == '%' {
+			if i+2 >= len(query) || !isHex(query[i+1]) || !isHex(query[i+2]) {
+				return false
+			}
+			b = unhex(query[i+1])<<4 | unhex(query[i+2])
+			i += 2
+		}
+		if b < 0x20 || b == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+func unhex(b byte) byte {
+	switch {
+	case b >= 'a':
+		return b - 'a' + 10
+	case b >= 'A':
+		return b - 'A' + 10
+	}
+	return b - '0'
+}
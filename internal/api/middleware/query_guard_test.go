@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+)
+
+var _ = Describe("QueryGuard", func() {
+	var (
+		reached bool
+		serve   func(maxLength int, query string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		reached = false
+
+		serve = func(maxLength int, query string) *httptest.ResponseRecorder {
+			router := gin.New()
+			router.Use(middleware.QueryGuard(maxLength))
+			router.GET("/:code", func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusFound)
+			})
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			req.URL.RawQuery = query
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+	})
+
+	It("should let a clean query through", func() {
+		recorder := serve(64, "utm_source=news%20letter&ref=a%2Fb&q=caf%C3%A9")
+
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+		Expect(reached).To(BeTrue())
+	})
+
+	It("should let a query of exactly the maximum length through", func() {
+		recorder := serve(10, "q="+strings.Repeat("a", 8))
+
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+	})
+
+	It("should reject an over-length query before the handler runs", func() {
+		recorder := serve(64, "q="+strings.Repeat("a", 100))
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("Query string is too long"))
+		Expect(reached).To(BeFalse())
+	})
+
+	DescribeTable("should reject control characters and broken escapes",
+		func(query string) {
+			recorder := serve(64, query)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Query string contains invalid characters"))
+			Expect(reached).To(BeFalse())
+		},
+		Entry("encoded NUL", "q=a%00b"),
+		Entry("encoded newline", "q=a%0Ab"),
+		Entry("encoded DEL", "q=%7f"),
+		Entry("raw tab", "q=a\tb"),
+		Entry("non-hex escape", "q=%zz"),
+		Entry("truncated escape", "q=%4"),
+	)
+
+	It("should check nothing when disabled", func() {
+		recorder := serve(0, "q=%00"+strings.Repeat("a", 10000))
+
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+	})
+})
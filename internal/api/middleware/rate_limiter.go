@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"strconv"
 	"sync"
 	"time"
@@ -8,9 +9,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/menezmethod/ref_go/internal/auth"
 	"github.com/menezmethod/ref_go/internal/config"
 )
 
+// WouldBlockRecorder counts requests a dry-run rate limiter let through
+// that it would otherwise have rejected
+type WouldBlockRecorder interface {
+	RecordRateLimitWouldBlock()
+}
+
 // RateLimiter implements a token bucket rate limiter
 type RateLimiter struct {
 	mu            sync.Mutex
@@ -18,7 +26,13 @@ type RateLimiter struct {
 	capacity      int           // Maximum tokens per bucket
 	refillRate    time.Duration // Rate at which tokens are refilled
 	cleanupPeriod time.Duration // How often to clean up old buckets
+	standard      bool          // Emit IETF RateLimit/RateLimit-Policy headers
+	dryRun        bool          // Report over-limit requests instead of rejecting them
+	recorder      WouldBlockRecorder
 	logger        *zap.Logger
+
+	bypassHeader string // Header carrying the internal token
+	bypassToken  string // Token exempting a request from limiting; empty disables the bypass
 }
 
 // tokenBucket represents a token bucket for an individual client
@@ -40,7 +54,11 @@ func NewRateLimiterWithCleanup(cfg *config.Config, logger *zap.Logger, cleanupPe
 		capacity:      cfg.RateLimit.Requests,
 		refillRate:    cfg.RateLimit.Window,
 		cleanupPeriod: cleanupPeriod,
+		standard:      cfg.RateLimit.StandardHeaders,
+		dryRun:        cfg.RateLimit.DryRun,
 		logger:        logger,
+		bypassHeader:  cfg.RateLimit.BypassHeader,
+		bypassToken:   cfg.RateLimit.BypassToken,
 	}
 
 	// Start a goroutine to periodically clean up old buckets
@@ -49,6 +67,11 @@ func NewRateLimiterWithCleanup(cfg *config.Config, logger *zap.Logger, cleanupPe
 	return limiter
 }
 
+// SetWouldBlockRecorder sets where dry-run mode reports would-be-blocked requests
+func (rl *RateLimiter) SetWouldBlockRecorder(recorder WouldBlockRecorder) {
+	rl.recorder = recorder
+}
+
 // cleanupTask removes buckets that haven't been seen in a while
 func (rl *RateLimiter) cleanupTask() {
 	ticker := time.NewTicker(rl.cleanupPeriod)
@@ -74,6 +97,16 @@ func (rl *RateLimiter) cleanup() {
 
 // Allow checks if a request is allowed based on the client's identifier
 func (rl *RateLimiter) Allow(identifier string) (bool, int, time.Time) {
+	allowed, remaining, reset := rl.take(identifier)
+	if allowed {
+		return true, remaining, time.Time{}
+	}
+	return false, remaining, reset
+}
+
+// take consumes a token for the client and also reports when its bucket
+// next refills, which the standard headers need even for allowed requests
+func (rl *RateLimiter) take(identifier string) (bool, int, time.Time) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -102,41 +135,88 @@ func (rl *RateLimiter) Allow(identifier string) (bool, int, time.Time) {
 		bucket.lastRefil = now
 	}
 
+	// Calculate when the next token will be available
+	nextRefill := bucket.lastRefil.Add(rl.refillRate)
+
 	// If the bucket has tokens, allow the request
 	if bucket.tokens > 0 {
 		bucket.tokens--
-		return true, bucket.tokens, time.Time{}
+		return true, bucket.tokens, nextRefill
 	}
 
-	// Calculate when the next token will be available
-	nextRefill := bucket.lastRefil.Add(rl.refillRate)
 	return false, 0, nextRefill
 }
 
+// bypassed reports whether the request carries the internal bypass token,
+// compared in constant time
+func (rl *RateLimiter) bypassed(c *gin.Context) bool {
+	if rl.bypassToken == "" {
+		return false
+	}
+	return auth.SecretsEqual(c.GetHeader(rl.bypassHeader), rl.bypassToken)
+}
+
 // RateLimit middleware limits the rate of requests
 func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get client identifier (IP address)
-		clientIP := c.ClientIP()
+		clientIP := ClientIP(c)
 		logger := GetLogger(c)
 
+		// Internal callers with the bypass token don't use up any tokens
+		if limiter.bypassed(c) {
+			logger.Info("Rate limit bypassed",
+				zap.String("client_ip", clientIP),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.Next()
+			return
+		}
+
 		// Check if the request is allowed
-		allowed, remaining, retryAfter := limiter.Allow(clientIP)
+		allowed, remaining, retryAfter := limiter.take(clientIP)
+		reset := secondsUntil(retryAfter)
 
 		// Set rate limit headers
 		c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.capacity))
 		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 
+		// Standardized headers from the IETF RateLimit header fields draft
+		if limiter.standard {
+			c.Header("RateLimit", fmt.Sprintf("limit=%d, remaining=%d, reset=%d", limiter.capacity, remaining, reset))
+			c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limiter.capacity, int(limiter.refillRate.Seconds())))
+		}
+
+		// In dry-run mode over-limit requests are only reported
+		if !allowed && limiter.dryRun {
+			logger.Info("Rate limit would be exceeded",
+				zap.String("client_ip", clientIP),
+				zap.Time("retry_after", retryAfter),
+			)
+			if limiter.recorder != nil {
+				limiter.recorder.RecordRateLimitWouldBlock()
+			}
+			c.Next()
+			return
+		}
+
 		if !allowed {
 			// Set retry-after header
-			c.Header("Retry-After", strconv.Itoa(int(time.Until(retryAfter).Seconds())))
+			c.Header("Retry-After", strconv.Itoa(reset))
 
-			// Return 429 Too Many Requests
+			// Return 429 Too Many Requests, repeating the headers in the
+			// body so clients can back off without parsing them
 			logger.Info("Rate limit exceeded",
 				zap.String("client_ip", clientIP),
 				zap.Time("retry_after", retryAfter),
 			)
-			c.AbortWithStatusJSON(429, gin.H{"error": "Rate limit exceeded"})
+			c.AbortWithStatusJSON(429, gin.H{
+				"error":               "Rate limit exceeded",
+				"limit":               limiter.capacity,
+				"remaining":           remaining,
+				"reset_at":            retryAfter.UTC().Format(time.RFC3339),
+				"retry_after_seconds": reset,
+			})
 			return
 		}
 
@@ -145,6 +225,15 @@ func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
 	}
 }
 
+// secondsUntil returns the whole seconds remaining until t, never negative
+func secondsUntil(t time.Time) int {
+	seconds := int(time.Until(t).Seconds())
+	if seconds < 0 {
+		return 0
+	}
+	return seconds
+}
+
 // Min returns the minimum of two integers (exported for testing)
 func Min(a, b int) int {
 	if a < b {
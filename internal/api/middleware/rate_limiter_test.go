@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -14,9 +15,12 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/menezmethod/ref_go/internal/api/middleware"
 	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/metrics"
 )
 
 var _ = Describe("RateLimiter", func() {
@@ -99,6 +103,38 @@ var _ = Describe("RateLimiter", func() {
 				Expect(recorder.Code).To(Equal(429))
 				Expect(recorder.Header().Get("Retry-After")).NotTo(BeEmpty())
 			})
+
+			It("describes the limit and reset in a JSON body matching the headers", func() {
+				for i := 0; i <= cfg.RateLimit.Requests; i++ {
+					recorder = httptest.NewRecorder()
+					req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+					req.RemoteAddr = "192.168.1.1:12345"
+					router.ServeHTTP(recorder, req)
+				}
+				now := time.Now()
+
+				Expect(recorder.Code).To(Equal(429))
+				Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+
+				var body struct {
+					Error             string `json:"error"`
+					Limit             int    `json:"limit"`
+					Remaining         int    `json:"remaining"`
+					ResetAt           string `json:"reset_at"`
+					RetryAfterSeconds int    `json:"retry_after_seconds"`
+				}
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+
+				Expect(body.Error).To(Equal("Rate limit exceeded"))
+				Expect(strconv.Itoa(body.Limit)).To(Equal(recorder.Header().Get("X-RateLimit-Limit")))
+				Expect(strconv.Itoa(body.Remaining)).To(Equal(recorder.Header().Get("X-RateLimit-Remaining")))
+				Expect(strconv.Itoa(body.RetryAfterSeconds)).To(Equal(recorder.Header().Get("Retry-After")))
+
+				resetAt, err := time.Parse(time.RFC3339, body.ResetAt)
+				Expect(err).NotTo(HaveOccurred())
+				retryAfter := time.Duration(body.RetryAfterSeconds) * time.Second
+				Expect(resetAt).To(BeTemporally("~", now.Add(retryAfter), 2*time.Second))
+			})
 		})
 
 		Context("when tokens are refilled", func() {
@@ -166,6 +202,183 @@ var _ = Describe("RateLimiter", func() {
 		})
 	})
 
+	Describe("Standard headers", func() {
+		serve := func(standard bool) {
+			cfg.RateLimit.StandardHeaders = standard
+			limiter = middleware.NewRateLimiterWithCleanup(cfg, logger, time.Minute)
+			router = gin.New()
+			router.GET("/test", middleware.RateLimit(limiter), func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+		}
+
+		request := func() {
+			recorder = httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.168.1.1:12345"
+			router.ServeHTTP(recorder, req)
+		}
+
+		Context("when enabled", func() {
+			BeforeEach(func() {
+				serve(true)
+			})
+
+			It("emits both header families with consistent values", func() {
+				for i := 0; i < cfg.RateLimit.Requests; i++ {
+					request()
+
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+					limit := recorder.Header().Get("X-RateLimit-Limit")
+					remaining := recorder.Header().Get("X-RateLimit-Remaining")
+					Expect(recorder.Header().Get("RateLimit")).To(MatchRegexp(
+						fmt.Sprintf(`^limit=%s, remaining=%s, reset=\d+$`, limit, remaining)))
+					Expect(recorder.Header().Get("RateLimit-Policy")).To(Equal(limit + ";w=2"))
+				}
+			})
+
+			It("reports the same reset as Retry-After when blocked", func() {
+				for i := 0; i < cfg.RateLimit.Requests; i++ {
+					request()
+				}
+				request()
+
+				Expect(recorder.Code).To(Equal(429))
+				retryAfter := recorder.Header().Get("Retry-After")
+				Expect(recorder.Header().Get("RateLimit")).To(Equal(
+					fmt.Sprintf("limit=%d, remaining=0, reset=%s", cfg.RateLimit.Requests, retryAfter)))
+				Expect(recorder.Header().Get("X-RateLimit-Remaining")).To(Equal("0"))
+			})
+		})
+
+		Context("when disabled", func() {
+			BeforeEach(func() {
+				serve(false)
+			})
+
+			It("emits only the legacy headers", func() {
+				request()
+
+				Expect(recorder.Header().Get("X-RateLimit-Limit")).NotTo(BeEmpty())
+				Expect(recorder.Header().Get("RateLimit")).To(BeEmpty())
+				Expect(recorder.Header().Get("RateLimit-Policy")).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Dry-run mode", func() {
+		var recorded *metrics.Metrics
+
+		BeforeEach(func() {
+			cfg.RateLimit.DryRun = true
+			recorded = metrics.NewMetrics()
+			limiter = middleware.NewRateLimiterWithCleanup(cfg, logger, time.Minute)
+			limiter.SetWouldBlockRecorder(recorded)
+			router = gin.New()
+			router.GET("/test", middleware.RateLimit(limiter), func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+		})
+
+		It("allows requests over the limit but counts them as would-block", func() {
+			for i := 0; i < cfg.RateLimit.Requests+2; i++ {
+				recorder = httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+				req.RemoteAddr = "192.168.1.1:12345"
+				router.ServeHTTP(recorder, req)
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Header().Get("X-RateLimit-Limit")).To(Equal(strconv.Itoa(cfg.RateLimit.Requests)))
+			}
+
+			Expect(recorder.Header().Get("X-RateLimit-Remaining")).To(Equal("0"))
+			Expect(recorded.GetRateLimitWouldBlock()).To(Equal(int64(2)))
+		})
+
+		It("does not count requests within the limit", func() {
+			recorder = httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.168.1.1:12345"
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorded.GetRateLimitWouldBlock()).To(BeZero())
+		})
+	})
+
+	Describe("Bypass token", func() {
+		const token = "monitor-token-0123456789"
+		var observedLogs *observer.ObservedLogs
+
+		request := func(header, value string) int {
+			recorder = httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.168.1.1:12345"
+			if header != "" {
+				req.Header.Set(header, value)
+			}
+			router.ServeHTTP(recorder, req)
+			return recorder.Code
+		}
+
+		BeforeEach(func() {
+			cfg.RateLimit.BypassHeader = "X-Internal-Token"
+			cfg.RateLimit.BypassToken = token
+			limiter = middleware.NewRateLimiterWithCleanup(cfg, logger, time.Minute)
+
+			var core zapcore.Core
+			core, observedLogs = observer.New(zapcore.InfoLevel)
+			router = gin.New()
+			router.Use(middleware.Logging(zap.New(core)))
+			router.GET("/test", middleware.RateLimit(limiter), func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+		})
+
+		It("never limits requests carrying the token", func() {
+			for i := 0; i < cfg.RateLimit.Requests*3; i++ {
+				Expect(request("X-Internal-Token", token)).To(Equal(http.StatusOK))
+			}
+			Expect(recorder.Header().Get("X-RateLimit-Remaining")).To(BeEmpty())
+			Expect(observedLogs.FilterMessage("Rate limit bypassed").Len()).To(Equal(cfg.RateLimit.Requests * 3))
+		})
+
+		It("doesn't spend the client's tokens on bypassed requests", func() {
+			request("X-Internal-Token", token)
+
+			Expect(request("", "")).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("X-RateLimit-Remaining")).To(Equal(strconv.Itoa(cfg.RateLimit.Requests - 1)))
+		})
+
+		DescribeTable("limits requests without the right token",
+			func(header, value string) {
+				for i := 0; i < cfg.RateLimit.Requests; i++ {
+					Expect(request(header, value)).To(Equal(http.StatusOK))
+				}
+				Expect(request(header, value)).To(Equal(http.StatusTooManyRequests))
+				Expect(observedLogs.FilterMessage("Rate limit bypassed").Len()).To(BeZero())
+			},
+			Entry("absent", "", ""),
+			Entry("wrong", "X-Internal-Token", "monitor-token-9876543210"),
+			Entry("a prefix of it", "X-Internal-Token", token[:8]),
+			Entry("in another header", "X-Other-Token", token),
+		)
+
+		It("is off without a token", func() {
+			cfg.RateLimit.BypassToken = ""
+			limiter = middleware.NewRateLimiterWithCleanup(cfg, logger, time.Minute)
+			router = gin.New()
+			router.GET("/test", middleware.RateLimit(limiter), func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+
+			for i := 0; i < cfg.RateLimit.Requests; i++ {
+				request("X-Internal-Token", "")
+			}
+			Expect(request("X-Internal-Token", "")).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
 	Describe("NewRateLimiter", func() {
 		var testLogger *zap.Logger
 
@@ -0,0 +1,164 @@
+// Package pages renders the HTML pages served to browsers, such as the link
+// preview interstitial, the not-found, expired and error pages and the root
+// status page, localized from embedded translations.
+package pages
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page names
+const (
+	Error    = "error"
+	Expired  = "expired"
+	NotFound = "not_found"
+	Preview  = "preview"
+	Status   = "status"
+)
+
+// DefaultLocale is used when no configured or requested locale is available
+const DefaultLocale = "en"
+
+//go:embed templates/*.html locales/*.json
+var files embed.FS
+
+// Data is passed to page templates
+type Data struct {
+	Lang  string
+	T     map[string]string
+	Brand string
+
+	// Page specific values
+	Code        string
+	OriginalURL string
+	Message     string // Overrides the page's default message when set
+	Status      int    // HTTP status shown on the error page
+}
+
+// Renderer renders localized pages
+type Renderer struct {
+	templates *template.Template
+	locales   map[string]map[string]string
+	fallback  string
+}
+
+// New creates a renderer from the embedded templates and translations.
+// fallback is the locale used when none of the requested ones are available.
+func New(fallback string) (*Renderer, error) {
+	templates, err := template.ParseFS(files, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parsing page templates: %w", err)
+	}
+
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("reading locales: %w", err)
+	}
+
+	locales := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		raw, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading locale %s: %w", entry.Name(), err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return nil, fmt.Errorf("parsing locale %s: %w", entry.Name(), err)
+		}
+
+		locales[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+
+	fallback = strings.ToLower(fallback)
+	if _, ok := locales[fallback]; !ok {
+		fallback = DefaultLocale
+	}
+
+	return &Renderer{
+		templates: templates,
+		locales:   locales,
+		fallback:  fallback,
+	}, nil
+}
+
+// MustNew is like New but panics if the embedded assets are invalid
+func MustNew(fallback string) *Renderer {
+	r, err := New(fallback)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Negotiate picks the best available locale for an Accept-Language header
+func (r *Renderer) Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if _, ok := r.locales[c.tag]; ok {
+			return c.tag
+		}
+
+		// Fall back from a regional tag such as fr-CA to its base language
+		if base, _, found := strings.Cut(c.tag, "-"); found {
+			if _, ok := r.locales[base]; ok {
+				return base
+			}
+		}
+	}
+
+	return r.fallback
+}
+
+// Render writes the named page in the given locale
+func (r *Renderer) Render(w io.Writer, page, locale string, data Data) error {
+	messages, ok := r.locales[locale]
+	if !ok {
+		locale = r.fallback
+		messages = r.locales[locale]
+	}
+
+	data.Lang = locale
+	data.T = messages
+
+	return r.templates.ExecuteTemplate(w, page+".html", data)
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/db"
+)
+
+// unreachableDB is a database/sql connector for a database that is down,
+// enough to build the router without one
+type unreachableDB struct{}
+
+func (unreachableDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return nil, errors.New("database unavailable")
+}
+func (unreachableDB) Driver() driver.Driver { return nil }
+
+var _ = Describe("Served routes", func() {
+	var engine *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		os.Setenv("MASTER_PASSWORD", "master_password_placeholder")
+		DeferCleanup(os.Unsetenv, "MASTER_PASSWORD")
+
+		cfg, err := config.LoadConfig()
+		Expect(err).NotTo(HaveOccurred())
+
+		conn := sql.OpenDB(unreachableDB{})
+		DeferCleanup(conn.Close)
+
+		handler, shutdown := New(cfg, zap.NewNop(), &db.DB{DB: conn})
+		DeferCleanup(shutdown, context.Background())
+		engine = handler.(*gin.Engine)
+	})
+
+	// The legacy Link model is only kept for its tests; deployments run on
+	// the ShortLink model alone, so there is no switch for its routes
+	It("should serve no route through the legacy link handler", func() {
+		routes := engine.Routes()
+		Expect(routes).NotTo(BeEmpty())
+
+		for _, route := range routes {
+			Expect(route.Handler).NotTo(ContainSubstring("MockLinkHandler"), "%s %s", route.Method, route.Path)
+		}
+	})
+
+	It("should serve the link routes through the short link handler", func() {
+		served := map[string]string{}
+		for _, route := range engine.Routes() {
+			served[route.Method+" "+route.Path] = route.Handler
+		}
+
+		for _, route := range []string{
+			http.MethodPost + " /api/links",
+			http.MethodGet + " /api/links",
+			http.MethodGet + " /api/links/:code",
+			http.MethodDelete + " /api/links/:code",
+		} {
+			Expect(served).To(HaveKeyWithValue(route, ContainSubstring("(*LinkHandler)")))
+		}
+	})
+})
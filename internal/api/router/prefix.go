@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+// legacyRootPaths are routes that used to live at the root, where they
+// shadowed any short code of the same name
+var legacyRootPaths = []string{"/metrics", "/version"}
+
+// mountAPI returns the group the API, admin, health, metrics and version
+// routes are registered on, leaving every other root path to short codes.
+// With LegacyRootRoutes on, the paths those routes had before, including
+// the default /api prefix when another one is configured, permanently
+// redirect to their new place so existing clients and scrapers keep working.
+func mountAPI(router *gin.Engine, cfg *config.Config) *gin.RouterGroup {
+	prefix := cfg.Server.APIPrefix
+
+	if cfg.Server.LegacyRootRoutes {
+		for _, path := range legacyRootPaths {
+			router.GET(path, redirectUnder(prefix, ""))
+		}
+		if prefix != handlers.DefaultAPIPrefix {
+			router.Any(handlers.DefaultAPIPrefix+"/*path", redirectUnder(prefix, handlers.DefaultAPIPrefix))
+		}
+	}
+
+	return router.Group(prefix)
+}
+
+// redirectUnder moves requests from below oldPrefix to the same path below
+// prefix. 308 keeps the method and body, so API writes follow it too.
+func redirectUnder(prefix, oldPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target := prefix + strings.TrimPrefix(c.Request.URL.Path, oldPrefix)
+		if c.Request.URL.RawQuery != "" {
+			target += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusPermanentRedirect, target)
+	}
+}
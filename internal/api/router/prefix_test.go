@@ -0,0 +1,102 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+var _ = Describe("API prefix", func() {
+	var (
+		cfg    *config.Config
+		engine *gin.Engine
+	)
+
+	serve := func(prefix string, legacy bool) {
+		cfg.Server.APIPrefix = prefix
+		cfg.Server.LegacyRootRoutes = legacy
+
+		engine = gin.New()
+		api := mountAPI(engine, cfg)
+		api.GET("/health", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+		api.GET("/metrics", func(c *gin.Context) {
+			c.String(http.StatusOK, "# metrics")
+		})
+		api.POST("/links", func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+
+		linkHandler := handlers.NewLinkHandlerWithOptions(redirectLinks{}, "http://localhost:8081", nil,
+			handlers.LinkHandlerOptions{Features: config.DefaultFeatures(), APIPrefix: prefix})
+		registerRedirects(engine, linkHandler, cfg, nil)
+	}
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(""))
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		cfg = &config.Config{Security: config.SecurityConfig{RedirectAccess: "public"}}
+	})
+
+	Context("with the default prefix", func() {
+		BeforeEach(func() {
+			serve("/api", true)
+		})
+
+		It("should resolve a code named health at the root", func() {
+			recorder := request(http.MethodGet, "/health")
+
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/health"))
+		})
+
+		It("should serve the health check under the prefix", func() {
+			recorder := request(http.MethodGet, "/api/health")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"status":"ok"`))
+		})
+
+		It("should redirect the old metrics path under the prefix", func() {
+			recorder := request(http.MethodGet, "/metrics?format=text")
+
+			Expect(recorder.Code).To(Equal(http.StatusPermanentRedirect))
+			Expect(recorder.Header().Get("Location")).To(Equal("/api/metrics?format=text"))
+		})
+	})
+
+	Context("with a custom prefix", func() {
+		It("should redirect the old API paths, keeping the method", func() {
+			serve("/v2", true)
+
+			recorder := request(http.MethodPost, "/api/links?dry_run=1")
+
+			Expect(recorder.Code).To(Equal(http.StatusPermanentRedirect))
+			Expect(recorder.Header().Get("Location")).To(Equal("/v2/links?dry_run=1"))
+			Expect(request(http.MethodPost, "/v2/links").Code).To(Equal(http.StatusCreated))
+			Expect(request(http.MethodGet, "/v2/health").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should leave the old paths to short codes without legacy routes", func() {
+			serve("/v2", false)
+
+			Expect(request(http.MethodGet, "/api/health").Code).To(Equal(http.StatusNotFound))
+			Expect(request(http.MethodGet, "/metrics").Code).To(Equal(http.StatusNotFound))
+			Expect(request(http.MethodGet, "/health").Code).To(Equal(http.StatusMovedPermanently))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/api/middleware"
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+// registerRedirects adds the short link routes. They are unprotected by
+// default, with a bearer token only identifying the visitor for links
+// restricted to some users; in private mode they need the same token as the
+// API, so anonymous visitors get a 401 instead of the destination.
+func registerRedirects(router *gin.Engine, linkHandler *handlers.LinkHandler, cfg *config.Config, tokens middleware.AuthService) {
+	guard := []gin.HandlerFunc{middleware.OptionalAuthentication(tokens)}
+	if cfg.Security.RedirectAccess == "private" {
+		guard = []gin.HandlerFunc{middleware.Authentication(tokens)}
+	}
+
+	// The root path never reaches :code, which needs at least one
+	// character, so it has its own handler. It names no link and stays public.
+	router.GET("/", linkHandler.Root)
+	router.GET("/:code", append(guard, linkHandler.RedirectLink)...)
+	// Otherwise Gin answers /:code/ with a 301 to /:code before resolving it
+	if cfg.ShortLink.TrailingSlash == "strip" {
+		router.GET("/:code/", append(guard, linkHandler.RedirectLink)...)
+	}
+	router.GET("/:code/preview", append(guard, linkHandler.PreviewLink)...)
+
+	// Paths below a code, e.g. /docs/guides/setup, are resolved by pattern links
+	router.NoRoute(append(guard, linkHandler.RedirectPattern)...)
+}
@@ -0,0 +1,168 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/handlers"
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/config"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// redirectLinks serves an open and a restricted link; the embedded
+// interface panics if the redirect routes reach any other method
+type redirectLinks struct {
+	handlers.LinkService
+}
+
+func (redirectLinks) GetShortLinkByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	link := &domain.ShortLink{
+		ID:       "link-" + code,
+		Code:     code,
+		IsActive: true,
+		URL:      &domain.URL{OriginalURL: "https://example.com/" + code},
+	}
+
+	switch code {
+	case "docs", "health":
+		return link, nil
+	case "team":
+		link.AllowedUsers = []string{"alice"}
+		return link, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (redirectLinks) RecordClick(ctx context.Context, shortLinkID, referrer, userAgent, ipAddress, rawQuery string) error {
+	return nil
+}
+
+var _ = Describe("Redirect routes", func() {
+	var (
+		cfg    *config.Config
+		tokens *auth.TokenService
+		engine *gin.Engine
+	)
+
+	serve := func(access string) {
+		cfg.Security.RedirectAccess = access
+		engine = gin.New()
+		linkHandler := handlers.NewLinkHandlerWithOptions(redirectLinks{}, "http://localhost:8081", nil,
+			handlers.LinkHandlerOptions{Features: config.DefaultFeatures()})
+		registerRedirects(engine, linkHandler, cfg, tokens)
+	}
+
+	request := func(path, token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		cfg = &config.Config{Security: config.SecurityConfig{
+			MasterPassword: "router-test-password",
+			TokenExpiry:    time.Hour,
+		}}
+		tokens = auth.NewTokenService(cfg)
+	})
+
+	Context("in public mode", func() {
+		BeforeEach(func() {
+			serve("public")
+		})
+
+		It("should redirect anonymous visitors", func() {
+			recorder := request("/docs", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/docs"))
+		})
+
+		It("should identify token holders for restricted links", func() {
+			token, err := tokens.GenerateToken("alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(request("/team", token).Code).To(Equal(http.StatusMovedPermanently))
+			Expect(request("/team", "").Code).To(Equal(http.StatusUnauthorized))
+			Expect(request("/team", "not-a-token").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("with a trailing slash", func() {
+		It("should send visitors to the path without it by default", func() {
+			serve("public")
+
+			recorder := request("/docs/", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal("/docs"))
+		})
+
+		It("should resolve both forms to the same destination when stripped", func() {
+			cfg.ShortLink.TrailingSlash = "strip"
+			serve("public")
+
+			for _, path := range []string{"/docs", "/docs/"} {
+				recorder := request(path, "")
+
+				Expect(recorder.Code).To(Equal(http.StatusMovedPermanently), path)
+				Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/docs"), path)
+			}
+		})
+
+		It("should keep previews and unknown codes apart when stripped", func() {
+			cfg.ShortLink.TrailingSlash = "strip"
+			serve("public")
+
+			Expect(request("/missing/", "").Code).To(Equal(http.StatusNotFound))
+			Expect(request("/docs/preview", "").Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("in private mode", func() {
+		BeforeEach(func() {
+			serve("private")
+		})
+
+		It("should redirect authenticated visitors", func() {
+			token, err := tokens.GenerateToken("alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := request("/docs", token)
+
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/docs"))
+		})
+
+		DescribeTable("should reject anonymous visitors",
+			func(path, token string) {
+				recorder := request(path, token)
+
+				Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+				Expect(recorder.Header().Get("Location")).To(BeEmpty())
+			},
+			Entry("redirect", "/docs", ""),
+			Entry("preview", "/docs/preview", ""),
+			Entry("pattern path", "/docs/guides/setup", ""),
+			Entry("invalid token", "/docs", "not-a-token"),
+		)
+
+		It("should leave the root path public", func() {
+			recorder := request("/", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -1,11 +1,14 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -14,6 +17,8 @@ import (
 	"github.com/menezmethod/ref_go/internal/api/handlers"
 	"github.com/menezmethod/ref_go/internal/api/middleware"
 	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/buildinfo"
+	"github.com/menezmethod/ref_go/internal/cache"
 	"github.com/menezmethod/ref_go/internal/config"
 	"github.com/menezmethod/ref_go/internal/db"
 	"github.com/menezmethod/ref_go/internal/metrics"
@@ -21,51 +26,236 @@ import (
 	"github.com/menezmethod/ref_go/internal/service"
 )
 
-// New creates a new HTTP router with middleware
-func New(cfg *config.Config, logger *zap.Logger, database *db.DB) http.Handler {
+// New creates a new HTTP router with middleware, along with a function to
+// call once the server has stopped serving requests
+func New(cfg *config.Config, logger *zap.Logger, database *db.DB) (http.Handler, func(context.Context)) {
 	// Set Gin to release mode in production
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Bind JSON numbers into interface{} values as json.Number rather than
+	// float64, so counts past 2^53 keep their precision
+	binding.EnableDecoderUseNumber = true
+
 	// Configure Swagger UI to use correct host
 	// Update Swagger info based on actual server config
 	docs.SwaggerInfo.Host = "localhost:" + fmt.Sprintf("%d", cfg.Server.Port)
+	docs.SwaggerInfo.BasePath = cfg.Server.APIPrefix
 	logger.Info("Configured Swagger UI with host", zap.String("host", docs.SwaggerInfo.Host))
 
 	// Create a new Gin router
 	router := gin.New()
 
+	// Only honor forwarding headers from configured proxies
+	if err := router.SetTrustedProxies(cfg.Security.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxy configuration", zap.Error(err))
+	}
+
 	// Initialize metrics
 	metricsCollector := metrics.NewMetrics()
 
 	// Create rate limiter
 	rateLimiter := middleware.NewRateLimiter(cfg, logger)
+	rateLimiter.SetWouldBlockRecorder(metricsCollector)
+
+	// Report slow queries before the repositories start using the database
+	database.LogSlowQueries(cfg.Database.SlowQueryThreshold, logger, metricsCollector)
 
 	// Create repositories
 	urlRepo := postgres.NewURLRepository(database)
 	linkRepo := postgres.NewShortLinkRepository(database)
 	clickRepo := postgres.NewLinkClickRepository(database)
+	auditRepo := postgres.NewAuditLogRepository(database)
+	collectionRepo := postgres.NewCollectionRepository(database)
 
 	// Create services
 	tokenService := auth.NewTokenService(cfg)
-	shortenerService := service.NewURLShortenerService(
+
+	var onClickRateAlert service.ClickRateAlertFunc
+	if cfg.Analytics.ClickRateWebhookURL != "" {
+		onClickRateAlert = service.ClickRateWebhook(cfg.Analytics.ClickRateWebhookURL, logger)
+	}
+
+	var clickBeacon service.ClickBeaconFunc
+	if cfg.Analytics.ClickBeaconURL != "" {
+		clickBeacon = service.ClickBeaconWebhook(cfg.Analytics.ClickBeaconURL)
+	}
+
+	shortenerService := service.NewURLShortenerServiceWithOptions(
 		urlRepo,
 		linkRepo,
 		clickRepo,
 		logger,
-		cfg.Server.BaseURL,
-		cfg.ShortLink.DefaultExpiry,
+		service.Options{
+			BaseURL:         cfg.Server.BaseURL,
+			DefaultExpiry:   cfg.ShortLink.DefaultExpiry,
+			CodeAttempts:    cfg.ShortLink.CodeAttempts,
+			MinAliasLength:  cfg.ShortLink.MinAliasLength,
+			CodeCase:        cfg.ShortLink.CodeCase,
+			AllowedSchemes:  cfg.ShortLink.AllowedSchemes,
+			RequireHTTPS:    cfg.ShortLink.RequireHTTPS,
+			IPAnonymization: cfg.Privacy.IPAnonymization,
+			IPHashSalt:      cfg.Privacy.IPHashSalt,
+			AuditLog:        auditRepo,
+			Collections:     collectionRepo,
+
+			ReachabilityCheck:     cfg.ShortLink.ReachabilityCheck,
+			ReachabilityTimeout:   cfg.ShortLink.ReachabilityTimeout,
+			ProbeAllowedAddresses: cfg.ShortLink.ProbeAllowedAddresses,
+
+			SelfLinks:  cfg.ShortLink.SelfLinks,
+			ShortHosts: cfg.ShortLink.OwnHosts,
+
+			ExportMaxRows:          cfg.ShortLink.ExportMaxRows,
+			HealthCheckConcurrency: cfg.ShortLink.HealthCheckConcurrency,
+			CodeDisplayGroup:       cfg.ShortLink.CodeDisplayGroup,
+
+			MaxLinksPerUser:  cfg.ShortLink.MaxLinksPerUser,
+			QuotaExemptUsers: cfg.ShortLink.QuotaExemptUsers,
+
+			UniqueDestinations: cfg.ShortLink.UniqueDestinations,
+
+			ClickDedupeWindow: cfg.Analytics.ClickDedupeWindow,
+
+			ClickRateThreshold: cfg.Analytics.ClickRateThreshold,
+			ClickRateWindow:    cfg.Analytics.ClickRateWindow,
+			OnClickRateAlert:   onClickRateAlert,
+
+			ClickBeacon:        clickBeacon,
+			ClickBeaconTimeout: cfg.Analytics.ClickBeaconTimeout,
+			ClickBeaconFields:  cfg.Analytics.ClickBeaconFields,
+
+			ClickSampleRate: cfg.Analytics.ClickSampleRate,
+			CampaignParams:  cfg.Analytics.ClickCampaignParams,
+
+			// Links to these on our own hosts would lend them our domain's trust
+			InternalPaths: []string{cfg.Server.APIPrefix + "/admin", cfg.Server.APIPrefix + "/metrics"},
+
+			// Codes can't take the API prefix's place at the root
+			ReservedAliases: []string{strings.TrimPrefix(cfg.Server.APIPrefix, "/")},
+		},
+	)
+
+	// Optionally serve link lookups through the cache
+	var linkService handlers.LinkService = shortenerService
+	var linkCache handlers.LinkCacheInvalidator
+	if cfg.Cache.Enabled {
+		cachedService := service.NewCachedURLShortenerServiceWithNamespace(
+			shortenerService,
+			cache.NewMemoryCache(),
+			logger,
+			cfg.Cache.Namespace,
+		)
+		cachedService.CacheLists(cfg.Cache.ListTTL)
+
+		// Load the hottest links before serving to avoid a cold-cache burst
+		if cfg.Cache.WarmUpEnabled {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if _, err := cachedService.WarmUp(ctx, cfg.Cache.WarmUpLinks); err != nil {
+				logger.Error("Cache warm-up failed", zap.Error(err))
+			}
+			cancel()
+		}
+
+		linkService = cachedService
+		linkCache = cachedService
+	}
+
+	// Lists cached as whole pages can't be streamed
+	var listStreamer handlers.LinkListStreamer
+	if !cfg.Cache.Enabled || cfg.Cache.ListTTL == 0 {
+		listStreamer = shortenerService
+	}
+
+	// Schedule purging of raw clicks past the retention period
+	retentionJob := service.NewClickRetentionJob(
+		clickRepo,
+		cfg.Analytics.ClickRetention,
+		cfg.Analytics.ArchiveExpiredClicks,
+		logger,
 	)
+	go retentionJob.Start(context.Background(), cfg.Analytics.ClickRetentionInterval)
+
+	// Schedule rolling up completed days into the daily stats table
+	rollupJob := service.NewClickRollupJob(clickRepo, logger)
+	go rollupJob.Start(context.Background(), cfg.Analytics.ClickRollupInterval)
+
+	// Keep the redirect counters across restarts when enabled
+	shutdown := func(context.Context) {}
+	if cfg.Analytics.MetricsFlushInterval > 0 {
+		persistJob := service.NewMetricsPersistJob(metricsCollector, postgres.NewMetricCounterRepository(database), logger)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := persistJob.Load(ctx); err != nil {
+			logger.Error("Loading metric counters failed", zap.Error(err))
+		}
+		cancel()
+
+		flushCtx, stopFlushing := context.WithCancel(context.Background())
+		go persistJob.Start(flushCtx, cfg.Analytics.MetricsFlushInterval)
+
+		shutdown = func(ctx context.Context) {
+			stopFlushing()
+			if _, err := persistJob.Flush(ctx); err != nil {
+				logger.Error("Final metric counter flush failed", zap.Error(err))
+			}
+		}
+	}
 
 	// Create handlers
 	authHandler := handlers.NewAuthHandler(tokenService)
-	linkHandler := handlers.NewLinkHandler(shortenerService, cfg.Server.BaseURL, metricsCollector)
+	collectionHandler := handlers.NewCollectionHandler(service.NewCollectionService(collectionRepo, logger))
+	statsService := service.NewSystemStatsService(linkRepo, clickRepo, cfg.Analytics.SystemStatsCacheTTL)
+	adminHandler := handlers.NewAdminHandler(retentionJob, statsService, shortenerService, shortenerService, shortenerService, shortenerService, linkCache, shortenerService)
+	// Validate has already checked the zone name
+	statsLocation, _ := time.LoadLocation(cfg.Analytics.StatsTimezone)
+	sharedStatsHandler := handlers.NewSharedStatsHandler(
+		linkService,
+		service.NewStatsShareService(linkRepo, clickRepo, cfg.Security.MasterPassword, cfg.Analytics.StatsShareTTL),
+		statsLocation,
+	)
+	linkHandler := handlers.NewLinkHandlerWithOptions(
+		linkService,
+		cfg.Server.BaseURL,
+		metricsCollector,
+		handlers.LinkHandlerOptions{
+			DefaultLocale:       cfg.Pages.DefaultLocale,
+			BrandName:           cfg.Pages.BrandName,
+			NotFoundMode:        cfg.Pages.NotFoundMode,
+			NotFoundRedirectURL: cfg.Pages.NotFoundRedirectURL,
+			ExpiredStatus:       cfg.Pages.ExpiredStatus,
+			ExpiredMessage:      cfg.Pages.ExpiredMessage,
+			RootMode:            cfg.Pages.RootMode,
+			RootRedirectURL:     cfg.Pages.RootRedirectURL,
+			PreviewCacheMaxAge:  cfg.Pages.PreviewCacheMaxAge,
+			MaxPageSize:         cfg.Server.MaxPageSize,
+			MaxCodeLength:       cfg.ShortLink.MaxCodeLength,
+			Features:            cfg.Features,
+			AllowedSchemes:      cfg.ShortLink.AllowedSchemes,
+			ForwardQueryParams:  cfg.ShortLink.ForwardQueryParams,
+			StatsLocation:       statsLocation,
+			LogDestinations:     cfg.Logging.Destinations,
+			APIPrefix:           cfg.Server.APIPrefix,
+			DisplayCode:         shortenerService.DisplayCode,
+			ListStreamer:        listStreamer,
+		},
+	)
 
 	// Apply global middleware
+	if cfg.Security.TrustForwardedHeader {
+		router.Use(middleware.ForwardedClientIP(cfg.Security.TrustedProxies))
+	}
+	if len(cfg.Security.ClientIPHeaders) > 0 {
+		router.Use(middleware.HeaderClientIP(cfg.Security.ClientIPHeaders, cfg.Security.TrustedProxies))
+	}
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logging(logger))
-	router.Use(middleware.Recovery())
+	router.Use(middleware.QueryGuard(cfg.Server.MaxQueryLength))
+	prefix := cfg.Server.APIPrefix
+	router.Use(middleware.MaxConcurrentRequests(cfg.Server.MaxConcurrentRequests,
+		prefix+"/health", prefix+"/ready", prefix+"/metrics", prefix+"/version"))
+	router.Use(middleware.RecoveryWithMetrics(metricsCollector))
 	router.Use(middleware.Metrics(metricsCollector))
 	router.Use(middleware.SecurityHeaders())
 	router.Use(middleware.CORS([]string{"*"})) // For development - change in production
@@ -79,8 +269,11 @@ func New(cfg *config.Config, logger *zap.Logger, database *db.DB) http.Handler {
 		ginSwagger.WrapHandler(swaggerFiles.Handler)(c)
 	})
 
+	// Mount everything but the short codes under the API prefix
+	api := mountAPI(router, cfg)
+
 	// Register health check and readiness endpoints (unprotected)
-	router.GET("/api/health", func(c *gin.Context) {
+	api.GET("/health", func(c *gin.Context) {
 		// Check database connectivity
 		dbStatus := "ok"
 		dbError := ""
@@ -115,7 +308,7 @@ func New(cfg *config.Config, logger *zap.Logger, database *db.DB) http.Handler {
 		})
 	})
 
-	router.GET("/api/ready", func(c *gin.Context) {
+	api.GET("/ready", func(c *gin.Context) {
 		// Check database connectivity
 		ctx := c.Request.Context()
 		if err := database.HealthCheck(ctx); err != nil {
@@ -157,7 +350,7 @@ func New(cfg *config.Config, logger *zap.Logger, database *db.DB) http.Handler {
 	})
 
 	// Register metrics endpoint (public)
-	router.GET("/metrics", func(c *gin.Context) {
+	api.GET("/metrics", func(c *gin.Context) {
 		// Update short link count before serving metrics
 		count, err := linkRepo.Count(c.Request.Context())
 		if err != nil {
@@ -169,24 +362,60 @@ func New(cfg *config.Config, logger *zap.Logger, database *db.DB) http.Handler {
 		metricsCollector.ServeHTTP(c.Writer, c.Request)
 	})
 
+	// Register build info endpoint (public)
+	api.GET("/version", handlers.NewVersionHandler(buildinfo.Get()).GetVersion)
+
 	// Register auth routes
-	router.POST("/api/auth/token", authHandler.GenerateToken)
+	api.POST("/auth/token", authHandler.GenerateToken)
+
+	// Register shared stats, where the share token in the query is the only credential
+	api.GET("/shared/stats", middleware.RateLimit(rateLimiter), sharedStatsHandler.GetSharedStats)
 
-	// Register redirect endpoint (unprotected)
-	router.GET("/:code", linkHandler.RedirectLink)
+	// Register redirect endpoints, public unless running in private mode
+	registerRedirects(router, linkHandler, cfg, tokenService)
 
 	// Group protected API routes
-	api := router.Group("/api/links")
-	api.Use(middleware.Authentication(tokenService))
-	api.Use(middleware.RateLimit(rateLimiter))
+	links := api.Group("/links")
+	links.Use(middleware.Authentication(tokenService))
+	links.Use(middleware.RateLimit(rateLimiter))
+	{
+		links.GET("", linkHandler.ListLinks)
+		links.POST("", linkHandler.CreateLink)
+		links.POST("/stats", linkHandler.GetLinkStatsBatch)
+		links.GET("/:code", linkHandler.GetLink)
+		links.PUT("/:code", linkHandler.UpdateLink)
+		links.DELETE("/:code", linkHandler.DeleteLink)
+		links.GET("/:code/stats", linkHandler.GetLinkStats)
+		links.POST("/:code/stats/share", sharedStatsHandler.ShareLinkStats)
+		links.GET("/:code/clicks/:clickID", linkHandler.GetLinkClick)
+	}
+
+	// Group protected collection routes, scoped to the token's user
+	collections := api.Group("/collections")
+	collections.Use(middleware.Authentication(tokenService))
+	collections.Use(middleware.RateLimit(rateLimiter))
+	{
+		collections.GET("", collectionHandler.ListCollections)
+		collections.POST("", collectionHandler.CreateCollection)
+		collections.GET("/:id", collectionHandler.GetCollection)
+		collections.PUT("/:id", collectionHandler.UpdateCollection)
+		collections.DELETE("/:id", collectionHandler.DeleteCollection)
+	}
+
+	// Group protected admin routes
+	admin := api.Group("/admin")
+	admin.Use(middleware.Authentication(tokenService))
+	admin.Use(middleware.RateLimit(rateLimiter))
 	{
-		api.GET("", linkHandler.ListLinks)
-		api.POST("", linkHandler.CreateLink)
-		api.GET("/:code", linkHandler.GetLink)
-		api.PUT("/:code", linkHandler.UpdateLink)
-		api.DELETE("/:code", linkHandler.DeleteLink)
-		api.GET("/:code/stats", linkHandler.GetLinkStats)
+		admin.POST("/clicks/purge", adminHandler.PurgeClicks)
+		admin.GET("/stats", adminHandler.GetStats)
+		admin.GET("/links/:code/history", adminHandler.GetLinkHistory)
+		admin.POST("/links/:code/invalidate-cache", adminHandler.InvalidateLinkCache)
+		admin.POST("/links/health-check", adminHandler.CheckLinkHealth)
+		admin.GET("/links/by-host", adminHandler.ListLinksByDestinationHost)
+		admin.POST("/import", adminHandler.ImportLinks)
+		admin.GET("/export", adminHandler.ExportLinks)
 	}
 
-	return router
+	return router, shutdown
 }
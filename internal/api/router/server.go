@@ -0,0 +1,23 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+// NewServer creates the HTTP server for handler with the configured
+// timeouts and header limit. ReadHeaderTimeout bounds how long a client may
+// take to send its headers, so slow clients can't hold connections open.
+func NewServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+}
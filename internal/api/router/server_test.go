@@ -0,0 +1,69 @@
+package router_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/api/router"
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+func TestRouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Router Suite")
+}
+
+var _ = Describe("NewServer", func() {
+	handler := http.NotFoundHandler()
+
+	It("should set the timeouts and header limit from config", func() {
+		cfg := &config.Config{Server: config.ServerConfig{
+			Port:              9090,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 3 * time.Second,
+			WriteTimeout:      20 * time.Second,
+			IdleTimeout:       90 * time.Second,
+			MaxHeaderBytes:    64 << 10,
+		}}
+
+		srv := router.NewServer(cfg, handler)
+
+		Expect(srv.Addr).To(Equal(":9090"))
+		Expect(srv.ReadTimeout).To(Equal(15 * time.Second))
+		Expect(srv.ReadHeaderTimeout).To(Equal(3 * time.Second))
+		Expect(srv.WriteTimeout).To(Equal(20 * time.Second))
+		Expect(srv.IdleTimeout).To(Equal(90 * time.Second))
+		Expect(srv.MaxHeaderBytes).To(Equal(64 << 10))
+	})
+
+	It("should bound every phase of a request with the default config", func() {
+		originalEnv := os.Environ()
+		DeferCleanup(func() {
+			os.Clearenv()
+			for _, envVar := range originalEnv {
+				if key, value, ok := strings.Cut(envVar, "="); ok {
+					os.Setenv(key, value)
+				}
+			}
+		})
+		os.Clearenv()
+		os.Setenv("MASTER_PASSWORD", "router-test-password")
+
+		cfg, err := config.LoadConfig()
+		Expect(err).NotTo(HaveOccurred())
+
+		srv := router.NewServer(cfg, handler)
+
+		Expect(srv.ReadTimeout).To(Equal(30 * time.Second))
+		Expect(srv.ReadHeaderTimeout).To(Equal(10 * time.Second))
+		Expect(srv.WriteTimeout).To(Equal(30 * time.Second))
+		Expect(srv.IdleTimeout).To(Equal(120 * time.Second))
+		Expect(srv.MaxHeaderBytes).To(Equal(1 << 20))
+	})
+})
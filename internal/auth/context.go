@@ -0,0 +1,19 @@
+package auth
+
+import "context"
+
+type contextKey string
+
+const userIDKey contextKey = "user_id"
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID, or "" when the
+// request was not made on behalf of a specific user
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
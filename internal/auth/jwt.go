@@ -26,13 +26,22 @@ func NewTokenService(cfg *config.Config) *TokenService {
 	}
 }
 
-// GenerateToken creates a new JWT token
-func (s *TokenService) GenerateToken() (string, error) {
+// GenerateToken creates a new JWT token. A non-empty userID is stored as the
+// token subject so actions can be attributed to that user.
+func (s *TokenService) GenerateToken(userID string) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(s.config.Security.TokenExpiry)
 
+	var audience jwt.ClaimStrings
+	if s.config.Security.TokenAudience != "" {
+		audience = jwt.ClaimStrings{s.config.Security.TokenAudience}
+	}
+
 	claims := TokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.config.Security.TokenIssuer,
+			Subject:   userID,
+			Audience:  audience,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -50,8 +59,17 @@ func (s *TokenService) GenerateToken() (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken verifies that a token is valid
+// ValidateToken verifies that a token is valid and, when configured, that it
+// was issued by and for this service
 func (s *TokenService) ValidateToken(tokenString string) (*TokenClaims, error) {
+	var opts []jwt.ParserOption
+	if s.config.Security.TokenIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.config.Security.TokenIssuer))
+	}
+	if s.config.Security.TokenAudience != "" {
+		opts = append(opts, jwt.WithAudience(s.config.Security.TokenAudience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -60,7 +78,7 @@ func (s *TokenService) ValidateToken(tokenString string) (*TokenClaims, error) {
 
 		// Return the secret used for signing
 		return []byte(s.config.Security.MasterPassword), nil
-	})
+	}, opts...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -80,5 +98,5 @@ func (s *TokenService) ValidateToken(tokenString string) (*TokenClaims, error) {
 
 // ValidateMasterPassword checks if the provided password matches the master password
 func (s *TokenService) ValidateMasterPassword(password string) bool {
-	return password == s.config.Security.MasterPassword
+	return SecretsEqual(password, s.config.Security.MasterPassword)
 }
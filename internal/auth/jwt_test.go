@@ -0,0 +1,91 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+func TestAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Auth Suite")
+}
+
+var _ = Describe("TokenService", func() {
+	const secret = "test_secret"
+
+	var tokens *auth.TokenService
+
+	// sign mints a token with the shared key, as another service could
+	sign := func(issuer string, audience ...string) string {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.TokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Audience:  audience,
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		})
+		signed, err := token.SignedString([]byte(secret))
+		Expect(err).NotTo(HaveOccurred())
+		return signed
+	}
+
+	BeforeEach(func() {
+		tokens = auth.NewTokenService(&config.Config{
+			Security: config.SecurityConfig{
+				MasterPassword: secret,
+				TokenExpiry:    time.Hour,
+				TokenIssuer:    "url-shortener",
+				TokenAudience:  "url-shortener-api",
+			},
+		})
+	})
+
+	It("should issue tokens carrying the configured issuer and audience", func() {
+		token, err := tokens.GenerateToken("user-1")
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := tokens.ValidateToken(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.Issuer).To(Equal("url-shortener"))
+		Expect(claims.Audience).To(ConsistOf("url-shortener-api"))
+		Expect(claims.Subject).To(Equal("user-1"))
+	})
+
+	It("should accept a token with the correct issuer and audience", func() {
+		_, err := tokens.ValidateToken(sign("url-shortener", "other-api", "url-shortener-api"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a token from another issuer", func() {
+		_, err := tokens.ValidateToken(sign("billing-service", "url-shortener-api"))
+		Expect(err).To(MatchError(jwt.ErrTokenInvalidIssuer))
+	})
+
+	It("should reject a token for another audience", func() {
+		_, err := tokens.ValidateToken(sign("url-shortener", "billing-api"))
+		Expect(err).To(MatchError(jwt.ErrTokenInvalidAudience))
+	})
+
+	It("should reject a token without the claims", func() {
+		_, err := tokens.ValidateToken(sign(""))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should skip the checks when no issuer or audience is configured", func() {
+		tokens = auth.NewTokenService(&config.Config{
+			Security: config.SecurityConfig{MasterPassword: secret, TokenExpiry: time.Hour},
+		})
+
+		_, err := tokens.ValidateToken(sign("billing-service", "billing-api"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// constantTimeCompare is subtle.ConstantTimeCompare, swappable in tests
+var constantTimeCompare = subtle.ConstantTimeCompare
+
+// SecretsEqual reports whether a provided secret matches the expected one
+// in constant time. Both are hashed first so the comparison doesn't leak
+// the expected secret's length either. An empty expected secret never
+// matches.
+func SecretsEqual(provided, expected string) bool {
+	if expected == "" {
+		return false
+	}
+
+	providedSum := sha256.Sum256([]byte(provided))
+	expectedSum := sha256.Sum256([]byte(expected))
+	return constantTimeCompare(providedSum[:], expectedSum[:]) == 1
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+var _ = Describe("SecretsEqual", func() {
+	var compared int
+
+	BeforeEach(func() {
+		compared = 0
+		constantTimeCompare = func(x, y []byte) int {
+			compared++
+			return subtle.ConstantTimeCompare(x, y)
+		}
+		DeferCleanup(func() { constantTimeCompare = subtle.ConstantTimeCompare })
+	})
+
+	It("should match equal secrets in constant time", func() {
+		Expect(SecretsEqual("s3cret-password", "s3cret-password")).To(BeTrue())
+		Expect(compared).To(Equal(1))
+	})
+
+	DescribeTable("should not match unequal secrets",
+		func(provided, expected string) {
+			Expect(SecretsEqual(provided, expected)).To(BeFalse())
+		},
+		Entry("different secret", "s3cret-passwore", "s3cret-password"),
+		Entry("prefix", "s3cret", "s3cret-password"),
+		Entry("longer", "s3cret-password!", "s3cret-password"),
+		Entry("different case", "S3CRET-PASSWORD", "s3cret-password"),
+		Entry("empty expected", "", ""),
+	)
+
+	It("should compare the master password with it", func() {
+		tokens := NewTokenService(&config.Config{
+			Security: config.SecurityConfig{MasterPassword: "s3cret-password", TokenExpiry: time.Hour},
+		})
+
+		Expect(tokens.ValidateMasterPassword("s3cret-password")).To(BeTrue())
+		Expect(tokens.ValidateMasterPassword("wrong")).To(BeFalse())
+		Expect(compared).To(Equal(2))
+	})
+})
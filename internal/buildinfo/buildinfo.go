@@ -0,0 +1,28 @@
+// Package buildinfo holds the version details stamped into the binary at
+// build time, e.g.
+//
+//	go build -ldflags "-X github.com/menezmethod/ref_go/internal/buildinfo.Version=v1.2.0" ./cmd/server
+package buildinfo
+
+// Set with -ldflags "-X"; a plain go build leaves the defaults
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version" example:"v1.2.0"`
+	Commit    string `json:"commit" example:"5ef3e86"`
+	BuildTime string `json:"build_time" example:"2024-03-15T10:00:00Z"`
+}
+
+// Get returns the build info of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+}
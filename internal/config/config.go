@@ -2,18 +2,24 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all application configuration
 type Config struct {
 	Server    ServerConfig
+	Logging   LoggingConfig
 	Database  DatabaseConfig
 	Security  SecurityConfig
 	RateLimit RateLimitConfig
 	ShortLink ShortLinkConfig
+	Pages     PagesConfig
+	Privacy   PrivacyConfig
+	Analytics AnalyticsConfig
+	Cache     CacheConfig
+	Features  FeaturesConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -24,6 +30,56 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	MaxPageSize  int // Largest page_size list endpoints accept
+
+	ReadHeaderTimeout time.Duration // Time allowed to read request headers
+	MaxHeaderBytes    int           // Largest request header block accepted
+
+	// MaxConcurrentRequests caps requests handled at once; further ones get
+	// a 503. Health, readiness and metrics are exempt. 0 means no limit.
+	MaxConcurrentRequests int
+
+	// APIPrefix is the single path segment, e.g. /api, that the API, admin,
+	// health, metrics and version routes are mounted under, leaving every
+	// other root path to short codes
+	APIPrefix string
+
+	// LegacyRootRoutes redirects the paths those routes used to have, such
+	// as /metrics, to their place under APIPrefix
+	LegacyRootRoutes bool
+
+	// MaxQueryLength rejects requests whose raw query string is longer, or
+	// has control characters or broken percent-encoding, with a 400 before
+	// any handler runs. 0 turns the check off.
+	MaxQueryLength int
+}
+
+// LoggingConfig holds log output settings
+type LoggingConfig struct {
+	// Per second, the first SampleInitial entries with the same level and
+	// message are logged and then every SampleThereafter-th one; a
+	// SampleInitial of 0 turns sampling off
+	SampleInitial    int
+	SampleThereafter int
+
+	// Destinations is how redirect and preview logs show destination URLs,
+	// which can carry tokens in their query: "full", "hash" (SHA-256) or
+	// "omit". The code and link ID are logged either way.
+	Destinations string
+}
+
+// PagesConfig holds settings for the HTML pages served to browsers
+type PagesConfig struct {
+	DefaultLocale       string // Used when Accept-Language names no supported locale
+	BrandName           string // Shown on preview and error pages
+	NotFoundMode        string // "page" renders the not found page, "redirect" sends visitors to NotFoundRedirectURL
+	NotFoundRedirectURL string
+	ExpiredStatus       int    // 404 or 410, the status expired links answer with
+	ExpiredMessage      string // Replaces the default text of the expired link page
+	RootMode            string // What / answers with: "not_found", "redirect" to RootRedirectURL or "status"
+	RootRedirectURL     string
+
+	PreviewCacheMaxAge time.Duration // How long CDNs and browsers may cache a preview page
 }
 
 // DatabaseConfig holds database-related configuration
@@ -36,132 +92,455 @@ type DatabaseConfig struct {
 	MaxConnections  int
 	MaxIdle         int
 	ConnMaxLifetime time.Duration
+
+	SlowQueryThreshold time.Duration // Queries taking longer are logged and counted; 0 disables
+
+	ConnectTimeout      time.Duration // How long startup keeps retrying an unreachable database; 0 tries once
+	ConnectRetryBackoff time.Duration // Wait before the first retry, doubled after each failed attempt
 }
 
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
 	MasterPassword string
 	TokenExpiry    time.Duration
+	TokenIssuer    string   // iss claim issued and required on tokens; empty skips the check
+	TokenAudience  string   // aud claim issued and required on tokens; empty skips the check
+	TrustedProxies []string // Proxies allowed to set X-Forwarded-For; empty trusts none
+	RedirectAccess string   // "public" redirects for anyone, "private" requires a bearer token
+
+	TrustForwardedHeader bool // Also read the client IP from RFC 7239 Forwarded headers sent by TrustedProxies
+
+	// ClientIPHeaders are consulted in order for the client IP of requests
+	// from TrustedProxies, e.g. CF-Connecting-IP; when set, requests without
+	// any of them use the peer address. Empty keeps X-Forwarded-For.
+	ClientIPHeaders []string
 }
 
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
-	Requests int
-	Window   time.Duration
+	Requests        int
+	Window          time.Duration
+	StandardHeaders bool // Also emit the IETF RateLimit and RateLimit-Policy headers
+	DryRun          bool // Log and count over-limit requests but let them through
+
+	// Requests whose BypassHeader carries BypassToken, e.g. from synthetic
+	// monitors, are never limited. An empty token disables the bypass.
+	BypassHeader string
+	BypassToken  string
 }
 
 // ShortLinkConfig holds URL shortener configuration
 type ShortLinkConfig struct {
 	DefaultExpiry time.Duration
+	CodeAttempts  int // Generated code variations tried before giving up
+
+	MinAliasLength int // Shortest custom alias accepted on create and update; 0 allows any length
+
+	CodeCase         string // Stored code case: "preserve" or "lower", which also resolves codes in any case
+	CodeDisplayGroup int    // Generated codes are shown split into groups of this many characters, e.g. abc-123; 0 shows them as stored
+
+	AllowedSchemes []string // Destination URL schemes accepted on create, lowercase
+	RequireHTTPS   bool     // Reject plain http destinations
+
+	ForwardQueryParams []string // Redirect request query params passed on to the destination; "*" passes all
+
+	ReachabilityCheck     string        // Destination probe on create: "off", "flag" or "reject"
+	ReachabilityTimeout   time.Duration // Upper bound for a single destination probe
+	ProbeAllowedAddresses []string      // Non-public addresses destination probes may reach, as CIDR prefixes or ip:port pairs
+
+	SelfLinks string   // Destinations on our own hosts: "reject" or "resolve" to the link's destination
+	OwnHosts  []string // Hosts serving our short links besides the BASE_URL host, lowercase
+
+	ExportMaxRows int // Most links a single admin export returns
+	MaxCodeLength int // Longer redirect codes are answered 404 without a lookup
+
+	TrailingSlash string // Codes with a trailing slash, e.g. /abc/: "redirect" to /abc or "strip" and resolve in place
+
+	HealthCheckConcurrency int // Destinations an admin health check probes at once
+
+	MaxLinksPerUser  int      // Active links a user may hold; 0 is unlimited
+	QuotaExemptUsers []string // User IDs, such as admins and paid accounts, not bound by MaxLinksPerUser
+
+	UniqueDestinations string // A user's second link to a destination: "off" creates it, "reuse" returns the first, "reject" refuses it
+}
+
+// PrivacyConfig holds settings for handling personal data
+type PrivacyConfig struct {
+	IPAnonymization string // How click IPs are stored: "none", "truncate" or "hash"
+	IPHashSalt      string // Salt mixed into hashed IPs
+}
+
+// AnalyticsConfig holds click analytics configuration
+type AnalyticsConfig struct {
+	ClickRetention         time.Duration // Raw clicks older than this are purged; 0 keeps them forever
+	ArchiveExpiredClicks   bool          // Roll purged clicks into per-link totals before deleting
+	ClickRetentionInterval time.Duration // How often the retention job runs
+	ClickRollupInterval    time.Duration // How often completed days are rolled up into daily stats; 0 disables
+	SystemStatsCacheTTL    time.Duration // How long admin system stats are cached
+	ClickDedupeWindow      time.Duration // Repeat clicks on a link from the same IP within this window are dropped; 0 keeps all
+	StatsTimezone          string        // IANA zone link stats bucket days in unless a request asks for another
+	ClickRateThreshold     int           // Clicks on one link within ClickRateWindow that raise an alert; 0 disables
+	ClickRateWindow        time.Duration // Sliding window click rates are measured over
+	ClickRateWebhookURL    string        // Alerts are POSTed here as JSON; empty only logs them
+	ClickBeaconURL         string        // Every recorded click is POSTed here as JSON; empty sends no beacons
+	ClickBeaconTimeout     time.Duration // Upper bound for delivering a single beacon
+	ClickBeaconFields      []string      // Fields of the beacon payload; empty sends the defaults
+	ClickSampleRate        int           // Store 1 in N clicks in detail for links without their own rate; totals stay exact
+	ClickCampaignParams    bool          // Store the utm_* params of the short link request with each click
+	MetricsFlushInterval   time.Duration // How often redirect counters are saved to the database, which also happens on shutdown; 0 keeps them in memory only
+	StatsShareTTL          time.Duration // Default and longest lifetime of a stats share token
+}
+
+// CacheConfig holds short link cache configuration
+type CacheConfig struct {
+	Enabled   bool   // Serve link lookups through the in-process cache
+	Namespace string // Prefix for every cache key, e.g. "prod:shortener:"
+
+	ListTTL time.Duration // How long link list pages are cached; 0 disables
+
+	WarmUpEnabled bool // Preload the most clicked links at startup
+	WarmUpLinks   int  // How many links the warm-up loads
 }
 
-// LoadConfig loads configuration from environment variables
+// FeaturesConfig switches optional behaviors on or off per deployment.
+// Features that shipped before the flag existed default to on; new features
+// default to off until an operator enables them.
+type FeaturesConfig struct {
+	PreviewPages bool // Serve the /:code/preview interstitial page
+	LinkURLs     bool // Add absolute short_url and stats_url fields to link responses
+}
+
+// DefaultFeatures returns the feature flags used when nothing is configured
+func DefaultFeatures() FeaturesConfig {
+	return FeaturesConfig{
+		PreviewPages: true,
+	}
+}
+
+// LoadConfig loads configuration from environment variables, falling back
+// to the KEY=VALUE file named by CONFIG_FILE for anything the environment
+// does not set
 func LoadConfig() (*Config, error) {
+	src, err := newSource()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 
 	// Server config
-	port, err := strconv.Atoi(getEnvOrDefault("PORT", "8081"))
+	// Bad durations are reported along with the validation errors rather
+	// than replaced, since a fallback could purge data or expire links early
+	var parseErrs []error
+	duration := func(key, fallback string) time.Duration {
+		value, err := parseDuration(src.getOrDefault(key, fallback))
+		if err != nil {
+			parseErrs = append(parseErrs, fmt.Errorf("invalid %s: %w", key, err))
+		}
+		return value
+	}
+
+	port, err := strconv.Atoi(src.getOrDefault("PORT", "8081"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid PORT: %w", err)
 	}
 
+	maxPageSize, err := strconv.Atoi(src.getOrDefault("MAX_PAGE_SIZE", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_PAGE_SIZE: %w", err)
+	}
+
+	maxHeaderBytes, err := strconv.Atoi(src.getOrDefault("MAX_HEADER_BYTES", "1048576"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_HEADER_BYTES: %w", err)
+	}
+
+	maxConcurrent, err := strconv.Atoi(src.getOrDefault("MAX_CONCURRENT_REQUESTS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_CONCURRENT_REQUESTS: %w", err)
+	}
+
+	maxQueryLength, err := strconv.Atoi(src.getOrDefault("MAX_QUERY_LENGTH", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_QUERY_LENGTH: %w", err)
+	}
+
 	cfg.Server = ServerConfig{
 		Port:         port,
-		BaseURL:      getEnvOrDefault("BASE_URL", fmt.Sprintf("http://localhost:%d", port)),
-		Environment:  getEnvOrDefault("ENVIRONMENT", "development"),
-		ReadTimeout:  parseDuration(getEnvOrDefault("READ_TIMEOUT", "30s")),
-		WriteTimeout: parseDuration(getEnvOrDefault("WRITE_TIMEOUT", "30s")),
-		IdleTimeout:  parseDuration(getEnvOrDefault("IDLE_TIMEOUT", "120s")),
+		BaseURL:      src.getOrDefault("BASE_URL", fmt.Sprintf("http://localhost:%d", port)),
+		Environment:  src.getOrDefault("ENVIRONMENT", "development"),
+		ReadTimeout:  duration("READ_TIMEOUT", "30s"),
+		WriteTimeout: duration("WRITE_TIMEOUT", "30s"),
+		IdleTimeout:  duration("IDLE_TIMEOUT", "120s"),
+		MaxPageSize:  maxPageSize,
+
+		ReadHeaderTimeout: duration("READ_HEADER_TIMEOUT", "10s"),
+		MaxHeaderBytes:    maxHeaderBytes,
+
+		MaxConcurrentRequests: maxConcurrent,
+
+		APIPrefix:        strings.TrimRight(src.getOrDefault("API_PREFIX", "/api"), "/"),
+		LegacyRootRoutes: parseBool(src.getOrDefault("LEGACY_ROOT_ROUTES", "true"), true),
+
+		MaxQueryLength: maxQueryLength,
+	}
+
+	// Logging config
+	sampleInitial, err := strconv.Atoi(src.getOrDefault("LOG_SAMPLE_INITIAL", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_SAMPLE_INITIAL: %w", err)
+	}
+
+	sampleThereafter, err := strconv.Atoi(src.getOrDefault("LOG_SAMPLE_THEREAFTER", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_SAMPLE_THEREAFTER: %w", err)
+	}
+
+	cfg.Logging = LoggingConfig{
+		SampleInitial:    sampleInitial,
+		SampleThereafter: sampleThereafter,
+
+		Destinations: src.getOrDefault("LOG_DESTINATIONS", "full"),
+	}
+
+	// Pages config
+	expiredStatus, err := strconv.Atoi(src.getOrDefault("EXPIRED_LINK_STATUS", "404"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPIRED_LINK_STATUS: %w", err)
+	}
+
+	cfg.Pages = PagesConfig{
+		DefaultLocale:       src.getOrDefault("DEFAULT_LOCALE", "en"),
+		BrandName:           src.getOrDefault("BRAND_NAME", "URL Shortener"),
+		NotFoundMode:        src.getOrDefault("NOT_FOUND_MODE", "page"),
+		NotFoundRedirectURL: src.get("NOT_FOUND_REDIRECT_URL"),
+		ExpiredStatus:       expiredStatus,
+		ExpiredMessage:      src.get("EXPIRED_LINK_MESSAGE"),
+		RootMode:            src.getOrDefault("ROOT_MODE", "not_found"),
+		RootRedirectURL:     src.get("ROOT_REDIRECT_URL"),
+
+		PreviewCacheMaxAge: duration("PREVIEW_CACHE_MAX_AGE", "5m"),
 	}
 
 	// Database config
-	dbPort, err := strconv.Atoi(getEnvOrDefault("POSTGRES_PORT", "5432"))
+	dbPort, err := strconv.Atoi(src.getOrDefault("POSTGRES_PORT", "5432"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid POSTGRES_PORT: %w", err)
 	}
 
-	maxConns, err := strconv.Atoi(getEnvOrDefault("POSTGRES_MAX_CONNECTIONS", "25"))
+	maxConns, err := strconv.Atoi(src.getOrDefault("POSTGRES_MAX_CONNECTIONS", "25"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid POSTGRES_MAX_CONNECTIONS: %w", err)
 	}
 
-	maxIdle, err := strconv.Atoi(getEnvOrDefault("POSTGRES_MAX_IDLE_CONNECTIONS", "5"))
+	maxIdle, err := strconv.Atoi(src.getOrDefault("POSTGRES_MAX_IDLE_CONNECTIONS", "5"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid POSTGRES_MAX_IDLE_CONNECTIONS: %w", err)
 	}
 
 	cfg.Database = DatabaseConfig{
-		Host:            getEnvOrDefault("POSTGRES_HOST", "localhost"),
+		Host:            src.getOrDefault("POSTGRES_HOST", "localhost"),
 		Port:            dbPort,
-		User:            getEnvOrDefault("POSTGRES_USER", "postgres"),
-		Password:        getEnv("POSTGRES_PASSWORD"),
-		Database:        getEnvOrDefault("POSTGRES_DB", "url_shortener"),
+		User:            src.getOrDefault("POSTGRES_USER", "postgres"),
+		Password:        src.get("POSTGRES_PASSWORD"),
+		Database:        src.getOrDefault("POSTGRES_DB", "url_shortener"),
 		MaxConnections:  maxConns,
 		MaxIdle:         maxIdle,
-		ConnMaxLifetime: parseDuration(getEnvOrDefault("POSTGRES_CONN_MAX_LIFETIME", "15m")),
+		ConnMaxLifetime: duration("POSTGRES_CONN_MAX_LIFETIME", "15m"),
+
+		SlowQueryThreshold: duration("POSTGRES_SLOW_QUERY_THRESHOLD", "500ms"),
+
+		ConnectTimeout:      duration("POSTGRES_CONNECT_TIMEOUT", "30s"),
+		ConnectRetryBackoff: duration("POSTGRES_CONNECT_RETRY_BACKOFF", "500ms"),
 	}
 
 	// Security config
 	cfg.Security = SecurityConfig{
-		MasterPassword: getEnv("MASTER_PASSWORD"),
-		TokenExpiry:    parseDuration(getEnvOrDefault("TOKEN_EXPIRY", "24h")),
+		MasterPassword: src.get("MASTER_PASSWORD"),
+		TokenExpiry:    duration("TOKEN_EXPIRY", "24h"),
+		TokenIssuer:    src.getOrDefault("JWT_ISSUER", "url-shortener"),
+		TokenAudience:  src.getOrDefault("JWT_AUDIENCE", "url-shortener-api"),
+		TrustedProxies: parseList(src.get("TRUSTED_PROXIES")),
+		RedirectAccess: src.getOrDefault("REDIRECT_ACCESS", "public"),
+
+		TrustForwardedHeader: parseBool(src.get("TRUST_FORWARDED_HEADER"), false),
+		ClientIPHeaders:      parseList(src.get("CLIENT_IP_HEADERS")),
 	}
 
 	// Rate limit config
-	requests, err := strconv.Atoi(getEnvOrDefault("RATE_LIMIT_REQUESTS", "60"))
+	requests, err := strconv.Atoi(src.getOrDefault("RATE_LIMIT_REQUESTS", "60"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid RATE_LIMIT_REQUESTS: %w", err)
 	}
 
 	cfg.RateLimit = RateLimitConfig{
-		Requests: requests,
-		Window:   parseDuration(getEnvOrDefault("RATE_LIMIT_WINDOW", "60s")),
+		Requests:        requests,
+		Window:          duration("RATE_LIMIT_WINDOW", "60s"),
+		StandardHeaders: parseBool(src.get("RATE_LIMIT_STANDARD_HEADERS"), true),
+		DryRun:          parseBool(src.get("RATE_LIMIT_DRY_RUN"), false),
+		BypassHeader:    src.getOrDefault("RATE_LIMIT_BYPASS_HEADER", "X-Internal-Token"),
+		BypassToken:     src.get("RATE_LIMIT_BYPASS_TOKEN"),
 	}
 
 	// Short link config
+	codeAttempts, err := strconv.Atoi(src.getOrDefault("SHORTLINK_CODE_ATTEMPTS", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_CODE_ATTEMPTS: %w", err)
+	}
+
+	exportMaxRows, err := strconv.Atoi(src.getOrDefault("SHORTLINK_EXPORT_MAX_ROWS", "100000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_EXPORT_MAX_ROWS: %w", err)
+	}
+
+	minAliasLength, err := strconv.Atoi(src.getOrDefault("SHORTLINK_MIN_ALIAS_LENGTH", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_MIN_ALIAS_LENGTH: %w", err)
+	}
+
+	codeDisplayGroup, err := strconv.Atoi(src.getOrDefault("SHORTLINK_CODE_DISPLAY_GROUP", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_CODE_DISPLAY_GROUP: %w", err)
+	}
+
+	maxCodeLength, err := strconv.Atoi(src.getOrDefault("SHORTLINK_MAX_CODE_LENGTH", "64"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_MAX_CODE_LENGTH: %w", err)
+	}
+
+	healthCheckConcurrency, err := strconv.Atoi(src.getOrDefault("SHORTLINK_HEALTH_CHECK_CONCURRENCY", "8"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_HEALTH_CHECK_CONCURRENCY: %w", err)
+	}
+
+	maxLinksPerUser, err := strconv.Atoi(src.getOrDefault("SHORTLINK_MAX_LINKS_PER_USER", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHORTLINK_MAX_LINKS_PER_USER: %w", err)
+	}
+
 	cfg.ShortLink = ShortLinkConfig{
-		DefaultExpiry: parseDuration(getEnvOrDefault("SHORTLINK_DEFAULT_EXPIRY", "30d")),
+		DefaultExpiry: duration("SHORTLINK_DEFAULT_EXPIRY", "720h"),
+		CodeAttempts:  codeAttempts,
+
+		MinAliasLength: minAliasLength,
+
+		CodeCase:         strings.ToLower(src.getOrDefault("SHORTLINK_CODE_CASE", "preserve")),
+		CodeDisplayGroup: codeDisplayGroup,
+
+		AllowedSchemes: parseList(strings.ToLower(src.getOrDefault("SHORTLINK_ALLOWED_SCHEMES", "http,https"))),
+		RequireHTTPS:   parseBool(src.get("SHORTLINK_REQUIRE_HTTPS"), false),
+
+		ForwardQueryParams: parseList(src.get("SHORTLINK_FORWARD_QUERY_PARAMS")),
+
+		ReachabilityCheck:     src.getOrDefault("SHORTLINK_REACHABILITY_CHECK", "off"),
+		ReachabilityTimeout:   duration("SHORTLINK_REACHABILITY_TIMEOUT", "3s"),
+		ProbeAllowedAddresses: parseList(src.get("SHORTLINK_PROBE_ALLOWED_ADDRESSES")),
+
+		SelfLinks: src.getOrDefault("SHORTLINK_SELF_LINKS", "reject"),
+		OwnHosts:  parseList(strings.ToLower(src.get("SHORTLINK_OWN_HOSTS"))),
+
+		ExportMaxRows: exportMaxRows,
+		MaxCodeLength: maxCodeLength,
+
+		TrailingSlash: src.getOrDefault("SHORTLINK_TRAILING_SLASH", "redirect"),
+
+		HealthCheckConcurrency: healthCheckConcurrency,
+
+		MaxLinksPerUser:  maxLinksPerUser,
+		QuotaExemptUsers: parseList(src.get("SHORTLINK_QUOTA_EXEMPT_USERS")),
+
+		UniqueDestinations: strings.ToLower(src.getOrDefault("SHORTLINK_UNIQUE_DESTINATIONS", "off")),
 	}
 
-	// Validate required configurations
-	if err := validateConfig(cfg); err != nil {
-		return nil, err
+	// Privacy config
+	cfg.Privacy = PrivacyConfig{
+		IPAnonymization: src.getOrDefault("CLICK_IP_ANONYMIZATION", "none"),
+		IPHashSalt:      src.get("CLICK_IP_HASH_SALT"),
 	}
 
-	return cfg, nil
-}
+	// Analytics config
+	clickRateThreshold, err := strconv.Atoi(src.getOrDefault("CLICK_RATE_THRESHOLD", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CLICK_RATE_THRESHOLD: %w", err)
+	}
 
-// parseDuration safely parses a duration string with a fallback
-func parseDuration(value string) time.Duration {
-	duration, err := time.ParseDuration(value)
+	clickSampleRate, err := strconv.Atoi(src.getOrDefault("CLICK_SAMPLE_RATE", "1"))
 	if err != nil {
-		// Log this in a real implementation
-		return 30 * time.Second // Default fallback
+		return nil, fmt.Errorf("invalid CLICK_SAMPLE_RATE: %w", err)
+	}
+
+	cfg.Analytics = AnalyticsConfig{
+		ClickRetention:         duration("CLICK_RETENTION", "0"),
+		ArchiveExpiredClicks:   parseBool(src.getOrDefault("CLICK_RETENTION_ARCHIVE", "true"), true),
+		ClickRetentionInterval: duration("CLICK_RETENTION_INTERVAL", "24h"),
+		ClickRollupInterval:    duration("CLICK_ROLLUP_INTERVAL", "1h"),
+		SystemStatsCacheTTL:    duration("SYSTEM_STATS_CACHE_TTL", "30s"),
+		ClickDedupeWindow:      duration("CLICK_DEDUPE_WINDOW", "0"),
+		StatsTimezone:          src.getOrDefault("STATS_TIMEZONE", "UTC"),
+		ClickRateThreshold:     clickRateThreshold,
+		ClickRateWindow:        duration("CLICK_RATE_WINDOW", "1m"),
+		ClickRateWebhookURL:    src.get("CLICK_RATE_WEBHOOK_URL"),
+		ClickBeaconURL:         src.get("CLICK_BEACON_URL"),
+		ClickBeaconTimeout:     duration("CLICK_BEACON_TIMEOUT", "2s"),
+		ClickBeaconFields:      parseList(strings.ToLower(src.get("CLICK_BEACON_FIELDS"))),
+		ClickSampleRate:        clickSampleRate,
+		ClickCampaignParams:    parseBool(src.get("CLICK_CAMPAIGN_PARAMS"), true),
+		MetricsFlushInterval:   duration("METRICS_FLUSH_INTERVAL", "0"),
+		StatsShareTTL:          duration("STATS_SHARE_TTL", "24h"),
 	}
-	return duration
-}
 
-// validateConfig ensures required fields are present
-func validateConfig(cfg *Config) error {
-	if cfg.Security.MasterPassword == "" {
-		return fmt.Errorf("MASTER_PASSWORD is required")
+	// Cache config
+	warmUpLinks, err := strconv.Atoi(src.getOrDefault("CACHE_WARMUP_LINKS", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CACHE_WARMUP_LINKS: %w", err)
 	}
 
-	return nil
+	cfg.Cache = CacheConfig{
+		Enabled:   parseBool(src.get("CACHE_ENABLED"), false),
+		Namespace: src.get("CACHE_NAMESPACE"),
+
+		ListTTL: duration("CACHE_LIST_TTL", "0"),
+
+		WarmUpEnabled: parseBool(src.get("CACHE_WARMUP_ENABLED"), false),
+		WarmUpLinks:   warmUpLinks,
+	}
+
+	// Feature flags
+	features := DefaultFeatures()
+	cfg.Features = FeaturesConfig{
+		PreviewPages: parseBool(src.get("FEATURE_PREVIEW_PAGES"), features.PreviewPages),
+		LinkURLs:     parseBool(src.get("FEATURE_LINK_URLS"), features.LinkURLs),
+	}
+
+	// Fail fast on values that would otherwise break at runtime
+	if err := cfg.validate(parseErrs); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
 }
 
-// getEnvOrDefault gets an environment variable or returns a default value
-func getEnvOrDefault(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// parseDuration parses a duration string such as 90s or 720h
+func parseDuration(value string) (time.Duration, error) {
+	return time.ParseDuration(strings.TrimSpace(value))
+}
+
+// parseBool safely parses a boolean string with a fallback
+func parseBool(value string, fallback bool) bool {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
 	}
-	return defaultValue
+	return parsed
 }
 
-// getEnv gets an environment variable or returns empty string
-func getEnv(key string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// parseList splits a comma-separated value into trimmed, non-empty items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return ""
+	return items
 }
@@ -96,6 +96,46 @@ var _ = Describe("Config", func() {
 				Expect(cfg.Server.Environment).To(Equal("development"))
 				Expect(cfg.Server.Port).To(Equal(8081)) // Default port
 			})
+
+			It("defaults links to expire after 30 days", func() {
+				cfg, err := config.LoadConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.ShortLink.DefaultExpiry).To(Equal(30 * 24 * time.Hour))
+			})
+
+			It("defaults the code generation attempts", func() {
+				cfg, err := config.LoadConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.ShortLink.CodeAttempts).To(Equal(5))
+			})
+
+			It("defaults the allowed URL schemes to HTTP and HTTPS", func() {
+				cfg, err := config.LoadConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.ShortLink.AllowedSchemes).To(Equal([]string{"http", "https"}))
+			})
+
+			It("uses the default feature flags", func() {
+				cfg, err := config.LoadConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.Features).To(Equal(config.DefaultFeatures()))
+				Expect(cfg.Features.PreviewPages).To(BeTrue())
+				Expect(cfg.Features.LinkURLs).To(BeFalse())
+			})
+		})
+
+		Context("with a feature switched off", func() {
+			BeforeEach(func() {
+				os.Clearenv()
+				os.Setenv("MASTER_PASSWORD", testMasterPassword)
+				os.Setenv("FEATURE_PREVIEW_PAGES", "false")
+			})
+
+			It("disables the feature", func() {
+				cfg, err := config.LoadConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.Features.PreviewPages).To(BeFalse())
+			})
 		})
 
 		Context("with invalid timeout format", func() {
@@ -108,10 +148,70 @@ var _ = Describe("Config", func() {
 				os.Setenv("MASTER_PASSWORD", testMasterPassword)
 			})
 
-			It("uses default values for invalid formats", func() {
+			It("returns an error naming the setting", func() {
+				_, err := config.LoadConfig()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid READ_TIMEOUT"))
+			})
+		})
+
+		Context("with trusted proxies configured", func() {
+			BeforeEach(func() {
+				os.Clearenv()
+				os.Setenv("MASTER_PASSWORD", testMasterPassword)
+				os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1,")
+			})
+
+			It("parses the comma-separated list", func() {
 				cfg, err := config.LoadConfig()
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cfg.Server.ReadTimeout).To(Equal(30 * time.Second)) // Default value
+				Expect(cfg.Security.TrustedProxies).To(Equal([]string{"10.0.0.0/8", "192.168.1.1"}))
+			})
+		})
+
+		Context("with an invalid code attempts count", func() {
+			BeforeEach(func() {
+				os.Clearenv()
+				os.Setenv("MASTER_PASSWORD", testMasterPassword)
+				os.Setenv("SHORTLINK_CODE_ATTEMPTS", "many")
+			})
+
+			It("returns an error", func() {
+				_, err := config.LoadConfig()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid SHORTLINK_CODE_ATTEMPTS"))
+			})
+		})
+
+		Context("with a click retention in days", func() {
+			BeforeEach(func() {
+				os.Clearenv()
+				os.Setenv("MASTER_PASSWORD", testMasterPassword)
+				os.Setenv("CLICK_RETENTION", "90d")
+				os.Setenv("PORT", "0")
+			})
+
+			It("fails instead of falling back to a short retention", func() {
+				cfg, err := config.LoadConfig()
+				Expect(cfg).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring(`invalid CLICK_RETENTION: time: unknown unit "d"`)))
+				// Reported together with the other problems
+				Expect(err).To(MatchError(ContainSubstring("PORT must be between 1 and 65535")))
+			})
+		})
+
+		Context("with an invalid click retention interval", func() {
+			BeforeEach(func() {
+				os.Clearenv()
+				os.Setenv("MASTER_PASSWORD", testMasterPassword)
+				os.Setenv("CLICK_RETENTION", "2160h")
+				os.Setenv("CLICK_RETENTION_INTERVAL", "daily")
+			})
+
+			It("returns an error", func() {
+				_, err := config.LoadConfig()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid CLICK_RETENTION_INTERVAL"))
 			})
 		})
 
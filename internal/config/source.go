@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sectionAliases maps settings whose variable names predate the section
+// naming to their section-prefixed names. Both spellings are accepted; the
+// prefixed one wins when both are set in the same place.
+var sectionAliases = map[string]string{
+	"PORT":                    "SERVER_PORT",
+	"BASE_URL":                "SERVER_BASE_URL",
+	"ENVIRONMENT":             "SERVER_ENVIRONMENT",
+	"READ_TIMEOUT":            "SERVER_READ_TIMEOUT",
+	"WRITE_TIMEOUT":           "SERVER_WRITE_TIMEOUT",
+	"IDLE_TIMEOUT":            "SERVER_IDLE_TIMEOUT",
+	"READ_HEADER_TIMEOUT":     "SERVER_READ_HEADER_TIMEOUT",
+	"MAX_HEADER_BYTES":        "SERVER_MAX_HEADER_BYTES",
+	"MAX_PAGE_SIZE":           "SERVER_MAX_PAGE_SIZE",
+	"MAX_CONCURRENT_REQUESTS": "SERVER_MAX_CONCURRENT_REQUESTS",
+	"API_PREFIX":              "SERVER_API_PREFIX",
+	"LEGACY_ROOT_ROUTES":      "SERVER_LEGACY_ROOT_ROUTES",
+	"MAX_QUERY_LENGTH":        "SERVER_MAX_QUERY_LENGTH",
+
+	"DEFAULT_LOCALE":         "PAGES_DEFAULT_LOCALE",
+	"BRAND_NAME":             "PAGES_BRAND_NAME",
+	"NOT_FOUND_MODE":         "PAGES_NOT_FOUND_MODE",
+	"NOT_FOUND_REDIRECT_URL": "PAGES_NOT_FOUND_REDIRECT_URL",
+	"EXPIRED_LINK_STATUS":    "PAGES_EXPIRED_LINK_STATUS",
+	"EXPIRED_LINK_MESSAGE":   "PAGES_EXPIRED_LINK_MESSAGE",
+	"ROOT_MODE":              "PAGES_ROOT_MODE",
+	"ROOT_REDIRECT_URL":      "PAGES_ROOT_REDIRECT_URL",
+	"PREVIEW_CACHE_MAX_AGE":  "PAGES_PREVIEW_CACHE_MAX_AGE",
+
+	"MASTER_PASSWORD": "SECURITY_MASTER_PASSWORD",
+	"TOKEN_EXPIRY":    "SECURITY_TOKEN_EXPIRY",
+	"JWT_ISSUER":      "SECURITY_JWT_ISSUER",
+	"JWT_AUDIENCE":    "SECURITY_JWT_AUDIENCE",
+	"TRUSTED_PROXIES": "SECURITY_TRUSTED_PROXIES",
+	"REDIRECT_ACCESS": "SECURITY_REDIRECT_ACCESS",
+
+	"TRUST_FORWARDED_HEADER": "SECURITY_TRUST_FORWARDED_HEADER",
+	"CLIENT_IP_HEADERS":      "SECURITY_CLIENT_IP_HEADERS",
+
+	"CLICK_IP_ANONYMIZATION": "PRIVACY_IP_ANONYMIZATION",
+	"CLICK_IP_HASH_SALT":     "PRIVACY_IP_HASH_SALT",
+
+	"CLICK_RETENTION":          "ANALYTICS_CLICK_RETENTION",
+	"CLICK_RETENTION_ARCHIVE":  "ANALYTICS_CLICK_RETENTION_ARCHIVE",
+	"CLICK_RETENTION_INTERVAL": "ANALYTICS_CLICK_RETENTION_INTERVAL",
+	"CLICK_ROLLUP_INTERVAL":    "ANALYTICS_CLICK_ROLLUP_INTERVAL",
+	"SYSTEM_STATS_CACHE_TTL":   "ANALYTICS_SYSTEM_STATS_CACHE_TTL",
+	"CLICK_DEDUPE_WINDOW":      "ANALYTICS_CLICK_DEDUPE_WINDOW",
+	"STATS_TIMEZONE":           "ANALYTICS_STATS_TIMEZONE",
+	"CLICK_RATE_THRESHOLD":     "ANALYTICS_CLICK_RATE_THRESHOLD",
+	"CLICK_RATE_WINDOW":        "ANALYTICS_CLICK_RATE_WINDOW",
+	"CLICK_RATE_WEBHOOK_URL":   "ANALYTICS_CLICK_RATE_WEBHOOK_URL",
+	"CLICK_BEACON_URL":         "ANALYTICS_CLICK_BEACON_URL",
+	"CLICK_BEACON_TIMEOUT":     "ANALYTICS_CLICK_BEACON_TIMEOUT",
+	"CLICK_BEACON_FIELDS":      "ANALYTICS_CLICK_BEACON_FIELDS",
+	"CLICK_SAMPLE_RATE":        "ANALYTICS_CLICK_SAMPLE_RATE",
+	"CLICK_CAMPAIGN_PARAMS":    "ANALYTICS_CLICK_CAMPAIGN_PARAMS",
+	"METRICS_FLUSH_INTERVAL":   "ANALYTICS_METRICS_FLUSH_INTERVAL",
+	"STATS_SHARE_TTL":          "ANALYTICS_STATS_SHARE_TTL",
+}
+
+// source resolves settings from the process environment first and the
+// optional CONFIG_FILE second, so operators can override any file value
+// with an env var
+type source struct {
+	file map[string]string
+}
+
+// newSource reads the file named by CONFIG_FILE, if any
+func newSource() (*source, error) {
+	src := &source{file: map[string]string{}}
+
+	path, ok := os.LookupEnv("CONFIG_FILE")
+	if !ok || path == "" {
+		return src, nil
+	}
+
+	values, err := readConfigFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CONFIG_FILE: %w", err)
+	}
+	src.file = values
+
+	return src, nil
+}
+
+// lookup returns the value for key, checking the section-prefixed alias
+// before the plain name, and the environment before the file
+func (s *source) lookup(key string) (string, bool) {
+	keys := []string{key}
+	if alias, ok := sectionAliases[key]; ok {
+		keys = []string{alias, key}
+	}
+
+	for _, k := range keys {
+		if value, exists := os.LookupEnv(k); exists {
+			return value, true
+		}
+	}
+	for _, k := range keys {
+		if value, exists := s.file[k]; exists {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// getOrDefault gets a setting or returns a default value
+func (s *source) getOrDefault(key, defaultValue string) string {
+	if value, exists := s.lookup(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// get gets a setting or returns empty string
+func (s *source) get(key string) string {
+	value, _ := s.lookup(key)
+	return value
+}
+
+// readConfigFile parses a file of KEY=VALUE lines in the same format as
+// .env.example. Blank lines and lines starting with # are skipped, and
+// values may be wrapped in single or double quotes.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+var _ = Describe("Config sources", func() {
+	var originalEnv []string
+
+	// writeConfigFile writes contents to a temporary file and points CONFIG_FILE at it
+	writeConfigFile := func(contents string) {
+		path := filepath.Join(GinkgoT().TempDir(), "shortener.env")
+		Expect(os.WriteFile(path, []byte(contents), 0o600)).To(Succeed())
+		os.Setenv("CONFIG_FILE", path)
+	}
+
+	BeforeEach(func() {
+		originalEnv = os.Environ()
+		os.Clearenv()
+	})
+
+	AfterEach(func() {
+		os.Clearenv()
+		for _, envVar := range originalEnv {
+			parts := splitEnvVar(envVar)
+			if len(parts) == 2 {
+				os.Setenv(parts[0], parts[1])
+			}
+		}
+	})
+
+	Context("with a config file", func() {
+		BeforeEach(func() {
+			writeConfigFile(`# Shortener settings
+SECURITY_MASTER_PASSWORD="` + testMasterPassword + `"
+SECURITY_TOKEN_EXPIRY=12h
+TRUSTED_PROXIES=10.0.0.1
+
+RATE_LIMIT_REQUESTS=10
+RATE_LIMIT_WINDOW=30s
+export RATE_LIMIT_DRY_RUN=false
+SERVER_BASE_URL=https://file.example.com
+`)
+		})
+
+		It("loads settings from the file", func() {
+			cfg, err := config.LoadConfig()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Security.MasterPassword).To(Equal(testMasterPassword))
+			Expect(cfg.Security.TokenExpiry).To(Equal(12 * time.Hour))
+			Expect(cfg.Security.TrustedProxies).To(Equal([]string{"10.0.0.1"}))
+			Expect(cfg.RateLimit.Requests).To(Equal(10))
+			Expect(cfg.RateLimit.Window).To(Equal(30 * time.Second))
+			Expect(cfg.RateLimit.DryRun).To(BeFalse())
+			Expect(cfg.Server.BaseURL).To(Equal("https://file.example.com"))
+		})
+
+		It("lets env vars override the nested RateLimit settings", func() {
+			os.Setenv("RATE_LIMIT_REQUESTS", "250")
+			os.Setenv("RATE_LIMIT_WINDOW", "2m")
+			os.Setenv("RATE_LIMIT_DRY_RUN", "true")
+
+			cfg, err := config.LoadConfig()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.RateLimit.Requests).To(Equal(250))
+			Expect(cfg.RateLimit.Window).To(Equal(2 * time.Minute))
+			Expect(cfg.RateLimit.DryRun).To(BeTrue())
+		})
+
+		It("lets env vars override the nested Security settings", func() {
+			os.Setenv("SECURITY_TOKEN_EXPIRY", "1h")
+			os.Setenv("MASTER_PASSWORD", "env_master_password")
+			os.Setenv("SECURITY_TRUSTED_PROXIES", "192.168.0.0/16")
+
+			cfg, err := config.LoadConfig()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Security.TokenExpiry).To(Equal(time.Hour))
+			Expect(cfg.Security.MasterPassword).To(Equal("env_master_password"))
+			Expect(cfg.Security.TrustedProxies).To(Equal([]string{"192.168.0.0/16"}))
+		})
+
+		It("lets a plain env var override a section-prefixed file setting", func() {
+			os.Setenv("BASE_URL", "https://env.example.com")
+
+			cfg, err := config.LoadConfig()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Server.BaseURL).To(Equal("https://env.example.com"))
+		})
+	})
+
+	Context("with section-prefixed env vars", func() {
+		It("prefers them over the plain names", func() {
+			os.Setenv("MASTER_PASSWORD", testMasterPassword)
+			os.Setenv("BASE_URL", "https://plain.example.com")
+			os.Setenv("SERVER_BASE_URL", "https://prefixed.example.com")
+			os.Setenv("SERVER_PORT", "9090")
+
+			cfg, err := config.LoadConfig()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Server.BaseURL).To(Equal("https://prefixed.example.com"))
+			Expect(cfg.Server.Port).To(Equal(9090))
+		})
+	})
+
+	Context("with an unusable config file", func() {
+		It("returns an error when the file does not exist", func() {
+			os.Setenv("CONFIG_FILE", filepath.Join(GinkgoT().TempDir(), "missing.env"))
+
+			_, err := config.LoadConfig()
+			Expect(err).To(MatchError(ContainSubstring("reading CONFIG_FILE")))
+		})
+
+		It("returns an error for a line without a value", func() {
+			writeConfigFile("MASTER_PASSWORD\n")
+
+			_, err := config.LoadConfig()
+			Expect(err).To(MatchError(ContainSubstring("expected KEY=VALUE")))
+		})
+	})
+})
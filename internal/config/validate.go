@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultMasterPasswords are well-known placeholder values that must not be
+// used in production
+var defaultMasterPasswords = []string{
+	"admin",
+	"changeme",
+	"dev_master_password",
+	"master",
+	"password",
+	"secret",
+}
+
+// clickBeaconFields are the fields a click beacon payload can carry
+var clickBeaconFields = []string{
+	"short_link_id", "clicked_at", "referrer", "user_agent", "ip_address", "browser", "os", "device", "query",
+}
+
+// minBypassTokenLength keeps the rate limit bypass token from being guessable
+const minBypassTokenLength = 16
+
+// Validate checks the configuration for values that would otherwise only
+// fail at runtime. All problems are reported together in one error.
+func (c *Config) Validate() error {
+	return c.validate(nil)
+}
+
+// validate checks the configuration, reporting errs, such as values that
+// failed to parse, along with the problems it finds
+func (c *Config) validate(errs []error) error {
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// Server
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "PORT must be between 1 and 65535, got %d", c.Server.Port)
+	if err := validateAbsoluteURL(c.Server.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("BASE_URL %w", err))
+	}
+	check(c.Server.ReadTimeout > 0, "READ_TIMEOUT must be positive")
+	check(c.Server.WriteTimeout > 0, "WRITE_TIMEOUT must be positive")
+	check(c.Server.IdleTimeout > 0, "IDLE_TIMEOUT must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "READ_HEADER_TIMEOUT must be positive")
+	check(c.Server.MaxHeaderBytes > 0, "MAX_HEADER_BYTES must be positive, got %d", c.Server.MaxHeaderBytes)
+	check(c.Server.MaxPageSize > 0, "MAX_PAGE_SIZE must be positive, got %d", c.Server.MaxPageSize)
+	check(c.Server.MaxConcurrentRequests >= 0, "MAX_CONCURRENT_REQUESTS must not be negative, got %d", c.Server.MaxConcurrentRequests)
+	check(c.Server.MaxQueryLength >= 0, "MAX_QUERY_LENGTH must not be negative, got %d", c.Server.MaxQueryLength)
+	check(strings.HasPrefix(c.Server.APIPrefix, "/") && len(c.Server.APIPrefix) > 1 && !strings.Contains(c.Server.APIPrefix[1:], "/"),
+		"API_PREFIX must be a single path segment such as /api, got %q", c.Server.APIPrefix)
+
+	// Database
+	check(c.Database.Host != "", "POSTGRES_HOST is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "POSTGRES_PORT must be between 1 and 65535, got %d", c.Database.Port)
+	check(c.Database.Database != "", "POSTGRES_DB is required")
+	check(c.Database.MaxConnections > 0, "POSTGRES_MAX_CONNECTIONS must be positive, got %d", c.Database.MaxConnections)
+	check(c.Database.MaxIdle >= 0, "POSTGRES_MAX_IDLE_CONNECTIONS must not be negative, got %d", c.Database.MaxIdle)
+	check(c.Database.SlowQueryThreshold >= 0, "POSTGRES_SLOW_QUERY_THRESHOLD must not be negative")
+	check(c.Database.ConnectTimeout >= 0, "POSTGRES_CONNECT_TIMEOUT must not be negative")
+	check(c.Database.ConnectTimeout == 0 || c.Database.ConnectRetryBackoff > 0,
+		"POSTGRES_CONNECT_RETRY_BACKOFF must be positive when POSTGRES_CONNECT_TIMEOUT is set")
+
+	// Security
+	check(c.Security.MasterPassword != "", "MASTER_PASSWORD is required")
+	if c.Server.Environment == "production" && c.Security.MasterPassword != "" {
+		check(!slices.Contains(defaultMasterPasswords, strings.ToLower(c.Security.MasterPassword)),
+			"MASTER_PASSWORD must be changed from its default value in production")
+	}
+	check(c.Security.TokenExpiry > 0, "TOKEN_EXPIRY must be positive")
+	check(c.Security.RedirectAccess == "public" || c.Security.RedirectAccess == "private",
+		"REDIRECT_ACCESS must be public or private, got %q", c.Security.RedirectAccess)
+	check(len(c.Security.ClientIPHeaders) == 0 || len(c.Security.TrustedProxies) > 0,
+		"CLIENT_IP_HEADERS are only read from TRUSTED_PROXIES, which is empty")
+
+	// Rate limiting
+	check(c.RateLimit.Requests > 0, "RATE_LIMIT_REQUESTS must be positive, got %d", c.RateLimit.Requests)
+	check(c.RateLimit.Window > 0, "RATE_LIMIT_WINDOW must be positive")
+	if c.RateLimit.BypassToken != "" {
+		check(c.RateLimit.BypassHeader != "", "RATE_LIMIT_BYPASS_HEADER is required when RATE_LIMIT_BYPASS_TOKEN is set")
+		check(len(c.RateLimit.BypassToken) >= minBypassTokenLength,
+			"RATE_LIMIT_BYPASS_TOKEN must be at least %d characters", minBypassTokenLength)
+	}
+
+	// Short links
+	check(c.ShortLink.DefaultExpiry >= 0, "SHORTLINK_DEFAULT_EXPIRY must not be negative")
+	check(c.ShortLink.CodeAttempts > 0, "SHORTLINK_CODE_ATTEMPTS must be positive, got %d", c.ShortLink.CodeAttempts)
+	check(c.ShortLink.MinAliasLength >= 0, "SHORTLINK_MIN_ALIAS_LENGTH must not be negative, got %d", c.ShortLink.MinAliasLength)
+	check(c.ShortLink.CodeCase == "preserve" || c.ShortLink.CodeCase == "lower",
+		"SHORTLINK_CODE_CASE must be preserve or lower, got %q", c.ShortLink.CodeCase)
+	check(c.ShortLink.CodeDisplayGroup >= 0,
+		"SHORTLINK_CODE_DISPLAY_GROUP must not be negative, got %d", c.ShortLink.CodeDisplayGroup)
+	check(len(c.ShortLink.AllowedSchemes) > 0, "SHORTLINK_ALLOWED_SCHEMES must list at least one scheme")
+	check(slices.Contains([]string{"off", "flag", "reject"}, c.ShortLink.ReachabilityCheck),
+		"SHORTLINK_REACHABILITY_CHECK must be off, flag or reject, got %q", c.ShortLink.ReachabilityCheck)
+	check(c.ShortLink.ReachabilityTimeout > 0, "SHORTLINK_REACHABILITY_TIMEOUT must be positive")
+	for _, entry := range c.ShortLink.ProbeAllowedAddresses {
+		_, prefixErr := netip.ParsePrefix(entry)
+		_, addrErr := netip.ParseAddrPort(entry)
+		check(prefixErr == nil || addrErr == nil,
+			"SHORTLINK_PROBE_ALLOWED_ADDRESSES must list CIDR prefixes or ip:port pairs, got %q", entry)
+	}
+	check(c.ShortLink.SelfLinks == "reject" || c.ShortLink.SelfLinks == "resolve",
+		"SHORTLINK_SELF_LINKS must be reject or resolve, got %q", c.ShortLink.SelfLinks)
+	check(c.ShortLink.ExportMaxRows > 0, "SHORTLINK_EXPORT_MAX_ROWS must be positive, got %d", c.ShortLink.ExportMaxRows)
+	check(c.ShortLink.MaxCodeLength > 0, "SHORTLINK_MAX_CODE_LENGTH must be positive, got %d", c.ShortLink.MaxCodeLength)
+	check(c.ShortLink.TrailingSlash == "redirect" || c.ShortLink.TrailingSlash == "strip",
+		"SHORTLINK_TRAILING_SLASH must be redirect or strip, got %q", c.ShortLink.TrailingSlash)
+	check(c.ShortLink.HealthCheckConcurrency > 0,
+		"SHORTLINK_HEALTH_CHECK_CONCURRENCY must be positive, got %d", c.ShortLink.HealthCheckConcurrency)
+	check(c.ShortLink.MaxLinksPerUser >= 0,
+		"SHORTLINK_MAX_LINKS_PER_USER must not be negative, got %d", c.ShortLink.MaxLinksPerUser)
+	check(slices.Contains([]string{"off", "reuse", "reject"}, c.ShortLink.UniqueDestinations),
+		"SHORTLINK_UNIQUE_DESTINATIONS must be off, reuse or reject, got %q", c.ShortLink.UniqueDestinations)
+
+	// Logging
+	check(c.Logging.SampleInitial >= 0, "LOG_SAMPLE_INITIAL must not be negative, got %d", c.Logging.SampleInitial)
+	check(c.Logging.SampleThereafter >= 0, "LOG_SAMPLE_THEREAFTER must not be negative, got %d", c.Logging.SampleThereafter)
+	check(slices.Contains([]string{"full", "hash", "omit"}, c.Logging.Destinations),
+		"LOG_DESTINATIONS must be full, hash or omit, got %q", c.Logging.Destinations)
+
+	// Pages
+	check(c.Pages.NotFoundMode == "page" || c.Pages.NotFoundMode == "redirect",
+		"NOT_FOUND_MODE must be page or redirect, got %q", c.Pages.NotFoundMode)
+	if c.Pages.NotFoundMode == "redirect" {
+		if err := validateAbsoluteURL(c.Pages.NotFoundRedirectURL); err != nil {
+			errs = append(errs, fmt.Errorf("NOT_FOUND_REDIRECT_URL %w", err))
+		}
+	}
+	check(c.Pages.ExpiredStatus == 404 || c.Pages.ExpiredStatus == 410,
+		"EXPIRED_LINK_STATUS must be 404 or 410, got %d", c.Pages.ExpiredStatus)
+	check(slices.Contains([]string{"not_found", "redirect", "status"}, c.Pages.RootMode),
+		"ROOT_MODE must be not_found, redirect or status, got %q", c.Pages.RootMode)
+	if c.Pages.RootMode == "redirect" {
+		if err := validateAbsoluteURL(c.Pages.RootRedirectURL); err != nil {
+			errs = append(errs, fmt.Errorf("ROOT_REDIRECT_URL %w", err))
+		}
+	}
+	check(c.Pages.PreviewCacheMaxAge >= 0, "PREVIEW_CACHE_MAX_AGE must not be negative")
+
+	// Privacy
+	check(slices.Contains([]string{"none", "truncate", "hash"}, c.Privacy.IPAnonymization),
+		"CLICK_IP_ANONYMIZATION must be none, truncate or hash, got %q", c.Privacy.IPAnonymization)
+
+	// Analytics
+	check(c.Analytics.ClickRetention >= 0, "CLICK_RETENTION must not be negative")
+	check(c.Analytics.ClickRetentionInterval >= 0, "CLICK_RETENTION_INTERVAL must not be negative")
+	check(c.Analytics.ClickRollupInterval >= 0, "CLICK_ROLLUP_INTERVAL must not be negative")
+	check(c.Analytics.MetricsFlushInterval >= 0, "METRICS_FLUSH_INTERVAL must not be negative")
+	check(c.Analytics.StatsShareTTL > 0, "STATS_SHARE_TTL must be positive")
+	check(c.Analytics.SystemStatsCacheTTL >= 0, "SYSTEM_STATS_CACHE_TTL must not be negative")
+	check(c.Analytics.ClickDedupeWindow >= 0, "CLICK_DEDUPE_WINDOW must not be negative")
+	check(c.Analytics.ClickRateThreshold >= 0,
+		"CLICK_RATE_THRESHOLD must not be negative, got %d", c.Analytics.ClickRateThreshold)
+	check(c.Analytics.ClickRateThreshold == 0 || c.Analytics.ClickRateWindow > 0,
+		"CLICK_RATE_WINDOW must be positive when CLICK_RATE_THRESHOLD is set")
+	if c.Analytics.ClickBeaconURL != "" {
+		if err := validateAbsoluteURL(c.Analytics.ClickBeaconURL); err != nil {
+			errs = append(errs, fmt.Errorf("CLICK_BEACON_URL %w", err))
+		}
+		check(c.Analytics.ClickBeaconTimeout > 0, "CLICK_BEACON_TIMEOUT must be positive")
+	}
+	for _, field := range c.Analytics.ClickBeaconFields {
+		check(slices.Contains(clickBeaconFields, field),
+			"CLICK_BEACON_FIELDS must only list %s, got %q", strings.Join(clickBeaconFields, ", "), field)
+	}
+	check(c.Analytics.ClickSampleRate >= 1,
+		"CLICK_SAMPLE_RATE must be at least 1, got %d", c.Analytics.ClickSampleRate)
+	if _, err := time.LoadLocation(c.Analytics.StatsTimezone); err != nil {
+		errs = append(errs, fmt.Errorf("STATS_TIMEZONE %q is not a known time zone", c.Analytics.StatsTimezone))
+	}
+
+	// Cache
+	check(c.Cache.ListTTL >= 0, "CACHE_LIST_TTL must not be negative")
+	check(c.Cache.WarmUpLinks >= 0, "CACHE_WARMUP_LINKS must not be negative, got %d", c.Cache.WarmUpLinks)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// validateAbsoluteURL checks that value is an http or https URL with a host
+func validateAbsoluteURL(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("is not a valid URL: %w", err)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL, got %q", value)
+	}
+
+	return nil
+}
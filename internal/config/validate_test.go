@@ -0,0 +1,236 @@
+package config_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/config"
+)
+
+var _ = Describe("Config.Validate", func() {
+	var (
+		originalEnv []string
+		cfg         *config.Config
+	)
+
+	BeforeEach(func() {
+		originalEnv = os.Environ()
+		os.Clearenv()
+		os.Setenv("MASTER_PASSWORD", testMasterPassword)
+
+		var err error
+		cfg, err = config.LoadConfig()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Clearenv()
+		for _, envVar := range originalEnv {
+			parts := splitEnvVar(envVar)
+			if len(parts) == 2 {
+				os.Setenv(parts[0], parts[1])
+			}
+		}
+	})
+
+	It("accepts a valid configuration", func() {
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("rejects a base URL that is not absolute", func() {
+		cfg.Server.BaseURL = "localhost:8081"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("BASE_URL must be an absolute http or https URL")))
+	})
+
+	It("rejects a short rate limit bypass token", func() {
+		cfg.RateLimit.BypassToken = "short"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("RATE_LIMIT_BYPASS_TOKEN must be at least 16 characters")))
+	})
+
+	It("rejects non-positive rate limits", func() {
+		cfg.RateLimit.Requests = -1
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("RATE_LIMIT_REQUESTS must be positive")))
+	})
+
+	It("rejects a default master password in production", func() {
+		cfg.Server.Environment = "production"
+		cfg.Security.MasterPassword = "changeme"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("MASTER_PASSWORD must be changed")))
+	})
+
+	It("allows a default master password outside production", func() {
+		cfg.Security.MasterPassword = "changeme"
+
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("rejects an unknown stats time zone", func() {
+		cfg.Analytics.StatsTimezone = "Mars/Olympus_Mons"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("STATS_TIMEZONE")))
+	})
+
+	It("requires a redirect URL in redirect not found mode", func() {
+		cfg.Pages.NotFoundMode = "redirect"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("NOT_FOUND_REDIRECT_URL is required")))
+	})
+
+	It("rejects an unknown redirect access mode", func() {
+		cfg.Security.RedirectAccess = "internal"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("REDIRECT_ACCESS must be public or private")))
+	})
+
+	It("rejects a negative minimum alias length", func() {
+		cfg.ShortLink.MinAliasLength = -1
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_MIN_ALIAS_LENGTH must not be negative")))
+	})
+
+	It("rejects an unknown self link mode", func() {
+		cfg.ShortLink.SelfLinks = "allow"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_SELF_LINKS must be reject or resolve")))
+	})
+
+	It("rejects an unknown trailing slash mode", func() {
+		cfg.ShortLink.TrailingSlash = "ignore"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_TRAILING_SLASH must be redirect or strip")))
+	})
+
+	It("rejects an unknown code case", func() {
+		cfg.ShortLink.CodeCase = "upper"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_CODE_CASE must be preserve or lower")))
+	})
+
+	It("rejects a negative code display group", func() {
+		cfg.ShortLink.CodeDisplayGroup = -3
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_CODE_DISPLAY_GROUP must not be negative")))
+	})
+
+	It("rejects a click beacon URL that isn't http or https", func() {
+		cfg.Analytics.ClickBeaconURL = "ftp://analytics.example.com/collect"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("CLICK_BEACON_URL must be an absolute http or https URL")))
+	})
+
+	It("rejects an unknown click beacon field", func() {
+		cfg.Analytics.ClickBeaconFields = []string{"referrer", "password"}
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring(`CLICK_BEACON_FIELDS must only list short_link_id, clicked_at, referrer, user_agent, ip_address, browser, os, device, query, got "password"`)))
+	})
+
+	It("rejects a probe allowed address that is neither a prefix nor ip:port", func() {
+		cfg.ShortLink.ProbeAllowedAddresses = []string{"10.1.0.0/16", "127.0.0.1:8080", "intranet.local"}
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring(`SHORTLINK_PROBE_ALLOWED_ADDRESSES must list CIDR prefixes or ip:port pairs, got "intranet.local"`)))
+	})
+
+	It("rejects a negative link quota", func() {
+		cfg.ShortLink.MaxLinksPerUser = -1
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_MAX_LINKS_PER_USER must not be negative")))
+	})
+
+	It("rejects an unknown unique destinations mode", func() {
+		cfg.ShortLink.UniqueDestinations = "warn"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHORTLINK_UNIQUE_DESTINATIONS must be off, reuse or reject")))
+	})
+
+	It("requires a redirect URL in redirect root mode", func() {
+		cfg.Pages.RootMode = "redirect"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("ROOT_REDIRECT_URL is required")))
+	})
+
+	It("rejects an unknown root mode", func() {
+		cfg.Pages.RootMode = "landing"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("ROOT_MODE must be not_found, redirect or status")))
+	})
+
+	It("rejects an expired link status other than 404 or 410", func() {
+		cfg.Pages.ExpiredStatus = 302
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("EXPIRED_LINK_STATUS must be 404 or 410")))
+	})
+
+	It("rejects negative log sampling settings", func() {
+		cfg.Logging.SampleInitial = -1
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("LOG_SAMPLE_INITIAL must not be negative")))
+	})
+
+	It("rejects an unknown destination logging mode", func() {
+		cfg.Logging.Destinations = "redact"
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("LOG_DESTINATIONS must be full, hash or omit")))
+	})
+
+	It("requires a click rate window when a threshold is set", func() {
+		cfg.Analytics.ClickRateThreshold = 100
+		cfg.Analytics.ClickRateWindow = 0
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("CLICK_RATE_WINDOW must be positive")))
+	})
+
+	It("requires trusted proxies for client IP headers", func() {
+		cfg.Security.ClientIPHeaders = []string{"CF-Connecting-IP"}
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("CLIENT_IP_HEADERS are only read from TRUSTED_PROXIES")))
+
+		cfg.Security.TrustedProxies = []string{"173.245.48.0/20"}
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("rejects a click sample rate below 1", func() {
+		cfg.Analytics.ClickSampleRate = 0
+
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("CLICK_SAMPLE_RATE must be at least 1")))
+	})
+
+	DescribeTable("rejects an API prefix that isn't a single path segment",
+		func(prefix string) {
+			cfg.Server.APIPrefix = prefix
+
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("API_PREFIX must be a single path segment")))
+		},
+		Entry("empty, as / is after trimming", ""),
+		Entry("no leading slash", "api"),
+		Entry("nested", "/api/v1"),
+	)
+
+	It("reports every problem at once", func() {
+		cfg.Security.MasterPassword = ""
+		cfg.Server.Port = 0
+		cfg.Server.ReadTimeout = 0
+		cfg.Privacy.IPAnonymization = "scramble"
+
+		err := cfg.Validate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("MASTER_PASSWORD is required"))
+		Expect(err.Error()).To(ContainSubstring("PORT must be between 1 and 65535"))
+		Expect(err.Error()).To(ContainSubstring("READ_TIMEOUT must be positive"))
+		Expect(err.Error()).To(ContainSubstring("CLICK_IP_ANONYMIZATION must be none, truncate or hash"))
+	})
+
+	It("fails LoadConfig with the aggregated error", func() {
+		os.Setenv("RATE_LIMIT_REQUESTS", "0")
+		os.Setenv("BASE_URL", "not a url")
+
+		_, err := config.LoadConfig()
+		Expect(err).To(MatchError(ContainSubstring("RATE_LIMIT_REQUESTS must be positive")))
+		Expect(err).To(MatchError(ContainSubstring("BASE_URL")))
+	})
+})
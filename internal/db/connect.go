@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Bounds for a single connection attempt and the wait between attempts
+const (
+	pingTimeout       = 5 * time.Second
+	maxConnectBackoff = 10 * time.Second
+)
+
+// WaitForConnection pings db until it answers, doubling the wait between
+// attempts from backoff up to maxConnectBackoff, so the app can start before
+// Postgres is ready. It gives up with the last error once timeout has
+// passed; a timeout of zero tries just once.
+func WaitForConnection(ctx context.Context, db *sql.DB, timeout, backoff time.Duration, logger *zap.Logger) error {
+	deadline := time.Now().Add(timeout)
+
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Connected to database", zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+
+		// The last wait is cut short so one more attempt lands on the deadline
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 || ctx.Err() != nil {
+			return fmt.Errorf("pinging database after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("Database not ready, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", wait),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pinging database after %d attempts: %w", attempt, err)
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
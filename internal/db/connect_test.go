@@ -0,0 +1,105 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/menezmethod/ref_go/internal/db"
+)
+
+// startingDB is a database/sql connector that refuses the first failures
+// connections, like a Postgres server that is still starting up
+type startingDB struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+}
+
+func (d *startingDB) Connect(ctx context.Context) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.attempts++
+	if d.attempts <= d.failures {
+		return nil, errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+	}
+	return &delayedConn{&delayedDB{}}, nil
+}
+
+func (d *startingDB) Driver() driver.Driver { return nil }
+
+var _ = Describe("WaitForConnection", func() {
+	var (
+		ctx    context.Context
+		logger *zap.Logger
+		logs   *observer.ObservedLogs
+	)
+
+	open := func(connector *startingDB) *sql.DB {
+		conn := sql.OpenDB(connector)
+		DeferCleanup(conn.Close)
+		return conn
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+		logger = zap.New(core)
+	})
+
+	It("should keep retrying until the database accepts connections", func() {
+		connector := &startingDB{failures: 3}
+
+		err := db.WaitForConnection(ctx, open(connector), time.Second, 5*time.Millisecond, logger)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(connector.attempts).To(Equal(4))
+
+		retries := logs.FilterMessage("Database not ready, retrying").All()
+		Expect(retries).To(HaveLen(3))
+		Expect(retries[0].ContextMap()).To(HaveKeyWithValue("attempt", int64(1)))
+		Expect(retries[1].ContextMap()).To(HaveKeyWithValue("retry_in", 10*time.Millisecond))
+		Expect(logs.FilterMessage("Connected to database").Len()).To(Equal(1))
+	})
+
+	It("should connect on the first attempt without logging", func() {
+		connector := &startingDB{}
+
+		Expect(db.WaitForConnection(ctx, open(connector), time.Second, 5*time.Millisecond, logger)).To(Succeed())
+
+		Expect(connector.attempts).To(Equal(1))
+		Expect(logs.Len()).To(BeZero())
+	})
+
+	It("should give up with the last error once the timeout has passed", func() {
+		connector := &startingDB{failures: 1000}
+
+		start := time.Now()
+		err := db.WaitForConnection(ctx, open(connector), 100*time.Millisecond, 10*time.Millisecond, logger)
+
+		Expect(err).To(MatchError(ContainSubstring("connection refused")))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(connector.attempts).To(BeNumerically(">", 1))
+	})
+
+	It("should try just once without a timeout", func() {
+		connector := &startingDB{failures: 1}
+
+		err := db.WaitForConnection(ctx, open(connector), 0, 10*time.Millisecond, logger)
+
+		Expect(err).To(MatchError(ContainSubstring("after 1 attempts")))
+		Expect(connector.attempts).To(Equal(1))
+	})
+})
@@ -4,9 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
+	"go.uber.org/zap"
 
 	"github.com/menezmethod/ref_go/internal/config"
 )
@@ -14,10 +14,13 @@ import (
 // DB represents a database connection
 type DB struct {
 	*sql.DB
+
+	slow *slowQueryLog
 }
 
-// New creates a new database connection
-func New(cfg *config.Config) (*DB, error) {
+// New creates a new database connection, waiting up to the configured
+// connect timeout for the server to accept connections
+func New(cfg *config.Config, logger *zap.Logger) (*DB, error) {
 	// Construct connection string
 	connStr := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
@@ -39,15 +42,20 @@ func New(cfg *config.Config) (*DB, error) {
 	db.SetMaxIdleConns(cfg.Database.MaxIdle)
 	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("pinging database: %w", err)
+	// Test connection, retrying while the server starts up
+	err = WaitForConnection(
+		context.Background(),
+		db,
+		cfg.Database.ConnectTimeout,
+		cfg.Database.ConnectRetryBackoff,
+		logger,
+	)
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db}, nil
 }
 
 // HealthCheck checks database connectivity
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SlowQueryRecorder counts queries that took longer than the slow query threshold
+type SlowQueryRecorder interface {
+	RecordSlowQuery(label string)
+}
+
+// slowQueryLog reports queries slower than threshold
+type slowQueryLog struct {
+	threshold time.Duration
+	logger    *zap.Logger
+	recorder  SlowQueryRecorder
+}
+
+// LogSlowQueries logs every query made through QueryContext, QueryRowContext
+// or ExecContext that takes longer than threshold and reports it to
+// recorder, which may be nil. Queries are labeled with the repository method
+// that ran them. A threshold of zero or less turns the log off. It must be
+// set up before the DB is shared.
+func (db *DB) LogSlowQueries(threshold time.Duration, logger *zap.Logger, recorder SlowQueryRecorder) {
+	if threshold <= 0 {
+		db.slow = nil
+		return
+	}
+
+	db.slow = &slowQueryLog{
+		threshold: threshold,
+		logger:    logger,
+		recorder:  recorder,
+	}
+}
+
+// QueryContext runs a query, timing it for the slow query log
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer db.observe(time.Now())
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning at most one row, timing it for the
+// slow query log
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer db.observe(time.Now())
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a statement, timing it for the slow query log
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer db.observe(time.Now())
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+// observe reports the query started at start if it was slow. It is
+// deferred by the query methods, so the caller two frames up is the
+// method that ran the query.
+func (db *DB) observe(start time.Time) {
+	if db.slow == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if elapsed <= db.slow.threshold {
+		return
+	}
+
+	label := queryLabel(2)
+	db.slow.logger.Warn("Slow query",
+		zap.String("query", label),
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", db.slow.threshold),
+	)
+	if db.slow.recorder != nil {
+		db.slow.recorder.RecordSlowQuery(label)
+	}
+}
+
+// queryLabel names the function skip frames above its caller without its
+// import path, e.g. "postgres.(*LinkClickRepository).CountAll"
+func queryLabel(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,165 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/menezmethod/ref_go/internal/db"
+)
+
+func TestDB(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DB Suite")
+}
+
+// delayedDB is a database/sql connector whose statements take delay to run
+type delayedDB struct {
+	delay time.Duration
+}
+
+func (d *delayedDB) Connect(ctx context.Context) (driver.Conn, error) { return &delayedConn{d}, nil }
+func (d *delayedDB) Driver() driver.Driver                            { return nil }
+
+type delayedConn struct{ d *delayedDB }
+
+func (c *delayedConn) Prepare(query string) (driver.Stmt, error) { return &delayedStmt{c.d}, nil }
+func (c *delayedConn) Close() error                              { return nil }
+func (c *delayedConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type delayedStmt struct{ d *delayedDB }
+
+func (s *delayedStmt) Close() error  { return nil }
+func (s *delayedStmt) NumInput() int { return -1 }
+
+func (s *delayedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	time.Sleep(s.d.delay)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *delayedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	time.Sleep(s.d.delay)
+	return &oneRow{}, nil
+}
+
+type oneRow struct{ done bool }
+
+func (r *oneRow) Columns() []string { return []string{"count"} }
+func (r *oneRow) Close() error      { return nil }
+
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// slowQueries collects the labels of reported slow queries
+type slowQueries struct {
+	mu     sync.Mutex
+	labels []string
+}
+
+func (s *slowQueries) RecordSlowQuery(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.labels = append(s.labels, label)
+}
+
+// countLinks stands in for a repository method
+func countLinks(ctx context.Context, database *db.DB) (int, error) {
+	var count int
+	err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM short_links").Scan(&count)
+	return count, err
+}
+
+// deleteLinks stands in for a repository method
+func deleteLinks(ctx context.Context, database *db.DB) error {
+	_, err := database.ExecContext(ctx, "DELETE FROM short_links")
+	return err
+}
+
+// listLinks stands in for a repository method
+func listLinks(ctx context.Context, database *db.DB) error {
+	rows, err := database.QueryContext(ctx, "SELECT id FROM short_links")
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+var _ = Describe("Slow query log", func() {
+	var (
+		ctx      context.Context
+		logs     *observer.ObservedLogs
+		recorder *slowQueries
+	)
+
+	newDB := func(delay, threshold time.Duration) *db.DB {
+		conn := sql.OpenDB(&delayedDB{delay: delay})
+		DeferCleanup(conn.Close)
+
+		var core zapcore.Core
+		core, logs = observer.New(zap.WarnLevel)
+
+		database := &db.DB{DB: conn}
+		database.LogSlowQueries(threshold, zap.New(core), recorder)
+		return database
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = &slowQueries{}
+	})
+
+	It("should log and count queries above the threshold with the calling method", func() {
+		database := newDB(30*time.Millisecond, 10*time.Millisecond)
+
+		count, err := countLinks(ctx, database)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+		Expect(deleteLinks(ctx, database)).To(Succeed())
+		Expect(listLinks(ctx, database)).To(Succeed())
+
+		Expect(recorder.labels).To(Equal([]string{"db_test.countLinks", "db_test.deleteLinks", "db_test.listLinks"}))
+
+		entries := logs.FilterMessage("Slow query").All()
+		Expect(entries).To(HaveLen(3))
+		fields := entries[0].ContextMap()
+		Expect(fields).To(HaveKeyWithValue("query", "db_test.countLinks"))
+		Expect(fields["duration"]).To(BeNumerically(">=", 30*time.Millisecond))
+	})
+
+	It("should not report queries under the threshold", func() {
+		database := newDB(0, time.Second)
+
+		_, err := countLinks(ctx, database)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleteLinks(ctx, database)).To(Succeed())
+
+		Expect(recorder.labels).To(BeEmpty())
+		Expect(logs.Len()).To(Equal(0))
+	})
+
+	It("should not report anything when the threshold is zero", func() {
+		database := newDB(30*time.Millisecond, 0)
+
+		Expect(deleteLinks(ctx, database)).To(Succeed())
+
+		Expect(recorder.labels).To(BeEmpty())
+		Expect(logs.Len()).To(Equal(0))
+	})
+})
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,8 +13,67 @@ var (
 	ErrConflict   = errors.New("resource already exists")
 	ErrForbidden  = errors.New("operation forbidden")
 	ErrValidation = errors.New("validation error")
+
+	// ErrCodeGenerationExhausted is returned when no free short code was found
+	// within the configured number of attempts
+	ErrCodeGenerationExhausted = errors.New("unable to generate a unique code")
+
+	// ErrQuotaExceeded is returned when a user already holds as many active
+	// links as they are allowed
+	ErrQuotaExceeded = errors.New("link quota exceeded")
 )
 
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every rejected field of a request so clients can
+// fix them all in one go. It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add records a rejected field
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns e when any field was rejected and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// QuotaError reports the link quota a create request ran into. It matches
+// ErrQuotaExceeded with errors.Is.
+type QuotaError struct {
+	Limit int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("link quota of %d active links reached", e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
 // URL represents a stored URL in the system
 type URL struct {
 	ID          string    `json:"id"`
@@ -33,10 +94,72 @@ type ShortLink struct {
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 
+	// LastAccessedAt is when the link was last followed, updated at most
+	// once a minute or so; nil until its first click
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+
+	// Reachable is set when the destination was probed at creation time
+	Reachable *bool `json:"reachable,omitempty"`
+
+	// Reused is set when a create request got this existing link back
+	// rather than a new one
+	Reused bool `json:"reused,omitempty"`
+
+	// IsPattern makes the code match as a path prefix: the rest of the
+	// request path is appended to the destination, so /docs/a/b leads to
+	// <destination>/a/b
+	IsPattern bool `json:"is_pattern,omitempty"`
+
+	// AllowedUsers restricts redirects to these authenticated user IDs;
+	// empty leaves the link open to everyone
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+
+	// CollectionID is the collection the link is filed in, if any
+	CollectionID *string `json:"collection_id,omitempty"`
+
+	// OwnerID is the user whose token created the link; nil for links
+	// created without one
+	OwnerID *string `json:"owner_id,omitempty"`
+
+	// ClickSampleRate stores only 1 in N clicks of the link in detail; nil
+	// uses the global rate. Totals stay exact either way.
+	ClickSampleRate *int `json:"click_sample_rate,omitempty"`
+
+	// TrackClicks set to false skips click recording on redirect entirely:
+	// no click rows, stats or last access time. Redirects are still counted
+	// by the per-link redirect metric. nil tracks clicks, see TracksClicks.
+	TrackClicks *bool `json:"track_clicks,omitempty"`
+
+	// Health is the result of the last destination health check; nil until
+	// one has run
+	Health *LinkHealth `json:"health,omitempty"`
+
+	// ClickCount totals recorded and archived clicks; only set on list results
+	ClickCount *int64 `json:"click_count,omitempty"`
+
 	// Embedded URL information when fetching a short link
 	URL *URL `json:"url,omitempty"`
 }
 
+// TracksClicks reports whether redirects through the link record clicks
+func (l *ShortLink) TracksClicks() bool {
+	return l.TrackClicks == nil || *l.TrackClicks
+}
+
+// Collection is a named folder grouping the short links of one owner
+type Collection struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CollectionRequest represents the request to create or rename a collection
+type CollectionRequest struct {
+	Name string `json:"name"`
+}
+
 // LinkClick represents a click on a shortened URL
 type LinkClick struct {
 	ID          string    `json:"id"`
@@ -50,25 +173,218 @@ type LinkClick struct {
 	Browser     *string   `json:"browser,omitempty"`
 	OS          *string   `json:"os,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// SampleWeight is how many clicks this one stands for when the link's
+	// clicks are sampled; 0 and 1 both mean just itself
+	SampleWeight int `json:"sample_weight,omitempty"`
+
+	// Campaign params found on the short link request itself
+	UTMSource   *string `json:"utm_source,omitempty"`
+	UTMMedium   *string `json:"utm_medium,omitempty"`
+	UTMCampaign *string `json:"utm_campaign,omitempty"`
+	UTMTerm     *string `json:"utm_term,omitempty"`
+	UTMContent  *string `json:"utm_content,omitempty"`
+}
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEntityShortLink identifies short links in the audit log
+const AuditEntityShortLink = "short_link"
+
+// AuditEntry records a change made to a short link
+type AuditEntry struct {
+	ID         string     `json:"id"`
+	EntityType string     `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	Action     string     `json:"action"`
+	UserID     *string    `json:"user_id,omitempty"`
+	Before     *ShortLink `json:"before,omitempty"`
+	After      *ShortLink `json:"after,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SystemStats represents aggregate statistics across all short links
+type SystemStats struct {
+	TotalLinks        int       `json:"total_links"`
+	TotalClicks       int64     `json:"total_clicks"`
+	LinksCreatedToday int       `json:"links_created_today"`
+	ActiveLinks       int       `json:"active_links"`
+	ExpiredLinks      int       `json:"expired_links"`
+	InactiveLinks     int       `json:"inactive_links"`
+	GeneratedAt       time.Time `json:"generated_at"`
+}
+
+// StatsShare is a signed token granting read-only access to one link's
+// stats until it expires
+type StatsShare struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Rollup dimensions for daily click aggregates
+const (
+	RollupDimensionTotal    = "total"
+	RollupDimensionReferrer = "referrer"
+	RollupDimensionBrowser  = "browser"
+	RollupDimensionOS       = "os"
+	RollupDimensionDevice   = "device"
+
+	RollupDimensionUTMSource   = "utm_source"
+	RollupDimensionUTMMedium   = "utm_medium"
+	RollupDimensionUTMCampaign = "utm_campaign"
+
+	RollupDimensionCountry = "country"
+	RollupDimensionCity    = "city"
+)
+
+// DailyClickRollup represents the clicks of one short link on one UTC day
+// for a single dimension value
+type DailyClickRollup struct {
+	ShortLinkID string    `json:"short_link_id"`
+	Day         time.Time `json:"day"`
+	Dimension   string    `json:"dimension"`
+	Value       string    `json:"value"`
+	Clicks      int       `json:"clicks"`
+}
+
+// LinkCursor marks a position in the newest-first link listing: the
+// created_at and ID of the last link already returned
+type LinkCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Link statuses a listing can be filtered by, evaluated at query time
+const (
+	LinkStatusActive   = "active"
+	LinkStatusExpired  = "expired"
+	LinkStatusInactive = "inactive"
+)
+
+// LinkFilter narrows a link listing; zero fields do not filter
+type LinkFilter struct {
+	CreatedAfter  *time.Time // Only links created after this instant
+	ExpiresBefore *time.Time // Only links expiring before this instant
+	Status        string     // LinkStatusActive, LinkStatusExpired or LinkStatusInactive
+	CollectionID  string     // Only links in this collection, which must belong to the requesting user
+}
+
+// IsZero reports whether the filter matches every link
+func (f LinkFilter) IsZero() bool {
+	return f.CreatedAfter == nil && f.ExpiresBefore == nil && f.Status == "" && f.CollectionID == ""
 }
 
 // CreateShortLinkRequest represents the request to create a short link
 type CreateShortLinkRequest struct {
-	URL            string     `json:"url"`
-	CustomAlias    *string    `json:"custom_alias,omitempty"`
-	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	URL             string     `json:"url"`
+	CustomAlias     *string    `json:"custom_alias,omitempty"`
+	ExpirationDate  *time.Time `json:"expiration_date,omitempty"`
+	IsPattern       bool       `json:"is_pattern,omitempty"`
+	AllowedUsers    []string   `json:"allowed_users,omitempty"`
+	CollectionID    *string    `json:"collection_id,omitempty"`
+	ClickSampleRate *int       `json:"click_sample_rate,omitempty"`
+	// TrackClicks false creates a link whose redirects record no clicks
+	TrackClicks *bool `json:"track_clicks,omitempty"`
+
+	// ReuseExisting returns the newest active link already shortening URL
+	// instead of creating another one; the other settings of the request
+	// are then ignored. It does not apply to custom aliases.
+	ReuseExisting bool `json:"reuse_existing,omitempty"`
+}
+
+// ImportShortLinkRequest describes a link migrated from another shortener
+// that keeps its original code
+type ImportShortLinkRequest struct {
+	Code           string
+	URL            string
+	ExpirationDate *time.Time
+	CustomAlias    *string
 }
 
 // LinkStats represents the stats for a short link
+//
+// When clicks are sampled, TotalClicks is still exact while the breakdowns
+// are scaled up from the stored sample. UniqueVisitors only counts the
+// visitors of stored clicks.
 type LinkStats struct {
-	TotalClicks  int            `json:"total_clicks"`
-	LastClicked  *time.Time     `json:"last_clicked,omitempty"`
-	TopReferrers map[string]int `json:"top_referrers,omitempty"`
-	TopBrowsers  map[string]int `json:"top_browsers,omitempty"`
-	TopOS        map[string]int `json:"top_os,omitempty"`
-	TopDevices   map[string]int `json:"top_devices,omitempty"`
-	ClicksByDay  map[string]int `json:"clicks_by_day,omitempty"`
-	RecentClicks []LinkClick    `json:"recent_clicks,omitempty"`
+	TotalClicks    int64          `json:"total_clicks"`
+	UniqueVisitors int64          `json:"unique_visitors"`
+	LastClicked    *time.Time     `json:"last_clicked,omitempty"`
+	TopReferrers   map[string]int `json:"top_referrers,omitempty"`
+	TopBrowsers    map[string]int `json:"top_browsers,omitempty"`
+	TopOS          map[string]int `json:"top_os,omitempty"`
+	TopDevices     map[string]int `json:"top_devices,omitempty"`
+	ClicksByDay    map[string]int `json:"clicks_by_day,omitempty"`
+	RecentClicks   []LinkClick    `json:"recent_clicks,omitempty"`
+
+	// Clicks grouped by the campaign params of the short link request
+	TopUTMSources   map[string]int `json:"top_utm_sources,omitempty"`
+	TopUTMMediums   map[string]int `json:"top_utm_mediums,omitempty"`
+	TopUTMCampaigns map[string]int `json:"top_utm_campaigns,omitempty"`
+
+	// Clicks grouped by where they came from, when it is known
+	TopCountries map[string]int `json:"top_countries,omitempty"`
+	TopCities    map[string]int `json:"top_cities,omitempty"`
+
+	// Timezone names the zone whose calendar days ClicksByDay uses
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// LinkStatsSummary holds the headline numbers of a link's statistics
+type LinkStatsSummary struct {
+	TotalClicks    int64      `json:"total_clicks"`
+	UniqueVisitors int64      `json:"unique_visitors"`
+	LastClicked    *time.Time `json:"last_clicked,omitempty"`
+}
+
+// LinkStatsBatchRequest names the links whose summaries are wanted at once
+type LinkStatsBatchRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// LinkStatsBatch holds the summaries of a batch keyed by code, along with
+// the requested codes that did not resolve to a link
+type LinkStatsBatch struct {
+	Stats    map[string]*LinkStatsSummary `json:"stats"`
+	NotFound []string                     `json:"not_found"`
+}
+
+// LinkHealth records the outcome of checking a link's destination.
+// StatusCode is the final response status, zero when no response came back,
+// and Error is empty when the destination answered with a non-error status.
+type LinkHealth struct {
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// LinkHealthCheckRequest names the links whose destinations should be
+// checked; no codes checks every link
+type LinkHealthCheckRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// LinkHealthResult is the health of one checked link
+type LinkHealthResult struct {
+	Code string `json:"code"`
+	URL  string `json:"url"`
+	LinkHealth
+}
+
+// LinkHealthReport holds the results of a health check run, the requested
+// codes that did not resolve to a link, and whether links beyond the cap on
+// a check of every link were left out
+type LinkHealthReport struct {
+	Results   []*LinkHealthResult `json:"results"`
+	Healthy   int                 `json:"healthy"`
+	Unhealthy int                 `json:"unhealthy"`
+	NotFound  []string            `json:"not_found"`
+	Truncated bool                `json:"truncated"`
 }
 
 // UpdateShortLinkRequest represents the request to update a short link
@@ -76,6 +392,16 @@ type UpdateShortLinkRequest struct {
 	CustomAlias    *string    `json:"custom_alias,omitempty"`
 	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
 	IsActive       *bool      `json:"is_active,omitempty"`
+	// AllowedUsers replaces the link's allowed users; an empty list opens it to everyone
+	AllowedUsers *[]string `json:"allowed_users,omitempty"`
+	// CollectionID files the link in one of the caller's collections; an
+	// empty ID takes it out of its collection
+	CollectionID *string `json:"collection_id,omitempty"`
+	// ClickSampleRate sets the link's 1 in N click sampling; 0 falls back
+	// to the global rate
+	ClickSampleRate *int `json:"click_sample_rate,omitempty"`
+	// TrackClicks turns click recording on redirect on or off
+	TrackClicks *bool `json:"track_clicks,omitempty"`
 }
 
 // Link represents a URL shortening link
@@ -84,7 +410,7 @@ type Link struct {
 	UserID      string    `json:"user_id"`
 	OriginalURL string    `json:"original_url"`
 	ShortURL    string    `json:"short_url"`
-	Visits      int       `json:"visits"`
+	Visits      int64     `json:"visits"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -108,3 +434,12 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// MetricCounter is the persisted total of one in-memory metric counter.
+// Label tells apart the series of a labelled counter, such as the link of
+// per-link redirects, and is empty otherwise.
+type MetricCounter struct {
+	Name  string
+	Label string
+	Value int64
+}
@@ -33,6 +33,15 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 
 	zapConfig.Level = zap.NewAtomicLevelAt(logLevel)
 
+	// Hot paths such as redirects would otherwise write an entry per request
+	zapConfig.Sampling = nil
+	if cfg.Logging.SampleInitial > 0 {
+		zapConfig.Sampling = &zap.SamplingConfig{
+			Initial:    cfg.Logging.SampleInitial,
+			Thereafter: cfg.Logging.SampleThereafter,
+		}
+	}
+
 	return zapConfig.Build()
 }
 
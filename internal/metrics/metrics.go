@@ -3,6 +3,7 @@ package metrics
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -43,6 +44,17 @@ type Metrics struct {
 	cacheHits       int64
 	cacheMisses     int64
 	cacheTotalItems int64
+
+	// Rate limit metrics
+	rateLimitWouldBlock int64
+
+	// Recovered panics
+	panicsByRoute   map[string]int64
+	panicsByRouteMu sync.RWMutex
+
+	// Slow database queries
+	slowQueriesByLabel   map[string]int64
+	slowQueriesByLabelMu sync.RWMutex
 }
 
 // NewMetrics creates a new metrics collector
@@ -53,6 +65,8 @@ func NewMetrics() *Metrics {
 		totalResponseTimeByPath: make(map[string]time.Duration),
 		requestCountByStatus:    make(map[int]int64),
 		redirectsByLink:         make(map[string]int64),
+		panicsByRoute:           make(map[string]int64),
+		slowQueriesByLabel:      make(map[string]int64),
 	}
 }
 
@@ -104,6 +118,18 @@ func (m *Metrics) RecordRedirect(linkID string) {
 	m.redirectsByLinkMu.Unlock()
 }
 
+// AddRedirects adds redirects counted elsewhere, e.g. the totals persisted
+// before a restart, to the redirect counters
+func (m *Metrics) AddRedirects(total int64, byLink map[string]int64) {
+	atomic.AddInt64(&m.totalRedirects, total)
+
+	m.redirectsByLinkMu.Lock()
+	for linkID, count := range byLink {
+		m.redirectsByLink[linkID] += count
+	}
+	m.redirectsByLinkMu.Unlock()
+}
+
 // SetShortLinkCount sets the current short link count
 func (m *Metrics) SetShortLinkCount(count int64) {
 	atomic.StoreInt64(&m.shortLinkCount, count)
@@ -231,6 +257,56 @@ func (m *Metrics) SetCacheTotalItems(count int64) {
 	atomic.StoreInt64(&m.cacheTotalItems, count)
 }
 
+// RecordRateLimitWouldBlock records a request the rate limiter let through in dry-run mode
+func (m *Metrics) RecordRateLimitWouldBlock() {
+	atomic.AddInt64(&m.rateLimitWouldBlock, 1)
+}
+
+// GetRateLimitWouldBlock returns the number of requests that would have been rate limited
+func (m *Metrics) GetRateLimitWouldBlock() int64 {
+	return atomic.LoadInt64(&m.rateLimitWouldBlock)
+}
+
+// RecordPanic records a panic recovered while serving route
+func (m *Metrics) RecordPanic(route string) {
+	m.panicsByRouteMu.Lock()
+	m.panicsByRoute[route]++
+	m.panicsByRouteMu.Unlock()
+}
+
+// GetPanicsByRoute returns recovered panic counts by route
+func (m *Metrics) GetPanicsByRoute() map[string]int64 {
+	m.panicsByRouteMu.RLock()
+	defer m.panicsByRouteMu.RUnlock()
+
+	result := make(map[string]int64, len(m.panicsByRoute))
+	for k, v := range m.panicsByRoute {
+		result[k] = v
+	}
+
+	return result
+}
+
+// RecordSlowQuery records a database query that exceeded the slow query threshold
+func (m *Metrics) RecordSlowQuery(label string) {
+	m.slowQueriesByLabelMu.Lock()
+	m.slowQueriesByLabel[label]++
+	m.slowQueriesByLabelMu.Unlock()
+}
+
+// GetSlowQueriesByLabel returns slow query counts by query label
+func (m *Metrics) GetSlowQueriesByLabel() map[string]int64 {
+	m.slowQueriesByLabelMu.RLock()
+	defer m.slowQueriesByLabelMu.RUnlock()
+
+	result := make(map[string]int64, len(m.slowQueriesByLabel))
+	for k, v := range m.slowQueriesByLabel {
+		result[k] = v
+	}
+
+	return result
+}
+
 // ServeHTTP implements the http.Handler interface for metrics
 func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Format metrics for Prometheus scraping or as JSON for manual review
@@ -254,11 +330,43 @@ func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		{"url_shortener_cache_hits_total", m.GetCacheHits(), "Total number of cache hits"},
 		{"url_shortener_cache_misses_total", m.GetCacheMisses(), "Total number of cache misses"},
 		{"url_shortener_cache_items_total", m.GetCacheTotalItems(), "Total number of items in cache"},
+		{"url_shortener_rate_limit_would_block_total", m.GetRateLimitWouldBlock(), "Total number of requests over the rate limit allowed by dry-run mode"},
 	}
 
 	for _, metric := range metrics {
 		w.Write([]byte(formatMetric(metric.name, metric.value, metric.help)))
 	}
+
+	w.Write([]byte(formatLabeledCounter(
+		"url_shortener_panics_total",
+		"route",
+		m.GetPanicsByRoute(),
+		"Total number of recovered panics by route",
+	)))
+
+	w.Write([]byte(formatLabeledCounter(
+		"url_shortener_slow_queries_total",
+		"query",
+		m.GetSlowQueriesByLabel(),
+		"Total number of database queries slower than the slow query threshold",
+	)))
+}
+
+// formatLabeledCounter formats a Prometheus-style counter with one label
+func formatLabeledCounter(name, label string, values map[string]int64, help string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := "# HELP " + name + " " + help + "\n" +
+		"# TYPE " + name + " counter\n"
+	for _, key := range keys {
+		out += fmt.Sprintf("%s{%s=%q} %d\n", name, label, key, values[key])
+	}
+
+	return out + "\n"
 }
 
 // formatMetric formats a Prometheus-style metric
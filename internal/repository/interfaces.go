@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/menezmethod/ref_go/internal/domain"
 )
@@ -16,6 +17,9 @@ type URLRepository interface {
 
 	// GetByHash retrieves a URL by hash
 	GetByHash(ctx context.Context, hash string) (*domain.URL, error)
+
+	// Update changes the destination of an existing URL
+	Update(ctx context.Context, url *domain.URL) error
 }
 
 // ShortLinkRepository defines operations for short links
@@ -38,14 +42,63 @@ type ShortLinkRepository interface {
 	// Update updates a short link
 	Update(ctx context.Context, link *domain.ShortLink) error
 
+	// UpdateHealth records the result of a destination health check
+	UpdateHealth(ctx context.Context, id string, health *domain.LinkHealth) error
+
+	// TouchLastAccessed records when a link was last followed
+	TouchLastAccessed(ctx context.Context, id string, at time.Time) error
+
 	// Delete deletes a short link
 	Delete(ctx context.Context, id string) error
 
+	// CountStats returns link totals as of now: all, created since the start of
+	// the UTC day, active, expired and deactivated
+	CountStats(ctx context.Context, now time.Time) (*domain.SystemStats, error)
+
+	// CountActiveByOwner counts the links of ownerID that are active as of now
+	CountActiveByOwner(ctx context.Context, ownerID string, now time.Time) (int, error)
+
+	// ListActiveByOwnerAndHost returns the links of ownerID that are active
+	// as of now and point at exactly host, newest first, with their URL
+	// data; an empty host matches destinations without one
+	ListActiveByOwnerAndHost(ctx context.Context, ownerID, host string, now time.Time) ([]*domain.ShortLink, error)
+
+	// ListByDestinationHost returns a page of the links pointing at host or
+	// one of its subdomains, newest first
+	ListByDestinationHost(ctx context.Context, host string, offset, limit int) ([]*domain.ShortLink, error)
+
+	// CountByDestinationHost returns the number of links pointing at host or
+	// one of its subdomains
+	CountByDestinationHost(ctx context.Context, host string) (int, error)
+
 	// List returns a paginated list of short links
 	List(ctx context.Context, offset, limit int) ([]*domain.ShortLink, error)
 
+	// StreamPage calls fn for each link List would return, as rows are
+	// read, stopping at the first error fn returns
+	StreamPage(ctx context.Context, offset, limit int, fn func(link *domain.ShortLink) error) error
+
+	// ListAfter returns up to limit links, newest first, that come after the
+	// cursor in (created_at, id) order; a nil cursor starts from the newest
+	ListAfter(ctx context.Context, cursor *domain.LinkCursor, limit int) ([]*domain.ShortLink, error)
+
 	// Count returns the total number of short links
 	Count(ctx context.Context) (int, error)
+
+	// ListFiltered returns a paginated list of the short links matching
+	// filter, with statuses evaluated at now
+	ListFiltered(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error)
+
+	// CountFiltered returns the number of short links matching filter at now
+	CountFiltered(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error)
+
+	// Stream calls fn for up to limit links, oldest first, with their URL data
+	// and click counts, stopping at the first error fn returns
+	Stream(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error
+
+	// ListMostClicked returns up to limit links that are active and unexpired
+	// at now, ordered by total clicks, with their URL data
+	ListMostClicked(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error)
 }
 
 // LinkClickRepository defines operations for link click analytics
@@ -53,9 +106,80 @@ type LinkClickRepository interface {
 	// Create records a new link click
 	Create(ctx context.Context, click *domain.LinkClick) error
 
+	// GetByID retrieves a single click, wrapping domain.ErrNotFound when it does not exist
+	GetByID(ctx context.Context, id string) (*domain.LinkClick, error)
+
 	// GetByShortLinkID retrieves all clicks for a short link
 	GetByShortLinkID(ctx context.Context, shortLinkID string, offset, limit int) ([]*domain.LinkClick, error)
 
-	// GetStatsByShortLinkID retrieves statistics for a short link
-	GetStatsByShortLinkID(ctx context.Context, shortLinkID string) (*domain.LinkStats, error)
+	// GetStatsByShortLinkID retrieves statistics for a short link, bucketing
+	// clicks by calendar day in loc; nil means UTC
+	GetStatsByShortLinkID(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error)
+
+	// GetStatsSummaries returns summary statistics keyed by short link ID
+	// for all of the given links in a single query
+	GetStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error)
+
+	// CountAll returns the number of clicks across all links, including
+	// archived and sampled out totals
+	CountAll(ctx context.Context) (int64, error)
+
+	// AddSampledOut adds clicks on a link that sampling left unstored to its total
+	AddSampledOut(ctx context.Context, shortLinkID string, clicks int) error
+
+	// DeleteOlderThan removes clicks created before the cutoff, optionally
+	// archiving their per-link totals first, and returns the number deleted
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, archive bool) (int64, error)
+
+	// StreamByCreatedRange calls fn for every click created in [start, end)
+	StreamByCreatedRange(ctx context.Context, start, end time.Time, fn func(click *domain.LinkClick) error) error
+
+	// GetEarliestCreatedAt returns the creation time of the oldest click, or nil when there are none
+	GetEarliestCreatedAt(ctx context.Context) (*time.Time, error)
+
+	// GetRollupWatermark returns the last fully rolled up day, or nil when nothing has been rolled up
+	GetRollupWatermark(ctx context.Context) (*time.Time, error)
+
+	// SaveDailyRollup replaces the aggregates for a day and advances the watermark to it
+	SaveDailyRollup(ctx context.Context, day time.Time, rollups []*domain.DailyClickRollup) error
+}
+
+// CollectionRepository defines operations for link collections. Reads and
+// writes are scoped to an owner: another owner's collection is not found.
+type CollectionRepository interface {
+	// Create stores a new collection, wrapping domain.ErrConflict when the
+	// owner already has one with the same name
+	Create(ctx context.Context, collection *domain.Collection) error
+
+	// GetByID retrieves a collection of owner, wrapping domain.ErrNotFound
+	// when it does not exist
+	GetByID(ctx context.Context, owner, id string) (*domain.Collection, error)
+
+	// ListByOwner returns the collections of owner ordered by name
+	ListByOwner(ctx context.Context, owner string) ([]*domain.Collection, error)
+
+	// Update renames a collection of its owner
+	Update(ctx context.Context, collection *domain.Collection) error
+
+	// Delete removes a collection of owner, leaving its links outside any collection
+	Delete(ctx context.Context, owner, id string) error
+}
+
+// AuditLogRepository defines operations for the audit log
+type AuditLogRepository interface {
+	// Create records a new audit entry
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+
+	// ListByEntity retrieves the entries for an entity, newest first
+	ListByEntity(ctx context.Context, entityType, entityID string, offset, limit int) ([]*domain.AuditEntry, error)
+}
+
+// MetricCounterRepository persists in-memory metric counters across restarts
+type MetricCounterRepository interface {
+	// List returns every persisted counter
+	List(ctx context.Context) ([]domain.MetricCounter, error)
+
+	// Add adds each counter's value to its persisted total, creating the
+	// counters not stored yet
+	Add(ctx context.Context, counters []domain.MetricCounter) error
 }
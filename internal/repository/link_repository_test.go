@@ -111,7 +111,7 @@ var _ = Describe("LinkRepository", func() {
 						*dest[1].(*string) = "user-id"
 						*dest[2].(*string) = "https://example.com"
 						*dest[3].(*string) = "abc123"
-						*dest[4].(*int) = 10
+						*dest[4].(*int64) = 10
 						// Timestamps
 						now := time.Now()
 						*dest[5].(*time.Time) = now
@@ -135,7 +135,7 @@ var _ = Describe("LinkRepository", func() {
 				Expect(link.UserID).To(Equal("user-id"))
 				Expect(link.OriginalURL).To(Equal("https://example.com"))
 				Expect(link.ShortURL).To(Equal("abc123"))
-				Expect(link.Visits).To(Equal(10))
+				Expect(link.Visits).To(Equal(int64(10)))
 			})
 		})
 
@@ -174,7 +174,7 @@ var _ = Describe("LinkRepository", func() {
 						*dest[1].(*string) = "user-id"
 						*dest[2].(*string) = "https://example.com"
 						*dest[3].(*string) = "abc123"
-						*dest[4].(*int) = 5
+						*dest[4].(*int64) = 5
 						// Timestamps
 						now := time.Now()
 						*dest[5].(*time.Time) = now
@@ -198,7 +198,7 @@ var _ = Describe("LinkRepository", func() {
 				Expect(link.UserID).To(Equal("user-id"))
 				Expect(link.OriginalURL).To(Equal("https://example.com"))
 				Expect(link.ShortURL).To(Equal("abc123"))
-				Expect(link.Visits).To(Equal(5))
+				Expect(link.Visits).To(Equal(int64(5)))
 			})
 		})
 
@@ -320,7 +320,7 @@ var _ = Describe("LinkRepository", func() {
 						*dest[1].(*string) = "user-id"
 						*dest[2].(*string) = "https://example.com"
 						*dest[3].(*string) = "abc123"
-						*dest[4].(*int) = 10
+						*dest[4].(*int64) = 10
 						// Timestamps
 						now := time.Now()
 						*dest[5].(*time.Time) = now
@@ -491,6 +491,103 @@ var _ = Describe("LinkRepository", func() {
 		})
 	})
 
+	Describe("RecordVisit", func() {
+		var (
+			recorder *txRecorder
+			click    *domain.Click
+		)
+
+		BeforeEach(func() {
+			recorder = &txRecorder{}
+			db := sql.OpenDB(recorder)
+			DeferCleanup(db.Close)
+
+			mockDB.BeginFunc = db.Begin
+			click = &domain.Click{
+				ID:        "click-id",
+				LinkID:    "link-id",
+				UserAgent: "Mozilla/5.0",
+				IPAddress: "192.168.1.1",
+			}
+		})
+
+		It("commits the visit increment and the click together", func() {
+			err := repo.RecordVisit(click)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.committed).To(HaveLen(2))
+			Expect(recorder.committed[0]).To(ContainSubstring("UPDATE links SET visits = visits + 1"))
+			Expect(recorder.committed[1]).To(ContainSubstring("INSERT INTO clicks"))
+			Expect(click.CreatedAt).NotTo(BeZero())
+		})
+
+		It("does not persist the visit increment when the click insert fails", func() {
+			recorder.failOn = "INSERT INTO clicks"
+
+			err := repo.RecordVisit(click)
+
+			Expect(err).To(MatchError("database error"))
+			Expect(recorder.committed).To(BeEmpty())
+			Expect(recorder.rolledBack).To(Equal(1))
+		})
+
+		It("does not insert the click when the visit increment fails", func() {
+			recorder.failOn = "UPDATE links"
+
+			err := repo.RecordVisit(click)
+
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.committed).To(BeEmpty())
+		})
+
+		It("returns the error when the transaction cannot start", func() {
+			mockDB.BeginFunc = func() (*sql.Tx, error) {
+				return nil, errors.New("connection refused")
+			}
+
+			Expect(repo.RecordVisit(click)).To(MatchError("connection refused"))
+		})
+	})
+
+	Describe("AddVisits", func() {
+		var recorder *txRecorder
+
+		BeforeEach(func() {
+			recorder = &txRecorder{}
+			db := sql.OpenDB(recorder)
+			DeferCleanup(db.Close)
+
+			mockDB.BeginFunc = db.Begin
+		})
+
+		It("commits one increment per link in a single transaction", func() {
+			err := repo.AddVisits(map[string]int64{"link-1": 5, "link-2": 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.committed).To(HaveLen(2))
+			Expect(recorder.committed).To(HaveEach(ContainSubstring("UPDATE links SET visits = visits + $2")))
+		})
+
+		It("stores nothing when one increment fails", func() {
+			recorder.failOn = "UPDATE links"
+
+			err := repo.AddVisits(map[string]int64{"link-1": 5})
+
+			Expect(err).To(MatchError("database error"))
+			Expect(recorder.committed).To(BeEmpty())
+			Expect(recorder.rolledBack).To(Equal(1))
+		})
+
+		It("does not open a transaction for an empty batch", func() {
+			mockDB.BeginFunc = func() (*sql.Tx, error) {
+				Fail("no transaction expected")
+				return nil, nil
+			}
+
+			Expect(repo.AddVisits(nil)).To(Succeed())
+		})
+	})
+
 	Describe("GetClicks", func() {
 		Context("when clicks exist", func() {
 			BeforeEach(func() {
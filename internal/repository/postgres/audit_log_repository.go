@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/menezmethod/ref_go/internal/db"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// AuditLogRepository implements the repository.AuditLogRepository interface
+type AuditLogRepository struct {
+	db *db.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *db.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+// Create records a new audit entry
+func (r *AuditLogRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (id, entity_type, entity_id, action, user_id, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	before, err := marshalSnapshot(entry.Before)
+	if err != nil {
+		return fmt.Errorf("encoding before snapshot: %w", err)
+	}
+
+	after, err := marshalSnapshot(entry.After)
+	if err != nil {
+		return fmt.Errorf("encoding after snapshot: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		entry.ID,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.UserID,
+		before,
+		after,
+		entry.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListByEntity retrieves the entries for an entity, newest first
+func (r *AuditLogRepository) ListByEntity(
+	ctx context.Context,
+	entityType,
+	entityID string,
+	offset,
+	limit int,
+) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT id, entity_type, entity_id, action, user_id, before, after, created_at
+		FROM audit_log
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var userID sql.NullString
+		var before, after []byte
+
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&userID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning audit entry row: %w", err)
+		}
+
+		if userID.Valid {
+			entry.UserID = &userID.String
+		}
+
+		if entry.Before, err = unmarshalSnapshot(before); err != nil {
+			return nil, fmt.Errorf("decoding before snapshot: %w", err)
+		}
+
+		if entry.After, err = unmarshalSnapshot(after); err != nil {
+			return nil, fmt.Errorf("decoding after snapshot: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// marshalSnapshot encodes a snapshot for a JSONB column, nil stays NULL.
+// The JSON is passed as text since lib/pq would send []byte as bytea.
+func marshalSnapshot(link *domain.ShortLink) (sql.NullString, error) {
+	if link == nil {
+		return sql.NullString{}, nil
+	}
+
+	raw, err := json.Marshal(link)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(raw), Valid: true}, nil
+}
+
+// unmarshalSnapshot decodes a JSONB column, NULL becomes nil
+func unmarshalSnapshot(raw []byte) (*domain.ShortLink, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var link domain.ShortLink
+	if err := json.Unmarshal(raw, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Click day bucketing", func() {
+	var (
+		newYork *time.Location
+		tokyo   *time.Location
+
+		// Two clicks an hour apart on either side of midnight UTC
+		beforeMidnight = time.Date(2024, 3, 14, 23, 30, 0, 0, time.UTC)
+		afterMidnight  = time.Date(2024, 3, 15, 0, 30, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		var err error
+		newYork, err = time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+		tokyo, err = time.LoadLocation("Asia/Tokyo")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	bucket := func(loc *time.Location, clicks ...time.Time) map[string]int {
+		days := map[string]int{}
+		for _, click := range clicks {
+			days[localDay(click, loc)]++
+		}
+		return days
+	}
+
+	It("should split clicks around midnight UTC into two UTC days", func() {
+		Expect(bucket(time.UTC, beforeMidnight, afterMidnight)).To(Equal(map[string]int{
+			"2024-03-14": 1,
+			"2024-03-15": 1,
+		}))
+	})
+
+	It("should put both clicks on the same evening in New York", func() {
+		Expect(bucket(newYork, beforeMidnight, afterMidnight)).To(Equal(map[string]int{
+			"2024-03-14": 2,
+		}))
+	})
+
+	It("should put both clicks on the next morning in Tokyo", func() {
+		Expect(bucket(tokyo, beforeMidnight, afterMidnight)).To(Equal(map[string]int{
+			"2024-03-15": 2,
+		}))
+	})
+
+	It("should split clicks around local midnight in the zone's own days", func() {
+		// 23:59 and 00:01 in New York are 03:59 and 04:01 UTC on the same UTC day
+		lateEvening := time.Date(2024, 3, 15, 3, 59, 0, 0, time.UTC)
+		earlyMorning := time.Date(2024, 3, 15, 4, 1, 0, 0, time.UTC)
+
+		Expect(bucket(newYork, lateEvening, earlyMorning)).To(Equal(map[string]int{
+			"2024-03-14": 1,
+			"2024-03-15": 1,
+		}))
+		Expect(bucket(time.UTC, lateEvening, earlyMorning)).To(Equal(map[string]int{
+			"2024-03-15": 2,
+		}))
+	})
+
+	It("should start the window at local midnight", func() {
+		start := localDayStart(afterMidnight, newYork, -30)
+
+		Expect(start.In(newYork).Format(time.RFC3339)).To(Equal("2024-02-13T00:00:00-05:00"))
+		Expect(localDayStart(afterMidnight, time.UTC, -30)).To(Equal(time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC)))
+	})
+})
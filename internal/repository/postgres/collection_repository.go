@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/menezmethod/ref_go/internal/db"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// collectionColumns lists the collections columns read by scanCollection
+const collectionColumns = `id, owner_id, name, created_at, updated_at`
+
+// scanCollection scans collectionColumns
+func scanCollection(row rowScanner) (*domain.Collection, error) {
+	var collection domain.Collection
+	if err := row.Scan(
+		&collection.ID,
+		&collection.OwnerID,
+		&collection.Name,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// CollectionRepository implements the repository.CollectionRepository interface
+type CollectionRepository struct {
+	db *db.DB
+}
+
+// NewCollectionRepository creates a new collection repository
+func NewCollectionRepository(db *db.DB) *CollectionRepository {
+	return &CollectionRepository{
+		db: db,
+	}
+}
+
+// Create stores a new collection
+func (r *CollectionRepository) Create(ctx context.Context, collection *domain.Collection) error {
+	query := `
+		INSERT INTO collections (id, owner_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		collection.ID,
+		collection.OwnerID,
+		collection.Name,
+		collection.CreatedAt,
+		collection.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("creating collection: %w", domain.ErrConflict)
+		}
+		return fmt.Errorf("creating collection: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a collection of owner by ID, wrapping
+// domain.ErrNotFound when it does not exist or belongs to someone else
+func (r *CollectionRepository) GetByID(ctx context.Context, owner, id string) (*domain.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections
+		WHERE id = $1 AND owner_id = $2
+	`
+
+	collection, err := scanCollection(r.db.QueryRowContext(ctx, query, id, owner))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("getting collection by id: %w", err)
+	}
+
+	return collection, nil
+}
+
+// ListByOwner returns the collections of owner ordered by name
+func (r *CollectionRepository) ListByOwner(ctx context.Context, owner string) ([]*domain.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections
+		WHERE owner_id = $1
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []*domain.Collection{}
+
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning collection row: %w", err)
+		}
+
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating collection rows: %w", err)
+	}
+
+	return collections, nil
+}
+
+// Update renames a collection of its owner
+func (r *CollectionRepository) Update(ctx context.Context, collection *domain.Collection) error {
+	query := `
+		UPDATE collections
+		SET name = $1, updated_at = $2
+		WHERE id = $3 AND owner_id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, collection.Name, collection.UpdatedAt, collection.ID, collection.OwnerID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("updating collection: %w", domain.ErrConflict)
+		}
+		return fmt.Errorf("updating collection: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Delete removes a collection of owner; its links stay, outside any collection
+func (r *CollectionRepository) Delete(ctx context.Context, owner, id string) error {
+	query := `
+		DELETE FROM collections
+		WHERE id = $1 AND owner_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, owner)
+	if err != nil {
+		return fmt.Errorf("deleting collection: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+	}
+
+	return nil
+}
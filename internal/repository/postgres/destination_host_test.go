@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// like evaluates a LIKE pattern with backslash escapes as Postgres does
+func like(pattern, s string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
+
+var _ = Describe("destinationHostCondition", func() {
+	// matches applies the condition for host to a destination host
+	matches := func(host, destination string) bool {
+		_, args := destinationHostCondition(host, nil)
+		reversed := reverseString(destination)
+		return reversed == args[0] || like(args[1].(string), reversed)
+	}
+
+	It("should compare reversed hosts after the bound args", func() {
+		where, args := destinationHostCondition("example.com", []interface{}{10, 0})
+
+		Expect(where).To(Equal("(reverse(u.host) = $3 OR reverse(u.host) LIKE $4)"))
+		Expect(args).To(Equal([]interface{}{10, 0, "moc.elpmaxe", "moc.elpmaxe.%"}))
+	})
+
+	DescribeTable("should match the exact host and its subdomains only",
+		func(destination string, expected bool) {
+			Expect(matches("example.com", destination)).To(Equal(expected))
+		},
+		Entry("the exact host", "example.com", true),
+		Entry("a subdomain", "www.example.com", true),
+		Entry("a nested subdomain", "a.b.example.com", true),
+		Entry("a host merely ending in the name", "notexample.com", false),
+		Entry("a host starting with it", "example.com.evil.net", false),
+		Entry("another TLD", "example.org", false),
+	)
+
+	It("should treat LIKE wildcards in the host literally", func() {
+		Expect(matches("my_site.com", "my_site.com")).To(BeTrue())
+		Expect(matches("my_site.com", "www.myXsite.com")).To(BeFalse())
+	})
+})
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/db"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// clickTable is a database/sql connector holding link_clicks rows by ID,
+// standing in for the primary key in click insert tests
+type clickTable struct {
+	rows map[string]bool
+}
+
+func (t *clickTable) Connect(ctx context.Context) (driver.Conn, error) { return &clickConn{t}, nil }
+func (t *clickTable) Driver() driver.Driver                            { return nil }
+
+type clickConn struct{ t *clickTable }
+
+func (c *clickConn) Prepare(query string) (driver.Stmt, error) { return &clickStmt{c.t, query}, nil }
+func (c *clickConn) Close() error                              { return nil }
+func (c *clickConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type clickStmt struct {
+	t     *clickTable
+	query string
+}
+
+func (s *clickStmt) Close() error  { return nil }
+func (s *clickStmt) NumInput() int { return -1 }
+
+// Exec inserts a click, failing like the primary key on a duplicate ID
+// unless the statement asks to skip conflicts
+func (s *clickStmt) Exec(args []driver.Value) (driver.Result, error) {
+	id, _ := args[0].(string)
+	if s.t.rows[id] {
+		if strings.Contains(s.query, "ON CONFLICT (id) DO NOTHING") {
+			return driver.RowsAffected(0), nil
+		}
+		return nil, &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint \"link_clicks_pkey\""}
+	}
+	s.t.rows[id] = true
+	return driver.RowsAffected(1), nil
+}
+
+// Query answers the CountAll query
+func (s *clickStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &countRows{count: int64(len(s.t.rows))}, nil
+}
+
+type countRows struct {
+	count int64
+	done  bool
+}
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.count
+	return nil
+}
+
+var _ = Describe("LinkClickRepository.Create", func() {
+	var (
+		repo  *LinkClickRepository
+		ctx   context.Context
+		click *domain.LinkClick
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		conn := sql.OpenDB(&clickTable{rows: map[string]bool{}})
+		DeferCleanup(conn.Close)
+		repo = NewLinkClickRepository(&db.DB{DB: conn})
+
+		click = &domain.LinkClick{
+			ID:          "8f14e45f-ceea-467f-a0e6-4b8e1e9e7a10",
+			ShortLinkID: "c9f0f895-fb98-4b91-9f53-1a2d3e4f5a6b",
+			CreatedAt:   time.Now().UTC(),
+		}
+	})
+
+	It("should store a click written twice only once", func() {
+		Expect(repo.Create(ctx, click)).To(Succeed())
+		Expect(repo.Create(ctx, click)).To(Succeed())
+
+		count, err := repo.CountAll(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(1)))
+	})
+
+	It("should store clicks with different IDs separately", func() {
+		Expect(repo.Create(ctx, click)).To(Succeed())
+
+		other := *click
+		other.ID = "45c48cce-2e2d-4fbd-aa1a-fc51b1d6b0c2"
+		Expect(repo.Create(ctx, &other)).To(Succeed())
+
+		count, err := repo.CountAll(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(2)))
+	})
+})
@@ -2,13 +2,50 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/menezmethod/ref_go/internal/db"
 	"github.com/menezmethod/ref_go/internal/domain"
 )
 
+// linkClickColumns lists the link_clicks columns read by scanLinkClick
+const linkClickColumns = `id, short_link_id, referrer, user_agent, ip_address,
+               country, city, device, browser, os, created_at, sample_weight,
+               utm_source, utm_medium, utm_campaign, utm_term, utm_content`
+
+// scanLinkClick scans linkClickColumns
+func scanLinkClick(row rowScanner) (*domain.LinkClick, error) {
+	var click domain.LinkClick
+	err := row.Scan(
+		&click.ID,
+		&click.ShortLinkID,
+		&click.Referrer,
+		&click.UserAgent,
+		&click.IPAddress,
+		&click.Country,
+		&click.City,
+		&click.Device,
+		&click.Browser,
+		&click.OS,
+		&click.CreatedAt,
+		&click.SampleWeight,
+		&click.UTMSource,
+		&click.UTMMedium,
+		&click.UTMCampaign,
+		&click.UTMTerm,
+		&click.UTMContent,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &click, nil
+}
+
 // LinkClickRepository implements the repository.LinkClickRepository interface
 type LinkClickRepository struct {
 	db *db.DB
@@ -21,14 +58,17 @@ func NewLinkClickRepository(db *db.DB) *LinkClickRepository {
 	}
 }
 
-// Create records a new link click
+// Create records a new link click. Click IDs are assigned once per click,
+// so writing the same click again, e.g. on a retry, is a no-op.
 func (r *LinkClickRepository) Create(ctx context.Context, click *domain.LinkClick) error {
 	query := `
 		INSERT INTO link_clicks (
 			id, short_link_id, referrer, user_agent, ip_address, 
-			country, city, device, browser, os, created_at
+			country, city, device, browser, os, created_at, sample_weight,
+			utm_source, utm_medium, utm_campaign, utm_term, utm_content
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (id) DO NOTHING
 	`
 
 	_, err := r.db.ExecContext(
@@ -45,6 +85,12 @@ func (r *LinkClickRepository) Create(ctx context.Context, click *domain.LinkClic
 		click.Browser,
 		click.OS,
 		click.CreatedAt,
+		max(click.SampleWeight, 1),
+		click.UTMSource,
+		click.UTMMedium,
+		click.UTMCampaign,
+		click.UTMTerm,
+		click.UTMContent,
 	)
 
 	if err != nil {
@@ -54,6 +100,25 @@ func (r *LinkClickRepository) Create(ctx context.Context, click *domain.LinkClic
 	return nil
 }
 
+// GetByID retrieves a single link click
+func (r *LinkClickRepository) GetByID(ctx context.Context, id string) (*domain.LinkClick, error) {
+	query := `
+		SELECT ` + linkClickColumns + `
+		FROM link_clicks
+		WHERE id = $1
+	`
+
+	click, err := scanLinkClick(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("link click not found: %w", domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("getting link click by id: %w", err)
+	}
+
+	return click, nil
+}
+
 // GetByShortLinkID retrieves all clicks for a short link
 func (r *LinkClickRepository) GetByShortLinkID(
 	ctx context.Context,
@@ -62,8 +127,7 @@ func (r *LinkClickRepository) GetByShortLinkID(
 	limit int,
 ) ([]*domain.LinkClick, error) {
 	query := `
-		SELECT id, short_link_id, referrer, user_agent, ip_address, 
-               country, city, device, browser, os, created_at
+		SELECT ` + linkClickColumns + `
 		FROM link_clicks
 		WHERE short_link_id = $1
 		ORDER BY created_at DESC
@@ -79,27 +143,12 @@ func (r *LinkClickRepository) GetByShortLinkID(
 	var clicks []*domain.LinkClick
 
 	for rows.Next() {
-		var click domain.LinkClick
-
-		err := rows.Scan(
-			&click.ID,
-			&click.ShortLinkID,
-			&click.Referrer,
-			&click.UserAgent,
-			&click.IPAddress,
-			&click.Country,
-			&click.City,
-			&click.Device,
-			&click.Browser,
-			&click.OS,
-			&click.CreatedAt,
-		)
-
+		click, err := scanLinkClick(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scanning link click row: %w", err)
 		}
 
-		clicks = append(clicks, &click)
+		clicks = append(clicks, click)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -109,162 +158,257 @@ func (r *LinkClickRepository) GetByShortLinkID(
 	return clicks, nil
 }
 
-// GetStatsByShortLinkID retrieves statistics for a short link
-func (r *LinkClickRepository) GetStatsByShortLinkID(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
-	// Get total clicks
+// GetStatsByShortLinkID retrieves statistics for a short link, bucketing
+// clicks by calendar day in loc; nil means UTC
+func (r *LinkClickRepository) GetStatsByShortLinkID(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// Get total clicks and unique visitors (IPs may be stored anonymized),
+	// plus the totals of clicks already removed by the retention job or
+	// never stored because of sampling
 	countQuery := `
-		SELECT COUNT(*)
+		SELECT COUNT(*), COUNT(DISTINCT ip_address),
+		       COALESCE((SELECT archived_clicks + sampled_out_clicks FROM link_click_summaries WHERE short_link_id = $1), 0)
 		FROM link_clicks
 		WHERE short_link_id = $1
 	`
 
-	var totalClicks int
-	err := r.db.QueryRowContext(ctx, countQuery, shortLinkID).Scan(&totalClicks)
+	var totalClicks, uniqueVisitors, unstoredClicks int64
+	err := r.db.QueryRowContext(ctx, countQuery, shortLinkID).Scan(&totalClicks, &uniqueVisitors, &unstoredClicks)
 	if err != nil {
 		return nil, fmt.Errorf("counting link clicks: %w", err)
 	}
 
-	// If no clicks, return empty stats
-	if totalClicks == 0 {
-		return &domain.LinkStats{
-			TotalClicks:  0,
-			TopReferrers: make(map[string]int),
-			TopBrowsers:  make(map[string]int),
-			TopOS:        make(map[string]int),
-			TopDevices:   make(map[string]int),
-			ClicksByDay:  make(map[string]int),
-		}, nil
+	// Get last clicked time. Once retention has purged every raw click the
+	// rollup below still holds the link's history, just not this.
+	var lastClicked *time.Time
+	if totalClicks > 0 {
+		lastClickedQuery := `
+			SELECT created_at
+			FROM link_clicks
+			WHERE short_link_id = $1
+			ORDER BY created_at DESC
+			LIMIT 1
+		`
+
+		var clickedAt time.Time
+		err = r.db.QueryRowContext(ctx, lastClickedQuery, shortLinkID).Scan(&clickedAt)
+		if err != nil {
+			return nil, fmt.Errorf("getting last clicked time: %w", err)
+		}
+		lastClicked = &clickedAt
 	}
 
-	// Get last clicked time
-	lastClickedQuery := `
-		SELECT created_at
-		FROM link_clicks
-		WHERE short_link_id = $1
-		ORDER BY created_at DESC
-		LIMIT 1
-	`
-
-	var lastClicked time.Time
-	err = r.db.QueryRowContext(ctx, lastClickedQuery, shortLinkID).Scan(&lastClicked)
+	// Completed days are read from the daily rollup; raw clicks are only
+	// scanned for the days after the rollup watermark (normally just today)
+	rolledThrough, err := r.GetRollupWatermark(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("getting last clicked time: %w", err)
+		return nil, err
 	}
 
-	// Get top referrers
-	topReferrersQuery := `
-		SELECT referrer, COUNT(*) as count
-		FROM link_clicks
-		WHERE short_link_id = $1 AND referrer IS NOT NULL
-		GROUP BY referrer
-		ORDER BY count DESC
-		LIMIT 5
-	`
+	var rollupUntil, rawSince time.Time
+	if rolledThrough != nil {
+		rollupUntil = *rolledThrough
+		rawSince = rolledThrough.AddDate(0, 0, 1)
+	}
 
-	referrerRows, err := r.db.QueryContext(ctx, topReferrersQuery, shortLinkID)
+	topReferrers, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionReferrer, "referrer", rollupUntil, rawSince)
 	if err != nil {
 		return nil, fmt.Errorf("getting top referrers: %w", err)
 	}
-	defer referrerRows.Close()
 
-	topReferrers := make(map[string]int)
-	for referrerRows.Next() {
-		var referrer string
-		var count int
-		if err := referrerRows.Scan(&referrer, &count); err != nil {
-			return nil, fmt.Errorf("scanning referrer row: %w", err)
-		}
-		topReferrers[referrer] = count
+	topBrowsers, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionBrowser, "browser", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top browsers: %w", err)
 	}
 
-	// Get top browsers
-	topBrowsersQuery := `
-		SELECT browser, COUNT(*) as count
-		FROM link_clicks
-		WHERE short_link_id = $1 AND browser IS NOT NULL
-		GROUP BY browser
-		ORDER BY count DESC
-		LIMIT 5
-	`
+	topOS, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionOS, "os", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top operating systems: %w", err)
+	}
 
-	browserRows, err := r.db.QueryContext(ctx, topBrowsersQuery, shortLinkID)
+	topDevices, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionDevice, "device", rollupUntil, rawSince)
 	if err != nil {
-		return nil, fmt.Errorf("getting top browsers: %w", err)
+		return nil, fmt.Errorf("getting top devices: %w", err)
 	}
-	defer browserRows.Close()
 
-	topBrowsers := make(map[string]int)
-	for browserRows.Next() {
-		var browser string
-		var count int
-		if err := browserRows.Scan(&browser, &count); err != nil {
-			return nil, fmt.Errorf("scanning browser row: %w", err)
+	topUTMSources, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionUTMSource, "utm_source", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top utm sources: %w", err)
+	}
+
+	topUTMMediums, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionUTMMedium, "utm_medium", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top utm mediums: %w", err)
+	}
+
+	topUTMCampaigns, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionUTMCampaign, "utm_campaign", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top utm campaigns: %w", err)
+	}
+
+	topCountries, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionCountry, "country", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top countries: %w", err)
+	}
+
+	topCities, err := r.topDimension(ctx, shortLinkID, domain.RollupDimensionCity, "city", rollupUntil, rawSince)
+	if err != nil {
+		return nil, fmt.Errorf("getting top cities: %w", err)
+	}
+
+	// Get clicks by day for the last 30 days
+	var clicksByDay map[string]int
+	if loc.String() == "UTC" {
+		clicksByDay, err = r.clicksByUTCDay(ctx, shortLinkID, rollupUntil, rawSince, time.Now())
+	} else {
+		clicksByDay, err = r.clicksByLocalDay(ctx, shortLinkID, loc, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Get recent clicks
+	var recentClicks []domain.LinkClick
+	if totalClicks > 0 {
+		recentClicks, err = r.recentClicks(ctx, shortLinkID)
+		if err != nil {
+			return nil, err
 		}
-		topBrowsers[browser] = count
 	}
 
-	// Get top operating systems
-	topOSQuery := `
-		SELECT os, COUNT(*) as count
+	return &domain.LinkStats{
+		TotalClicks:    totalClicks + unstoredClicks,
+		UniqueVisitors: uniqueVisitors,
+		LastClicked:    lastClicked,
+		TopReferrers:   topReferrers,
+		TopBrowsers:    topBrowsers,
+		TopOS:          topOS,
+		TopDevices:     topDevices,
+		ClicksByDay:    clicksByDay,
+		RecentClicks:   recentClicks,
+		Timezone:       loc.String(),
+
+		TopUTMSources:   topUTMSources,
+		TopUTMMediums:   topUTMMediums,
+		TopUTMCampaigns: topUTMCampaigns,
+
+		TopCountries: topCountries,
+		TopCities:    topCities,
+	}, nil
+}
+
+// recentClicks returns the last 10 raw clicks of a link, newest first
+func (r *LinkClickRepository) recentClicks(ctx context.Context, shortLinkID string) ([]domain.LinkClick, error) {
+	recentClicksQuery := `
+		SELECT ` + linkClickColumns + `
 		FROM link_clicks
-		WHERE short_link_id = $1 AND os IS NOT NULL
-		GROUP BY os
-		ORDER BY count DESC
-		LIMIT 5
+		WHERE short_link_id = $1
+		ORDER BY created_at DESC
+		LIMIT 10
 	`
 
-	osRows, err := r.db.QueryContext(ctx, topOSQuery, shortLinkID)
+	recentRows, err := r.db.QueryContext(ctx, recentClicksQuery, shortLinkID)
 	if err != nil {
-		return nil, fmt.Errorf("getting top operating systems: %w", err)
+		return nil, fmt.Errorf("getting recent clicks: %w", err)
 	}
-	defer osRows.Close()
+	defer recentRows.Close()
 
-	topOS := make(map[string]int)
-	for osRows.Next() {
-		var os string
-		var count int
-		if err := osRows.Scan(&os, &count); err != nil {
-			return nil, fmt.Errorf("scanning os row: %w", err)
+	var recentClicks []domain.LinkClick
+	for recentRows.Next() {
+		click, err := scanLinkClick(recentRows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning recent click row: %w", err)
 		}
-		topOS[os] = count
+		recentClicks = append(recentClicks, *click)
 	}
 
-	// Get top devices
-	topDevicesQuery := `
-		SELECT device, COUNT(*) as count
-		FROM link_clicks
-		WHERE short_link_id = $1 AND device IS NOT NULL
-		GROUP BY device
-		ORDER BY count DESC
-		LIMIT 5
+	return recentClicks, recentRows.Err()
+}
+
+// GetStatsSummaries returns the total clicks, unique visitors and last
+// click time of every given link in one grouped query. Links without any
+// clicks are included with zero counts.
+func (r *LinkClickRepository) GetStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+	summaries := make(map[string]*domain.LinkStatsSummary, len(shortLinkIDs))
+	if len(shortLinkIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT ids.id, COUNT(c.id), COUNT(DISTINCT c.ip_address), MAX(c.created_at),
+		       COALESCE(MAX(s.archived_clicks + s.sampled_out_clicks), 0)
+		FROM unnest($1::uuid[]) AS ids(id)
+		LEFT JOIN link_clicks c ON c.short_link_id = ids.id
+		LEFT JOIN link_click_summaries s ON s.short_link_id = ids.id
+		GROUP BY ids.id
 	`
 
-	deviceRows, err := r.db.QueryContext(ctx, topDevicesQuery, shortLinkID)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(shortLinkIDs))
 	if err != nil {
-		return nil, fmt.Errorf("getting top devices: %w", err)
+		return nil, fmt.Errorf("summarizing link clicks: %w", err)
 	}
-	defer deviceRows.Close()
+	defer rows.Close()
 
-	topDevices := make(map[string]int)
-	for deviceRows.Next() {
-		var device string
-		var count int
-		if err := deviceRows.Scan(&device, &count); err != nil {
-			return nil, fmt.Errorf("scanning device row: %w", err)
+	for rows.Next() {
+		var (
+			id                          string
+			totalClicks, uniqueVisitors int64
+			archivedClicks              int64
+			lastClicked                 sql.NullTime
+		)
+		if err := rows.Scan(&id, &totalClicks, &uniqueVisitors, &lastClicked, &archivedClicks); err != nil {
+			return nil, fmt.Errorf("scanning link click summary: %w", err)
+		}
+
+		summary := &domain.LinkStatsSummary{
+			TotalClicks:    totalClicks + archivedClicks,
+			UniqueVisitors: uniqueVisitors,
+		}
+		if lastClicked.Valid {
+			summary.LastClicked = &lastClicked.Time
 		}
-		topDevices[device] = count
+		summaries[id] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating link click summaries: %w", err)
 	}
 
-	// Get clicks by day for the last 30 days
+	return summaries, nil
+}
+
+// clicksByUTCDay counts the clicks of the last 30 UTC days, reading
+// completed days from the daily rollup and later days from raw clicks. The
+// window is computed here rather than with NOW() so it doesn't depend on the
+// database session's time zone.
+func (r *LinkClickRepository) clicksByUTCDay(
+	ctx context.Context,
+	shortLinkID string,
+	rollupUntil, rawSince, now time.Time,
+) (map[string]int, error) {
+	since := localDayStart(now, time.UTC, -30)
+
 	clicksByDayQuery := `
-		SELECT DATE(created_at) as date, COUNT(*) as count
-		FROM link_clicks
-		WHERE short_link_id = $1 AND created_at >= NOW() - INTERVAL '30 days'
-		GROUP BY date
-		ORDER BY date
+		SELECT day, SUM(clicks) AS count
+		FROM (
+			SELECT day, clicks
+			FROM link_click_daily
+			WHERE short_link_id = $1 AND dimension = $2 AND day <= $3
+			  AND day >= $5::date
+			UNION ALL
+			SELECT (created_at AT TIME ZONE 'UTC')::date AS day, sample_weight
+			FROM link_clicks
+			WHERE short_link_id = $1 AND created_at >= $4
+			  AND created_at >= $6
+		) AS combined
+		GROUP BY day
+		ORDER BY day
 	`
 
-	dayRows, err := r.db.QueryContext(ctx, clicksByDayQuery, shortLinkID)
+	dayRows, err := r.db.QueryContext(ctx, clicksByDayQuery,
+		shortLinkID, domain.RollupDimensionTotal, rollupUntil, rawSince, since.Format("2006-01-02"), since)
 	if err != nil {
 		return nil, fmt.Errorf("getting clicks by day: %w", err)
 	}
@@ -280,51 +424,299 @@ func (r *LinkClickRepository) GetStatsByShortLinkID(ctx context.Context, shortLi
 		clicksByDay[date.Format("2006-01-02")] = count
 	}
 
-	// Get recent clicks
-	recentClicksQuery := `
-		SELECT id, short_link_id, referrer, user_agent, ip_address, 
-               country, city, device, browser, os, created_at
+	if err := dayRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating day rows: %w", err)
+	}
+
+	return clicksByDay, nil
+}
+
+// clicksByLocalDay counts the raw clicks of the last 30 calendar days in
+// loc, scaled by their sample weights. The daily rollup is kept in UTC days, which can't be split into
+// another zone's days, so days whose raw clicks were already purged by the
+// retention job are missing.
+func (r *LinkClickRepository) clicksByLocalDay(
+	ctx context.Context,
+	shortLinkID string,
+	loc *time.Location,
+	now time.Time,
+) (map[string]int, error) {
+	query := `
+		SELECT created_at, sample_weight
 		FROM link_clicks
-		WHERE short_link_id = $1
-		ORDER BY created_at DESC
-		LIMIT 10
+		WHERE short_link_id = $1 AND created_at >= $2
 	`
 
-	recentRows, err := r.db.QueryContext(ctx, recentClicksQuery, shortLinkID)
+	rows, err := r.db.QueryContext(ctx, query, shortLinkID, localDayStart(now, loc, -30))
 	if err != nil {
-		return nil, fmt.Errorf("getting recent clicks: %w", err)
+		return nil, fmt.Errorf("getting clicks by day: %w", err)
 	}
-	defer recentRows.Close()
+	defer rows.Close()
 
-	var recentClicks []domain.LinkClick
-	for recentRows.Next() {
-		var click domain.LinkClick
-		if err := recentRows.Scan(
-			&click.ID,
-			&click.ShortLinkID,
-			&click.Referrer,
-			&click.UserAgent,
-			&click.IPAddress,
-			&click.Country,
-			&click.City,
-			&click.Device,
-			&click.Browser,
-			&click.OS,
-			&click.CreatedAt,
+	clicksByDay := make(map[string]int)
+	for rows.Next() {
+		var createdAt time.Time
+		var weight int
+		if err := rows.Scan(&createdAt, &weight); err != nil {
+			return nil, fmt.Errorf("scanning click time: %w", err)
+		}
+		clicksByDay[localDay(createdAt, loc)] += weight
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating click times: %w", err)
+	}
+
+	return clicksByDay, nil
+}
+
+// localDay returns the calendar day t falls on in loc as YYYY-MM-DD
+func localDay(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+// localDayStart returns midnight in loc of the day offset days away from
+// the day now falls on in loc
+func localDayStart(now time.Time, loc *time.Location, offset int) time.Time {
+	year, month, day := now.In(loc).Date()
+	return time.Date(year, month, day+offset, 0, 0, 0, 0, loc)
+}
+
+// CountAll returns the number of clicks across all links, including archived
+// and sampled out totals
+func (r *LinkClickRepository) CountAll(ctx context.Context) (int64, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM link_clicks) +
+		       COALESCE((SELECT SUM(archived_clicks + sampled_out_clicks) FROM link_click_summaries), 0)
+	`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting link clicks: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddSampledOut counts clicks on a link that sampling left unstored, so the
+// link's total stays exact
+func (r *LinkClickRepository) AddSampledOut(ctx context.Context, shortLinkID string, clicks int) error {
+	query := `
+		INSERT INTO link_click_summaries (short_link_id, sampled_out_clicks, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (short_link_id) DO UPDATE
+		SET sampled_out_clicks = link_click_summaries.sampled_out_clicks + EXCLUDED.sampled_out_clicks,
+		    updated_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, shortLinkID, clicks); err != nil {
+		return fmt.Errorf("counting sampled out clicks: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOlderThan removes clicks created before the cutoff and returns the number deleted.
+// When archive is set, the deleted clicks are rolled into link_click_summaries in the
+// same statement so historical totals survive the purge.
+func (r *LinkClickRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, archive bool) (int64, error) {
+	if !archive {
+		result, err := r.db.ExecContext(ctx, `DELETE FROM link_clicks WHERE created_at < $1`, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("deleting old link clicks: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("checking affected rows: %w", err)
+		}
+
+		return deleted, nil
+	}
+
+	query := `
+		WITH deleted AS (
+			DELETE FROM link_clicks
+			WHERE created_at < $1
+			RETURNING short_link_id, created_at
+		), archived AS (
+			INSERT INTO link_click_summaries (short_link_id, archived_clicks, last_archived_at, updated_at)
+			SELECT short_link_id, COUNT(*), MAX(created_at), NOW()
+			FROM deleted
+			GROUP BY short_link_id
+			ON CONFLICT (short_link_id) DO UPDATE
+			SET archived_clicks = link_click_summaries.archived_clicks + EXCLUDED.archived_clicks,
+			    last_archived_at = GREATEST(link_click_summaries.last_archived_at, EXCLUDED.last_archived_at),
+			    updated_at = NOW()
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var deleted int64
+	if err := r.db.QueryRowContext(ctx, query, cutoff).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("archiving old link clicks: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// topDimension returns the five most frequent values of a click dimension,
+// combining rollup rows up to rollupUntil with raw clicks created since rawSince,
+// each weighted by how many clicks it was sampled for.
+// column must be a trusted link_clicks column name.
+func (r *LinkClickRepository) topDimension(
+	ctx context.Context,
+	shortLinkID, dimension, column string,
+	rollupUntil, rawSince time.Time,
+) (map[string]int, error) {
+	query := fmt.Sprintf(`
+		SELECT value, SUM(clicks) AS count
+		FROM (
+			SELECT value, clicks
+			FROM link_click_daily
+			WHERE short_link_id = $1 AND dimension = $2 AND day <= $3
+			UNION ALL
+			SELECT %[1]s AS value, sample_weight
+			FROM link_clicks
+			WHERE short_link_id = $1 AND %[1]s IS NOT NULL AND created_at >= $4
+		) AS combined
+		GROUP BY value
+		ORDER BY count DESC
+		LIMIT 5
+	`, column)
+
+	rows, err := r.db.QueryContext(ctx, query, shortLinkID, dimension, rollupUntil, rawSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("scanning %s row: %w", dimension, err)
+		}
+		counts[value] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// StreamByCreatedRange calls fn for every click created in [start, end)
+func (r *LinkClickRepository) StreamByCreatedRange(
+	ctx context.Context,
+	start, end time.Time,
+	fn func(click *domain.LinkClick) error,
+) error {
+	query := `
+		SELECT ` + linkClickColumns + `
+		FROM link_clicks
+		WHERE created_at >= $1 AND created_at < $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, start, end)
+	if err != nil {
+		return fmt.Errorf("getting link clicks by created range: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		click, err := scanLinkClick(rows)
+		if err != nil {
+			return fmt.Errorf("scanning link click row: %w", err)
+		}
+
+		if err := fn(click); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating link click rows: %w", err)
+	}
+
+	return nil
+}
+
+// GetEarliestCreatedAt returns the creation time of the oldest click, or nil when there are none
+func (r *LinkClickRepository) GetEarliestCreatedAt(ctx context.Context) (*time.Time, error) {
+	var earliest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT MIN(created_at) FROM link_clicks`).Scan(&earliest); err != nil {
+		return nil, fmt.Errorf("getting earliest link click: %w", err)
+	}
+
+	if !earliest.Valid {
+		return nil, nil
+	}
+
+	return &earliest.Time, nil
+}
+
+// GetRollupWatermark returns the last fully rolled up day, or nil when nothing has been rolled up
+func (r *LinkClickRepository) GetRollupWatermark(ctx context.Context) (*time.Time, error) {
+	var day time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT rolled_through FROM link_click_rollup_state WHERE id = 1`).Scan(&day)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting rollup watermark: %w", err)
+	}
+
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return &day, nil
+}
+
+// SaveDailyRollup replaces the aggregates for a day and advances the watermark to it
+func (r *LinkClickRepository) SaveDailyRollup(ctx context.Context, day time.Time, rollups []*domain.DailyClickRollup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning rollup transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_click_daily WHERE day = $1`, day); err != nil {
+		return fmt.Errorf("clearing daily rollup: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO link_click_daily (short_link_id, day, dimension, value, clicks)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for _, rollup := range rollups {
+		if _, err := tx.ExecContext(
+			ctx,
+			insertQuery,
+			rollup.ShortLinkID,
+			rollup.Day,
+			rollup.Dimension,
+			rollup.Value,
+			rollup.Clicks,
 		); err != nil {
-			return nil, fmt.Errorf("scanning recent click row: %w", err)
+			return fmt.Errorf("inserting daily rollup: %w", err)
 		}
-		recentClicks = append(recentClicks, click)
 	}
 
-	return &domain.LinkStats{
-		TotalClicks:  totalClicks,
-		LastClicked:  &lastClicked,
-		TopReferrers: topReferrers,
-		TopBrowsers:  topBrowsers,
-		TopOS:        topOS,
-		TopDevices:   topDevices,
-		ClicksByDay:  clicksByDay,
-		RecentClicks: recentClicks,
-	}, nil
+	watermarkQuery := `
+		INSERT INTO link_click_rollup_state (id, rolled_through, updated_at)
+		VALUES (1, $1, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET rolled_through = GREATEST(link_click_rollup_state.rolled_through, EXCLUDED.rolled_through),
+		    updated_at = NOW()
+	`
+
+	if _, err := tx.ExecContext(ctx, watermarkQuery, day); err != nil {
+		return fmt.Errorf("advancing rollup watermark: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing daily rollup: %w", err)
+	}
+
+	return nil
 }
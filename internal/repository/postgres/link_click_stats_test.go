@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/db"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// purgedClicks is a database/sql connector for a link whose raw clicks the
+// retention job removed, leaving only archived totals and the daily rollup
+type purgedClicks struct {
+	rolledThrough time.Time
+	// rollup holds the rolled up counts by dimension and value
+	rollup map[string]map[string]int64
+}
+
+func (p *purgedClicks) Connect(ctx context.Context) (driver.Conn, error) { return &purgedConn{p}, nil }
+func (p *purgedClicks) Driver() driver.Driver                            { return nil }
+
+type purgedConn struct{ p *purgedClicks }
+
+func (c *purgedConn) Prepare(query string) (driver.Stmt, error) { return &purgedStmt{c.p, query}, nil }
+func (c *purgedConn) Close() error                              { return nil }
+func (c *purgedConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type purgedStmt struct {
+	p     *purgedClicks
+	query string
+}
+
+func (s *purgedStmt) Close() error  { return nil }
+func (s *purgedStmt) NumInput() int { return -1 }
+
+func (s *purgedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+// Query answers the stats queries from the rollup; queries reading raw
+// clicks only fail, since there are none to read
+func (s *purgedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(s.query, "COUNT(DISTINCT ip_address)"):
+		// No raw clicks left, 7 archived by the retention job
+		return &valueRows{rows: [][]driver.Value{{int64(0), int64(0), int64(7)}}}, nil
+	case strings.Contains(s.query, "link_click_rollup_state"):
+		return &valueRows{rows: [][]driver.Value{{s.p.rolledThrough}}}, nil
+	case strings.Contains(s.query, "GROUP BY day"):
+		return &valueRows{rows: [][]driver.Value{{s.p.rolledThrough, s.p.rollup[domain.RollupDimensionTotal][""]}}}, nil
+	case strings.Contains(s.query, "GROUP BY value"):
+		var rows [][]driver.Value
+		for value, clicks := range s.p.rollup[args[1].(string)] {
+			rows = append(rows, []driver.Value{value, clicks})
+		}
+		return &valueRows{rows: rows}, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", s.query)
+}
+
+type valueRows struct {
+	rows [][]driver.Value
+}
+
+func (r *valueRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{"value", "count"}
+	}
+	columns := make([]string, len(r.rows[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+	return columns
+}
+
+func (r *valueRows) Close() error { return nil }
+
+func (r *valueRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var _ = Describe("LinkClickRepository.GetStatsByShortLinkID", func() {
+	It("should keep the rolled up stats of a link whose raw clicks were purged", func() {
+		yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		conn := sql.OpenDB(&purgedClicks{
+			rolledThrough: yesterday,
+			rollup: map[string]map[string]int64{
+				domain.RollupDimensionTotal:    {"": 7},
+				domain.RollupDimensionReferrer: {"https://news.example": 5, "https://mail.example": 2},
+				domain.RollupDimensionBrowser:  {"Firefox": 7},
+				domain.RollupDimensionOS:       {"Linux": 7},
+				domain.RollupDimensionDevice:   {"Desktop": 7},
+			},
+		})
+		DeferCleanup(conn.Close)
+		repo := NewLinkClickRepository(&db.DB{DB: conn})
+
+		stats, err := repo.GetStatsByShortLinkID(context.Background(), "c9f0f895-fb98-4b91-9f53-1a2d3e4f5a6b", nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalClicks).To(Equal(int64(7)))
+		Expect(stats.TopReferrers).To(Equal(map[string]int{"https://news.example": 5, "https://mail.example": 2}))
+		Expect(stats.TopBrowsers).To(Equal(map[string]int{"Firefox": 7}))
+		Expect(stats.TopOS).To(Equal(map[string]int{"Linux": 7}))
+		Expect(stats.TopDevices).To(Equal(map[string]int{"Desktop": 7}))
+		Expect(stats.ClicksByDay).To(Equal(map[string]int{yesterday.Format("2006-01-02"): 7}))
+		Expect(stats.LastClicked).To(BeNil())
+		Expect(stats.RecentClicks).To(BeEmpty())
+	})
+})
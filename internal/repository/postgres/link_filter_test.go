@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+var _ = Describe("linkFilterConditions", func() {
+	var (
+		now       = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		weekAgo   = now.AddDate(0, 0, -7)
+		nextWeek  = now.AddDate(0, 0, 7)
+		paginated = []interface{}{10, 0}
+	)
+
+	It("should not filter when the filter is empty", func() {
+		where, args := linkFilterConditions(domain.LinkFilter{}, now, paginated)
+
+		Expect(where).To(BeEmpty())
+		Expect(args).To(Equal(paginated))
+	})
+
+	It("should filter on creation time", func() {
+		where, args := linkFilterConditions(domain.LinkFilter{CreatedAfter: &weekAgo}, now, nil)
+
+		Expect(where).To(Equal("WHERE s.created_at > $1"))
+		Expect(args).To(Equal([]interface{}{weekAgo}))
+	})
+
+	It("should only match links with an expiry before the bound", func() {
+		where, args := linkFilterConditions(domain.LinkFilter{ExpiresBefore: &nextWeek}, now, nil)
+
+		Expect(where).To(Equal("WHERE s.expiration_date IS NOT NULL AND s.expiration_date < $1"))
+		Expect(args).To(Equal([]interface{}{nextWeek}))
+	})
+
+	It("should filter on collection", func() {
+		where, args := linkFilterConditions(domain.LinkFilter{CollectionID: "col-1"}, now, paginated)
+
+		Expect(where).To(Equal("WHERE s.collection_id = $3"))
+		Expect(args).To(Equal([]interface{}{10, 0, "col-1"}))
+	})
+
+	DescribeTable("should filter on status at now",
+		func(status, expected string) {
+			where, args := linkFilterConditions(domain.LinkFilter{Status: status}, now, nil)
+
+			Expect(where).To(Equal(expected))
+			Expect(args).To(Equal([]interface{}{now}))
+		},
+		Entry("active", domain.LinkStatusActive,
+			"WHERE s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > $1)"),
+		Entry("expired", domain.LinkStatusExpired,
+			"WHERE s.expiration_date IS NOT NULL AND s.expiration_date <= $1"),
+		Entry("inactive", domain.LinkStatusInactive,
+			"WHERE NOT s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > $1)"),
+	)
+
+	It("should combine filters and number placeholders after the caller's args", func() {
+		filter := domain.LinkFilter{
+			CreatedAfter:  &weekAgo,
+			ExpiresBefore: &nextWeek,
+			Status:        domain.LinkStatusActive,
+		}
+
+		where, args := linkFilterConditions(filter, now, paginated)
+
+		Expect(where).To(Equal("WHERE s.created_at > $3" +
+			" AND s.expiration_date IS NOT NULL AND s.expiration_date < $4" +
+			" AND s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > $5)"))
+		Expect(args).To(Equal([]interface{}{10, 0, weekAgo, nextWeek, now}))
+	})
+})
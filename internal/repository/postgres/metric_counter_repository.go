@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/menezmethod/ref_go/internal/db"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// MetricCounterRepository implements the repository.MetricCounterRepository interface
+type MetricCounterRepository struct {
+	db *db.DB
+}
+
+// NewMetricCounterRepository creates a new metric counter repository
+func NewMetricCounterRepository(db *db.DB) *MetricCounterRepository {
+	return &MetricCounterRepository{
+		db: db,
+	}
+}
+
+// List returns every persisted counter
+func (r *MetricCounterRepository) List(ctx context.Context) ([]domain.MetricCounter, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, label, value FROM metric_counters`)
+	if err != nil {
+		return nil, fmt.Errorf("listing metric counters: %w", err)
+	}
+	defer rows.Close()
+
+	var counters []domain.MetricCounter
+	for rows.Next() {
+		var counter domain.MetricCounter
+		if err := rows.Scan(&counter.Name, &counter.Label, &counter.Value); err != nil {
+			return nil, fmt.Errorf("scanning metric counter: %w", err)
+		}
+		counters = append(counters, counter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating metric counters: %w", err)
+	}
+
+	return counters, nil
+}
+
+// Add adds each counter's value to its persisted total in one transaction,
+// so a failed flush can be retried without counting anything twice
+func (r *MetricCounterRepository) Add(ctx context.Context, counters []domain.MetricCounter) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning metric counter transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		INSERT INTO metric_counters (name, label, value, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (name, label) DO UPDATE
+		SET value = metric_counters.value + EXCLUDED.value,
+		    updated_at = NOW()
+	`
+
+	for _, counter := range counters {
+		if _, err := tx.ExecContext(ctx, query, counter.Name, counter.Label, counter.Value); err != nil {
+			return fmt.Errorf("adding to metric counter %s: %w", counter.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing metric counters: %w", err)
+	}
+
+	return nil
+}
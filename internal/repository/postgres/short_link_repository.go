@@ -4,12 +4,161 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/menezmethod/ref_go/internal/db"
 	"github.com/menezmethod/ref_go/internal/domain"
 )
 
+// shortLinkColumns lists the short_links columns read by scanShortLink, aliased as s
+const shortLinkColumns = `s.id, s.code, s.custom_alias, s.url_id, s.expiration_date, s.is_active,
+               s.created_at, s.updated_at, s.reachable, s.is_pattern, s.allowed_users,
+               s.health_status_code, s.health_error, s.health_checked_at, s.collection_id,
+               s.click_sample_rate, s.last_accessed_at, s.owner_id, s.track_clicks`
+
+// urlColumns lists the joined urls columns read by scanShortLink, aliased as u
+const urlColumns = `u.id, u.original_url, u.hash, u.created_at, u.updated_at`
+
+// clickCountColumn totals a link's raw clicks, the clicks archived by the
+// retention job and those left out by sampling, for queries over short_links
+// aliased as s
+const clickCountColumn = `(SELECT COUNT(*) FROM link_clicks lc WHERE lc.short_link_id = s.id)
+               + COALESCE((SELECT archived_clicks + sampled_out_clicks FROM link_click_summaries a WHERE a.short_link_id = s.id), 0)`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanShortLink scans shortLinkColumns, followed by urlColumns when withURL
+// is set and then any extra destinations
+func scanShortLink(row rowScanner, withURL bool, extra ...interface{}) (*domain.ShortLink, error) {
+	var link domain.ShortLink
+	var url domain.URL
+
+	// Nullable fields
+	var customAlias sql.NullString
+	var expirationDate sql.NullTime
+	var reachable sql.NullBool
+	var healthStatus sql.NullInt64
+	var healthError sql.NullString
+	var healthCheckedAt sql.NullTime
+	var collectionID sql.NullString
+	var clickSampleRate sql.NullInt64
+	var lastAccessedAt sql.NullTime
+	var ownerID sql.NullString
+	var trackClicks bool
+
+	dest := []interface{}{
+		&link.ID,
+		&link.Code,
+		&customAlias,
+		&link.URLID,
+		&expirationDate,
+		&link.IsActive,
+		&link.CreatedAt,
+		&link.UpdatedAt,
+		&reachable,
+		&link.IsPattern,
+		pq.Array(&link.AllowedUsers),
+		&healthStatus,
+		&healthError,
+		&healthCheckedAt,
+		&collectionID,
+		&clickSampleRate,
+		&lastAccessedAt,
+		&ownerID,
+		&trackClicks,
+	}
+
+	if withURL {
+		dest = append(dest,
+			&url.ID,
+			&url.OriginalURL,
+			&url.Hash,
+			&url.CreatedAt,
+			&url.UpdatedAt,
+		)
+	}
+
+	dest = append(dest, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if customAlias.Valid {
+		link.CustomAlias = &customAlias.String
+	}
+
+	if expirationDate.Valid {
+		link.ExpirationDate = &expirationDate.Time
+	}
+
+	if reachable.Valid {
+		link.Reachable = &reachable.Bool
+	}
+
+	if healthCheckedAt.Valid {
+		link.Health = &domain.LinkHealth{
+			StatusCode: int(healthStatus.Int64),
+			Error:      healthError.String,
+			CheckedAt:  healthCheckedAt.Time,
+		}
+	}
+
+	if collectionID.Valid {
+		link.CollectionID = &collectionID.String
+	}
+
+	if clickSampleRate.Valid {
+		rate := int(clickSampleRate.Int64)
+		link.ClickSampleRate = &rate
+	}
+
+	if lastAccessedAt.Valid {
+		link.LastAccessedAt = &lastAccessedAt.Time
+	}
+
+	if ownerID.Valid {
+		link.OwnerID = &ownerID.String
+	}
+
+	link.TrackClicks = &trackClicks
+
+	if withURL {
+		link.URL = &url
+	}
+
+	return &link, nil
+}
+
+// scanListedShortLink scans shortLinkColumns, urlColumns and clickCountColumn
+func scanListedShortLink(row rowScanner) (*domain.ShortLink, error) {
+	var clicks int64
+
+	link, err := scanShortLink(row, true, &clicks)
+	if err != nil {
+		return nil, err
+	}
+	link.ClickCount = &clicks
+
+	return link, nil
+}
+
+// allowedUsers stores an empty allowed users list as NULL, meaning everyone
+func allowedUsers(users []string) interface{} {
+	if len(users) == 0 {
+		return nil
+	}
+	return pq.Array(users)
+}
+
 // ShortLinkRepository implements the repository.ShortLinkRepository interface
 type ShortLinkRepository struct {
 	db *db.DB
@@ -25,8 +174,8 @@ func NewShortLinkRepository(db *db.DB) *ShortLinkRepository {
 // Create stores a new short link
 func (r *ShortLinkRepository) Create(ctx context.Context, link *domain.ShortLink) error {
 	query := `
-		INSERT INTO short_links (id, code, custom_alias, url_id, expiration_date, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO short_links (id, code, custom_alias, url_id, expiration_date, is_active, created_at, updated_at, reachable, is_pattern, allowed_users, collection_id, click_sample_rate, owner_id, track_clicks)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.db.ExecContext(
@@ -40,9 +189,19 @@ func (r *ShortLinkRepository) Create(ctx context.Context, link *domain.ShortLink
 		link.IsActive,
 		link.CreatedAt,
 		link.UpdatedAt,
+		link.Reachable,
+		link.IsPattern,
+		allowedUsers(link.AllowedUsers),
+		link.CollectionID,
+		link.ClickSampleRate,
+		link.OwnerID,
+		link.TracksClicks(),
 	)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+		}
 		return fmt.Errorf("creating short link: %w", err)
 	}
 
@@ -52,36 +211,13 @@ func (r *ShortLinkRepository) Create(ctx context.Context, link *domain.ShortLink
 // GetByID retrieves a short link by ID
 func (r *ShortLinkRepository) GetByID(ctx context.Context, id string) (*domain.ShortLink, error) {
 	query := `
-		SELECT s.id, s.code, s.custom_alias, s.url_id, s.expiration_date, s.is_active, s.created_at, s.updated_at,
-               u.id, u.original_url, u.hash, u.created_at, u.updated_at
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `
 		FROM short_links s
 		JOIN urls u ON s.url_id = u.id
 		WHERE s.id = $1
 	`
 
-	var link domain.ShortLink
-	var url domain.URL
-
-	// Nullable fields
-	var customAlias sql.NullString
-	var expirationDate sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&link.ID,
-		&link.Code,
-		&customAlias,
-		&link.URLID,
-		&expirationDate,
-		&link.IsActive,
-		&link.CreatedAt,
-		&link.UpdatedAt,
-		&url.ID,
-		&url.OriginalURL,
-		&url.Hash,
-		&url.CreatedAt,
-		&url.UpdatedAt,
-	)
-
+	link, err := scanShortLink(r.db.QueryRowContext(ctx, query, id), true)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("short link not found: %w", err)
@@ -89,54 +225,19 @@ func (r *ShortLinkRepository) GetByID(ctx context.Context, id string) (*domain.S
 		return nil, fmt.Errorf("getting short link by id: %w", err)
 	}
 
-	// Handle nullable fields
-	if customAlias.Valid {
-		link.CustomAlias = &customAlias.String
-	}
-
-	if expirationDate.Valid {
-		link.ExpirationDate = &expirationDate.Time
-	}
-
-	// Set the URL object
-	link.URL = &url
-
-	return &link, nil
+	return link, nil
 }
 
 // GetByCode retrieves a short link by code
 func (r *ShortLinkRepository) GetByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
 	query := `
-		SELECT s.id, s.code, s.custom_alias, s.url_id, s.expiration_date, s.is_active, s.created_at, s.updated_at,
-               u.id, u.original_url, u.hash, u.created_at, u.updated_at
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `
 		FROM short_links s
 		JOIN urls u ON s.url_id = u.id
 		WHERE s.code = $1
 	`
 
-	var link domain.ShortLink
-	var url domain.URL
-
-	// Nullable fields
-	var customAlias sql.NullString
-	var expirationDate sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, code).Scan(
-		&link.ID,
-		&link.Code,
-		&customAlias,
-		&link.URLID,
-		&expirationDate,
-		&link.IsActive,
-		&link.CreatedAt,
-		&link.UpdatedAt,
-		&url.ID,
-		&url.OriginalURL,
-		&url.Hash,
-		&url.CreatedAt,
-		&url.UpdatedAt,
-	)
-
+	link, err := scanShortLink(r.db.QueryRowContext(ctx, query, code), true)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("short link not found: %w", err)
@@ -144,54 +245,19 @@ func (r *ShortLinkRepository) GetByCode(ctx context.Context, code string) (*doma
 		return nil, fmt.Errorf("getting short link by code: %w", err)
 	}
 
-	// Handle nullable fields
-	if customAlias.Valid {
-		link.CustomAlias = &customAlias.String
-	}
-
-	if expirationDate.Valid {
-		link.ExpirationDate = &expirationDate.Time
-	}
-
-	// Set the URL object
-	link.URL = &url
-
-	return &link, nil
+	return link, nil
 }
 
 // GetByCustomAlias retrieves a short link by custom alias
 func (r *ShortLinkRepository) GetByCustomAlias(ctx context.Context, alias string) (*domain.ShortLink, error) {
 	query := `
-		SELECT s.id, s.code, s.custom_alias, s.url_id, s.expiration_date, s.is_active, s.created_at, s.updated_at,
-               u.id, u.original_url, u.hash, u.created_at, u.updated_at
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `
 		FROM short_links s
 		JOIN urls u ON s.url_id = u.id
 		WHERE s.custom_alias = $1
 	`
 
-	var link domain.ShortLink
-	var url domain.URL
-
-	// Nullable fields
-	var customAlias sql.NullString
-	var expirationDate sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, alias).Scan(
-		&link.ID,
-		&link.Code,
-		&customAlias,
-		&link.URLID,
-		&expirationDate,
-		&link.IsActive,
-		&link.CreatedAt,
-		&link.UpdatedAt,
-		&url.ID,
-		&url.OriginalURL,
-		&url.Hash,
-		&url.CreatedAt,
-		&url.UpdatedAt,
-	)
-
+	link, err := scanShortLink(r.db.QueryRowContext(ctx, query, alias), true)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("short link not found: %w", err)
@@ -199,28 +265,16 @@ func (r *ShortLinkRepository) GetByCustomAlias(ctx context.Context, alias string
 		return nil, fmt.Errorf("getting short link by custom alias: %w", err)
 	}
 
-	// Handle nullable fields
-	if customAlias.Valid {
-		link.CustomAlias = &customAlias.String
-	}
-
-	if expirationDate.Valid {
-		link.ExpirationDate = &expirationDate.Time
-	}
-
-	// Set the URL object
-	link.URL = &url
-
-	return &link, nil
+	return link, nil
 }
 
 // GetAllByURLID retrieves all short links for a URL
 func (r *ShortLinkRepository) GetAllByURLID(ctx context.Context, urlID string) ([]*domain.ShortLink, error) {
 	query := `
-		SELECT id, code, custom_alias, url_id, expiration_date, is_active, created_at, updated_at
-		FROM short_links
-		WHERE url_id = $1
-		ORDER BY created_at DESC
+		SELECT ` + shortLinkColumns + `
+		FROM short_links s
+		WHERE s.url_id = $1
+		ORDER BY s.created_at DESC
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, urlID)
@@ -232,35 +286,12 @@ func (r *ShortLinkRepository) GetAllByURLID(ctx context.Context, urlID string) (
 	var links []*domain.ShortLink
 
 	for rows.Next() {
-		var link domain.ShortLink
-		var customAlias sql.NullString
-		var expirationDate sql.NullTime
-
-		err := rows.Scan(
-			&link.ID,
-			&link.Code,
-			&customAlias,
-			&link.URLID,
-			&expirationDate,
-			&link.IsActive,
-			&link.CreatedAt,
-			&link.UpdatedAt,
-		)
-
+		link, err := scanShortLink(rows, false)
 		if err != nil {
 			return nil, fmt.Errorf("scanning short link row: %w", err)
 		}
 
-		// Handle nullable fields
-		if customAlias.Valid {
-			link.CustomAlias = &customAlias.String
-		}
-
-		if expirationDate.Valid {
-			link.ExpirationDate = &expirationDate.Time
-		}
-
-		links = append(links, &link)
+		links = append(links, link)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -274,8 +305,9 @@ func (r *ShortLinkRepository) GetAllByURLID(ctx context.Context, urlID string) (
 func (r *ShortLinkRepository) Update(ctx context.Context, link *domain.ShortLink) error {
 	query := `
 		UPDATE short_links
-		SET custom_alias = $1, expiration_date = $2, is_active = $3, updated_at = $4
-		WHERE id = $5
+		SET custom_alias = $1, expiration_date = $2, is_active = $3, updated_at = $4, allowed_users = $5, collection_id = $6,
+		    click_sample_rate = $7, track_clicks = $8
+		WHERE id = $9
 	`
 
 	_, err := r.db.ExecContext(
@@ -285,16 +317,63 @@ func (r *ShortLinkRepository) Update(ctx context.Context, link *domain.ShortLink
 		link.ExpirationDate,
 		link.IsActive,
 		time.Now().UTC(),
+		allowedUsers(link.AllowedUsers),
+		link.CollectionID,
+		link.ClickSampleRate,
+		link.TracksClicks(),
 		link.ID,
 	)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("updating short link: %w", domain.ErrConflict)
+		}
 		return fmt.Errorf("updating short link: %w", err)
 	}
 
 	return nil
 }
 
+// UpdateHealth records the result of a destination health check. The
+// link's updated_at is left alone, as its settings haven't changed.
+func (r *ShortLinkRepository) UpdateHealth(ctx context.Context, id string, health *domain.LinkHealth) error {
+	query := `
+		UPDATE short_links
+		SET health_status_code = $1, health_error = $2, health_checked_at = $3
+		WHERE id = $4
+	`
+
+	var status, message interface{}
+	if health.StatusCode != 0 {
+		status = health.StatusCode
+	}
+	if health.Error != "" {
+		message = health.Error
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, status, message, health.CheckedAt, id); err != nil {
+		return fmt.Errorf("updating short link health: %w", err)
+	}
+
+	return nil
+}
+
+// TouchLastAccessed records that a link was followed at the given time.
+// An older time never replaces a newer one, and updated_at is left alone.
+func (r *ShortLinkRepository) TouchLastAccessed(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE short_links
+		SET last_accessed_at = $1
+		WHERE id = $2 AND (last_accessed_at IS NULL OR last_accessed_at < $1)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, at, id); err != nil {
+		return fmt.Errorf("updating short link last access: %w", err)
+	}
+
+	return nil
+}
+
 // Delete deletes a short link
 func (r *ShortLinkRepository) Delete(ctx context.Context, id string) error {
 	query := `
@@ -322,9 +401,25 @@ func (r *ShortLinkRepository) Delete(ctx context.Context, id string) error {
 
 // List returns a paginated list of short links
 func (r *ShortLinkRepository) List(ctx context.Context, offset, limit int) ([]*domain.ShortLink, error) {
+	var links []*domain.ShortLink
+	err := r.StreamPage(ctx, offset, limit, func(link *domain.ShortLink) error {
+		links = append(links, link)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// StreamPage calls fn for each link List would return, newest first with
+// their URL data and click counts, stopping at the first error fn returns.
+// Rows are handed over as they arrive, so a large page is never held in
+// memory.
+func (r *ShortLinkRepository) StreamPage(ctx context.Context, offset, limit int, fn func(link *domain.ShortLink) error) error {
 	query := `
-		SELECT s.id, s.code, s.custom_alias, s.url_id, s.expiration_date, s.is_active, s.created_at, s.updated_at,
-               u.id, u.original_url, u.hash, u.created_at, u.updated_at
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
 		FROM short_links s
 		JOIN urls u ON s.url_id = u.id
 		ORDER BY s.created_at DESC
@@ -333,51 +428,345 @@ func (r *ShortLinkRepository) List(ctx context.Context, offset, limit int) ([]*d
 
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("listing short links: %w", err)
+		return fmt.Errorf("listing short links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanListedShortLink(rows)
+		if err != nil {
+			return fmt.Errorf("scanning short link row: %w", err)
+		}
+
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return nil
+}
+
+// ListMostClicked returns up to limit links that are active and unexpired
+// at now, ordered by total clicks including archived and sampled out ones,
+// with their URL data
+func (r *ShortLinkRepository) ListMostClicked(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error) {
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		LEFT JOIN (
+			SELECT short_link_id, COUNT(*) AS clicks
+			FROM link_clicks
+			GROUP BY short_link_id
+		) c ON c.short_link_id = s.id
+		LEFT JOIN link_click_summaries a ON a.short_link_id = s.id
+		WHERE s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > $1)
+		ORDER BY COALESCE(c.clicks, 0) + COALESCE(a.archived_clicks + a.sampled_out_clicks, 0) DESC, s.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing most clicked short links: %w", err)
 	}
 	defer rows.Close()
 
 	var links []*domain.ShortLink
 
 	for rows.Next() {
-		var link domain.ShortLink
-		var url domain.URL
-		var customAlias sql.NullString
-		var expirationDate sql.NullTime
-
-		err := rows.Scan(
-			&link.ID,
-			&link.Code,
-			&customAlias,
-			&link.URLID,
-			&expirationDate,
-			&link.IsActive,
-			&link.CreatedAt,
-			&link.UpdatedAt,
-			&url.ID,
-			&url.OriginalURL,
-			&url.Hash,
-			&url.CreatedAt,
-			&url.UpdatedAt,
-		)
+		link, err := scanShortLink(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("scanning short link row: %w", err)
+		}
+
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return links, nil
+}
 
+// ListAfter returns up to limit links, newest first, that come after the
+// cursor in (created_at, id) order; a nil cursor starts from the newest
+func (r *ShortLinkRepository) ListAfter(ctx context.Context, cursor *domain.LinkCursor, limit int) ([]*domain.ShortLink, error) {
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		ORDER BY s.created_at DESC, s.id DESC
+		LIMIT $1
+	`
+	args := []interface{}{limit}
+
+	// Keyset condition, so rows inserted meanwhile cannot shift the page
+	if cursor != nil {
+		query = `
+			SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
+			FROM short_links s
+			JOIN urls u ON s.url_id = u.id
+			WHERE (s.created_at, s.id) < ($2, $3)
+			ORDER BY s.created_at DESC, s.id DESC
+			LIMIT $1
+		`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing short links after cursor: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*domain.ShortLink
+
+	for rows.Next() {
+		link, err := scanListedShortLink(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scanning short link row: %w", err)
 		}
 
-		// Handle nullable fields
-		if customAlias.Valid {
-			link.CustomAlias = &customAlias.String
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// ListFiltered returns a paginated list of the short links matching filter,
+// with statuses evaluated at now
+func (r *ShortLinkRepository) ListFiltered(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error) {
+	where, args := linkFilterConditions(filter, now, []interface{}{limit, offset})
+
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		` + where + `
+		ORDER BY s.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing filtered short links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*domain.ShortLink
+
+	for rows.Next() {
+		link, err := scanListedShortLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning short link row: %w", err)
 		}
 
-		if expirationDate.Valid {
-			link.ExpirationDate = &expirationDate.Time
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// CountFiltered returns the number of short links matching filter at now
+func (r *ShortLinkRepository) CountFiltered(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error) {
+	where, args := linkFilterConditions(filter, now, nil)
+
+	query := `
+		SELECT COUNT(*)
+		FROM short_links s
+		` + where
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting filtered short links: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListByDestinationHost returns a page of the links whose destination host
+// is host or a subdomain of it, newest first, with their click counts.
+// host must be lowercase.
+func (r *ShortLinkRepository) ListByDestinationHost(ctx context.Context, host string, offset, limit int) ([]*domain.ShortLink, error) {
+	condition, args := destinationHostCondition(host, []interface{}{limit, offset})
+
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		WHERE ` + condition + `
+		ORDER BY s.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing short links by destination host: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*domain.ShortLink
+
+	for rows.Next() {
+		link, err := scanListedShortLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning short link row: %w", err)
 		}
 
-		// Set the URL object
-		link.URL = &url
+		links = append(links, link)
+	}
 
-		links = append(links, &link)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// CountByDestinationHost returns the number of links whose destination host
+// is host or a subdomain of it
+func (r *ShortLinkRepository) CountByDestinationHost(ctx context.Context, host string) (int, error) {
+	condition, args := destinationHostCondition(host, nil)
+
+	query := `
+		SELECT COUNT(*)
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		WHERE ` + condition
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting short links by destination host: %w", err)
+	}
+
+	return count, nil
+}
+
+// destinationHostCondition matches urls aliased as u whose host is host or
+// ends in "."+host. Both are compared reversed, so the subdomain match is a
+// prefix match idx_urls_host_reversed can serve. Placeholders are numbered
+// after the args already bound by the caller, and the returned args extend
+// them.
+func destinationHostCondition(host string, args []interface{}) (string, []interface{}) {
+	reversed := reverseString(host)
+	args = append(args, reversed, escapeLike(reversed)+".%")
+
+	return fmt.Sprintf("(reverse(u.host) = $%d OR reverse(u.host) LIKE $%d)", len(args)-1, len(args)), args
+}
+
+// reverseString reverses s by character, like the Postgres reverse function
+func reverseString(s string) string {
+	runes := []rune(s)
+	slices.Reverse(runes)
+	return string(runes)
+}
+
+// escapeLike escapes the LIKE wildcards in s so it only matches itself
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// linkFilterConditions translates filter into a WHERE clause over
+// short_links aliased as s. Placeholders are numbered after the args already
+// bound by the caller, and the returned args extend them.
+func linkFilterConditions(filter domain.LinkFilter, now time.Time, args []interface{}) (string, []interface{}) {
+	var conditions []string
+
+	bind := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "s.created_at > "+bind(*filter.CreatedAfter))
+	}
+
+	if filter.ExpiresBefore != nil {
+		conditions = append(conditions, "s.expiration_date IS NOT NULL AND s.expiration_date < "+bind(*filter.ExpiresBefore))
+	}
+
+	if filter.CollectionID != "" {
+		conditions = append(conditions, "s.collection_id = "+bind(filter.CollectionID))
+	}
+
+	// Same definitions as CountStats: expiry wins over deactivation
+	switch filter.Status {
+	case domain.LinkStatusActive:
+		conditions = append(conditions, "s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > "+bind(now)+")")
+	case domain.LinkStatusExpired:
+		conditions = append(conditions, "s.expiration_date IS NOT NULL AND s.expiration_date <= "+bind(now))
+	case domain.LinkStatusInactive:
+		conditions = append(conditions, "NOT s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > "+bind(now)+")")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// CountActiveByOwner counts the links of ownerID that are active as of now,
+// by the same definition as CountStats
+func (r *ShortLinkRepository) CountActiveByOwner(ctx context.Context, ownerID string, now time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM short_links
+		WHERE owner_id = $1 AND is_active AND (expiration_date IS NULL OR expiration_date > $2)
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, ownerID, now.UTC()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting active short links by owner: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListActiveByOwnerAndHost returns the links of ownerID that are active as
+// of now and whose destination host is exactly host, newest first, with
+// their URL data. An empty host matches destinations without one, such as
+// mailto: URLs.
+func (r *ShortLinkRepository) ListActiveByOwnerAndHost(ctx context.Context, ownerID, host string, now time.Time) ([]*domain.ShortLink, error) {
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		WHERE s.owner_id = $1 AND s.is_active AND (s.expiration_date IS NULL OR s.expiration_date > $2)
+			AND u.host IS NOT DISTINCT FROM NULLIF($3, '')
+		ORDER BY s.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID, now.UTC(), host)
+	if err != nil {
+		return nil, fmt.Errorf("listing active short links by owner and host: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*domain.ShortLink
+
+	for rows.Next() {
+		link, err := scanShortLink(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("scanning short link row: %w", err)
+		}
+
+		links = append(links, link)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -387,6 +776,42 @@ func (r *ShortLinkRepository) List(ctx context.Context, offset, limit int) ([]*d
 	return links, nil
 }
 
+// Stream calls fn for up to limit links, oldest first, with their URL data
+// and click counts, stopping at the first error fn returns. Rows are read as
+// they arrive, so the whole catalog is never held in memory.
+func (r *ShortLinkRepository) Stream(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error {
+	query := `
+		SELECT ` + shortLinkColumns + `, ` + urlColumns + `, ` + clickCountColumn + `
+		FROM short_links s
+		JOIN urls u ON s.url_id = u.id
+		ORDER BY s.created_at, s.id
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return fmt.Errorf("streaming short links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanListedShortLink(rows)
+		if err != nil {
+			return fmt.Errorf("scanning short link row: %w", err)
+		}
+
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating short link rows: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the total number of short links
 func (r *ShortLinkRepository) Count(ctx context.Context) (int, error) {
 	query := `
@@ -402,3 +827,33 @@ func (r *ShortLinkRepository) Count(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+// CountStats returns link totals as of now: all, created since the start of
+// the UTC day, active, expired and deactivated
+func (r *ShortLinkRepository) CountStats(ctx context.Context, now time.Time) (*domain.SystemStats, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE created_at >= $1),
+		       COUNT(*) FILTER (WHERE is_active AND (expiration_date IS NULL OR expiration_date > $2)),
+		       COUNT(*) FILTER (WHERE expiration_date IS NOT NULL AND expiration_date <= $2),
+		       COUNT(*) FILTER (WHERE NOT is_active AND (expiration_date IS NULL OR expiration_date > $2))
+		FROM short_links
+	`
+
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var stats domain.SystemStats
+	err := r.db.QueryRowContext(ctx, query, dayStart, now).Scan(
+		&stats.TotalLinks,
+		&stats.LinksCreatedToday,
+		&stats.ActiveLinks,
+		&stats.ExpiredLinks,
+		&stats.InactiveLinks,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("counting short link stats: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -98,3 +98,35 @@ func (r *URLRepository) GetByHash(ctx context.Context, hash string) (*domain.URL
 
 	return &url, nil
 }
+
+// Update changes the destination of an existing URL
+func (r *URLRepository) Update(ctx context.Context, url *domain.URL) error {
+	query := `
+		UPDATE urls
+		SET original_url = $1, hash = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		url.OriginalURL,
+		url.Hash,
+		url.UpdatedAt,
+		url.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating url: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("url not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
@@ -151,22 +151,77 @@ func (r *PostgresLinkRepository) Count(userID string) (int, error) {
 	return count, err
 }
 
+// Statements shared by the single and transactional click recording paths
+const (
+	incrementVisitsQuery = "UPDATE links SET visits = visits + 1 WHERE id = $1"
+	addVisitsQuery       = "UPDATE links SET visits = visits + $2 WHERE id = $1"
+	createClickQuery     = "INSERT INTO clicks (id, link_id, user_agent, referer, ip_address, created_at) VALUES ($1, $2, $3, $4, $5, $6)"
+)
+
 // IncrementVisits increments the visits count for a link
 func (r *PostgresLinkRepository) IncrementVisits(id string) error {
-	_, err := r.db.Exec("UPDATE links SET visits = visits + 1 WHERE id = $1", id)
+	_, err := r.db.Exec(incrementVisitsQuery, id)
 	return err
 }
 
+// AddVisits adds each link's delta to its visits count in one transaction,
+// so a batch of coalesced increments is stored entirely or not at all
+func (r *PostgresLinkRepository) AddVisits(deltas map[string]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rolling back after a successful commit is a no-op
+	defer tx.Rollback()
+
+	for id, delta := range deltas {
+		if _, err := tx.Exec(addVisitsQuery, id, delta); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // CreateClick creates a new click record
 func (r *PostgresLinkRepository) CreateClick(click *domain.Click) error {
 	click.CreatedAt = time.Now()
 	_, err := r.db.Exec(
-		"INSERT INTO clicks (id, link_id, user_agent, referer, ip_address, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		createClickQuery,
 		click.ID, click.LinkID, click.UserAgent, click.Referer, click.IPAddress, click.CreatedAt,
 	)
 	return err
 }
 
+// RecordVisit increments the visits count of click.LinkID and creates the
+// click record in one transaction, so neither persists without the other
+func (r *PostgresLinkRepository) RecordVisit(click *domain.Click) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rolling back after a successful commit is a no-op
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(incrementVisitsQuery, click.LinkID); err != nil {
+		return err
+	}
+
+	click.CreatedAt = time.Now()
+	if _, err := tx.Exec(
+		createClickQuery,
+		click.ID, click.LinkID, click.UserAgent, click.Referer, click.IPAddress, click.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // GetClicks gets clicks for a link with pagination
 func (r *PostgresLinkRepository) GetClicks(linkID string, limit, offset int) ([]*domain.Click, error) {
 	rows, err := r.db.Query(
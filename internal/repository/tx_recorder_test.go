@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// txRecorder is a database/sql connector whose transactions only keep their
+// statements when committed, standing in for a database in transaction tests
+type txRecorder struct {
+	failOn     string // Statements containing this fail
+	pending    []string
+	committed  []string
+	rolledBack int
+}
+
+func (r *txRecorder) Connect(ctx context.Context) (driver.Conn, error) { return &txConn{r}, nil }
+func (r *txRecorder) Driver() driver.Driver                            { return nil }
+
+type txConn struct{ r *txRecorder }
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) { return &txStmt{c.r, query}, nil }
+func (c *txConn) Close() error                              { return nil }
+func (c *txConn) Begin() (driver.Tx, error)                 { return &txTx{c.r}, nil }
+
+type txTx struct{ r *txRecorder }
+
+func (t *txTx) Commit() error {
+	t.r.committed = append(t.r.committed, t.r.pending...)
+	t.r.pending = nil
+	return nil
+}
+
+func (t *txTx) Rollback() error {
+	t.r.pending = nil
+	t.r.rolledBack++
+	return nil
+}
+
+type txStmt struct {
+	r     *txRecorder
+	query string
+}
+
+func (s *txStmt) Close() error  { return nil }
+func (s *txStmt) NumInput() int { return -1 }
+
+func (s *txStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.r.failOn != "" && strings.Contains(s.query, s.r.failOn) {
+		return nil, errors.New("database error")
+	}
+	s.r.pending = append(s.r.pending, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *txStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
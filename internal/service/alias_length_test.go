@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService minimum custom alias length", func() {
+	var (
+		svc     *service.URLShortenerService
+		created *domain.ShortLink
+		updated *domain.ShortLink
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created, updated = nil, nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id, Code: "abc123", IsActive: true}, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					updated = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com", MinAliasLength: 4},
+		)
+	})
+
+	expectTooShort := func(err error) {
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields).To(ConsistOf(domain.FieldError{
+			Field:   "custom_alias",
+			Message: "custom alias must be at least 4 characters",
+		}))
+	}
+
+	create := func(alias string) error {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: &alias})
+		return err
+	}
+
+	update := func(alias string) error {
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{CustomAlias: &alias})
+		return err
+	}
+
+	Describe("on create", func() {
+		It("should reject an alias below the minimum", func() {
+			expectTooShort(create("abc"))
+			Expect(created).To(BeNil())
+		})
+
+		It("should accept an alias at the minimum", func() {
+			Expect(create("abcd")).To(Succeed())
+			Expect(created).NotTo(BeNil())
+			Expect(created.Code).To(Equal("abcd"))
+		})
+
+		It("should still generate codes when no alias is given", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).NotTo(BeNil())
+		})
+	})
+
+	Describe("on update", func() {
+		It("should reject an alias below the minimum", func() {
+			expectTooShort(update("ab"))
+			Expect(updated).To(BeNil())
+		})
+
+		It("should accept an alias at the minimum", func() {
+			Expect(update("abcd")).To(Succeed())
+			Expect(updated).NotTo(BeNil())
+			Expect(*updated.CustomAlias).To(Equal("abcd"))
+		})
+	})
+})
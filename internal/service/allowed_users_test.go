@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService allowed users", func() {
+	var (
+		svc     *service.URLShortenerService
+		created *domain.ShortLink
+		updated *domain.ShortLink
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created, updated = nil, nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id, Code: "abc123", IsActive: true, AllowedUsers: []string{"alice"}}, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					updated = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+	})
+
+	It("should store trimmed allowed users without repeats", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:          "https://example.com",
+			AllowedUsers: []string{" alice ", "Bob", "ALICE"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.AllowedUsers).To(Equal([]string{"alice", "Bob"}))
+	})
+
+	It("should reject blank allowed users", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:          "https://example.com",
+			AllowedUsers: []string{"alice", "  "},
+		})
+
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields).To(ConsistOf(domain.FieldError{Field: "allowed_users", Message: "allowed users must not be blank"}))
+		Expect(created).To(BeNil())
+	})
+
+	It("should keep allowed users on updates that don't mention them", func() {
+		active := false
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{IsActive: &active})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.AllowedUsers).To(Equal([]string{"alice"}))
+	})
+
+	It("should open a link to everyone when updated with no allowed users", func() {
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{AllowedUsers: &[]string{}})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.AllowedUsers).To(BeEmpty())
+	})
+})
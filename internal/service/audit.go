@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// maxHistoryEntries caps how many audit entries are returned for a link
+const maxHistoryEntries = 100
+
+// recordAudit stores an audit entry for a short link change made by the user
+// in ctx. Failures are logged rather than returned since the change itself
+// has already been applied.
+func (s *URLShortenerService) recordAudit(ctx context.Context, action, linkID string, before, after *domain.ShortLink) {
+	if s.opts.AuditLog == nil {
+		return
+	}
+
+	entry := &domain.AuditEntry{
+		ID:         uuid.New().String(),
+		EntityType: domain.AuditEntityShortLink,
+		EntityID:   linkID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if userID := auth.UserIDFromContext(ctx); userID != "" {
+		entry.UserID = &userID
+	}
+
+	if err := s.opts.AuditLog.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry",
+			zap.String("action", action),
+			zap.String("link_id", linkID),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetLinkHistory returns the audit entries of the link with the given code, newest first
+func (s *URLShortenerService) GetLinkHistory(ctx context.Context, code string) ([]*domain.AuditEntry, error) {
+	if s.opts.AuditLog == nil {
+		return []*domain.AuditEntry{}, nil
+	}
+
+	link, err := s.GetShortLinkByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.opts.AuditLog.ListByEntity(ctx, domain.AuditEntityShortLink, link.ID, 0, maxHistoryEntries)
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService audit log", func() {
+	var (
+		mockURLRepo       *mocks.MockURLRepository
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		mockAuditRepo     *mocks.MockAuditLogRepository
+		entries           []*domain.AuditEntry
+		stored            *domain.ShortLink
+		svc               *service.URLShortenerService
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		oldAlias := "old-alias"
+		stored = &domain.ShortLink{
+			ID:          "link-1",
+			Code:        "abc123",
+			CustomAlias: &oldAlias,
+			URLID:       "url-1",
+			IsActive:    true,
+			CreatedAt:   time.Now().Add(-time.Hour),
+		}
+		entries = nil
+
+		mockURLRepo = &mocks.MockURLRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+				return &domain.URL{ID: id, OriginalURL: "https://example.com"}, nil
+			},
+			GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+				return nil, errors.New("url not found")
+			},
+		}
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+				link := *stored
+				return &link, nil
+			},
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code == stored.Code {
+					link := *stored
+					return &link, nil
+				}
+				return nil, errors.New("short link not found")
+			},
+			GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+				return nil, errors.New("short link not found")
+			},
+		}
+		mockAuditRepo = &mocks.MockAuditLogRepository{
+			CreateFunc: func(ctx context.Context, entry *domain.AuditEntry) error {
+				entries = append(entries, entry)
+				return nil
+			},
+			ListByEntityFunc: func(ctx context.Context, entityType, entityID string, offset, limit int) ([]*domain.AuditEntry, error) {
+				var matched []*domain.AuditEntry
+				for _, entry := range entries {
+					if entry.EntityType == entityType && entry.EntityID == entityID {
+						matched = append(matched, entry)
+					}
+				}
+				return matched, nil
+			},
+		}
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			mockURLRepo,
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{AuditLog: mockAuditRepo},
+		)
+		ctx = auth.WithUserID(context.Background(), "alice")
+	})
+
+	It("records the before and after values of an update", func() {
+		newAlias := "new-alias"
+		inactive := false
+
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{
+			CustomAlias: &newAlias,
+			IsActive:    &inactive,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(entries).To(HaveLen(1))
+		entry := entries[0]
+		Expect(entry.Action).To(Equal(domain.AuditActionUpdate))
+		Expect(entry.EntityType).To(Equal(domain.AuditEntityShortLink))
+		Expect(entry.EntityID).To(Equal("link-1"))
+		Expect(entry.UserID).NotTo(BeNil())
+		Expect(*entry.UserID).To(Equal("alice"))
+		Expect(entry.CreatedAt).To(BeTemporally("~", time.Now(), time.Second))
+
+		Expect(*entry.Before.CustomAlias).To(Equal("old-alias"))
+		Expect(entry.Before.IsActive).To(BeTrue())
+		Expect(*entry.After.CustomAlias).To(Equal("new-alias"))
+		Expect(entry.After.IsActive).To(BeFalse())
+	})
+
+	It("records creates with only an after snapshot", func() {
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com/new"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Action).To(Equal(domain.AuditActionCreate))
+		Expect(entries[0].EntityID).To(Equal(link.ID))
+		Expect(entries[0].Before).To(BeNil())
+		Expect(entries[0].After.Code).To(Equal(link.Code))
+	})
+
+	It("records deletes with only a before snapshot", func() {
+		Expect(svc.DeleteShortLink(ctx, "link-1")).To(Succeed())
+
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Action).To(Equal(domain.AuditActionDelete))
+		Expect(entries[0].Before.ID).To(Equal("link-1"))
+		Expect(entries[0].After).To(BeNil())
+	})
+
+	It("leaves the actor empty without an authenticated user", func() {
+		inactive := false
+		_, err := svc.UpdateShortLink(context.Background(), "link-1", &domain.UpdateShortLinkRequest{IsActive: &inactive})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].UserID).To(BeNil())
+	})
+
+	It("returns a link's history by code", func() {
+		inactive := false
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{IsActive: &inactive})
+		Expect(err).NotTo(HaveOccurred())
+
+		history, err := svc.GetLinkHistory(ctx, "abc123")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history).To(HaveLen(1))
+		Expect(history[0].Action).To(Equal(domain.AuditActionUpdate))
+	})
+})
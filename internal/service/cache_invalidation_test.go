@@ -0,0 +1,314 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("CachedURLShortenerService URL updates", func() {
+	var (
+		mockURLRepo       *mocks.MockURLRepository
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		store             *cache.MemoryCache
+		svc               *service.CachedURLShortenerService
+		destination       string
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		destination = "https://example.com/old"
+		alias := "promo"
+		links := map[string]*domain.ShortLink{
+			"abc123": {ID: "link-1", Code: "abc123", URLID: "url-1", IsActive: true},
+			"def456": {ID: "link-2", Code: "def456", URLID: "url-1", IsActive: true, CustomAlias: &alias},
+			"zzz999": {ID: "link-3", Code: "zzz999", URLID: "url-2", IsActive: true},
+		}
+
+		mockURLRepo = &mocks.MockURLRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+				original := destination
+				if id != "url-1" {
+					original = "https://example.com/other"
+				}
+				return &domain.URL{ID: id, OriginalURL: original}, nil
+			},
+			UpdateFunc: func(ctx context.Context, url *domain.URL) error {
+				destination = url.OriginalURL
+				return nil
+			},
+		}
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+				if alias == "promo" {
+					link := *links["def456"]
+					return &link, nil
+				}
+				return nil, errors.New("short link not found")
+			},
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				link := *links[code]
+				return &link, nil
+			},
+			GetAllByURLIDFunc: func(ctx context.Context, urlID string) ([]*domain.ShortLink, error) {
+				var result []*domain.ShortLink
+				for _, link := range links {
+					if link.URLID == urlID {
+						result = append(result, link)
+					}
+				}
+				return result, nil
+			},
+		}
+
+		base := service.NewURLShortenerService(
+			mockURLRepo,
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+		store = cache.NewMemoryCache()
+		svc = service.NewCachedURLShortenerService(base, store, zaptest.NewLogger(GinkgoT()))
+	})
+
+	It("should evict every cached short link referencing the URL", func() {
+		for _, code := range []string{"abc123", "promo", "zzz999"} {
+			_, err := svc.GetShortLinkByCode(ctx, code)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		_, err := svc.UpdateURL(ctx, "url-1", "https://example.com/new")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, key := range []string{"abc123", "id:link-1", "promo", "id:link-2"} {
+			_, found := store.Get(key)
+			Expect(found).To(BeFalse(), key)
+		}
+		_, found := store.Get("zzz999")
+		Expect(found).To(BeTrue())
+	})
+
+	It("should serve the new destination after the update", func() {
+		link, err := svc.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/old"))
+
+		_, err = svc.UpdateURL(ctx, "url-1", "https://example.com/new")
+		Expect(err).NotTo(HaveOccurred())
+
+		link, err = svc.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/new"))
+	})
+
+	It("should reject an invalid destination without touching the cache", func() {
+		_, err := svc.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.UpdateURL(ctx, "url-1", "ftp://example.com/file")
+		Expect(err).To(HaveOccurred())
+
+		_, found := store.Get("abc123")
+		Expect(found).To(BeTrue())
+	})
+})
+
+var _ = Describe("CachedURLShortenerService InvalidateLink", func() {
+	var (
+		store       *cache.MemoryCache
+		svc         *service.CachedURLShortenerService
+		destination string
+		dbReads     int
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		destination = "https://example.com/old"
+		dbReads = 0
+		alias := "promo"
+
+		base := service.NewURLShortenerService(
+			&mocks.MockURLRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: destination}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCustomAliasFunc: func(ctx context.Context, a string) (*domain.ShortLink, error) {
+					if a == alias {
+						dbReads++
+						return &domain.ShortLink{ID: "link-1", Code: "abc123", URLID: "url-1", CustomAlias: &alias}, nil
+					}
+					return nil, errors.New("short link not found")
+				},
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if code == "abc123" {
+						dbReads++
+						return &domain.ShortLink{ID: "link-1", Code: "abc123", URLID: "url-1", CustomAlias: &alias}, nil
+					}
+					return nil, errors.New("short link not found: sql: no rows in result set")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					dbReads++
+					return &domain.ShortLink{ID: id, Code: "abc123", URLID: "url-1", CustomAlias: &alias}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			0,
+		)
+		store = cache.NewMemoryCache()
+		svc = service.NewCachedURLShortenerService(base, store, zaptest.NewLogger(GinkgoT()))
+
+		for _, code := range []string{"abc123", "promo"} {
+			_, err := svc.GetShortLinkByCode(ctx, code)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := svc.GetShortLink(ctx, "link-1")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should drop the entries keyed by ID, code and alias", func() {
+		Expect(svc.InvalidateLink(ctx, "abc123")).To(Succeed())
+
+		for _, key := range []string{"abc123", "promo", "id:link-1"} {
+			_, found := store.Get(key)
+			Expect(found).To(BeFalse(), key)
+		}
+	})
+
+	It("should accept the alias as well", func() {
+		Expect(svc.InvalidateLink(ctx, "promo")).To(Succeed())
+
+		for _, key := range []string{"abc123", "promo", "id:link-1"} {
+			_, found := store.Get(key)
+			Expect(found).To(BeFalse(), key)
+		}
+	})
+
+	It("should repopulate from the database on the next read", func() {
+		// Simulate a manual edit the cache knows nothing about
+		destination = "https://example.com/edited"
+		link, err := svc.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/old"))
+
+		Expect(svc.InvalidateLink(ctx, "abc123")).To(Succeed())
+		reads := dbReads
+
+		link, err = svc.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/edited"))
+		Expect(dbReads).To(Equal(reads + 1))
+
+		// And caches it again
+		_, found := store.Get("abc123")
+		Expect(found).To(BeTrue())
+	})
+
+	It("should report an unknown code as not found", func() {
+		Expect(svc.InvalidateLink(ctx, "nope")).To(MatchError(domain.ErrNotFound))
+	})
+})
+
+var _ = Describe("CachedURLShortenerService alias entries", func() {
+	var (
+		stored *domain.ShortLink
+		svc    *service.CachedURLShortenerService
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		alias := "promo"
+		stored = &domain.ShortLink{ID: "link-1", Code: "abc123", URLID: "url-1", IsActive: true, CustomAlias: &alias}
+
+		mockShortLinkRepo := &mocks.MockShortLinkRepository{
+			GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+				if stored == nil || stored.CustomAlias == nil || *stored.CustomAlias != alias {
+					return nil, errors.New("short link not found")
+				}
+				link := *stored
+				return &link, nil
+			},
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if stored == nil || stored.Code != code {
+					return nil, errors.New("short link not found")
+				}
+				link := *stored
+				return &link, nil
+			},
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+				if stored == nil {
+					return nil, errors.New("short link not found")
+				}
+				link := *stored
+				return &link, nil
+			},
+			UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+				updated := *link
+				stored = &updated
+				return nil
+			},
+			DeleteFunc: func(ctx context.Context, id string) error {
+				stored = nil
+				return nil
+			},
+		}
+
+		base := service.NewURLShortenerService(
+			&mocks.MockURLRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com"}, nil
+				},
+			},
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+		svc = service.NewCachedURLShortenerService(base, cache.NewMemoryCache(), zaptest.NewLogger(GinkgoT()))
+	})
+
+	It("should stop resolving the alias of a deleted link", func() {
+		link, err := svc.GetShortLinkByCode(ctx, "promo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.ID).To(Equal("link-1"))
+
+		Expect(svc.DeleteShortLink(ctx, "link-1")).To(Succeed())
+
+		_, err = svc.GetShortLinkByCode(ctx, "promo")
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should stop resolving the old alias of a renamed link", func() {
+		_, err := svc.GetShortLinkByCode(ctx, "promo")
+		Expect(err).NotTo(HaveOccurred())
+
+		alias := "launch"
+		_, err = svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{CustomAlias: &alias})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.GetShortLinkByCode(ctx, "promo")
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+
+		link, err := svc.GetShortLinkByCode(ctx, "launch")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.ID).To(Equal("link-1"))
+	})
+})
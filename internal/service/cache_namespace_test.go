@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("CachedURLShortenerService namespace", func() {
+	var (
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		baseService       *service.URLShortenerService
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: "link-1", Code: code, IsActive: true}, nil
+			},
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+				return &domain.ShortLink{ID: id, Code: "abc123", IsActive: true}, nil
+			},
+			DeleteFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+		}
+		baseService = service.NewURLShortenerService(
+			&mocks.MockURLRepository{},
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+	})
+
+	It("should prefix every cache key with the namespace", func() {
+		var keys []string
+		mockCache := &mocks.MockCache{
+			SetFunc: func(key string, value interface{}, ttl int) {
+				keys = append(keys, key)
+			},
+		}
+		svc := service.NewCachedURLShortenerServiceWithNamespace(baseService, mockCache, zaptest.NewLogger(GinkgoT()), "prod:shortener:")
+
+		_, err := svc.GetShortLinkByCode(ctx, "abc123")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(ConsistOf("prod:shortener:abc123", "prod:shortener:id:link-1"))
+	})
+
+	It("should invalidate using the namespaced keys", func() {
+		var deleted []string
+		mockCache := &mocks.MockCache{
+			DeleteFunc: func(key string) {
+				deleted = append(deleted, key)
+			},
+		}
+		svc := service.NewCachedURLShortenerServiceWithNamespace(baseService, mockCache, zaptest.NewLogger(GinkgoT()), "prod:shortener:")
+
+		Expect(svc.DeleteShortLink(ctx, "link-1")).To(Succeed())
+
+		Expect(deleted).To(ConsistOf("prod:shortener:abc123", "prod:shortener:id:link-1"))
+	})
+
+	It("should keep entries of different namespaces apart in a shared cache", func() {
+		shared := cache.NewMemoryCache()
+		staging := service.NewCachedURLShortenerServiceWithNamespace(baseService, shared, zaptest.NewLogger(GinkgoT()), "staging:shortener:")
+		prod := service.NewCachedURLShortenerServiceWithNamespace(baseService, shared, zaptest.NewLogger(GinkgoT()), "prod:shortener:")
+
+		stagingLink := &domain.ShortLink{ID: "staging-id", Code: "abc123", IsActive: true}
+		shared.Set("staging:shortener:abc123", stagingLink, 0)
+
+		link, err := prod.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.ID).To(Equal("link-1"))
+
+		link, err = staging.GetShortLinkByCode(ctx, "abc123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(BeIdenticalTo(stagingLink))
+
+		Expect(prod.DeleteShortLink(ctx, "link-1")).To(Succeed())
+		_, found := shared.Get("staging:shortener:abc123")
+		Expect(found).To(BeTrue())
+	})
+})
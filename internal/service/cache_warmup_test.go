@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("CachedURLShortenerService warm-up", func() {
+	var (
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		store             *cache.MemoryCache
+		svc               *service.CachedURLShortenerService
+		requestedLimit    int
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requestedLimit = 0
+		alias := "promo"
+		top := []*domain.ShortLink{
+			{ID: "link-1", Code: "abc123", IsActive: true, URL: &domain.URL{OriginalURL: "https://example.com/1"}},
+			{ID: "link-2", Code: "def456", IsActive: true, CustomAlias: &alias, URL: &domain.URL{OriginalURL: "https://example.com/2"}},
+			{ID: "link-3", Code: "ghi789", IsActive: true, URL: &domain.URL{OriginalURL: "https://example.com/3"}},
+		}
+
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			ListMostClickedFunc: func(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error) {
+				requestedLimit = limit
+				if limit < len(top) {
+					return top[:limit], nil
+				}
+				return top, nil
+			},
+		}
+
+		base := service.NewURLShortenerService(
+			&mocks.MockURLRepository{},
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+		store = cache.NewMemoryCache()
+		svc = service.NewCachedURLShortenerServiceWithNamespace(base, store, zaptest.NewLogger(GinkgoT()), "test:")
+	})
+
+	It("should load the top links into the cache", func() {
+		loaded, err := svc.WarmUp(ctx, 2)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(2))
+		Expect(requestedLimit).To(Equal(2))
+		for _, key := range []string{"test:abc123", "test:id:link-1", "test:def456", "test:promo", "test:id:link-2"} {
+			_, found := store.Get(key)
+			Expect(found).To(BeTrue(), key)
+		}
+		_, found := store.Get("test:ghi789")
+		Expect(found).To(BeFalse())
+	})
+
+	It("should serve warmed links without hitting the repository", func() {
+		_, err := svc.WarmUp(ctx, 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		mockShortLinkRepo.GetByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+			return nil, errors.New("should not be called")
+		}
+
+		link, err := svc.GetShortLinkByCode(ctx, "ghi789")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/3"))
+	})
+
+	It("should do nothing when no links are requested", func() {
+		loaded, err := svc.WarmUp(ctx, 0)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(BeZero())
+		Expect(store.GetStats().Size).To(BeZero())
+	})
+
+	It("should return repository errors", func() {
+		mockShortLinkRepo.ListMostClickedFunc = func(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error) {
+			return nil, errors.New("database error")
+		}
+
+		_, err := svc.WarmUp(ctx, 10)
+		Expect(err).To(MatchError(ContainSubstring("database error")))
+	})
+})
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// maxCampaignParamLength caps each stored campaign param, which visitors
+// control, so odd links can't bloat the click table
+const maxCampaignParamLength = 200
+
+// setCampaign stores the utm_* params of a short link request's query
+// string on the click. Malformed query strings keep whatever parsed.
+func setCampaign(click *domain.LinkClick, rawQuery string) {
+	if rawQuery == "" {
+		return
+	}
+
+	query, _ := url.ParseQuery(rawQuery)
+
+	for _, param := range []struct {
+		name string
+		dest **string
+	}{
+		{"utm_source", &click.UTMSource},
+		{"utm_medium", &click.UTMMedium},
+		{"utm_campaign", &click.UTMCampaign},
+		{"utm_term", &click.UTMTerm},
+		{"utm_content", &click.UTMContent},
+	} {
+		value := strings.TrimSpace(query.Get(param.name))
+		if value == "" {
+			continue
+		}
+
+		if len(value) > maxCampaignParamLength {
+			value = value[:maxCampaignParamLength]
+			// Don't leave half a character behind
+			for !utf8.ValidString(value) {
+				value = value[:len(value)-1]
+			}
+		}
+		*param.dest = &value
+	}
+}
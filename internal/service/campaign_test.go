@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Click campaign params", func() {
+	var (
+		ctx    context.Context
+		mu     sync.Mutex
+		clicks []*domain.LinkClick
+	)
+
+	newService := func(campaignParams bool) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					mu.Lock()
+					defer mu.Unlock()
+					clicks = append(clicks, click)
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{CampaignParams: campaignParams},
+		)
+	}
+
+	recorded := func() []*domain.LinkClick {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*domain.LinkClick(nil), clicks...)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clicks = nil
+	})
+
+	It("should store the utm params of the short link request", func() {
+		svc := newService(true)
+
+		Expect(svc.RecordClick(ctx, "link-1", "https://mail.example", "", "203.0.113.7",
+			"utm_source=newsletter&utm_medium=email&utm_campaign=spring+sale&utm_term=shoes&utm_content=hero&ref=x")).To(Succeed())
+
+		Eventually(recorded).Should(HaveLen(1))
+		click := recorded()[0]
+		Expect(click.UTMSource).To(HaveValue(Equal("newsletter")))
+		Expect(click.UTMMedium).To(HaveValue(Equal("email")))
+		Expect(click.UTMCampaign).To(HaveValue(Equal("spring sale")))
+		Expect(click.UTMTerm).To(HaveValue(Equal("shoes")))
+		Expect(click.UTMContent).To(HaveValue(Equal("hero")))
+		Expect(click.Referrer).To(HaveValue(Equal("https://mail.example")))
+	})
+
+	It("should leave out missing and blank params", func() {
+		svc := newService(true)
+
+		Expect(svc.RecordClick(ctx, "link-1", "", "", "203.0.113.7", "utm_source=twitter&utm_medium=+")).To(Succeed())
+
+		Eventually(recorded).Should(HaveLen(1))
+		click := recorded()[0]
+		Expect(click.UTMSource).To(HaveValue(Equal("twitter")))
+		Expect(click.UTMMedium).To(BeNil())
+		Expect(click.UTMCampaign).To(BeNil())
+	})
+
+	It("should cap long values without splitting a character", func() {
+		svc := newService(true)
+
+		Expect(svc.RecordClick(ctx, "link-1", "", "", "203.0.113.7", "utm_campaign=a"+strings.Repeat("é", 150))).To(Succeed())
+
+		Eventually(recorded).Should(HaveLen(1))
+		Expect(*recorded()[0].UTMCampaign).To(Equal("a" + strings.Repeat("é", 99)))
+	})
+
+	It("should not store them when disabled", func() {
+		svc := newService(false)
+
+		Expect(svc.RecordClick(ctx, "link-1", "", "", "203.0.113.7", "utm_source=newsletter")).To(Succeed())
+
+		Eventually(recorded).Should(HaveLen(1))
+		Expect(recorded()[0].UTMSource).To(BeNil())
+	})
+})
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Fields a click beacon can carry
+const (
+	ClickBeaconShortLinkID = "short_link_id"
+	ClickBeaconClickedAt   = "clicked_at"
+	ClickBeaconReferrer    = "referrer"
+	ClickBeaconUserAgent   = "user_agent"
+	ClickBeaconIPAddress   = "ip_address"
+	ClickBeaconBrowser     = "browser"
+	ClickBeaconOS          = "os"
+	ClickBeaconDevice      = "device"
+	ClickBeaconQuery       = "query"
+)
+
+// defaultClickBeaconFields are sent when no fields are configured
+var defaultClickBeaconFields = []string{
+	ClickBeaconShortLinkID,
+	ClickBeaconClickedAt,
+	ClickBeaconReferrer,
+	ClickBeaconUserAgent,
+}
+
+// defaultClickBeaconTimeout bounds a single beacon when no timeout is configured
+const defaultClickBeaconTimeout = 2 * time.Second
+
+// maxClickBeaconsInFlight bounds how many beacons are sent at once. A slow
+// analytics endpoint costs dropped beacons rather than piling up goroutines.
+const maxClickBeaconsInFlight = 64
+
+// ClickBeacon is the payload sent to an analytics endpoint for a click.
+// Only the configured fields are set; the IP address is anonymized like a
+// stored click's.
+type ClickBeacon struct {
+	ShortLinkID string     `json:"short_link_id,omitempty"`
+	ClickedAt   *time.Time `json:"clicked_at,omitempty"`
+	Referrer    string     `json:"referrer,omitempty"`
+	UserAgent   string     `json:"user_agent,omitempty"`
+	IPAddress   string     `json:"ip_address,omitempty"`
+	Browser     string     `json:"browser,omitempty"`
+	OS          string     `json:"os,omitempty"`
+	Device      string     `json:"device,omitempty"`
+	Query       string     `json:"query,omitempty"`
+}
+
+// ClickBeaconFunc delivers a click beacon, giving up when ctx is done
+type ClickBeaconFunc func(ctx context.Context, beacon ClickBeacon) error
+
+// ClickBeaconWebhook returns a ClickBeaconFunc that POSTs each beacon as
+// JSON to url
+func ClickBeaconWebhook(url string) ClickBeaconFunc {
+	client := &http.Client{}
+
+	return func(ctx context.Context, beacon ClickBeacon) error {
+		body, err := json.Marshal(beacon)
+		if err != nil {
+			return fmt.Errorf("encoding beacon: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("building request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("posting beacon: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("analytics endpoint responded with status %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// clickBeaconSender sends beacons off the click path, each with its own
+// deadline, so a slow or failing analytics endpoint never holds up or
+// breaks a redirect
+type clickBeaconSender struct {
+	send    ClickBeaconFunc
+	fields  []string
+	timeout time.Duration
+	slots   chan struct{}
+	logger  *zap.Logger
+}
+
+// newClickBeaconSender creates a sender of beacons carrying fields, or the
+// default ones when empty
+func newClickBeaconSender(send ClickBeaconFunc, fields []string, timeout time.Duration, logger *zap.Logger) *clickBeaconSender {
+	if len(fields) == 0 {
+		fields = defaultClickBeaconFields
+	}
+	if timeout <= 0 {
+		timeout = defaultClickBeaconTimeout
+	}
+
+	return &clickBeaconSender{
+		send:    send,
+		fields:  fields,
+		timeout: timeout,
+		slots:   make(chan struct{}, maxClickBeaconsInFlight),
+		logger:  logger,
+	}
+}
+
+// has reports whether beacons carry field
+func (b *clickBeaconSender) has(field string) bool {
+	return slices.Contains(b.fields, field)
+}
+
+// Send delivers beacon in the background. Beacons are dropped while too
+// many are in flight; failures are logged and not retried.
+func (b *clickBeaconSender) Send(beacon ClickBeacon) {
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		b.logger.Debug("Dropped click beacon, too many in flight",
+			zap.String("short_link_id", beacon.ShortLinkID),
+		)
+		return
+	}
+
+	go func() {
+		defer func() { <-b.slots }()
+		defer func() {
+			if r := recover(); r != nil {
+				b.logger.Error("Click beacon panicked", zap.Any("panic", r))
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+		defer cancel()
+
+		if err := b.send(ctx, beacon); err != nil {
+			b.logger.Warn("Failed to send click beacon",
+				zap.String("short_link_id", beacon.ShortLinkID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// sendClickBeacon builds the beacon for a click from the configured fields
+// and sends it
+func (s *URLShortenerService) sendClickBeacon(shortLinkID, referrer, userAgent, ipAddress, rawQuery string, now time.Time) {
+	b := s.clickBeacon
+	var beacon ClickBeacon
+
+	if b.has(ClickBeaconShortLinkID) {
+		beacon.ShortLinkID = shortLinkID
+	}
+	if b.has(ClickBeaconClickedAt) {
+		beacon.ClickedAt = &now
+	}
+	if b.has(ClickBeaconReferrer) {
+		beacon.Referrer = referrer
+	}
+	if b.has(ClickBeaconUserAgent) {
+		beacon.UserAgent = userAgent
+	}
+	if b.has(ClickBeaconIPAddress) {
+		beacon.IPAddress = anonymizeIP(ipAddress, s.opts.IPAnonymization, s.opts.IPHashSalt)
+	}
+	if b.has(ClickBeaconBrowser) || b.has(ClickBeaconOS) || b.has(ClickBeaconDevice) {
+		browser, os, device := parseUserAgent(userAgent)
+		if b.has(ClickBeaconBrowser) {
+			beacon.Browser = browser
+		}
+		if b.has(ClickBeaconOS) {
+			beacon.OS = os
+		}
+		if b.has(ClickBeaconDevice) {
+			beacon.Device = device
+		}
+	}
+	if b.has(ClickBeaconQuery) {
+		beacon.Query = rawQuery
+	}
+
+	b.Send(beacon)
+}
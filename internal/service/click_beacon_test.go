@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Click beacons", func() {
+	const userAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148 Safari/604.1"
+
+	var (
+		ctx     context.Context
+		stored  chan string
+		beacons chan map[string]interface{}
+		status  int
+		server  *httptest.Server
+	)
+
+	newService := func(opts service.Options) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					stored <- click.ShortLinkID
+					return nil
+				},
+				AddSampledOutFunc: func(ctx context.Context, shortLinkID string, n int) error {
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			opts,
+		)
+	}
+
+	click := func(svc *service.URLShortenerService) {
+		Expect(svc.RecordClick(ctx, "link-1", "https://news.example", userAgent, "203.0.113.7", "utm_source=mail")).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = make(chan string, 10)
+		beacons = make(chan map[string]interface{}, 10)
+		status = http.StatusNoContent
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			var beacon map[string]interface{}
+			Expect(json.Unmarshal(body, &beacon)).To(Succeed())
+			beacons <- beacon
+
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should post the default payload to the analytics endpoint", func() {
+		svc := newService(service.Options{ClickBeacon: service.ClickBeaconWebhook(server.URL)})
+
+		click(svc)
+
+		var beacon map[string]interface{}
+		Eventually(beacons).Should(Receive(&beacon))
+		Expect(beacon).To(HaveLen(4))
+		Expect(beacon).To(HaveKeyWithValue("short_link_id", "link-1"))
+		Expect(beacon).To(HaveKeyWithValue("referrer", "https://news.example"))
+		Expect(beacon).To(HaveKeyWithValue("user_agent", userAgent))
+		Expect(beacon).To(HaveKey("clicked_at"))
+
+		clickedAt, err := time.Parse(time.RFC3339Nano, beacon["clicked_at"].(string))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clickedAt).To(BeTemporally("~", time.Now(), 5*time.Second))
+
+		// Our own recording happens as well
+		Eventually(stored).Should(Receive(Equal("link-1")))
+	})
+
+	It("should only carry the configured fields, with the IP anonymized", func() {
+		svc := newService(service.Options{
+			ClickBeacon:       service.ClickBeaconWebhook(server.URL),
+			ClickBeaconFields: []string{"short_link_id", "ip_address", "device", "query"},
+			IPAnonymization:   "truncate",
+		})
+
+		click(svc)
+
+		var beacon map[string]interface{}
+		Eventually(beacons).Should(Receive(&beacon))
+		Expect(beacon).To(Equal(map[string]interface{}{
+			"short_link_id": "link-1",
+			"ip_address":    "203.0.113.0",
+			"device":        "Mobile",
+			"query":         "utm_source=mail",
+		}))
+	})
+
+	It("should send beacons for clicks sampling leaves out", func() {
+		svc := newService(service.Options{
+			ClickBeacon:     service.ClickBeaconWebhook(server.URL),
+			ClickSampleRate: 3,
+		})
+
+		for i := 0; i < 3; i++ {
+			click(svc)
+		}
+
+		Eventually(func() int { return len(beacons) }).Should(Equal(3))
+		Eventually(stored).Should(Receive())
+		Consistently(stored, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should keep recording clicks when the endpoint fails", func() {
+		status = http.StatusInternalServerError
+		svc := newService(service.Options{ClickBeacon: service.ClickBeaconWebhook(server.URL)})
+
+		click(svc)
+
+		Eventually(beacons).Should(Receive())
+		Eventually(stored).Should(Receive(Equal("link-1")))
+	})
+
+	It("should give each beacon its own deadline", func() {
+		deadlines := make(chan time.Duration, 1)
+		svc := newService(service.Options{
+			ClickBeacon: func(ctx context.Context, beacon service.ClickBeacon) error {
+				deadline, _ := ctx.Deadline()
+				deadlines <- time.Until(deadline)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			ClickBeaconTimeout: 50 * time.Millisecond,
+		})
+
+		start := time.Now()
+		click(svc)
+		Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+
+		Eventually(deadlines).Should(Receive(BeNumerically("~", 50*time.Millisecond, 25*time.Millisecond)))
+		Eventually(stored).Should(Receive(Equal("link-1")))
+	})
+
+	It("should contain a beacon that panics", func() {
+		svc := newService(service.Options{
+			ClickBeacon: func(ctx context.Context, beacon service.ClickBeacon) error {
+				panic(errors.New("analytics client bug"))
+			},
+		})
+
+		click(svc)
+
+		Eventually(stored).Should(Receive(Equal("link-1")))
+	})
+})
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// clickDeduper remembers when each client last clicked each link so repeat
+// clicks inside the window, from double-clicks or prefetching browsers, can
+// be dropped
+type clickDeduper struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+// newClickDeduper creates a deduper that suppresses clicks repeated within window
+func newClickDeduper(window time.Duration) *clickDeduper {
+	return &clickDeduper{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Allow reports whether a click on shortLinkID from ipAddress should be
+// recorded, and remembers it when it is
+func (d *clickDeduper) Allow(shortLinkID, ipAddress string, now time.Time) bool {
+	key := shortLinkID + "|" + ipAddress
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Drop stale entries now and then so the map only holds recent clients
+	if now.Sub(d.lastSweep) >= d.window {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	if last, ok := d.seen[key]; ok && now.Sub(last) < d.window {
+		return false
+	}
+	d.seen[key] = now
+
+	return true
+}
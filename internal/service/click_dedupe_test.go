@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Click deduplication", func() {
+	var (
+		clicks chan *domain.LinkClick
+		ctx    context.Context
+	)
+
+	newService := func(window time.Duration) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					clicks <- click
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:           "https://short.example.com",
+				ClickDedupeWindow: window,
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clicks = make(chan *domain.LinkClick, 10)
+	})
+
+	It("should drop a second click from the same IP within the window", func() {
+		svc := newService(time.Minute)
+
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+
+		Eventually(clicks).Should(Receive())
+		Consistently(clicks, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should record a click once the window has passed", func() {
+		svc := newService(50 * time.Millisecond)
+
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+		Eventually(clicks).Should(Receive())
+
+		time.Sleep(60 * time.Millisecond)
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+		Eventually(clicks).Should(Receive())
+	})
+
+	It("should record clicks from other IPs and on other links", func() {
+		svc := newService(time.Minute)
+
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "198.51.100.2", "")).To(Succeed())
+		Expect(svc.RecordClick(ctx, "link-456", "", "", "203.0.113.7", "")).To(Succeed())
+
+		for range 3 {
+			Eventually(clicks).Should(Receive())
+		}
+	})
+
+	It("should record every click when the window is zero", func() {
+		svc := newService(0)
+
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+		Expect(svc.RecordClick(ctx, "link-123", "", "", "203.0.113.7", "")).To(Succeed())
+
+		Eventually(clicks).Should(Receive())
+		Eventually(clicks).Should(Receive())
+	})
+})
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// clickRateBuckets is how many slices a click rate window is counted in.
+// Each tracked link holds this many counters, whatever its traffic.
+const clickRateBuckets = 10
+
+// maxClickRateLinks bounds how many links the click rate detector follows
+// at once. Links beyond it go untracked until idle ones are swept.
+const maxClickRateLinks = 10000
+
+// clickRateWebhookTimeout bounds a single webhook delivery
+const clickRateWebhookTimeout = 5 * time.Second
+
+// ClickRateAlert describes a link whose clicks within Window reached the
+// configured threshold
+type ClickRateAlert struct {
+	ShortLinkID string        `json:"short_link_id"`
+	Clicks      int           `json:"clicks"`
+	Window      time.Duration `json:"-"`
+	At          time.Time     `json:"at"`
+}
+
+// MarshalJSON adds the window in seconds, which is friendlier to webhook
+// consumers than nanoseconds
+func (a ClickRateAlert) MarshalJSON() ([]byte, error) {
+	type alert ClickRateAlert
+	return json.Marshal(struct {
+		alert
+		WindowSeconds int `json:"window_seconds"`
+	}{alert(a), int(a.Window.Seconds())})
+}
+
+// ClickRateAlertFunc is called once each time a link's click rate crosses
+// the threshold. It runs on its own goroutine, off the redirect path.
+type ClickRateAlertFunc func(alert ClickRateAlert)
+
+// linkClickRate counts one link's recent clicks in a ring of buckets
+type linkClickRate struct {
+	counts  [clickRateBuckets]int
+	indexes [clickRateBuckets]int64 // Bucket number each count belongs to
+	latest  int64
+	alerted bool // Set while the link stays above the threshold
+}
+
+// clickRateDetector tracks recent click counts per link and reports when a
+// link's count within the window reaches the threshold. Memory is bounded:
+// each link costs a fixed number of counters and at most maxLinks are held.
+type clickRateDetector struct {
+	threshold  int
+	window     time.Duration
+	bucketSize time.Duration
+	maxLinks   int
+
+	mu        sync.Mutex
+	links     map[string]*linkClickRate
+	lastSweep time.Time
+}
+
+// newClickRateDetector creates a detector for threshold clicks within window
+func newClickRateDetector(threshold int, window time.Duration) *clickRateDetector {
+	bucketSize := window / clickRateBuckets
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	return &clickRateDetector{
+		threshold:  threshold,
+		window:     window,
+		bucketSize: bucketSize,
+		maxLinks:   maxClickRateLinks,
+		links:      make(map[string]*linkClickRate),
+	}
+}
+
+// Record counts a click on shortLinkID and returns an alert when it takes
+// the link to the threshold. Further clicks don't alert again until the
+// link's rate has dropped below the threshold.
+func (d *clickRateDetector) Record(shortLinkID string, now time.Time) (ClickRateAlert, bool) {
+	bucket := now.UnixNano() / int64(d.bucketSize)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Forget links without clicks in the window now and then
+	if now.Sub(d.lastSweep) >= d.window {
+		for id, rate := range d.links {
+			if bucket-rate.latest >= clickRateBuckets {
+				delete(d.links, id)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	rate, ok := d.links[shortLinkID]
+	if !ok {
+		if len(d.links) >= d.maxLinks {
+			return ClickRateAlert{}, false
+		}
+		rate = &linkClickRate{}
+		d.links[shortLinkID] = rate
+	}
+
+	slot := bucket % clickRateBuckets
+	if rate.indexes[slot] != bucket {
+		rate.indexes[slot] = bucket
+		rate.counts[slot] = 0
+	}
+	rate.counts[slot]++
+	rate.latest = bucket
+
+	clicks := 0
+	for i, count := range rate.counts {
+		if bucket-rate.indexes[i] < clickRateBuckets {
+			clicks += count
+		}
+	}
+
+	if clicks < d.threshold {
+		rate.alerted = false
+		return ClickRateAlert{}, false
+	}
+	if rate.alerted {
+		return ClickRateAlert{}, false
+	}
+	rate.alerted = true
+
+	return ClickRateAlert{
+		ShortLinkID: shortLinkID,
+		Clicks:      clicks,
+		Window:      d.window,
+		At:          now,
+	}, true
+}
+
+// ClickRateWebhook returns a ClickRateAlertFunc that POSTs each alert as
+// JSON to url. Delivery failures are logged and not retried.
+func ClickRateWebhook(url string, logger *zap.Logger) ClickRateAlertFunc {
+	client := &http.Client{Timeout: clickRateWebhookTimeout}
+
+	return func(alert ClickRateAlert) {
+		if err := postClickRateAlert(client, url, alert); err != nil {
+			logger.Warn("Failed to deliver click rate alert",
+				zap.String("short_link_id", alert.ShortLinkID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// postClickRateAlert delivers a single alert to a webhook
+func postClickRateAlert(client *http.Client, url string, alert ClickRateAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("encoding alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clickRateWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Click rate alerts", func() {
+	var (
+		alerts chan service.ClickRateAlert
+		ctx    context.Context
+	)
+
+	newService := func(threshold int, window time.Duration) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:            "https://short.example.com",
+				ClickRateThreshold: threshold,
+				ClickRateWindow:    window,
+				OnClickRateAlert: func(alert service.ClickRateAlert) {
+					alerts <- alert
+				},
+			},
+		)
+	}
+
+	click := func(svc *service.URLShortenerService, shortLinkID string, times int) {
+		for range times {
+			Expect(svc.RecordClick(ctx, shortLinkID, "", "", "203.0.113.7", "")).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		alerts = make(chan service.ClickRateAlert, 10)
+	})
+
+	It("should not alert below the threshold", func() {
+		svc := newService(5, time.Minute)
+
+		click(svc, "link-123", 4)
+
+		Consistently(alerts, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should alert once when the threshold is crossed", func() {
+		svc := newService(5, time.Minute)
+
+		click(svc, "link-123", 12)
+
+		var alert service.ClickRateAlert
+		Eventually(alerts).Should(Receive(&alert))
+		Expect(alert.ShortLinkID).To(Equal("link-123"))
+		Expect(alert.Clicks).To(Equal(5))
+		Expect(alert.Window).To(Equal(time.Minute))
+		Consistently(alerts, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should count each link separately", func() {
+		svc := newService(5, time.Minute)
+
+		click(svc, "link-123", 3)
+		click(svc, "link-456", 3)
+
+		Consistently(alerts, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should alert again after the rate drops and rises", func() {
+		svc := newService(3, 100*time.Millisecond)
+
+		click(svc, "link-123", 3)
+		Eventually(alerts).Should(Receive())
+
+		time.Sleep(150 * time.Millisecond)
+		click(svc, "link-123", 1)
+		click(svc, "link-123", 2)
+		Eventually(alerts).Should(Receive())
+	})
+
+	It("should not track clicks when the threshold is zero", func() {
+		svc := newService(0, time.Minute)
+
+		click(svc, "link-123", 20)
+
+		Consistently(alerts, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	Describe("ClickRateWebhook", func() {
+		It("should post the alert as JSON", func() {
+			bodies := make(chan map[string]interface{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+				var body map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				bodies <- body
+			}))
+			DeferCleanup(server.Close)
+
+			notify := service.ClickRateWebhook(server.URL, zaptest.NewLogger(GinkgoT()))
+			notify(service.ClickRateAlert{
+				ShortLinkID: "link-123",
+				Clicks:      5,
+				Window:      time.Minute,
+				At:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			})
+
+			var body map[string]interface{}
+			Eventually(bodies).Should(Receive(&body))
+			Expect(body).To(HaveKeyWithValue("short_link_id", "link-123"))
+			Expect(body).To(HaveKeyWithValue("clicks", BeNumerically("==", 5)))
+			Expect(body).To(HaveKeyWithValue("window_seconds", BeNumerically("==", 60)))
+			Expect(body).To(HaveKeyWithValue("at", "2024-01-01T12:00:00Z"))
+		})
+	})
+})
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// clickSampleRateTTL is how long a link's own sample rate is remembered
+// before it is read again, bounding how stale an updated rate can be on
+// other instances
+const clickSampleRateTTL = time.Minute
+
+// clickSampler picks which clicks are stored in detail. Each link keeps a
+// counter so exactly the first of every N clicks is stored, where N is the
+// link's own rate or else the global one.
+type clickSampler struct {
+	global int
+
+	mu        sync.Mutex
+	links     map[string]*sampledLink
+	lastSweep time.Time
+}
+
+// sampledLink is the sampling state of one link
+type sampledLink struct {
+	rate      int // the link's own rate; 0 uses the global rate
+	fetchedAt time.Time
+	lastClick time.Time
+	clicks    int
+}
+
+// newClickSampler creates a sampler storing 1 in global clicks of links
+// without a rate of their own
+func newClickSampler(global int) *clickSampler {
+	return &clickSampler{
+		global: max(global, 1),
+		links:  make(map[string]*sampledLink),
+	}
+}
+
+// knows reports whether the link's own rate was read recently enough to be used
+func (c *clickSampler) knows(shortLinkID string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	link, ok := c.links[shortLinkID]
+	return ok && now.Sub(link.fetchedAt) < clickSampleRateTTL
+}
+
+// setRate remembers the link's own rate; nil means it has none
+func (c *clickSampler) setRate(shortLinkID string, rate *int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	link := c.link(shortLinkID, now)
+	link.rate = 0
+	if rate != nil {
+		link.rate = *rate
+	}
+	link.fetchedAt = now
+}
+
+// forget drops the remembered rate of a link so the next click reads it again
+func (c *clickSampler) forget(shortLinkID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if link, ok := c.links[shortLinkID]; ok {
+		link.fetchedAt = time.Time{}
+	}
+}
+
+// Sample counts a click on the link and reports whether it should be stored,
+// along with the number of clicks the stored one stands for
+func (c *clickSampler) Sample(shortLinkID string, now time.Time) (bool, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop links nobody clicked for a while so the map only holds busy ones
+	if now.Sub(c.lastSweep) >= clickSampleRateTTL {
+		for id, link := range c.links {
+			if now.Sub(link.lastClick) >= clickSampleRateTTL {
+				delete(c.links, id)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	link := c.link(shortLinkID, now)
+	link.lastClick = now
+
+	rate := c.global
+	if link.rate > 0 {
+		rate = link.rate
+	}
+
+	store := link.clicks%rate == 0
+	link.clicks++
+
+	return store, rate
+}
+
+// link returns the state of a link, creating it when missing; c.mu must be held
+func (c *clickSampler) link(shortLinkID string, now time.Time) *sampledLink {
+	link, ok := c.links[shortLinkID]
+	if !ok {
+		link = &sampledLink{lastClick: now}
+		c.links[shortLinkID] = link
+	}
+	return link
+}
+
+// sampleClick reports whether a click on the link is stored in detail and
+// how many clicks the stored one stands for. The link's own rate is read
+// through the repository at most once per clickSampleRateTTL.
+func (s *URLShortenerService) sampleClick(ctx context.Context, shortLinkID string, now time.Time) (bool, int) {
+	if !s.clickSampler.knows(shortLinkID, now) {
+		var rate *int
+		link, err := s.linkRepo.GetByID(ctx, shortLinkID)
+		if err != nil {
+			// Fall back to the global rate rather than losing the click
+			s.logger.Debug("Failed to read click sample rate",
+				zap.String("short_link_id", shortLinkID),
+				zap.Error(err),
+			)
+		} else if link != nil {
+			rate = link.ClickSampleRate
+		}
+		s.clickSampler.setRate(shortLinkID, rate, now)
+	}
+
+	return s.clickSampler.Sample(shortLinkID, now)
+}
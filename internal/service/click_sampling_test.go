@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+// sampledClicks tallies what the click repository was asked to store per link
+type sampledClicks struct {
+	mu         sync.Mutex
+	rows       map[string]int
+	weights    map[string]int
+	sampledOut map[string]int
+}
+
+// total is the exact click total of a link: stored rows plus sampled out clicks
+func (c *sampledClicks) total(shortLinkID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rows[shortLinkID] + c.sampledOut[shortLinkID]
+}
+
+func (c *sampledClicks) stored(shortLinkID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rows[shortLinkID]
+}
+
+// scaled is the click total estimated from the stored rows' weights
+func (c *sampledClicks) scaled(shortLinkID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.weights[shortLinkID]
+}
+
+var _ = Describe("Click sampling", func() {
+	var (
+		ctx     context.Context
+		clicks  *sampledClicks
+		rates   map[string]*int
+		lookups int
+	)
+
+	intPtr := func(n int) *int { return &n }
+
+	newService := func(globalRate int) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					lookups++
+					return &domain.ShortLink{ID: id, ClickSampleRate: rates[id]}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					clicks.mu.Lock()
+					defer clicks.mu.Unlock()
+					clicks.rows[click.ShortLinkID]++
+					clicks.weights[click.ShortLinkID] += max(click.SampleWeight, 1)
+					return nil
+				},
+				AddSampledOutFunc: func(ctx context.Context, shortLinkID string, n int) error {
+					clicks.mu.Lock()
+					defer clicks.mu.Unlock()
+					clicks.sampledOut[shortLinkID] += n
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:         "https://short.example.com",
+				ClickSampleRate: globalRate,
+			},
+		)
+	}
+
+	record := func(svc *service.URLShortenerService, shortLinkID string, n int) {
+		for i := 0; i < n; i++ {
+			Expect(svc.RecordClick(ctx, shortLinkID, "https://news.example", "", "203.0.113.7", "")).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clicks = &sampledClicks{
+			rows:       map[string]int{},
+			weights:    map[string]int{},
+			sampledOut: map[string]int{},
+		}
+		rates = map[string]*int{}
+		lookups = 0
+	})
+
+	It("should store every click by default", func() {
+		svc := newService(0)
+
+		record(svc, "link-1", 25)
+
+		Eventually(func() int { return clicks.stored("link-1") }).Should(Equal(25))
+		Expect(clicks.scaled("link-1")).To(Equal(25))
+		Consistently(func() int { return clicks.total("link-1") }, 50*time.Millisecond).Should(Equal(25))
+	})
+
+	It("should keep the total exact while storing about 1 in N clicks", func() {
+		svc := newService(10)
+
+		record(svc, "link-1", 1000)
+
+		Eventually(func() int { return clicks.total("link-1") }).Should(Equal(1000))
+		Expect(clicks.stored("link-1")).To(BeNumerically("~", 100, 1))
+		Expect(clicks.scaled("link-1")).To(BeNumerically("~", 1000, 10))
+	})
+
+	It("should prefer a link's own rate over the global one", func() {
+		rates["busy"] = intPtr(4)
+		svc := newService(1)
+
+		record(svc, "busy", 40)
+		record(svc, "quiet", 40)
+
+		Eventually(func() int { return clicks.total("busy") + clicks.total("quiet") }).Should(Equal(80))
+		Expect(clicks.stored("busy")).To(Equal(10))
+		Expect(clicks.scaled("busy")).To(Equal(40))
+		Expect(clicks.stored("quiet")).To(Equal(40))
+	})
+
+	It("should read each link's rate once rather than per click", func() {
+		svc := newService(5)
+
+		record(svc, "link-1", 50)
+
+		Expect(lookups).To(Equal(1))
+		Eventually(func() int { return clicks.total("link-1") }).Should(Equal(50))
+	})
+
+	It("should fall back to the global rate when the link can't be read", func() {
+		svc := service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return nil, errors.New("connection refused")
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+					clicks.mu.Lock()
+					defer clicks.mu.Unlock()
+					clicks.rows[click.ShortLinkID]++
+					return nil
+				},
+			},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{ClickSampleRate: 2},
+		)
+
+		record(svc, "link-1", 10)
+
+		Eventually(func() int { return clicks.stored("link-1") }).Should(Equal(5))
+	})
+
+	Context("when setting a link's rate", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			svc = newService(1)
+		})
+
+		It("should reject a rate below 1 on create", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+				URL:             "https://example.com",
+				ClickSampleRate: intPtr(0),
+			})
+
+			var verr *domain.ValidationError
+			Expect(errors.As(err, &verr)).To(BeTrue())
+			Expect(verr.Fields).To(ContainElement(domain.FieldError{
+				Field:   "click_sample_rate",
+				Message: "click sample rate must be at least 1",
+			}))
+		})
+
+		It("should reject a negative rate on update", func() {
+			_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{
+				ClickSampleRate: intPtr(-1),
+			})
+
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+		})
+	})
+})
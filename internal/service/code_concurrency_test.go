@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService concurrent code generation", func() {
+	const creators = 20
+
+	It("should give every concurrent link for the same URL its own code", func() {
+		var (
+			mu    sync.Mutex
+			codes = map[string]bool{}
+		)
+
+		svc := service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+				CreateFunc: func(ctx context.Context, url *domain.URL) error {
+					return nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com/popular"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				// Every pre-check sees a free code, as when all requests check
+				// before any of them has inserted
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				// The unique constraint on insert is the only safety net
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					mu.Lock()
+					defer mu.Unlock()
+					if codes[link.Code] {
+						return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+					}
+					codes[link.Code] = true
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			// The default attempt bound, well below the number of creators
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+
+		var (
+			wg      sync.WaitGroup
+			start   = make(chan struct{})
+			results = make(chan string, creators)
+			errs    = make(chan error, creators)
+		)
+		for range creators {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				<-start
+				link, err := svc.CreateShortLink(context.Background(), &domain.CreateShortLinkRequest{
+					URL: "https://example.com/popular",
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+				results <- link.Code
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(results)
+		close(errs)
+
+		Expect(errs).To(BeEmpty())
+
+		seen := map[string]bool{}
+		for code := range results {
+			Expect(seen).NotTo(HaveKey(code))
+			seen[code] = true
+		}
+		Expect(seen).To(HaveLen(creators))
+	})
+
+	It("should keep creating links for the same URL one after another", func() {
+		const links = 50
+		stored := map[string]bool{}
+
+		svc := service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com/popular"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if stored[code] {
+						return &domain.ShortLink{Code: code}, nil
+					}
+					return nil, errors.New("not found")
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					if stored[link.Code] {
+						return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+					}
+					stored[link.Code] = true
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+
+		for range links {
+			_, err := svc.CreateShortLink(context.Background(), &domain.CreateShortLinkRequest{
+				URL: "https://example.com/popular",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(stored).To(HaveLen(links))
+	})
+})
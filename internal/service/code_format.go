@@ -0,0 +1,95 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// Code case modes
+const (
+	// CodeCasePreserve stores and resolves codes exactly as given
+	CodeCasePreserve = "preserve"
+	// CodeCaseLower stores new codes and aliases lowercased and resolves
+	// codes in any case
+	CodeCaseLower = "lower"
+)
+
+// CodeDisplaySeparator joins the groups of a code formatted for display.
+// It is stripped again when a formatted code is resolved.
+//
+// Generated codes can contain the separator themselves, so only separators
+// at group boundaries are formatting.
+const CodeDisplaySeparator = "-"
+
+// canonicalCode returns code in the form it is stored in
+func (s *URLShortenerService) canonicalCode(code string) string {
+	if s.opts.CodeCase == CodeCaseLower {
+		return strings.ToLower(code)
+	}
+	return code
+}
+
+// codeCandidates returns the stored codes a requested code may stand for,
+// in the order they are looked up: the code in canonical case, then without
+// display formatting, so aliases that look formatted still win, and finally
+// as given, for links stored before lowercasing was turned on
+func (s *URLShortenerService) codeCandidates(code string) []string {
+	var candidates []string
+	add := func(candidate string) {
+		if candidate != "" && !slices.Contains(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	add(s.canonicalCode(code))
+	if stored, ok := s.ungroupCode(code); ok {
+		add(s.canonicalCode(stored))
+	}
+	add(code)
+
+	return candidates
+}
+
+// ungroupCode undoes DisplayCode's grouping, reporting false when code
+// doesn't have a separator after every full group
+func (s *URLShortenerService) ungroupCode(code string) (string, bool) {
+	size := s.opts.CodeDisplayGroup
+	runes := []rune(code)
+	if size <= 0 || len(runes) <= size || len(runes)%(size+1) == 0 {
+		return "", false
+	}
+
+	stored := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		if (i+1)%(size+1) != 0 {
+			stored = append(stored, r)
+		} else if string(r) != CodeDisplaySeparator {
+			return "", false
+		}
+	}
+
+	return string(stored), true
+}
+
+// DisplayCode returns the code of link formatted for showing to people.
+// Generated codes are split into groups of CodeDisplayGroup characters,
+// e.g. abc-123, which resolve like the stored code; custom aliases are
+// shown as chosen.
+func (s *URLShortenerService) DisplayCode(link *domain.ShortLink) string {
+	size := s.opts.CodeDisplayGroup
+	if size <= 0 || (link.CustomAlias != nil && *link.CustomAlias != "") {
+		return link.Code
+	}
+
+	runes := []rune(link.Code)
+	var groups []string
+	for len(runes) > size {
+		groups = append(groups, string(runes[:size]))
+		runes = runes[size:]
+	}
+	groups = append(groups, string(runes))
+
+	return strings.Join(groups, CodeDisplaySeparator)
+}
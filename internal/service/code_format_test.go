@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService code case and display format", func() {
+	var (
+		ctx     context.Context
+		links   map[string]*domain.ShortLink
+		aliases map[string]*domain.ShortLink
+		lookups []string
+		created *domain.ShortLink
+	)
+
+	alias := func(s string) *string { return &s }
+
+	newService := func(opts service.Options) *service.URLShortenerService {
+		opts.BaseURL = "https://short.example.com"
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					lookups = append(lookups, code)
+					if link, ok := links[code]; ok {
+						return link, nil
+					}
+					return nil, errors.New("short link not found")
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if link, ok := aliases[code]; ok {
+						return link, nil
+					}
+					return nil, errors.New("short link not found")
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			opts,
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		links = map[string]*domain.ShortLink{
+			"abc123": {ID: "link-1", Code: "abc123", IsActive: true},
+			"Legacy": {ID: "link-2", Code: "Legacy", IsActive: true},
+			"ab-c12": {ID: "link-3", Code: "ab-c12", IsActive: true},
+		}
+		aliases = map[string]*domain.ShortLink{
+			"my-link": {ID: "link-4", Code: "my-link", CustomAlias: alias("my-link"), IsActive: true},
+		}
+		lookups = nil
+		created = nil
+	})
+
+	Context("with lowercase codes grouped by three", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			svc = newService(service.Options{CodeCase: service.CodeCaseLower, CodeDisplayGroup: 3})
+		})
+
+		DescribeTable("should resolve a formatted code to the canonical stored code",
+			func(requested, id string) {
+				link, err := svc.GetShortLinkByCode(ctx, requested)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link.ID).To(Equal(id))
+			},
+			Entry("stored form", "abc123", "link-1"),
+			Entry("display form", "abc-123", "link-1"),
+			Entry("display form in upper case", "ABC-123", "link-1"),
+			Entry("stored form in mixed case", "AbC123", "link-1"),
+			Entry("code containing the separator", "ab--c12", "link-3"),
+			Entry("alias containing the separator", "My-Link", "link-4"),
+			Entry("mixed-case code stored before lowercasing", "Legacy", "link-2"),
+		)
+
+		It("should not treat separators off the group boundaries as formatting", func() {
+			_, err := svc.GetShortLinkByCode(ctx, "ab-c123")
+
+			Expect(err).To(HaveOccurred())
+			Expect(lookups).To(ConsistOf("ab-c123"))
+		})
+
+		It("should look a code up once per distinct candidate", func() {
+			_, err := svc.GetShortLinkByCode(ctx, "xyz-789")
+
+			Expect(err).To(HaveOccurred())
+			Expect(lookups).To(Equal([]string{"xyz-789", "xyz789"}))
+		})
+
+		It("should store generated codes in lower case", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com/Some/Path"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Code).To(MatchRegexp(`^[a-z0-9_-]+$`))
+		})
+
+		It("should store custom aliases in lower case", func() {
+			req := &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: alias("Launch")}
+
+			_, err := svc.CreateShortLink(ctx, req)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Code).To(Equal("launch"))
+			Expect(*created.CustomAlias).To(Equal("launch"))
+			Expect(*req.CustomAlias).To(Equal("Launch"))
+		})
+
+		It("should group generated codes for display", func() {
+			Expect(svc.DisplayCode(&domain.ShortLink{Code: "abc123"})).To(Equal("abc-123"))
+			Expect(svc.DisplayCode(&domain.ShortLink{Code: "abc1234"})).To(Equal("abc-123-4"))
+			Expect(svc.DisplayCode(&domain.ShortLink{Code: "abc"})).To(Equal("abc"))
+		})
+
+		It("should show custom aliases as chosen", func() {
+			Expect(svc.DisplayCode(aliases["my-link"])).To(Equal("my-link"))
+		})
+	})
+
+	Context("with the defaults", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			svc = newService(service.Options{})
+		})
+
+		It("should look codes up exactly as given", func() {
+			_, err := svc.GetShortLinkByCode(ctx, "ABC-123")
+
+			Expect(err).To(HaveOccurred())
+			Expect(lookups).To(Equal([]string{"ABC-123"}))
+		})
+
+		It("should keep the case of custom aliases", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: alias("Launch")})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Code).To(Equal("Launch"))
+		})
+
+		It("should display codes as stored", func() {
+			Expect(svc.DisplayCode(links["abc123"])).To(Equal("abc123"))
+		})
+	})
+})
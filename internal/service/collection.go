@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// maxCollectionNameLength bounds collection names, in characters
+const maxCollectionNameLength = 100
+
+// CollectionService manages the link collections of the user in the
+// request context. Users only ever see their own collections; requests
+// made without a user share a single unnamed owner.
+type CollectionService struct {
+	repo   repository.CollectionRepository
+	logger *zap.Logger
+}
+
+// NewCollectionService creates a new collection service
+func NewCollectionService(repo repository.CollectionRepository, logger *zap.Logger) *CollectionService {
+	return &CollectionService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateCollection creates a collection owned by the user in ctx
+func (s *CollectionService) CreateCollection(ctx context.Context, req *domain.CollectionRequest) (*domain.Collection, error) {
+	name, err := validateCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	collection := &domain.Collection{
+		ID:        uuid.New().String(),
+		OwnerID:   auth.UserIDFromContext(ctx),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, collection); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("collection name already in use: %w", domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("creating collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetCollection returns a collection of the user in ctx, wrapping
+// domain.ErrNotFound when it does not exist or belongs to someone else
+func (s *CollectionService) GetCollection(ctx context.Context, id string) (*domain.Collection, error) {
+	return ownedCollection(ctx, s.repo, id)
+}
+
+// ListCollections returns the collections of the user in ctx ordered by name
+func (s *CollectionService) ListCollections(ctx context.Context) ([]*domain.Collection, error) {
+	collections, err := s.repo.ListByOwner(ctx, auth.UserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	return collections, nil
+}
+
+// UpdateCollection renames a collection of the user in ctx
+func (s *CollectionService) UpdateCollection(ctx context.Context, id string, req *domain.CollectionRequest) (*domain.Collection, error) {
+	collection, err := ownedCollection(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := validateCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Name = name
+	collection.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.Update(ctx, collection); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("collection name already in use: %w", domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("updating collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection of the user in ctx. Its links are
+// kept and no longer belong to any collection.
+func (s *CollectionService) DeleteCollection(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+	}
+
+	if err := s.repo.Delete(ctx, auth.UserIDFromContext(ctx), id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("deleting collection: %w", err)
+	}
+
+	s.logger.Info("Deleted collection", zap.String("id", id))
+	return nil
+}
+
+// ownedCollection looks up a collection of the user in ctx. IDs that
+// aren't UUIDs can't name a collection and are not found either.
+func ownedCollection(ctx context.Context, repo repository.CollectionRepository, id string) (*domain.Collection, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+	}
+
+	collection, err := repo.GetByID(ctx, auth.UserIDFromContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("getting collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// validateCollectionName trims a collection name and checks it isn't empty or too long
+func validateCollectionName(name string) (string, error) {
+	verr := &domain.ValidationError{}
+
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		verr.Add("name", "name is required")
+	case utf8.RuneCountInString(name) > maxCollectionNameLength:
+		verr.Add("name", fmt.Sprintf("name must not be longer than %d characters", maxCollectionNameLength))
+	}
+
+	return name, verr.Err()
+}
+
+// checkCollection makes sure a link is only filed in one of the caller's
+// own collections; a nil or empty ID files it nowhere
+func (s *URLShortenerService) checkCollection(ctx context.Context, id *string) error {
+	if id == nil || *id == "" {
+		return nil
+	}
+
+	verr := &domain.ValidationError{}
+	if s.opts.Collections == nil {
+		verr.Add("collection_id", "collections are not enabled")
+		return verr.Err()
+	}
+
+	if _, err := ownedCollection(ctx, s.opts.Collections, *id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			verr.Add("collection_id", "collection not found")
+			return verr.Err()
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Collections", func() {
+	var (
+		alice       context.Context
+		bob         context.Context
+		collections map[string]*domain.Collection
+		links       map[string]*domain.ShortLink
+		listed      []domain.LinkFilter
+		repo        *mocks.MockCollectionRepository
+		svc         *service.CollectionService
+		shortener   *service.URLShortenerService
+	)
+
+	BeforeEach(func() {
+		alice = auth.WithUserID(context.Background(), "alice")
+		bob = auth.WithUserID(context.Background(), "bob")
+		collections = map[string]*domain.Collection{}
+		links = map[string]*domain.ShortLink{}
+		listed = nil
+
+		// An in-memory collections table scoped by owner
+		repo = &mocks.MockCollectionRepository{
+			CreateFunc: func(ctx context.Context, collection *domain.Collection) error {
+				for _, existing := range collections {
+					if existing.OwnerID == collection.OwnerID && existing.Name == collection.Name {
+						return fmt.Errorf("creating collection: %w", domain.ErrConflict)
+					}
+				}
+				copied := *collection
+				collections[collection.ID] = &copied
+				return nil
+			},
+			GetByIDFunc: func(ctx context.Context, owner, id string) (*domain.Collection, error) {
+				if collection, ok := collections[id]; ok && collection.OwnerID == owner {
+					copied := *collection
+					return &copied, nil
+				}
+				return nil, fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+			},
+			ListByOwnerFunc: func(ctx context.Context, owner string) ([]*domain.Collection, error) {
+				owned := []*domain.Collection{}
+				for _, collection := range collections {
+					if collection.OwnerID == owner {
+						owned = append(owned, collection)
+					}
+				}
+				return owned, nil
+			},
+		}
+		svc = service.NewCollectionService(repo, zaptest.NewLogger(GinkgoT()))
+
+		shortener = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					copied := *links[id]
+					return &copied, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					links[link.ID] = link
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					links[link.ID] = link
+					return nil
+				},
+				CountFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error) {
+					return 0, nil
+				},
+				ListFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error) {
+					listed = append(listed, filter)
+					var matched []*domain.ShortLink
+					for _, link := range links {
+						if link.CollectionID != nil && *link.CollectionID == filter.CollectionID {
+							matched = append(matched, link)
+						}
+					}
+					return matched, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com", Collections: repo},
+		)
+	})
+
+	fieldErrors := func(err error) []domain.FieldError {
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		return verr.Fields
+	}
+
+	Describe("managing collections", func() {
+		It("should create a collection owned by the caller", func() {
+			collection, err := svc.CreateCollection(alice, &domain.CollectionRequest{Name: "  Campaigns  "})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(collection.OwnerID).To(Equal("alice"))
+			Expect(collection.Name).To(Equal("Campaigns"))
+			Expect(collections).To(HaveKey(collection.ID))
+		})
+
+		It("should reject blank and duplicate names", func() {
+			_, err := svc.CreateCollection(alice, &domain.CollectionRequest{Name: " "})
+			Expect(fieldErrors(err)).To(ConsistOf(domain.FieldError{Field: "name", Message: "name is required"}))
+
+			_, err = svc.CreateCollection(alice, &domain.CollectionRequest{Name: "Campaigns"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = svc.CreateCollection(alice, &domain.CollectionRequest{Name: "Campaigns"})
+			Expect(errors.Is(err, domain.ErrConflict)).To(BeTrue())
+
+			// Names only need to be unique per owner
+			_, err = svc.CreateCollection(bob, &domain.CollectionRequest{Name: "Campaigns"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should only show owners their own collections", func() {
+			collection, err := svc.CreateCollection(alice, &domain.CollectionRequest{Name: "Campaigns"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = svc.GetCollection(bob, collection.ID)
+			Expect(errors.Is(err, domain.ErrNotFound)).To(BeTrue())
+			_, err = svc.UpdateCollection(bob, collection.ID, &domain.CollectionRequest{Name: "Mine now"})
+			Expect(errors.Is(err, domain.ErrNotFound)).To(BeTrue())
+
+			owned, err := svc.ListCollections(bob)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(owned).To(BeEmpty())
+
+			owned, err = svc.ListCollections(alice)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(owned).To(HaveLen(1))
+		})
+
+		It("should not look up IDs that aren't UUIDs", func() {
+			_, err := svc.GetCollection(alice, "not-a-uuid")
+			Expect(errors.Is(err, domain.ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(svc.DeleteCollection(alice, "not-a-uuid"), domain.ErrNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("filing links", func() {
+		var campaigns *domain.Collection
+
+		BeforeEach(func() {
+			var err error
+			campaigns, err = svc.CreateCollection(alice, &domain.CollectionRequest{Name: "Campaigns"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should create a link in the caller's collection and list it by collection", func() {
+			link, err := shortener.CreateShortLink(alice, &domain.CreateShortLinkRequest{
+				URL:          "https://example.com/spring-sale",
+				CollectionID: &campaigns.ID,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.CollectionID).To(HaveValue(Equal(campaigns.ID)))
+
+			_, err = shortener.CreateShortLink(alice, &domain.CreateShortLinkRequest{URL: "https://example.com/other"})
+			Expect(err).NotTo(HaveOccurred())
+
+			filed, _, err := shortener.ListShortLinksFiltered(alice, domain.LinkFilter{CollectionID: campaigns.ID}, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filed).To(HaveLen(1))
+			Expect(filed[0].ID).To(Equal(link.ID))
+		})
+
+		It("should move a link into and out of a collection", func() {
+			link, err := shortener.CreateShortLink(alice, &domain.CreateShortLinkRequest{URL: "https://example.com/spring-sale"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.CollectionID).To(BeNil())
+
+			updated, err := shortener.UpdateShortLink(alice, link.ID, &domain.UpdateShortLinkRequest{CollectionID: &campaigns.ID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.CollectionID).To(HaveValue(Equal(campaigns.ID)))
+
+			none := ""
+			updated, err = shortener.UpdateShortLink(alice, link.ID, &domain.UpdateShortLinkRequest{CollectionID: &none})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.CollectionID).To(BeNil())
+		})
+
+		It("should not file links in someone else's collection", func() {
+			_, err := shortener.CreateShortLink(bob, &domain.CreateShortLinkRequest{
+				URL:          "https://example.com/spring-sale",
+				CollectionID: &campaigns.ID,
+			})
+			Expect(fieldErrors(err)).To(ConsistOf(domain.FieldError{Field: "collection_id", Message: "collection not found"}))
+			Expect(links).To(BeEmpty())
+		})
+
+		It("should not list someone else's collection", func() {
+			_, _, err := shortener.ListShortLinksFiltered(bob, domain.LinkFilter{CollectionID: campaigns.ID}, 1, 20)
+
+			Expect(errors.Is(err, domain.ErrNotFound)).To(BeTrue())
+			Expect(listed).To(BeEmpty())
+		})
+	})
+})
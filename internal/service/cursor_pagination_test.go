@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService cursor pagination", func() {
+	var (
+		mu    sync.Mutex
+		links []*domain.ShortLink
+		base  time.Time
+		svc   *service.URLShortenerService
+		ctx   context.Context
+	)
+
+	insert := func(id string, createdAt time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		links = append(links, &domain.ShortLink{ID: id, Code: id, CreatedAt: createdAt})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		links = nil
+
+		// Two links share a timestamp so the id tie-breaker matters
+		for i := 0; i < 7; i++ {
+			insert(fmt.Sprintf("link-%d", i), base.Add(time.Duration(i/2)*time.Minute))
+		}
+
+		repo := &mocks.MockShortLinkRepository{
+			// Mirrors the keyset query: newest first, (created_at, id) below the cursor
+			ListAfterFunc: func(ctx context.Context, cursor *domain.LinkCursor, limit int) ([]*domain.ShortLink, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				sorted := append([]*domain.ShortLink(nil), links...)
+				sort.Slice(sorted, func(i, j int) bool {
+					if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+						return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+					}
+					return sorted[i].ID > sorted[j].ID
+				})
+
+				var page []*domain.ShortLink
+				for _, link := range sorted {
+					if cursor != nil {
+						if link.CreatedAt.After(cursor.CreatedAt) {
+							continue
+						}
+						if link.CreatedAt.Equal(cursor.CreatedAt) && link.ID >= cursor.ID {
+							continue
+						}
+					}
+					page = append(page, link)
+					if len(page) == limit {
+						break
+					}
+				}
+				return page, nil
+			},
+		}
+
+		svc = service.NewURLShortenerService(
+			&mocks.MockURLRepository{},
+			repo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+	})
+
+	It("should walk every link exactly once while new links are inserted", func() {
+		var seen []string
+		cursor := ""
+
+		for page := 0; ; page++ {
+			result, next, err := svc.ListShortLinksAfter(ctx, cursor, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(BeNumerically("<=", 2))
+
+			for _, link := range result {
+				seen = append(seen, link.ID)
+			}
+
+			// Newer links arriving mid-walk must not shift later pages
+			insert(fmt.Sprintf("new-%d", page), base.Add(time.Hour+time.Duration(page)*time.Minute))
+
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		Expect(seen).To(Equal([]string{
+			"link-6", "link-5", "link-4", "link-3", "link-2", "link-1", "link-0",
+		}))
+	})
+
+	It("should return no cursor on the last page", func() {
+		result, next, err := svc.ListShortLinksAfter(ctx, "", 10)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(HaveLen(7))
+		Expect(next).To(BeEmpty())
+	})
+
+	It("should reject a malformed cursor", func() {
+		_, _, err := svc.ListShortLinksAfter(ctx, "not-a-cursor!", 2)
+
+		Expect(err).To(MatchError(domain.ErrValidation))
+	})
+})
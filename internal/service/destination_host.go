@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// ListShortLinksByDestinationHost lists the links pointing at host or any of
+// its subdomains, newest first, with pagination. host may also be given as
+// a URL, whose host is used; anything else is a validation error.
+func (s *URLShortenerService) ListShortLinksByDestinationHost(ctx context.Context, host string, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	host, err := normalizeDestinationHost(host)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	total, err := s.linkRepo.CountByDestinationHost(ctx, host)
+	if err != nil {
+		return nil, 0, fmt.Errorf("counting short links by destination host: %w", err)
+	}
+
+	links, err := s.linkRepo.ListByDestinationHost(ctx, host, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing short links by destination host: %w", err)
+	}
+
+	return links, total, nil
+}
+
+// normalizeDestinationHost lowercases a host to search destinations by,
+// taking the host out of a URL and dropping the trailing dot of a fully
+// qualified name
+func normalizeDestinationHost(raw string) (string, error) {
+	host := strings.TrimSpace(raw)
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil {
+			host = u.Hostname()
+		}
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	valid := host != "" && !strings.HasPrefix(host, ".") && !strings.ContainsFunc(host, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_'
+	})
+	if !valid {
+		verr := &domain.ValidationError{}
+		verr.Add("host", "host must be a domain name such as example.com")
+		return "", verr
+	}
+
+	return host, nil
+}
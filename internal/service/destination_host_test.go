@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService links by destination host", func() {
+	var (
+		svc          *service.URLShortenerService
+		searched     string
+		offset, size int
+	)
+
+	BeforeEach(func() {
+		searched, offset, size = "", -1, -1
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{
+				CountByDestinationHostFunc: func(ctx context.Context, host string) (int, error) {
+					return 42, nil
+				},
+				ListByDestinationHostFunc: func(ctx context.Context, host string, o, l int) ([]*domain.ShortLink, error) {
+					searched, offset, size = host, o, l
+					return []*domain.ShortLink{{ID: "link-1", Code: "abc123"}}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+	})
+
+	It("should page through the links pointing at the host", func() {
+		links, total, err := svc.ListShortLinksByDestinationHost(context.Background(), "example.com", 3, 20)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(links).To(HaveLen(1))
+		Expect(total).To(Equal(42))
+		Expect(searched).To(Equal("example.com"))
+		Expect(offset).To(Equal(40))
+		Expect(size).To(Equal(20))
+	})
+
+	DescribeTable("should search by the normalized host",
+		func(input, expected string) {
+			_, _, err := svc.ListShortLinksByDestinationHost(context.Background(), input, 1, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(searched).To(Equal(expected))
+		},
+		Entry("mixed case", "Phish.Example.COM", "phish.example.com"),
+		Entry("a fully qualified name", "example.com.", "example.com"),
+		Entry("a URL", "https://user@Example.com:8443/login?next=/", "example.com"),
+	)
+
+	DescribeTable("should reject what isn't a host",
+		func(input string) {
+			_, _, err := svc.ListShortLinksByDestinationHost(context.Background(), input, 1, 10)
+
+			var verr *domain.ValidationError
+			Expect(errors.As(err, &verr)).To(BeTrue())
+			Expect(verr.Fields[0].Field).To(Equal("host"))
+			Expect(searched).To(BeEmpty())
+		},
+		Entry("blank", "  "),
+		Entry("a path", "example.com/login"),
+		Entry("a wildcard", "%.com"),
+		Entry("a leading dot", ".example.com"),
+	)
+})
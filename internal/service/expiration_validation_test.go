@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService expiration date validation", func() {
+	var (
+		svc     *service.URLShortenerService
+		created *domain.ShortLink
+		updated *domain.ShortLink
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created, updated = nil, nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id, Code: "abc123", IsActive: true}, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					updated = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+	})
+
+	expectExpirationRejected := func(err error) {
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(err).To(MatchError(domain.ErrValidation))
+		Expect(verr.Fields).To(ConsistOf(domain.FieldError{
+			Field:   "expiration_date",
+			Message: "expiration date must be in the future",
+		}))
+	}
+
+	create := func(expiration time.Time) error {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:            "https://example.com",
+			ExpirationDate: &expiration,
+		})
+		return err
+	}
+
+	update := func(expiration time.Time) error {
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{ExpirationDate: &expiration})
+		return err
+	}
+
+	Describe("on create", func() {
+		It("should reject a past expiration date", func() {
+			expectExpirationRejected(create(time.Now().Add(-time.Hour)))
+			Expect(created).To(BeNil())
+		})
+
+		It("should reject an expiration date of now", func() {
+			expectExpirationRejected(create(time.Now()))
+			Expect(created).To(BeNil())
+		})
+
+		It("should accept a future expiration date", func() {
+			expiration := time.Now().Add(time.Hour)
+
+			Expect(create(expiration)).To(Succeed())
+			Expect(created.ExpirationDate).To(HaveValue(BeTemporally("==", expiration)))
+		})
+	})
+
+	Describe("on update", func() {
+		It("should reject a past expiration date", func() {
+			expectExpirationRejected(update(time.Now().Add(-time.Hour)))
+			Expect(updated).To(BeNil())
+		})
+
+		It("should reject an expiration date of now", func() {
+			expectExpirationRejected(update(time.Now()))
+			Expect(updated).To(BeNil())
+		})
+
+		It("should accept a future expiration date", func() {
+			expiration := time.Now().Add(time.Hour)
+
+			Expect(update(expiration)).To(Succeed())
+			Expect(updated.ExpirationDate).To(HaveValue(BeTemporally("==", expiration)))
+		})
+	})
+})
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// defaultExportMaxRows caps exports when no limit is configured
+const defaultExportMaxRows = 100000
+
+// errExportCapReached stops the repository stream once the cap is exceeded
+var errExportCapReached = errors.New("export row cap reached")
+
+// ExportShortLinks calls fn for every link, oldest first, with its URL data
+// and click count, up to the configured row cap. Links are streamed from the
+// database rather than loaded at once. truncated reports whether links beyond
+// the cap were left out.
+func (s *URLShortenerService) ExportShortLinks(ctx context.Context, fn func(link *domain.ShortLink) error) (truncated bool, err error) {
+	maxRows := s.opts.ExportMaxRows
+	if maxRows <= 0 {
+		maxRows = defaultExportMaxRows
+	}
+
+	// One extra row tells whether the cap cut the export short
+	exported := 0
+	err = s.linkRepo.Stream(ctx, maxRows+1, func(link *domain.ShortLink) error {
+		if exported == maxRows {
+			return errExportCapReached
+		}
+		exported++
+		return fn(link)
+	})
+
+	if errors.Is(err, errExportCapReached) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("exporting short links: %w", err)
+	}
+
+	return false, nil
+}
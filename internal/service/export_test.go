@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService export", func() {
+	var (
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		stored            int
+		requestedLimit    int
+		ctx               context.Context
+	)
+
+	newService := func(maxRows int) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{ExportMaxRows: maxRows},
+		)
+	}
+
+	// export collects the codes passed to the callback
+	export := func(svc *service.URLShortenerService) ([]string, bool, error) {
+		var codes []string
+		truncated, err := svc.ExportShortLinks(ctx, func(link *domain.ShortLink) error {
+			codes = append(codes, link.Code)
+			return nil
+		})
+		return codes, truncated, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requestedLimit = 0
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			StreamFunc: func(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error {
+				requestedLimit = limit
+				for i := 0; i < stored && i < limit; i++ {
+					if err := fn(&domain.ShortLink{Code: fmt.Sprintf("code%d", i)}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+	})
+
+	It("should pass every link through when under the cap", func() {
+		stored = 3
+
+		codes, truncated, err := export(newService(5))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes).To(Equal([]string{"code0", "code1", "code2"}))
+		Expect(truncated).To(BeFalse())
+	})
+
+	It("should not report an export of exactly the cap as truncated", func() {
+		stored = 5
+
+		codes, truncated, err := export(newService(5))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes).To(HaveLen(5))
+		Expect(truncated).To(BeFalse())
+	})
+
+	It("should stop at the cap and report truncation", func() {
+		stored = 8
+
+		codes, truncated, err := export(newService(5))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes).To(HaveLen(5))
+		Expect(truncated).To(BeTrue())
+		Expect(requestedLimit).To(Equal(6))
+	})
+
+	It("should return errors from the callback", func() {
+		stored = 3
+
+		_, err := newService(5).ExportShortLinks(ctx, func(link *domain.ShortLink) error {
+			return errors.New("client went away")
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("client went away")))
+	})
+})
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// defaultHealthCheckConcurrency bounds concurrent probes when no limit is configured
+const defaultHealthCheckConcurrency = 8
+
+// CheckLinkHealth probes the destinations of the links named by codes, or of
+// every link up to the export row cap when codes is empty, a bounded number
+// at a time. Each result is stored on its link as the last health check.
+// Probes are guarded like reachability checks, so a destination on a private
+// address, or redirecting to one, is reported unhealthy without being reached.
+func (s *URLShortenerService) CheckLinkHealth(ctx context.Context, codes []string) (*domain.LinkHealthReport, error) {
+	report := &domain.LinkHealthReport{NotFound: []string{}}
+
+	var links []*domain.ShortLink
+	if len(codes) == 0 {
+		truncated, err := s.ExportShortLinks(ctx, func(link *domain.ShortLink) error {
+			links = append(links, link)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing links to check: %w", err)
+		}
+		report.Truncated = truncated
+	} else {
+		// A code and an alias of the same link only check it once
+		seenCodes := make(map[string]bool, len(codes))
+		seenIDs := make(map[string]bool, len(codes))
+		for _, code := range codes {
+			if seenCodes[code] {
+				continue
+			}
+			seenCodes[code] = true
+
+			link, err := s.GetShortLinkByCode(ctx, code)
+			if err != nil {
+				if errors.Is(err, domain.ErrNotFound) || strings.Contains(err.Error(), "not found") {
+					report.NotFound = append(report.NotFound, code)
+					continue
+				}
+				return nil, fmt.Errorf("getting link %q to check: %w", code, err)
+			}
+
+			if !seenIDs[link.ID] {
+				seenIDs[link.ID] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	concurrency := s.opts.HealthCheckConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultHealthCheckConcurrency
+	}
+
+	report.Results = make([]*domain.LinkHealthResult, len(links))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report.Results[i] = s.checkLinkHealth(ctx, link)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		if result.Error == "" {
+			report.Healthy++
+		} else {
+			report.Unhealthy++
+		}
+	}
+
+	return report, nil
+}
+
+// checkLinkHealth probes a single link's destination and stores the result
+func (s *URLShortenerService) checkLinkHealth(ctx context.Context, link *domain.ShortLink) *domain.LinkHealthResult {
+	result := &domain.LinkHealthResult{Code: link.Code}
+	if link.URL != nil {
+		result.URL = link.URL.OriginalURL
+	}
+
+	if isWebURL(result.URL) {
+		status, err := s.health.Status(ctx, result.URL)
+		result.StatusCode = status
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case status >= http.StatusBadRequest:
+			result.Error = fmt.Sprintf("destination responded with status %d", status)
+		}
+	} else {
+		result.Error = "destination is not an http or https URL"
+	}
+	result.CheckedAt = time.Now().UTC()
+
+	// A cancelled run says nothing about the destination
+	if ctx.Err() != nil {
+		return result
+	}
+
+	health := result.LinkHealth
+	if err := s.linkRepo.UpdateHealth(ctx, link.ID, &health); err != nil {
+		s.logger.Warn("Failed to store link health", zap.String("code", link.Code), zap.Error(err))
+	}
+
+	return result
+}
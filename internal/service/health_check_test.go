@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService link health check", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		links    []*domain.ShortLink
+		mu       sync.Mutex
+		stored   map[string]*domain.LinkHealth
+		inFlight int
+		peak     int
+		allowed  []string
+	)
+
+	// destination starts stub destinations answering by path: /ok with 200,
+	// /gone with 404, /broken with 500 and /no-head with 405 to HEAD only.
+	// Every probe is held for a moment so overlapping ones can be counted.
+	destination := func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+			time.Sleep(20 * time.Millisecond)
+
+			switch r.URL.Path {
+			case "/ok":
+				w.WriteHeader(http.StatusOK)
+			case "/gone":
+				w.WriteHeader(http.StatusNotFound)
+			case "/no-head":
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		DeferCleanup(server.Close)
+	}
+
+	link := func(code, url string) *domain.ShortLink {
+		return &domain.ShortLink{ID: "id-" + code, Code: code, URLID: "url-" + code, URL: &domain.URL{ID: "url-" + code, OriginalURL: url}}
+	}
+
+	newService := func(concurrency int) *service.URLShortenerService {
+		byCode := make(map[string]*domain.ShortLink, len(links))
+		byURLID := make(map[string]*domain.URL, len(links))
+		for _, l := range links {
+			byCode[l.Code] = l
+			byURLID[l.URLID] = l.URL
+		}
+
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return byURLID[id], nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("short link not found")
+				},
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if l, ok := byCode[code]; ok {
+						copied := *l
+						return &copied, nil
+					}
+					return nil, errors.New("short link not found")
+				},
+				StreamFunc: func(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error {
+					for _, l := range links[:min(limit, len(links))] {
+						if err := fn(l); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				UpdateHealthFunc: func(ctx context.Context, id string, health *domain.LinkHealth) error {
+					mu.Lock()
+					defer mu.Unlock()
+					stored[id] = health
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				ReachabilityTimeout:    time.Second,
+				HealthCheckConcurrency: concurrency,
+				ProbeAllowedAddresses:  allowed,
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = map[string]*domain.LinkHealth{}
+		inFlight, peak = 0, 0
+		destination()
+
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		// The stub destinations listen on loopback, which probes refuse
+		// unless allowed
+		allowed = []string{server.Listener.Addr().String(), closed.Listener.Addr().String()}
+
+		links = []*domain.ShortLink{
+			link("ok", server.URL+"/ok"),
+			link("gone", server.URL+"/gone"),
+			link("broken", server.URL+"/broken"),
+			link("nohead", server.URL+"/no-head"),
+			link("down", closed.URL+"/ok"),
+			link("mail", "mailto:team@example.com"),
+		}
+	})
+
+	It("should check every link and store each result", func() {
+		before := time.Now().UTC()
+		report, err := newService(0).CheckLinkHealth(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Truncated).To(BeFalse())
+		Expect(report.NotFound).To(BeEmpty())
+		Expect(report.Healthy).To(Equal(2))
+		Expect(report.Unhealthy).To(Equal(4))
+
+		byCode := map[string]*domain.LinkHealthResult{}
+		for _, result := range report.Results {
+			byCode[result.Code] = result
+			Expect(result.CheckedAt).To(BeTemporally(">=", before))
+			Expect(stored).To(HaveKeyWithValue("id-"+result.Code, Equal(&result.LinkHealth)))
+		}
+		Expect(byCode).To(HaveLen(6))
+
+		Expect(byCode["ok"].StatusCode).To(Equal(http.StatusOK))
+		Expect(byCode["ok"].Error).To(BeEmpty())
+		Expect(byCode["nohead"].StatusCode).To(Equal(http.StatusOK))
+		Expect(byCode["nohead"].Error).To(BeEmpty())
+		Expect(byCode["gone"].StatusCode).To(Equal(http.StatusNotFound))
+		Expect(byCode["gone"].Error).To(Equal("destination responded with status 404"))
+		Expect(byCode["broken"].StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(byCode["broken"].Error).To(Equal("destination responded with status 500"))
+		Expect(byCode["down"].StatusCode).To(BeZero())
+		Expect(byCode["down"].Error).To(ContainSubstring("requesting destination"))
+		Expect(byCode["mail"].StatusCode).To(BeZero())
+		Expect(byCode["mail"].Error).To(Equal("destination is not an http or https URL"))
+	})
+
+	It("should only check the named codes and report unknown ones", func() {
+		report, err := newService(0).CheckLinkHealth(ctx, []string{"gone", "missing", "ok", "gone"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.NotFound).To(Equal([]string{"missing"}))
+		Expect(report.Results).To(HaveLen(2))
+		Expect(report.Results[0].Code).To(Equal("gone"))
+		Expect(report.Results[0].URL).To(Equal(server.URL + "/gone"))
+		Expect(report.Results[1].Code).To(Equal("ok"))
+		Expect(stored).To(HaveLen(2))
+	})
+
+	It("should probe no more destinations at once than the concurrency limit", func() {
+		for i := range 6 {
+			links = append(links, link(fmt.Sprintf("extra%d", i), server.URL+"/ok"))
+		}
+
+		_, err := newService(2).CheckLinkHealth(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(peak).To(Equal(2))
+	})
+
+	It("should refuse destinations on private addresses, redirects included", func() {
+		internalRequests := 0
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			internalRequests++
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(internal.Close)
+
+		target := "http://127.0.0.1:" + internal.URL[strings.LastIndex(internal.URL, ":")+1:] + "/admin"
+		redirector := httptest.NewServer(http.RedirectHandler(target, http.StatusFound))
+		DeferCleanup(redirector.Close)
+		allowed = append(allowed, redirector.Listener.Addr().String())
+
+		links = []*domain.ShortLink{
+			link("internal", internal.URL+"/admin"),
+			link("redirect", redirector.URL+"/"),
+		}
+
+		report, err := newService(0).CheckLinkHealth(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Unhealthy).To(Equal(2))
+		for _, result := range report.Results {
+			Expect(result.StatusCode).To(BeZero(), result.Code)
+			Expect(result.Error).To(ContainSubstring("not public: 127.0.0.1"), result.Code)
+			Expect(stored).To(HaveKeyWithValue("id-"+result.Code, Equal(&result.LinkHealth)))
+		}
+		Expect(internalRequests).To(BeZero())
+	})
+})
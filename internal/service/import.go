@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// maxImportedCodeLength bounds codes brought in from other shorteners
+const maxImportedCodeLength = 64
+
+// ImportShortLink creates a link that keeps the code it had in another
+// shortener. Unlike CreateShortLink it never generates a code, applies no
+// default expiry and does not probe the destination, so bulk imports stay
+// fast and preserve the original links as they were. A code or alias that is
+// already taken is an ErrConflict.
+func (s *URLShortenerService) ImportShortLink(ctx context.Context, req *domain.ImportShortLinkRequest) (*domain.ShortLink, error) {
+	if err := validateImportedCode(req.Code); err != nil {
+		return nil, err
+	}
+
+	if s.isReservedAlias(req.Code) {
+		return nil, fmt.Errorf("code '%s' is reserved and cannot be used: %w", req.Code, domain.ErrValidation)
+	}
+
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, fmt.Errorf("invalid URL: %v: %w", err, domain.ErrValidation)
+	}
+
+	existingLink, err := s.linkRepo.GetByCode(ctx, req.Code)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, fmt.Errorf("checking existing code: %w", err)
+	}
+	if existingLink != nil {
+		return nil, fmt.Errorf("code already in use: %w", domain.ErrConflict)
+	}
+
+	urlID, err := s.findOrCreateURL(ctx, req.URL, s.generateHash(req.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	shortLink := &domain.ShortLink{
+		ID:             uuid.New().String(),
+		Code:           req.Code,
+		CustomAlias:    req.CustomAlias,
+		URLID:          urlID,
+		ExpirationDate: req.ExpirationDate,
+		IsActive:       true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	// The unique constraints catch codes and aliases claimed since the check above
+	if err := s.linkRepo.Create(ctx, shortLink); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("code or alias already in use: %w", domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("creating short link: %w", err)
+	}
+
+	url, err := s.urlRepo.GetByID(ctx, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving URL data: %w", err)
+	}
+
+	shortLink.URL = url
+	s.recordAudit(ctx, domain.AuditActionCreate, shortLink.ID, nil, shortLink)
+	return shortLink, nil
+}
+
+// validateImportedCode rejects codes that could not be served as a single
+// path segment
+func validateImportedCode(code string) error {
+	if code == "" {
+		return fmt.Errorf("code is required: %w", domain.ErrValidation)
+	}
+
+	if len(code) > maxImportedCodeLength {
+		return fmt.Errorf("code must not exceed %d characters: %w", maxImportedCodeLength, domain.ErrValidation)
+	}
+
+	for _, r := range code {
+		if r == '/' || r == '?' || r == '#' || r == '%' || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("code contains invalid character %q: %w", r, domain.ErrValidation)
+		}
+	}
+
+	return nil
+}
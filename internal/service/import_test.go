@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService import", func() {
+	var (
+		mockURLRepo       *mocks.MockURLRepository
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		svc               *service.URLShortenerService
+		created           *domain.ShortLink
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = nil
+
+		mockURLRepo = &mocks.MockURLRepository{
+			GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+				return nil, errors.New("url not found")
+			},
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+				return &domain.URL{ID: id, OriginalURL: "https://example.com/page"}, nil
+			},
+		}
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				if code == "taken" {
+					return &domain.ShortLink{ID: "existing", Code: code}, nil
+				}
+				return nil, errors.New("short link not found")
+			},
+			CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+				created = link
+				return nil
+			},
+		}
+		svc = service.NewURLShortenerService(
+			mockURLRepo,
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+	})
+
+	It("should keep the given code and expiry without applying the default expiry", func() {
+		link, err := svc.ImportShortLink(ctx, &domain.ImportShortLinkRequest{
+			Code: "legacy42",
+			URL:  "https://example.com/page",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Code).To(Equal("legacy42"))
+		Expect(link.URL.OriginalURL).To(Equal("https://example.com/page"))
+		Expect(created.Code).To(Equal("legacy42"))
+		Expect(created.ExpirationDate).To(BeNil())
+		Expect(created.IsActive).To(BeTrue())
+	})
+
+	It("should report a code already in use as a conflict", func() {
+		_, err := svc.ImportShortLink(ctx, &domain.ImportShortLinkRequest{
+			Code: "taken",
+			URL:  "https://example.com/page",
+		})
+
+		Expect(err).To(MatchError(domain.ErrConflict))
+		Expect(created).To(BeNil())
+	})
+
+	It("should report a conflict raised by the unique constraint", func() {
+		mockShortLinkRepo.CreateFunc = func(ctx context.Context, link *domain.ShortLink) error {
+			return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+		}
+
+		_, err := svc.ImportShortLink(ctx, &domain.ImportShortLinkRequest{
+			Code: "racey",
+			URL:  "https://example.com/page",
+		})
+
+		Expect(err).To(MatchError(domain.ErrConflict))
+	})
+
+	DescribeTable("should reject unusable codes and URLs",
+		func(code, url string) {
+			_, err := svc.ImportShortLink(ctx, &domain.ImportShortLinkRequest{Code: code, URL: url})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+			Expect(created).To(BeNil())
+		},
+		Entry("empty code", "", "https://example.com"),
+		Entry("code with a slash", "a/b", "https://example.com"),
+		Entry("code with a space", "a b", "https://example.com"),
+		Entry("reserved code", "api", "https://example.com"),
+		Entry("invalid URL", "ok", "not a url"),
+	)
+})
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+)
+
+// IP anonymization modes
+const (
+	IPAnonymizationNone     = "none"
+	IPAnonymizationTruncate = "truncate"
+	IPAnonymizationHash     = "hash"
+)
+
+// anonymizeIP transforms an IP address according to the anonymization mode.
+// Truncation zeroes the last IPv4 octet or the last 80 bits of an IPv6 address,
+// hashing returns a salted SHA-256 so unique visitors can still be counted.
+func anonymizeIP(ipAddress, mode, salt string) string {
+	if ipAddress == "" {
+		return ""
+	}
+
+	switch mode {
+	case IPAnonymizationTruncate:
+		ip := net.ParseIP(ipAddress)
+		if ip == nil {
+			// Never store a value we could not anonymize
+			return ""
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return ip.Mask(net.CIDRMask(48, 128)).String()
+	case IPAnonymizationHash:
+		hasher := sha256.New()
+		hasher.Write([]byte(salt))
+		hasher.Write([]byte(ipAddress))
+		return fmt.Sprintf("%x", hasher.Sum(nil))
+	default:
+		return ipAddress
+	}
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Click IP anonymization", func() {
+	var (
+		mockClickRepo *mocks.MockLinkClickRepository
+		clicks        chan *domain.LinkClick
+		ctx           context.Context
+	)
+
+	newService := func(mode string) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			mockClickRepo,
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:         "https://short.example.com",
+				DefaultExpiry:   24 * time.Hour,
+				IPAnonymization: mode,
+				IPHashSalt:      "pepper",
+			},
+		)
+	}
+
+	recordedIP := func(svc *service.URLShortenerService, ip string) string {
+		Expect(svc.RecordClick(ctx, "link-123", "", "", ip, "")).To(Succeed())
+
+		var click *domain.LinkClick
+		Eventually(clicks).Should(Receive(&click))
+		if click.IPAddress == nil {
+			return ""
+		}
+		return *click.IPAddress
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clicks = make(chan *domain.LinkClick, 10)
+		mockClickRepo = &mocks.MockLinkClickRepository{
+			CreateFunc: func(ctx context.Context, click *domain.LinkClick) error {
+				clicks <- click
+				return nil
+			},
+		}
+	})
+
+	Context("when anonymization is disabled", func() {
+		It("stores the raw IP", func() {
+			Expect(recordedIP(newService(service.IPAnonymizationNone), "203.0.113.7")).To(Equal("203.0.113.7"))
+		})
+	})
+
+	Context("when truncation is enabled", func() {
+		It("zeroes the last IPv4 octet", func() {
+			Expect(recordedIP(newService(service.IPAnonymizationTruncate), "203.0.113.7")).To(Equal("203.0.113.0"))
+		})
+
+		It("zeroes the IPv6 interface suffix", func() {
+			Expect(recordedIP(newService(service.IPAnonymizationTruncate), "2001:db8:abcd:12:1:2:3:4")).To(Equal("2001:db8:abcd::"))
+		})
+
+		It("drops addresses it cannot parse", func() {
+			Expect(recordedIP(newService(service.IPAnonymizationTruncate), "not-an-ip")).To(BeEmpty())
+		})
+	})
+
+	Context("when hashing is enabled", func() {
+		It("never persists the raw IP", func() {
+			stored := recordedIP(newService(service.IPAnonymizationHash), "203.0.113.7")
+
+			Expect(stored).NotTo(BeEmpty())
+			Expect(stored).NotTo(ContainSubstring("203.0.113.7"))
+			Expect(stored).To(HaveLen(64))
+		})
+
+		It("keeps unique visitors countable", func() {
+			svc := newService(service.IPAnonymizationHash)
+
+			first := recordedIP(svc, "203.0.113.7")
+			repeat := recordedIP(svc, "203.0.113.7")
+			other := recordedIP(svc, "198.51.100.1")
+
+			Expect(repeat).To(Equal(first))
+			Expect(other).NotTo(Equal(first))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultLastAccessedInterval is how often a busy link's last access time
+// is written at most when Options.LastAccessedInterval is zero
+const defaultLastAccessedInterval = time.Minute
+
+// accessThrottle decides when a link's last access time is worth writing,
+// so a busy link costs one write per interval rather than one per click
+type accessThrottle struct {
+	interval time.Duration
+
+	mu        sync.Mutex
+	written   map[string]time.Time
+	lastSweep time.Time
+}
+
+// newAccessThrottle creates a throttle allowing one write per link per
+// interval; zero uses defaultLastAccessedInterval
+func newAccessThrottle(interval time.Duration) *accessThrottle {
+	if interval <= 0 {
+		interval = defaultLastAccessedInterval
+	}
+	return &accessThrottle{
+		interval: interval,
+		written:  make(map[string]time.Time),
+	}
+}
+
+// Due reports whether the link's last access should be written at now,
+// counting it as written when it should
+func (t *accessThrottle) Due(shortLinkID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget links written longer ago than the interval, they are due anyway
+	if now.Sub(t.lastSweep) >= t.interval {
+		for id, at := range t.written {
+			if now.Sub(at) >= t.interval {
+				delete(t.written, id)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	if at, ok := t.written[shortLinkID]; ok && now.Sub(at) < t.interval {
+		return false
+	}
+	t.written[shortLinkID] = now
+	return true
+}
+
+// touchLastAccessed records that the link was followed at now, unless it
+// was already written within the throttle interval. Other instances keep
+// their own throttle, so each writes at most once per interval.
+func (s *URLShortenerService) touchLastAccessed(shortLinkID string, now time.Time) {
+	if !s.lastAccessed.Due(shortLinkID, now) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := s.linkRepo.TouchLastAccessed(ctx, shortLinkID, now); err != nil {
+			s.logger.Error("Failed to update last access time",
+				zap.String("short_link_id", shortLinkID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
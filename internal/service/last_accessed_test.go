@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Last accessed time", func() {
+	var (
+		ctx     context.Context
+		mu      sync.Mutex
+		touched map[string][]time.Time
+	)
+
+	// writes returns the last access times written for a link
+	writes := func(shortLinkID string) []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Time(nil), touched[shortLinkID]...)
+	}
+
+	newService := func(interval time.Duration) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id}, nil
+				},
+				TouchLastAccessedFunc: func(ctx context.Context, id string, at time.Time) error {
+					mu.Lock()
+					defer mu.Unlock()
+					touched[id] = append(touched[id], at)
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{LastAccessedInterval: interval},
+		)
+	}
+
+	click := func(svc *service.URLShortenerService, shortLinkID string) {
+		Expect(svc.RecordClick(ctx, shortLinkID, "", "", "203.0.113.7", "")).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		touched = map[string][]time.Time{}
+	})
+
+	It("should record the time of a click", func() {
+		svc := newService(0)
+		before := time.Now()
+
+		click(svc, "link-1")
+
+		Eventually(func() []time.Time { return writes("link-1") }).Should(HaveLen(1))
+		Expect(writes("link-1")[0]).To(BeTemporally(">=", before.Add(-time.Millisecond)))
+		Expect(writes("link-1")[0]).To(BeTemporally("<=", time.Now()))
+	})
+
+	It("should write at most once per link within the throttle window", func() {
+		svc := newService(time.Minute)
+
+		for i := 0; i < 20; i++ {
+			click(svc, "link-1")
+		}
+		click(svc, "link-2")
+
+		Eventually(func() []time.Time { return writes("link-2") }).Should(HaveLen(1))
+		Consistently(func() []time.Time { return writes("link-1") }, 50*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("should write again once the window has passed", func() {
+		svc := newService(20 * time.Millisecond)
+
+		click(svc, "link-1")
+		click(svc, "link-1")
+		Eventually(func() []time.Time { return writes("link-1") }).Should(HaveLen(1))
+
+		time.Sleep(25 * time.Millisecond)
+		click(svc, "link-1")
+
+		Eventually(func() []time.Time { return writes("link-1") }).Should(HaveLen(2))
+		Expect(writes("link-1")[1]).To(BeTemporally(">", writes("link-1")[0]))
+	})
+})
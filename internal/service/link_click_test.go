@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService GetLinkClick", func() {
+	var (
+		mockClickRepo *mocks.MockLinkClickRepository
+		svc           *service.URLShortenerService
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockClickRepo = &mocks.MockLinkClickRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.LinkClick, error) {
+				if id != "click-1" {
+					return nil, fmt.Errorf("link click not found: %w", domain.ErrNotFound)
+				}
+				return &domain.LinkClick{ID: id, ShortLinkID: "link-1"}, nil
+			},
+		}
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			mockClickRepo,
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+	})
+
+	It("should return a click of the link", func() {
+		click, err := svc.GetLinkClick(ctx, "link-1", "click-1")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(click.ID).To(Equal("click-1"))
+	})
+
+	It("should return not found for an unknown click", func() {
+		_, err := svc.GetLinkClick(ctx, "link-1", "missing")
+
+		Expect(err).To(MatchError(domain.ErrNotFound))
+	})
+
+	It("should return not found for a click of another link", func() {
+		click, err := svc.GetLinkClick(ctx, "link-2", "click-1")
+
+		Expect(err).To(MatchError(domain.ErrNotFound))
+		Expect(click).To(BeNil())
+	})
+})
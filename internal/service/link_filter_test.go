@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService filtered listing", func() {
+	var (
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		svc               *service.URLShortenerService
+		ctx               context.Context
+
+		listedFilter  domain.LinkFilter
+		countedFilter domain.LinkFilter
+		listedOffset  int
+		listedNow     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		listedFilter = domain.LinkFilter{}
+		countedFilter = domain.LinkFilter{}
+
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			CountFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error) {
+				countedFilter = filter
+				return 12, nil
+			},
+			ListFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error) {
+				listedFilter = filter
+				listedOffset = offset
+				listedNow = now
+				return []*domain.ShortLink{{ID: "link-1"}}, nil
+			},
+		}
+		svc = service.NewURLShortenerService(
+			&mocks.MockURLRepository{},
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			30*24*time.Hour,
+		)
+	})
+
+	It("should pass the filter to both the count and the page query", func() {
+		nextWeek := time.Now().UTC().AddDate(0, 0, 7)
+		filter := domain.LinkFilter{ExpiresBefore: &nextWeek, Status: domain.LinkStatusActive}
+
+		links, total, err := svc.ListShortLinksFiltered(ctx, filter, 2, 5)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(links).To(HaveLen(1))
+		Expect(total).To(Equal(12))
+		Expect(listedFilter).To(Equal(filter))
+		Expect(countedFilter).To(Equal(filter))
+		Expect(listedOffset).To(Equal(5))
+		Expect(listedNow).To(BeTemporally("~", time.Now().UTC(), time.Second))
+	})
+
+	It("should reject an unknown status", func() {
+		_, _, err := svc.ListShortLinksFiltered(ctx, domain.LinkFilter{Status: "archived"}, 1, 10)
+
+		Expect(err).To(MatchError(domain.ErrValidation))
+		Expect(listedFilter).To(Equal(domain.LinkFilter{}))
+	})
+})
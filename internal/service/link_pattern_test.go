@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService pattern links", func() {
+	var (
+		svc     *service.URLShortenerService
+		created *domain.ShortLink
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:        "https://short.example.com",
+				AllowedSchemes: []string{"http", "https", "mailto"},
+			},
+		)
+	})
+
+	It("should store the pattern flag", func() {
+		alias := "wiki"
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:         "https://intranet.example.com/wiki",
+			CustomAlias: &alias,
+			IsPattern:   true,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.IsPattern).To(BeTrue())
+	})
+
+	It("should reject pattern links to destinations without a path", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:       "mailto:team@example.com",
+			IsPattern: true,
+		})
+
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields).To(ConsistOf(HaveField("Field", "is_pattern")))
+		Expect(created).To(BeNil())
+	})
+})
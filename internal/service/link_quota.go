@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// checkLinkQuota refuses a create with a *domain.QuotaError when the caller
+// already holds MaxLinksPerUser active links. Requests made without a user
+// and users in QuotaExemptUsers are not limited. Counting and inserting
+// aren't atomic, so concurrent creates can overshoot the quota slightly.
+func (s *URLShortenerService) checkLinkQuota(ctx context.Context) error {
+	if s.opts.MaxLinksPerUser <= 0 {
+		return nil
+	}
+
+	userID := auth.UserIDFromContext(ctx)
+	if userID == "" || slices.Contains(s.opts.QuotaExemptUsers, userID) {
+		return nil
+	}
+
+	count, err := s.linkRepo.CountActiveByOwner(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("counting user's active links: %w", err)
+	}
+
+	if count >= s.opts.MaxLinksPerUser {
+		return &domain.QuotaError{Limit: s.opts.MaxLinksPerUser}
+	}
+
+	return nil
+}
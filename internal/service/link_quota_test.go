@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService link quota", func() {
+	var (
+		svc     *service.URLShortenerService
+		active  int
+		counted []string
+		created *domain.ShortLink
+	)
+
+	BeforeEach(func() {
+		active = 0
+		counted = nil
+		created = nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				CountActiveByOwnerFunc: func(ctx context.Context, ownerID string, now time.Time) (int, error) {
+					counted = append(counted, ownerID)
+					return active, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:          "https://short.example.com",
+				MaxLinksPerUser:  3,
+				QuotaExemptUsers: []string{"admin"},
+			},
+		)
+	})
+
+	create := func(userID string) (*domain.ShortLink, error) {
+		ctx := context.Background()
+		if userID != "" {
+			ctx = auth.WithUserID(ctx, userID)
+		}
+		return svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com"})
+	}
+
+	It("should create the last link the quota allows and record its owner", func() {
+		active = 2
+
+		link, err := create("alice")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counted).To(Equal([]string{"alice"}))
+		Expect(link.OwnerID).To(HaveValue(Equal("alice")))
+		Expect(created).NotTo(BeNil())
+	})
+
+	It("should refuse a link once the quota is reached", func() {
+		active = 3
+
+		_, err := create("alice")
+
+		var qerr *domain.QuotaError
+		Expect(errors.As(err, &qerr)).To(BeTrue())
+		Expect(qerr.Limit).To(Equal(3))
+		Expect(errors.Is(err, domain.ErrQuotaExceeded)).To(BeTrue())
+		Expect(created).To(BeNil())
+	})
+
+	It("should let exempt users past the quota without counting", func() {
+		active = 10
+
+		link, err := create("admin")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counted).To(BeEmpty())
+		Expect(link.OwnerID).To(HaveValue(Equal("admin")))
+	})
+
+	It("should not limit links created without a user", func() {
+		active = 10
+
+		link, err := create("")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counted).To(BeEmpty())
+		Expect(link.OwnerID).To(BeNil())
+	})
+})
@@ -109,15 +109,17 @@ func (s *LinkService) ListLinks(userID string, page, perPage int) ([]*domain.Lin
 	return links, count, nil
 }
 
-// RecordClick records a click on a link
-func (s *LinkService) RecordClick(linkID, userAgent, referer, ipAddress string) error {
-	// Increment visits count
-	err := s.linkRepo.IncrementVisits(linkID)
-	if err != nil {
-		return err
-	}
+// BatchVisits coalesces visit increments in batcher instead of updating the
+// visits count on every click. The count then trails the click records
+// until the batcher flushes.
+func (s *LinkService) BatchVisits(batcher *VisitBatcher) {
+	s.visits = batcher
+}
 
-	// Create click record
+// RecordClick records a click on a link, incrementing its visits count and
+// storing the click record atomically. With batched visits the click is
+// stored first and the increment only counted once it was.
+func (s *LinkService) RecordClick(linkID, userAgent, referer, ipAddress string) error {
 	click := &domain.Click{
 		LinkID:    linkID,
 		UserAgent: userAgent,
@@ -125,7 +127,15 @@ func (s *LinkService) RecordClick(linkID, userAgent, referer, ipAddress string)
 		IPAddress: ipAddress,
 	}
 
-	return s.linkRepo.CreateClick(click)
+	if s.visits != nil {
+		if err := s.linkRepo.CreateClick(click); err != nil {
+			return err
+		}
+		s.visits.Increment(linkID)
+		return nil
+	}
+
+	return s.linkRepo.RecordVisit(click)
 }
 
 // GetClicks gets click data for a link
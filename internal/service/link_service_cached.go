@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// linkNotFoundTTL is how many seconds a short URL that doesn't exist is
+// remembered. It is kept short so a link created elsewhere shows up soon.
+const linkNotFoundTTL = 30
+
+// linkNotFound is cached for short URLs the repository reported as not found
+type linkNotFound struct{}
+
+// CachedLinkService wraps LinkService with a cache of links by short URL,
+// including short URLs known not to exist
+type CachedLinkService struct {
+	base   *LinkService
+	cache  cache.CacheInterface
+	logger *zap.Logger
+}
+
+// NewCachedLinkService creates a new cached link service
+func NewCachedLinkService(base *LinkService, cache cache.CacheInterface, logger *zap.Logger) *CachedLinkService {
+	return &CachedLinkService{
+		base:   base,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// shortURLKey returns the cache key for a link looked up by short URL
+func (s *CachedLinkService) shortURLKey(shortURL string) string {
+	return "link:short:" + shortURL
+}
+
+// CreateLink creates a new link (delegated to base service, updates cache)
+func (s *CachedLinkService) CreateLink(req CreateLinkRequest) (*domain.Link, error) {
+	link, err := s.base.CreateLink(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replaces a not found entry for the same short URL
+	s.cache.Set(s.shortURLKey(link.ShortURL), link, 0)
+
+	return link, nil
+}
+
+// GetLink retrieves a link by ID (not cached)
+func (s *CachedLinkService) GetLink(id string) (*domain.Link, error) {
+	return s.base.GetLink(id)
+}
+
+// GetLinkByShortURL retrieves a link by short URL (with caching). Unknown
+// short URLs are cached for linkNotFoundTTL seconds; other errors are not.
+func (s *CachedLinkService) GetLinkByShortURL(shortURL string) (*domain.Link, error) {
+	key := s.shortURLKey(shortURL)
+	if value, found := s.cache.Get(key); found {
+		switch cached := value.(type) {
+		case *domain.Link:
+			s.logger.Debug("Cache hit for short URL", zap.String("short_url", shortURL))
+			return cached, nil
+		case linkNotFound:
+			s.logger.Debug("Cached not found for short URL", zap.String("short_url", shortURL))
+			return nil, domain.ErrNotFound
+		default:
+			s.logger.Warn("Unexpected value type in cache, falling back to database",
+				zap.String("key", key),
+			)
+			s.cache.Delete(key)
+		}
+	}
+
+	link, err := s.base.GetLinkByShortURL(shortURL)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.cache.Set(key, linkNotFound{}, linkNotFoundTTL)
+		}
+		return nil, err
+	}
+
+	s.cache.Set(key, link, 0)
+
+	return link, nil
+}
+
+// UpdateLink updates a link (invalidates cache)
+func (s *CachedLinkService) UpdateLink(id string, req UpdateLinkRequest) (*domain.Link, error) {
+	// Get the current link to know what to invalidate
+	if oldLink, err := s.base.GetLink(id); err == nil && oldLink != nil {
+		s.cache.Delete(s.shortURLKey(oldLink.ShortURL))
+	}
+
+	link, err := s.base.UpdateLink(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(s.shortURLKey(link.ShortURL), link, 0)
+
+	return link, nil
+}
+
+// DeleteLink deletes a link (invalidates cache)
+func (s *CachedLinkService) DeleteLink(id string) error {
+	// Get the current link to know what to invalidate
+	oldLink, err := s.base.GetLink(id)
+	if err == nil && oldLink != nil {
+		s.cache.Delete(s.shortURLKey(oldLink.ShortURL))
+	}
+
+	return s.base.DeleteLink(id)
+}
+
+// ListLinks lists links for a user (not cached)
+func (s *CachedLinkService) ListLinks(userID string, page, perPage int) ([]*domain.Link, int, error) {
+	return s.base.ListLinks(userID, page, perPage)
+}
+
+// RecordClick records a click on a link
+func (s *CachedLinkService) RecordClick(linkID, userAgent, referer, ipAddress string) error {
+	return s.base.RecordClick(linkID, userAgent, referer, ipAddress)
+}
+
+// GetClicks gets click data for a link (not cached)
+func (s *CachedLinkService) GetClicks(linkID string, page, perPage int) ([]*domain.Click, int, error) {
+	return s.base.GetClicks(linkID, page, perPage)
+}
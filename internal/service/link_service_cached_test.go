@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("CachedLinkService", func() {
+	var (
+		mockRepo *mocks.MockLinkRepository
+		links    map[string]*domain.Link
+		lookups  map[string]int
+		svc      *service.CachedLinkService
+	)
+
+	BeforeEach(func() {
+		links = map[string]*domain.Link{
+			"link-1": {ID: "link-1", OriginalURL: "https://example.com", ShortURL: "promo"},
+		}
+		lookups = map[string]int{}
+
+		mockRepo = &mocks.MockLinkRepository{
+			GetByIDFunc: func(id string) (*domain.Link, error) {
+				link, ok := links[id]
+				if !ok {
+					return nil, domain.ErrNotFound
+				}
+				copied := *link
+				return &copied, nil
+			},
+			GetByShortURLFunc: func(shortURL string) (*domain.Link, error) {
+				lookups[shortURL]++
+				for _, link := range links {
+					if link.ShortURL == shortURL {
+						copied := *link
+						return &copied, nil
+					}
+				}
+				return nil, domain.ErrNotFound
+			},
+			UpdateFunc: func(link *domain.Link) error {
+				links[link.ID] = link
+				return nil
+			},
+			DeleteFunc: func(id string) error {
+				delete(links, id)
+				return nil
+			},
+		}
+
+		svc = service.NewCachedLinkService(service.NewLinkService(mockRepo), cache.NewMemoryCache(), zaptest.NewLogger(GinkgoT()))
+	})
+
+	It("should serve repeated lookups from the cache", func() {
+		first, err := svc.GetLinkByShortURL("promo")
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := svc.GetLinkByShortURL("promo")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(Equal(first))
+		Expect(lookups["promo"]).To(Equal(1))
+	})
+
+	It("should remember short URLs that do not exist", func() {
+		_, err := svc.GetLinkByShortURL("missing")
+		Expect(err).To(MatchError(domain.ErrNotFound))
+
+		_, err = svc.GetLinkByShortURL("missing")
+		Expect(err).To(MatchError(domain.ErrNotFound))
+
+		Expect(lookups["missing"]).To(Equal(1))
+	})
+
+	It("should not cache lookup failures other than not found", func() {
+		mockRepo.GetByShortURLFunc = func(shortURL string) (*domain.Link, error) {
+			lookups[shortURL]++
+			return nil, errors.New("connection refused")
+		}
+
+		_, err := svc.GetLinkByShortURL("promo")
+		Expect(err).To(HaveOccurred())
+		_, err = svc.GetLinkByShortURL("promo")
+		Expect(err).To(HaveOccurred())
+
+		Expect(lookups["promo"]).To(Equal(2))
+	})
+
+	It("should invalidate the old short URL on update", func() {
+		_, err := svc.GetLinkByShortURL("promo")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.UpdateLink("link-1", service.UpdateLinkRequest{
+			OriginalURL: "https://example.com/new",
+			CustomAlias: "sale",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.GetLinkByShortURL("promo")
+		Expect(err).To(MatchError(domain.ErrNotFound))
+		Expect(lookups["promo"]).To(Equal(2))
+
+		link, err := svc.GetLinkByShortURL("sale")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.OriginalURL).To(Equal("https://example.com/new"))
+	})
+
+	It("should replace a cached not found when the short URL is created", func() {
+		mockRepo.GetByShortURLFunc = func(shortURL string) (*domain.Link, error) {
+			lookups[shortURL]++
+			return nil, domain.ErrNotFound
+		}
+
+		_, err := svc.GetLinkByShortURL("fresh")
+		Expect(err).To(MatchError(domain.ErrNotFound))
+
+		_, err = svc.CreateLink(service.CreateLinkRequest{OriginalURL: "https://example.com", CustomAlias: "fresh"})
+		Expect(err).NotTo(HaveOccurred())
+
+		link, err := svc.GetLinkByShortURL("fresh")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.ShortURL).To(Equal("fresh"))
+	})
+
+	It("should invalidate the short URL on delete", func() {
+		_, err := svc.GetLinkByShortURL("promo")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(svc.DeleteLink("link-1")).To(Succeed())
+
+		_, err = svc.GetLinkByShortURL("promo")
+		Expect(err).To(MatchError(domain.ErrNotFound))
+		Expect(lookups["promo"]).To(Equal(2))
+	})
+})
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/cache"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("CachedURLShortenerService list caching", func() {
+	var (
+		ctx           context.Context
+		listCalls     int
+		filteredCalls int
+		links         []*domain.ShortLink
+		svc           *service.CachedURLShortenerService
+	)
+
+	newService := func(listTTL time.Duration) *service.CachedURLShortenerService {
+		linkRepo := &mocks.MockShortLinkRepository{
+			CountFunc: func(ctx context.Context) (int, error) {
+				return len(links), nil
+			},
+			ListFunc: func(ctx context.Context, offset, limit int) ([]*domain.ShortLink, error) {
+				listCalls++
+				return links, nil
+			},
+			CountFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error) {
+				return len(links), nil
+			},
+			ListFilteredFunc: func(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error) {
+				filteredCalls++
+				return links, nil
+			},
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return nil, errors.New("not found")
+			},
+			CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+				links = append(links, link)
+				return nil
+			},
+		}
+		urlRepo := &mocks.MockURLRepository{
+			GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+				return nil, errors.New("not found")
+			},
+		}
+
+		base := service.NewURLShortenerService(
+			urlRepo,
+			linkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			0,
+		)
+		cached := service.NewCachedURLShortenerService(base, cache.NewMemoryCache(), zaptest.NewLogger(GinkgoT()))
+		cached.CacheLists(listTTL)
+		return cached
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		listCalls = 0
+		filteredCalls = 0
+		links = []*domain.ShortLink{{ID: "link-1", Code: "abc123", IsActive: true}}
+	})
+
+	Context("with a list TTL", func() {
+		BeforeEach(func() {
+			svc = newService(time.Minute)
+		})
+
+		It("should serve a repeated identical list request from cache", func() {
+			first, total, err := svc.ListShortLinks(ctx, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+			second, secondTotal, err := svc.ListShortLinks(ctx, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listCalls).To(Equal(1))
+			Expect(second).To(Equal(first))
+			Expect(secondTotal).To(Equal(total))
+		})
+
+		It("should key pages on their params", func() {
+			_, _, err := svc.ListShortLinks(ctx, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = svc.ListShortLinks(ctx, 2, 20)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = svc.ListShortLinks(ctx, 1, 50)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listCalls).To(Equal(3))
+		})
+
+		It("should key filtered pages on the filter", func() {
+			after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			active := domain.LinkFilter{Status: domain.LinkStatusActive}
+			recent := domain.LinkFilter{Status: domain.LinkStatusActive, CreatedAfter: &after}
+
+			for range 2 {
+				_, _, err := svc.ListShortLinksFiltered(ctx, active, 1, 20)
+				Expect(err).NotTo(HaveOccurred())
+				_, _, err = svc.ListShortLinksFiltered(ctx, recent, 1, 20)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(filteredCalls).To(Equal(2))
+		})
+
+		It("should drop cached pages when a link is created", func() {
+			_, total, err := svc.ListShortLinks(ctx, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(1))
+
+			_, err = svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com/new"})
+			Expect(err).NotTo(HaveOccurred())
+
+			listed, total, err := svc.ListShortLinks(ctx, 1, 20)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listCalls).To(Equal(2))
+			Expect(total).To(Equal(2))
+			Expect(listed).To(HaveLen(2))
+		})
+	})
+
+	It("should not cache lists without a list TTL", func() {
+		svc = newService(0)
+
+		_, _, err := svc.ListShortLinks(ctx, 1, 20)
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = svc.ListShortLinks(ctx, 1, 20)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(listCalls).To(Equal(2))
+	})
+})
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService streamed link list", func() {
+	var (
+		ctx      context.Context
+		linkRepo *mocks.MockShortLinkRepository
+		svc      *service.URLShortenerService
+		events   []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		events = nil
+		linkRepo = &mocks.MockShortLinkRepository{
+			CountFunc: func(ctx context.Context) (int, error) {
+				return 42, nil
+			},
+			StreamPageFunc: func(ctx context.Context, offset, limit int, fn func(link *domain.ShortLink) error) error {
+				Expect(offset).To(Equal(20))
+				Expect(limit).To(Equal(10))
+				for _, code := range []string{"abc", "def"} {
+					if err := fn(&domain.ShortLink{Code: code}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+		svc = service.NewURLShortenerService(
+			&mocks.MockURLRepository{},
+			linkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://short.example.com",
+			0,
+		)
+	})
+
+	stream := func() error {
+		return svc.StreamShortLinks(ctx, 3, 10,
+			func(total int) error {
+				events = append(events, "total")
+				Expect(total).To(Equal(42))
+				return nil
+			},
+			func(link *domain.ShortLink) error {
+				events = append(events, link.Code)
+				return nil
+			},
+		)
+	}
+
+	It("should report the total before handing over the page's links", func() {
+		Expect(stream()).To(Succeed())
+		Expect(events).To(Equal([]string{"total", "abc", "def"}))
+	})
+
+	It("should not start when the links can't be counted", func() {
+		linkRepo.CountFunc = func(ctx context.Context) (int, error) {
+			return 0, errors.New("connection refused")
+		}
+
+		Expect(stream()).To(MatchError(ContainSubstring("counting short links")))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should stop at the first error of the callback", func() {
+		stop := errors.New("client went away")
+		err := svc.StreamShortLinks(ctx, 3, 10,
+			func(int) error { return nil },
+			func(link *domain.ShortLink) error {
+				events = append(events, link.Code)
+				return stop
+			},
+		)
+
+		Expect(err).To(MatchError(stop))
+		Expect(events).To(Equal([]string{"abc"}))
+	})
+})
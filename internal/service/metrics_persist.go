@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// Names of the persisted metric counters
+const (
+	metricRedirectsTotal  = "redirects_total"
+	metricRedirectsByLink = "redirects_by_link"
+)
+
+// RedirectCounters is the part of the metrics collector whose counters are persisted
+type RedirectCounters interface {
+	GetTotalRedirects() int64
+	GetRedirectsByLink() map[string]int64
+	AddRedirects(total int64, byLink map[string]int64)
+}
+
+// MetricsPersistJob keeps the in-memory redirect counters across restarts.
+// Load adds the persisted totals to the counters on startup and Flush adds
+// whatever was counted since the last flush to the store, so instances
+// sharing a database each contribute their own redirects.
+type MetricsPersistJob struct {
+	counters RedirectCounters
+	repo     repository.MetricCounterRepository
+	logger   *zap.Logger
+
+	mu            sync.Mutex
+	flushedTotal  int64
+	flushedByLink map[string]int64
+}
+
+// NewMetricsPersistJob creates a new metrics persistence job
+func NewMetricsPersistJob(counters RedirectCounters, repo repository.MetricCounterRepository, logger *zap.Logger) *MetricsPersistJob {
+	return &MetricsPersistJob{
+		counters:      counters,
+		repo:          repo,
+		logger:        logger,
+		flushedByLink: make(map[string]int64),
+	}
+}
+
+// Load adds the persisted totals to the counters. It is meant to run once
+// before the server starts counting.
+func (j *MetricsPersistJob) Load(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	counters, err := j.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("loading metric counters: %w", err)
+	}
+
+	var total int64
+	byLink := make(map[string]int64)
+	for _, counter := range counters {
+		switch counter.Name {
+		case metricRedirectsTotal:
+			total += counter.Value
+		case metricRedirectsByLink:
+			byLink[counter.Label] += counter.Value
+		}
+	}
+
+	j.counters.AddRedirects(total, byLink)
+
+	// What was just loaded is already stored and must not be flushed again
+	j.flushedTotal += total
+	for linkID, count := range byLink {
+		j.flushedByLink[linkID] += count
+	}
+
+	j.logger.Info("Loaded persisted metric counters",
+		zap.Int64("redirects", total),
+		zap.Int("links", len(byLink)),
+	)
+
+	return nil
+}
+
+// Flush stores what the counters gained since the last flush and returns
+// how many counters were written. After a failed flush the next one stores
+// the same redirects again along with any newer ones.
+func (j *MetricsPersistJob) Flush(ctx context.Context) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	total := j.counters.GetTotalRedirects()
+	byLink := j.counters.GetRedirectsByLink()
+
+	var deltas []domain.MetricCounter
+	if delta := total - j.flushedTotal; delta > 0 {
+		deltas = append(deltas, domain.MetricCounter{Name: metricRedirectsTotal, Value: delta})
+	}
+	for linkID, count := range byLink {
+		if delta := count - j.flushedByLink[linkID]; delta > 0 {
+			deltas = append(deltas, domain.MetricCounter{Name: metricRedirectsByLink, Label: linkID, Value: delta})
+		}
+	}
+
+	if len(deltas) == 0 {
+		return 0, nil
+	}
+
+	if err := j.repo.Add(ctx, deltas); err != nil {
+		return 0, fmt.Errorf("flushing metric counters: %w", err)
+	}
+
+	j.flushedTotal = total
+	j.flushedByLink = byLink
+
+	return len(deltas), nil
+}
+
+// Start flushes the counters periodically until the context is cancelled.
+// The final flush on shutdown is left to the caller, which can give it a
+// deadline of its own.
+func (j *MetricsPersistJob) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Flush(ctx); err != nil {
+				j.logger.Error("Metric counter flush failed", zap.Error(err))
+			}
+		}
+	}
+}
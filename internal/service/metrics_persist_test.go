@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/metrics"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("MetricsPersistJob", func() {
+	var (
+		ctx     context.Context
+		stored  map[domain.MetricCounter]int64 // keyed by name and label
+		addErr  error
+		repo    *mocks.MockMetricCounterRepository
+		writes  int
+		collect *metrics.Metrics
+		job     *service.MetricsPersistJob
+	)
+
+	key := func(counter domain.MetricCounter) domain.MetricCounter {
+		return domain.MetricCounter{Name: counter.Name, Label: counter.Label}
+	}
+
+	// start simulates a process start: fresh counters loaded from the store
+	start := func() {
+		collect = metrics.NewMetrics()
+		job = service.NewMetricsPersistJob(collect, repo, zaptest.NewLogger(GinkgoT()))
+		Expect(job.Load(ctx)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = map[domain.MetricCounter]int64{}
+		addErr = nil
+		writes = 0
+		repo = &mocks.MockMetricCounterRepository{
+			ListFunc: func(ctx context.Context) ([]domain.MetricCounter, error) {
+				var counters []domain.MetricCounter
+				for k, v := range stored {
+					counters = append(counters, domain.MetricCounter{Name: k.Name, Label: k.Label, Value: v})
+				}
+				return counters, nil
+			},
+			AddFunc: func(ctx context.Context, counters []domain.MetricCounter) error {
+				if addErr != nil {
+					return addErr
+				}
+				writes++
+				for _, counter := range counters {
+					stored[key(counter)] += counter.Value
+				}
+				return nil
+			},
+		}
+		start()
+	})
+
+	It("should keep the redirect counters across a restart", func() {
+		collect.RecordRedirect("link-1")
+		collect.RecordRedirect("link-1")
+		collect.RecordRedirect("link-2")
+		_, err := job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		start()
+
+		Expect(collect.GetTotalRedirects()).To(Equal(int64(3)))
+		Expect(collect.GetRedirectsByLink()).To(Equal(map[string]int64{"link-1": 2, "link-2": 1}))
+
+		// And keep counting on top of the loaded totals
+		collect.RecordRedirect("link-2")
+		_, err = job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		start()
+
+		Expect(collect.GetTotalRedirects()).To(Equal(int64(4)))
+		Expect(collect.GetRedirectsByLink()).To(Equal(map[string]int64{"link-1": 2, "link-2": 2}))
+	})
+
+	It("should only store what was counted since the last flush", func() {
+		collect.RecordRedirect("link-1")
+		n, err := job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+
+		n, err = job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+		Expect(writes).To(Equal(1))
+
+		collect.RecordRedirect("link-1")
+		_, err = job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stored).To(Equal(map[domain.MetricCounter]int64{
+			{Name: "redirects_total"}:                    2,
+			{Name: "redirects_by_link", Label: "link-1"}: 2,
+		}))
+	})
+
+	It("should store the redirects of a failed flush with the next one", func() {
+		collect.RecordRedirect("link-1")
+		addErr = errors.New("connection refused")
+		_, err := job.Flush(ctx)
+		Expect(err).To(HaveOccurred())
+
+		addErr = nil
+		collect.RecordRedirect("link-1")
+		_, err = job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		start()
+
+		Expect(collect.GetTotalRedirects()).To(Equal(int64(2)))
+	})
+
+	It("should add the counts of every instance sharing the store", func() {
+		other := metrics.NewMetrics()
+		otherJob := service.NewMetricsPersistJob(other, repo, zaptest.NewLogger(GinkgoT()))
+		Expect(otherJob.Load(ctx)).To(Succeed())
+
+		collect.RecordRedirect("link-1")
+		other.RecordRedirect("link-1")
+		_, err := job.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = otherJob.Flush(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		start()
+
+		Expect(collect.GetRedirectsByLink()).To(HaveKeyWithValue("link-1", int64(2)))
+	})
+})
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// Reachability check modes
+const (
+	ReachabilityOff    = "off"
+	ReachabilityFlag   = "flag"
+	ReachabilityReject = "reject"
+)
+
+// defaultReachabilityTimeout bounds a single probe when no timeout is configured
+const defaultReachabilityTimeout = 3 * time.Second
+
+// errPrivateDestination is returned for probes that would connect to a
+// private, loopback or link-local address
+var errPrivateDestination = errors.New("destination address is not public")
+
+// reachabilityChecker probes destination URLs with a HEAD request, falling
+// back to GET for servers that do not implement HEAD
+type reachabilityChecker struct {
+	client *http.Client
+}
+
+// newReachabilityChecker creates a checker whose probes never exceed timeout
+// and only connect to public addresses or those allowed, given as CIDR
+// prefixes or ip:port pairs. Malformed allowed entries are ignored.
+func newReachabilityChecker(timeout time.Duration, allowed []string) *reachabilityChecker {
+	if timeout <= 0 {
+		timeout = defaultReachabilityTimeout
+	}
+
+	guard := newProbeGuard(allowed)
+	dialer := &net.Dialer{Timeout: timeout, Control: guard.control}
+
+	// Probes go straight to the destination so the guard sees its address
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &reachabilityChecker{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// Redirects are followed so the final destination is what gets
+			// judged; every hop dials through the guard
+		},
+	}
+}
+
+// probeGuard refuses connections to addresses that are not public, so
+// destinations cannot make probes reach the internal network
+type probeGuard struct {
+	prefixes []netip.Prefix
+	addrs    []netip.AddrPort
+}
+
+func newProbeGuard(allowed []string) *probeGuard {
+	g := &probeGuard{}
+	for _, entry := range allowed {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.prefixes = append(g.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddrPort(entry); err == nil {
+			g.addrs = append(g.addrs, netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port()))
+		}
+	}
+	return g
+}
+
+// control runs after DNS resolution and before each connect, so it sees
+// the address actually dialed, including those of redirect hops
+func (g *probeGuard) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parsing dialed address %q: %w", address, err)
+	}
+	addr := addrPort.Addr().Unmap().WithZone("")
+
+	if !isPrivateAddr(addr) || g.allows(netip.AddrPortFrom(addr, addrPort.Port())) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errPrivateDestination, addr)
+}
+
+func (g *probeGuard) allows(addrPort netip.AddrPort) bool {
+	for _, prefix := range g.prefixes {
+		if prefix.Contains(addrPort.Addr()) {
+			return true
+		}
+	}
+	for _, allowed := range g.addrs {
+		if allowed == addrPort {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateAddr reports whether addr is loopback, private, link-local or
+// unspecified, none of which a public destination resolves to
+func isPrivateAddr(addr netip.Addr) bool {
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsUnspecified()
+}
+
+// Check returns nil when the URL answers with a non-error status. DNS
+// failures, connection errors, timeouts and 4xx/5xx responses are errors.
+func (r *reachabilityChecker) Check(ctx context.Context, rawURL string) error {
+	status, err := r.Status(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("destination responded with status %d", status)
+	}
+
+	return nil
+}
+
+// Status returns the status code the URL finally answers with, retrying
+// with GET when HEAD is not supported
+func (r *reachabilityChecker) Status(ctx context.Context, rawURL string) (int, error) {
+	status, err := r.probe(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = r.probe(ctx, http.MethodGet, rawURL)
+	}
+	return status, err
+}
+
+// probe issues a single request and returns the response status code
+func (r *reachabilityChecker) probe(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("requesting destination: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
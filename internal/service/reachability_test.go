@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService reachability check", func() {
+	var (
+		mockURLRepo       *mocks.MockURLRepository
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		server            *httptest.Server
+		requests          int32
+		created           *domain.ShortLink
+		ctx               context.Context
+	)
+
+	newService := func(mode string) *service.URLShortenerService {
+		// The stub destination listens on loopback, which probes refuse
+		// unless allowed
+		var allowed []string
+		if server != nil {
+			allowed = []string{server.Listener.Addr().String()}
+		}
+
+		return service.NewURLShortenerServiceWithOptions(
+			mockURLRepo,
+			mockShortLinkRepo,
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				ReachabilityCheck:     mode,
+				ReachabilityTimeout:   100 * time.Millisecond,
+				ProbeAllowedAddresses: allowed,
+			},
+		)
+	}
+
+	// serve starts a stub destination that answers with status after delay
+	serve := func(status int, delay time.Duration) {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			w.WriteHeader(status)
+		}))
+	}
+
+	BeforeEach(func() {
+		requests = 0
+		created = nil
+		ctx = context.Background()
+
+		mockURLRepo = &mocks.MockURLRepository{
+			GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+				return nil, errors.New("not found")
+			},
+			CreateFunc: func(ctx context.Context, url *domain.URL) error {
+				return nil
+			},
+		}
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+				return nil, errors.New("not found")
+			},
+			CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+				created = link
+				return nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+			server = nil
+		}
+	})
+
+	Context("when the check is disabled", func() {
+		It("should create the link without probing the destination", func() {
+			serve(http.StatusNotFound, 0)
+
+			link, err := newService(service.ReachabilityOff).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Reachable).To(BeNil())
+			Expect(atomic.LoadInt32(&requests)).To(BeZero())
+		})
+	})
+
+	Context("when unreachable destinations are rejected", func() {
+		It("should create the link when the destination returns 200", func() {
+			serve(http.StatusOK, 0)
+
+			link, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Reachable).To(HaveValue(BeTrue()))
+			Expect(atomic.LoadInt32(&requests)).To(BeNumerically(">", 0))
+		})
+
+		It("should reject a destination that returns 404", func() {
+			serve(http.StatusNotFound, 0)
+
+			_, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+			Expect(err.Error()).To(ContainSubstring("status 404"))
+			Expect(created).To(BeNil())
+		})
+
+		It("should reject a destination that does not answer within the timeout", func() {
+			serve(http.StatusOK, 2*time.Second)
+
+			start := time.Now()
+			_, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(created).To(BeNil())
+		})
+
+		It("should reject a destination whose host does not resolve", func() {
+			_, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "http://unreachable.invalid/"})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+		})
+	})
+
+	Context("when the destination leads to a private address", func() {
+		var internal *httptest.Server
+		var internalRequests int32
+
+		BeforeEach(func() {
+			internalRequests = 0
+			internal = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&internalRequests, 1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(internal.Close)
+		})
+
+		It("should refuse a destination on a loopback address", func() {
+			_, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: internal.URL})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+			Expect(err.Error()).To(ContainSubstring("not public"))
+			Expect(atomic.LoadInt32(&internalRequests)).To(BeZero())
+			Expect(created).To(BeNil())
+		})
+
+		It("should refuse a redirect to 127.0.0.1", func() {
+			target := "http://127.0.0.1:" + internal.URL[strings.LastIndex(internal.URL, ":")+1:] + "/admin"
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				http.Redirect(w, r, target, http.StatusFound)
+			}))
+
+			_, err := newService(service.ReachabilityReject).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).To(MatchError(domain.ErrValidation))
+			Expect(err.Error()).To(ContainSubstring("not public: 127.0.0.1"))
+			Expect(atomic.LoadInt32(&requests)).To(BeNumerically(">", 0))
+			Expect(atomic.LoadInt32(&internalRequests)).To(BeZero())
+			Expect(created).To(BeNil())
+		})
+
+		It("should flag the link as unreachable", func() {
+			link, err := newService(service.ReachabilityFlag).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: internal.URL})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Reachable).To(HaveValue(BeFalse()))
+			Expect(atomic.LoadInt32(&internalRequests)).To(BeZero())
+		})
+	})
+
+	Context("when reachability is only flagged", func() {
+		It("should store the link as unreachable when the destination returns 404", func() {
+			serve(http.StatusNotFound, 0)
+
+			link, err := newService(service.ReachabilityFlag).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Reachable).To(HaveValue(BeFalse()))
+			Expect(created.Reachable).To(HaveValue(BeFalse()))
+		})
+
+		It("should store the link as unreachable when the destination times out", func() {
+			serve(http.StatusOK, 2*time.Second)
+
+			link, err := newService(service.ReachabilityFlag).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: server.URL})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Reachable).To(HaveValue(BeFalse()))
+		})
+	})
+})
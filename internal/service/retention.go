@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// ClickRetentionJob purges raw click data older than the retention period
+type ClickRetentionJob struct {
+	clickRepo repository.LinkClickRepository
+	retention time.Duration
+	archive   bool
+	logger    *zap.Logger
+}
+
+// NewClickRetentionJob creates a new click retention job
+func NewClickRetentionJob(
+	clickRepo repository.LinkClickRepository,
+	retention time.Duration,
+	archive bool,
+	logger *zap.Logger,
+) *ClickRetentionJob {
+	return &ClickRetentionJob{
+		clickRepo: clickRepo,
+		retention: retention,
+		archive:   archive,
+		logger:    logger,
+	}
+}
+
+// Enabled reports whether a retention period is configured
+func (j *ClickRetentionJob) Enabled() bool {
+	return j.retention > 0
+}
+
+// Run deletes clicks older than the retention window and returns how many were removed
+func (j *ClickRetentionJob) Run(ctx context.Context) (int64, error) {
+	if !j.Enabled() {
+		return 0, nil
+	}
+
+	cutoff := time.Now().UTC().Add(-j.retention)
+	deleted, err := j.clickRepo.DeleteOlderThan(ctx, cutoff, j.archive)
+	if err != nil {
+		return 0, fmt.Errorf("purging expired clicks: %w", err)
+	}
+
+	j.logger.Info("Purged expired clicks",
+		zap.Int64("deleted", deleted),
+		zap.Time("cutoff", cutoff),
+		zap.Bool("archived", j.archive),
+	)
+
+	return deleted, nil
+}
+
+// Start runs the job periodically until the context is cancelled
+func (j *ClickRetentionJob) Start(ctx context.Context, interval time.Duration) {
+	if !j.Enabled() || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				j.logger.Error("Click retention run failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("ClickRetentionJob", func() {
+	var (
+		mockClickRepo *mocks.MockLinkClickRepository
+		stored        []*domain.LinkClick
+		archived      map[string]int
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now := time.Now().UTC()
+		stored = []*domain.LinkClick{
+			{ID: "old-1", ShortLinkID: "link-1", CreatedAt: now.Add(-40 * 24 * time.Hour)},
+			{ID: "old-2", ShortLinkID: "link-1", CreatedAt: now.Add(-31 * 24 * time.Hour)},
+			{ID: "recent-1", ShortLinkID: "link-1", CreatedAt: now.Add(-2 * 24 * time.Hour)},
+			{ID: "recent-2", ShortLinkID: "link-2", CreatedAt: now.Add(-time.Hour)},
+		}
+		archived = map[string]int{}
+
+		// In-memory stand-in for the link_clicks table
+		mockClickRepo = &mocks.MockLinkClickRepository{
+			DeleteOlderThanFunc: func(ctx context.Context, cutoff time.Time, archive bool) (int64, error) {
+				var kept []*domain.LinkClick
+				var deleted int64
+				for _, click := range stored {
+					if click.CreatedAt.Before(cutoff) {
+						deleted++
+						if archive {
+							archived[click.ShortLinkID]++
+						}
+						continue
+					}
+					kept = append(kept, click)
+				}
+				stored = kept
+				return deleted, nil
+			},
+		}
+	})
+
+	It("deletes clicks older than the window and preserves recent ones", func() {
+		job := service.NewClickRetentionJob(mockClickRepo, 30*24*time.Hour, true, zaptest.NewLogger(GinkgoT()))
+
+		deleted, err := job.Run(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal(int64(2)))
+
+		var remaining []string
+		for _, click := range stored {
+			remaining = append(remaining, click.ID)
+		}
+		Expect(remaining).To(ConsistOf("recent-1", "recent-2"))
+	})
+
+	It("archives the totals of the deleted clicks when requested", func() {
+		job := service.NewClickRetentionJob(mockClickRepo, 30*24*time.Hour, true, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archived).To(Equal(map[string]int{"link-1": 2}))
+	})
+
+	It("does nothing when no retention period is configured", func() {
+		job := service.NewClickRetentionJob(mockClickRepo, 0, true, zaptest.NewLogger(GinkgoT()))
+
+		deleted, err := job.Run(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeZero())
+		Expect(stored).To(HaveLen(4))
+	})
+
+	It("returns repository errors", func() {
+		mockClickRepo.DeleteOlderThanFunc = func(ctx context.Context, cutoff time.Time, archive bool) (int64, error) {
+			return 0, errors.New("database error")
+		}
+		job := service.NewClickRetentionJob(mockClickRepo, time.Hour, false, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("database error"))
+	})
+})
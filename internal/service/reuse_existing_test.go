@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("Creating a link for an already shortened URL", func() {
+	var (
+		ctx      context.Context
+		existing []*domain.ShortLink
+		created  []*domain.ShortLink
+		svc      *service.URLShortenerService
+	)
+
+	const destination = "https://example.com/already/shortened"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = nil
+
+		now := time.Now().UTC()
+		expired := now.Add(-time.Hour)
+		existing = []*domain.ShortLink{
+			{ID: "old", Code: "old111", URLID: "url-1", IsActive: true, CreatedAt: now.Add(-72 * time.Hour)},
+			{ID: "newest", Code: "new222", URLID: "url-1", IsActive: true, CreatedAt: now.Add(-24 * time.Hour)},
+			{ID: "disabled", Code: "off333", URLID: "url-1", IsActive: false, CreatedAt: now.Add(-time.Hour)},
+			{ID: "expired", Code: "exp444", URLID: "url-1", IsActive: true, CreatedAt: now.Add(-2 * time.Hour), ExpirationDate: &expired},
+		}
+
+		svc = service.NewURLShortenerService(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return &domain.URL{ID: "url-1", OriginalURL: destination, Hash: hash}, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: destination}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetAllByURLIDFunc: func(ctx context.Context, urlID string) ([]*domain.ShortLink, error) {
+					return existing, nil
+				},
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, domain.ErrNotFound
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, domain.ErrNotFound
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = append(created, link)
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://sho.rt",
+			0,
+		)
+	})
+
+	It("should create a second link by default", func() {
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: destination})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(HaveLen(1))
+		Expect(link.ID).To(Equal(created[0].ID))
+		Expect(link.Reused).To(BeFalse())
+	})
+
+	It("should return the newest active link with reuse_existing", func() {
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: destination, ReuseExisting: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeEmpty())
+		Expect(link.ID).To(Equal("newest"))
+		Expect(link.Reused).To(BeTrue())
+		Expect(link.URL.OriginalURL).To(Equal(destination))
+	})
+
+	It("should create a link with reuse_existing when none is usable", func() {
+		existing = existing[2:]
+
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: destination, ReuseExisting: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(HaveLen(1))
+		Expect(link.Reused).To(BeFalse())
+	})
+
+	It("should not reuse a link for a custom alias", func() {
+		alias := "my-alias"
+
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: destination, CustomAlias: &alias, ReuseExisting: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Code).To(Equal(alias))
+		Expect(created).To(HaveLen(1))
+	})
+})
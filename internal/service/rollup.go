@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// ClickRollupJob aggregates raw clicks of completed UTC days into daily rollups
+type ClickRollupJob struct {
+	clickRepo repository.LinkClickRepository
+	logger    *zap.Logger
+}
+
+// NewClickRollupJob creates a new click rollup job
+func NewClickRollupJob(clickRepo repository.LinkClickRepository, logger *zap.Logger) *ClickRollupJob {
+	return &ClickRollupJob{
+		clickRepo: clickRepo,
+		logger:    logger,
+	}
+}
+
+// Run rolls up every completed day after the current watermark and returns how many days were processed
+func (j *ClickRollupJob) Run(ctx context.Context) (int, error) {
+	today := truncateToDay(time.Now())
+
+	var next time.Time
+	watermark, err := j.clickRepo.GetRollupWatermark(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting rollup watermark: %w", err)
+	}
+
+	if watermark != nil {
+		next = truncateToDay(*watermark).AddDate(0, 0, 1)
+	} else {
+		earliest, err := j.clickRepo.GetEarliestCreatedAt(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("getting earliest click: %w", err)
+		}
+		if earliest == nil {
+			return 0, nil
+		}
+		next = truncateToDay(*earliest)
+	}
+
+	days := 0
+	for ; next.Before(today); next = next.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return days, err
+		}
+
+		if err := j.RollupDay(ctx, next); err != nil {
+			return days, err
+		}
+		days++
+	}
+
+	if days > 0 {
+		j.logger.Info("Rolled up daily click stats",
+			zap.Int("days", days),
+			zap.Time("through", next.AddDate(0, 0, -1)),
+		)
+	}
+
+	return days, nil
+}
+
+// RollupDay recomputes the aggregates for a single UTC day from the raw clicks
+func (j *ClickRollupJob) RollupDay(ctx context.Context, day time.Time) error {
+	day = truncateToDay(day)
+	agg := newClickAggregator(day)
+
+	err := j.clickRepo.StreamByCreatedRange(ctx, day, day.AddDate(0, 0, 1), func(click *domain.LinkClick) error {
+		agg.add(click)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading clicks for %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	if err := j.clickRepo.SaveDailyRollup(ctx, day, agg.rollups()); err != nil {
+		return fmt.Errorf("saving rollup for %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	return nil
+}
+
+// Start runs the job periodically until the context is cancelled
+func (j *ClickRollupJob) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	if _, err := j.Run(ctx); err != nil {
+		j.logger.Error("Click rollup run failed", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				j.logger.Error("Click rollup run failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+type rollupKey struct {
+	shortLinkID string
+	dimension   string
+	value       string
+}
+
+// clickAggregator counts clicks per link and dimension value for one day,
+// scaling sampled clicks by their weight
+type clickAggregator struct {
+	day    time.Time
+	counts map[rollupKey]int
+	order  []rollupKey
+}
+
+func newClickAggregator(day time.Time) *clickAggregator {
+	return &clickAggregator{
+		day:    day,
+		counts: make(map[rollupKey]int),
+	}
+}
+
+func (a *clickAggregator) add(click *domain.LinkClick) {
+	weight := max(click.SampleWeight, 1)
+	a.inc(click.ShortLinkID, domain.RollupDimensionTotal, "", weight)
+
+	// Unset dimensions are left out, matching the raw stats queries
+	dimensions := []struct {
+		name  string
+		value *string
+	}{
+		{domain.RollupDimensionReferrer, click.Referrer},
+		{domain.RollupDimensionBrowser, click.Browser},
+		{domain.RollupDimensionOS, click.OS},
+		{domain.RollupDimensionDevice, click.Device},
+		{domain.RollupDimensionUTMSource, click.UTMSource},
+		{domain.RollupDimensionUTMMedium, click.UTMMedium},
+		{domain.RollupDimensionUTMCampaign, click.UTMCampaign},
+		{domain.RollupDimensionCountry, click.Country},
+		{domain.RollupDimensionCity, click.City},
+	}
+
+	for _, d := range dimensions {
+		if d.value != nil {
+			a.inc(click.ShortLinkID, d.name, *d.value, weight)
+		}
+	}
+}
+
+func (a *clickAggregator) inc(shortLinkID, dimension, value string, clicks int) {
+	key := rollupKey{shortLinkID: shortLinkID, dimension: dimension, value: value}
+	if _, ok := a.counts[key]; !ok {
+		a.order = append(a.order, key)
+	}
+	a.counts[key] += clicks
+}
+
+func (a *clickAggregator) rollups() []*domain.DailyClickRollup {
+	rollups := make([]*domain.DailyClickRollup, 0, len(a.order))
+	for _, key := range a.order {
+		rollups = append(rollups, &domain.DailyClickRollup{
+			ShortLinkID: key.shortLinkID,
+			Day:         a.day,
+			Dimension:   key.dimension,
+			Value:       key.value,
+			Clicks:      a.counts[key],
+		})
+	}
+	return rollups
+}
+
+// truncateToDay returns the start of the UTC day containing t
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
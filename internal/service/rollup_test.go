@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("ClickRollupJob", func() {
+	var (
+		mockClickRepo *mocks.MockLinkClickRepository
+		stored        []*domain.LinkClick
+		daily         map[string][]*domain.DailyClickRollup
+		watermark     *time.Time
+		today         time.Time
+		ctx           context.Context
+	)
+
+	strPtr := func(s string) *string { return &s }
+
+	day := func(t time.Time) string { return t.UTC().Format("2006-01-02") }
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now := time.Now().UTC()
+		today = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		daily = map[string][]*domain.DailyClickRollup{}
+		watermark = nil
+
+		browsers := []string{"Chrome", "Firefox", "Safari"}
+		referrers := []string{"https://news.example", "https://social.example"}
+		stored = nil
+		for i := 0; i < 60; i++ {
+			click := &domain.LinkClick{
+				ID:          "click",
+				ShortLinkID: []string{"link-1", "link-2"}[i%2],
+				Browser:     strPtr(browsers[i%3]),
+				OS:          strPtr("Linux"),
+				// Spread clicks across three completed days and today
+				CreatedAt: today.AddDate(0, 0, -(i % 4)).Add(time.Duration(i) * time.Minute),
+			}
+			if i%5 != 0 {
+				click.Referrer = strPtr(referrers[i%2])
+			}
+			stored = append(stored, click)
+		}
+
+		// In-memory stand-in for link_clicks, link_click_daily and the watermark
+		mockClickRepo = &mocks.MockLinkClickRepository{
+			StreamByCreatedRangeFunc: func(ctx context.Context, start, end time.Time, fn func(click *domain.LinkClick) error) error {
+				for _, click := range stored {
+					if !click.CreatedAt.Before(start) && click.CreatedAt.Before(end) {
+						if err := fn(click); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			},
+			GetEarliestCreatedAtFunc: func(ctx context.Context) (*time.Time, error) {
+				var earliest *time.Time
+				for _, click := range stored {
+					if earliest == nil || click.CreatedAt.Before(*earliest) {
+						t := click.CreatedAt
+						earliest = &t
+					}
+				}
+				return earliest, nil
+			},
+			GetRollupWatermarkFunc: func(ctx context.Context) (*time.Time, error) {
+				return watermark, nil
+			},
+			SaveDailyRollupFunc: func(ctx context.Context, d time.Time, rollups []*domain.DailyClickRollup) error {
+				daily[day(d)] = rollups
+				if watermark == nil || d.After(*watermark) {
+					watermark = &d
+				}
+				return nil
+			},
+		}
+	})
+
+	// rawCounts recomputes a dimension directly from the raw clicks before today
+	rawCounts := func(shortLinkID string, value func(*domain.LinkClick) *string) map[string]int {
+		counts := map[string]int{}
+		for _, click := range stored {
+			if click.ShortLinkID != shortLinkID || !click.CreatedAt.Before(today) {
+				continue
+			}
+			if v := value(click); v != nil {
+				counts[*v]++
+			}
+		}
+		return counts
+	}
+
+	rolledCounts := func(shortLinkID, dimension string) map[string]int {
+		counts := map[string]int{}
+		for _, rollups := range daily {
+			for _, r := range rollups {
+				if r.ShortLinkID == shortLinkID && r.Dimension == dimension {
+					counts[r.Value] += r.Clicks
+				}
+			}
+		}
+		return counts
+	}
+
+	It("rolls up every completed day but not today", func() {
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		days, err := job.Run(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(Equal(3))
+		Expect(daily).To(HaveLen(3))
+		Expect(daily).NotTo(HaveKey(day(today)))
+		Expect(*watermark).To(Equal(today.AddDate(0, 0, -1)))
+	})
+
+	It("produces rollups that match a recomputation from raw clicks", func() {
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, link := range []string{"link-1", "link-2"} {
+			Expect(rolledCounts(link, domain.RollupDimensionReferrer)).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.Referrer })))
+			Expect(rolledCounts(link, domain.RollupDimensionBrowser)).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.Browser })))
+			Expect(rolledCounts(link, domain.RollupDimensionOS)).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.OS })))
+			Expect(rolledCounts(link, domain.RollupDimensionDevice)).To(BeEmpty())
+		}
+
+		for d, rollups := range daily {
+			raw := 0
+			for _, click := range stored {
+				if day(click.CreatedAt) == d {
+					raw++
+				}
+			}
+
+			rolled := 0
+			for _, r := range rollups {
+				if r.Dimension == domain.RollupDimensionTotal {
+					rolled += r.Clicks
+				}
+			}
+			Expect(rolled).To(Equal(raw), "totals for %s", d)
+		}
+	})
+
+	It("scales sampled clicks by their weight", func() {
+		for _, click := range stored {
+			if click.ShortLinkID == "link-1" {
+				click.SampleWeight = 10
+			}
+		}
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		raw := rawCounts("link-1", func(c *domain.LinkClick) *string { return c.Browser })
+		for browser, count := range raw {
+			raw[browser] = count * 10
+		}
+		Expect(rolledCounts("link-1", domain.RollupDimensionBrowser)).To(Equal(raw))
+		Expect(rolledCounts("link-2", domain.RollupDimensionBrowser)).To(Equal(
+			rawCounts("link-2", func(c *domain.LinkClick) *string { return c.Browser })))
+	})
+
+	It("groups clicks by campaign source", func() {
+		sources := []string{"newsletter", "twitter", "newsletter"}
+		for i, click := range stored {
+			if i%4 != 0 {
+				click.UTMSource = strPtr(sources[i%3])
+			}
+		}
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, link := range []string{"link-1", "link-2"} {
+			rolled := rolledCounts(link, domain.RollupDimensionUTMSource)
+			Expect(rolled).To(HaveKey("newsletter"))
+			Expect(rolled).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.UTMSource })))
+		}
+	})
+
+	It("groups clicks by country and city, leaving unknown ones out", func() {
+		countries := []string{"US", "US", "US", "DE", "DE", "FR"}
+		for i, click := range stored {
+			if i%7 != 0 {
+				click.Country = strPtr(countries[i%6])
+				click.City = strPtr(map[string]string{"US": "Boston", "DE": "Berlin", "FR": "Paris"}[countries[i%6]])
+			}
+		}
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, link := range []string{"link-1", "link-2"} {
+			rolled := rolledCounts(link, domain.RollupDimensionCountry)
+			Expect(rolled).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.Country })))
+			Expect(rolled).NotTo(HaveKey(""))
+			Expect(rolledCounts(link, domain.RollupDimensionCity)).To(Equal(
+				rawCounts(link, func(c *domain.LinkClick) *string { return c.City })))
+		}
+
+		// Three in six clicks come from the US, two from Germany, one from France
+		rolled := rolledCounts("link-1", domain.RollupDimensionCountry)
+		rolled2 := rolledCounts("link-2", domain.RollupDimensionCountry)
+		for country, count := range rolled2 {
+			rolled[country] += count
+		}
+		Expect(rolled["US"]).To(BeNumerically(">", rolled["DE"]))
+		Expect(rolled["DE"]).To(BeNumerically(">", rolled["FR"]))
+	})
+
+	It("only processes days after the watermark on later runs", func() {
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		_, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		days, err := job.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(BeZero())
+	})
+
+	It("does nothing when there are no clicks", func() {
+		stored = nil
+		job := service.NewClickRollupJob(mockClickRepo, zaptest.NewLogger(GinkgoT()))
+
+		days, err := job.Run(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(BeZero())
+		Expect(daily).To(BeEmpty())
+	})
+})
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// Handling of destinations that are short links on this service
+const (
+	SelfLinkReject  = "reject"
+	SelfLinkResolve = "resolve"
+)
+
+// defaultInternalPaths are the paths on our own hosts refused as
+// destinations when none are configured
+var defaultInternalPaths = []string{"/metrics", "/admin", "/api/metrics", "/api/admin"}
+
+// ownShortURL reports whether rawURL points at one of the hosts serving
+// our short links: the base URL's host and any configured ShortHosts
+func (s *URLShortenerService) ownShortURL(rawURL string) (*url.URL, bool) {
+	dest, err := url.Parse(rawURL)
+	if err != nil || dest.Host == "" || !isWebScheme(strings.ToLower(dest.Scheme)) {
+		return nil, false
+	}
+
+	hosts := s.opts.ShortHosts
+	if base, err := url.Parse(s.baseURL); err == nil && base.Host != "" {
+		hosts = append([]string{base.Host}, hosts...)
+	}
+
+	for _, host := range hosts {
+		// A trailing dot names the same host, e.g. short.example.com.
+		hostname := strings.TrimSuffix(dest.Hostname(), ".")
+		if strings.EqualFold(dest.Host, host) || strings.EqualFold(hostname, host) {
+			return dest, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveSelfLink returns the destination of the short link dest points
+// at, so shortening one of our own short URLs doesn't chain redirects
+// through us. Unless SelfLinks is SelfLinkResolve, such destinations are
+// rejected instead.
+func (s *URLShortenerService) resolveSelfLink(ctx context.Context, dest *url.URL) (string, error) {
+	verr := &domain.ValidationError{}
+
+	if s.opts.SelfLinks != SelfLinkResolve {
+		verr.Add("url", "destination must not be a short link on this service")
+		return "", verr.Err()
+	}
+
+	// Only a plain code or its preview page names a single link
+	code, rest, _ := strings.Cut(strings.TrimPrefix(dest.Path, "/"), "/")
+	if code == "" || (rest != "" && rest != "preview") {
+		verr.Add("url", "destination must not be a page on this service")
+		return "", verr.Err()
+	}
+
+	link, err := s.GetShortLinkByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || strings.Contains(err.Error(), "not found") {
+			verr.Add("url", "destination is not an existing short link")
+			return "", verr.Err()
+		}
+		return "", err
+	}
+
+	if !link.IsActive || (link.ExpirationDate != nil && link.ExpirationDate.Before(time.Now())) {
+		verr.Add("url", "destination short link is inactive or expired")
+		return "", verr.Err()
+	}
+
+	if link.URL == nil || link.URL.OriginalURL == "" {
+		verr.Add("url", "destination short link has no destination")
+		return "", verr.Err()
+	}
+
+	return link.URL.OriginalURL, nil
+}
+
+// internalDestination reports whether rawURL points at an internal endpoint
+// on one of our hosts, such as metrics or the admin API, which a short link
+// on our trusted domain would make look legitimate. Whichever way a
+// destination on our hosts is handled otherwise, these are always refused.
+func (s *URLShortenerService) internalDestination(rawURL string) bool {
+	dest, ok := s.ownShortURL(rawURL)
+	if !ok {
+		return false
+	}
+
+	// Match the path the router would see, whatever its case or extra slashes
+	destPath := strings.ToLower(path.Clean("/" + dest.Path))
+	for _, internal := range s.internalPaths() {
+		internal = strings.ToLower(strings.TrimRight(internal, "/"))
+		if destPath == internal || strings.HasPrefix(destPath, internal+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// internalPaths returns the configured internal paths, defaulting to the
+// metrics and admin endpoints
+func (s *URLShortenerService) internalPaths() []string {
+	if len(s.opts.InternalPaths) == 0 {
+		return defaultInternalPaths
+	}
+	return s.opts.InternalPaths
+}
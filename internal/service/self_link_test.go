@@ -0,0 +1,229 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService self-referential links", func() {
+	var (
+		ctx    context.Context
+		stored []string
+	)
+
+	newService := func(selfLinks string) *service.URLShortenerService {
+		expired := time.Now().Add(-time.Hour)
+		links := map[string]*domain.ShortLink{
+			"abc": {ID: "link-1", Code: "abc", URLID: "url-1", IsActive: true},
+			"off": {ID: "link-2", Code: "off", URLID: "url-1", IsActive: false},
+			"old": {ID: "link-3", Code: "old", URLID: "url-1", IsActive: true, ExpirationDate: &expired},
+		}
+
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com/article"}, nil
+				},
+				CreateFunc: func(ctx context.Context, url *domain.URL) error {
+					stored = append(stored, url.OriginalURL)
+					return nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					if link, ok := links[code]; ok {
+						copied := *link
+						return &copied, nil
+					}
+					return nil, domain.ErrNotFound
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:    "https://sho.rt",
+				SelfLinks:  selfLinks,
+				ShortHosts: []string{"go.example.com"},
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = nil
+	})
+
+	Context("by default", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			svc = newService("")
+		})
+
+		It("should reject one of our own short URLs", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://sho.rt/abc"})
+
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+			var verr *domain.ValidationError
+			Expect(errors.As(err, &verr)).To(BeTrue())
+			Expect(verr.Fields).To(ContainElement(domain.FieldError{
+				Field:   "url",
+				Message: "destination must not be a short link on this service",
+			}))
+			Expect(stored).To(BeEmpty())
+		})
+
+		It("should match hosts case-insensitively and on configured extra hosts", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "http://SHO.RT/abc"})
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+
+			_, err = svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://go.example.com/abc"})
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+		})
+
+		It("should accept an external URL", func() {
+			link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.org/page"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).NotTo(BeNil())
+			Expect(stored).To(Equal([]string{"https://example.org/page"}))
+		})
+
+		It("should accept a host that only ends with ours", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://notsho.rt/abc"})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when resolving self links", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			svc = newService(service.SelfLinkResolve)
+		})
+
+		It("should store the destination of the short link", func() {
+			link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://sho.rt/abc"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).NotTo(BeNil())
+			Expect(stored).To(Equal([]string{"https://example.com/article"}))
+		})
+
+		It("should resolve a link's preview page", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://go.example.com/abc/preview"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(Equal([]string{"https://example.com/article"}))
+		})
+
+		It("should leave external URLs alone", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.org/page"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(Equal([]string{"https://example.org/page"}))
+		})
+
+		DescribeTable("should reject URLs that don't name a live link",
+			func(url, message string) {
+				_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: url})
+
+				var verr *domain.ValidationError
+				Expect(errors.As(err, &verr)).To(BeTrue())
+				Expect(verr.Fields).To(ContainElement(domain.FieldError{Field: "url", Message: message}))
+				Expect(stored).To(BeEmpty())
+			},
+			Entry("unknown code", "https://sho.rt/nope", "destination is not an existing short link"),
+			Entry("inactive link", "https://sho.rt/off", "destination short link is inactive or expired"),
+			Entry("expired link", "https://sho.rt/old", "destination short link is inactive or expired"),
+			Entry("root path", "https://sho.rt/", "destination must not be a page on this service"),
+			Entry("API path", "https://sho.rt/api/links", "destination must not be a page on this service"),
+		)
+	})
+
+	Context("with destinations on our internal endpoints", func() {
+		var svc *service.URLShortenerService
+
+		BeforeEach(func() {
+			// Resolving is the most lenient mode, so the rejection below
+			// can't come from the self link handling
+			svc = newService(service.SelfLinkResolve)
+		})
+
+		DescribeTable("should reject them whatever the self link mode",
+			func(url string) {
+				_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: url})
+
+				var verr *domain.ValidationError
+				Expect(errors.As(err, &verr)).To(BeTrue())
+				Expect(verr.Fields).To(ContainElement(domain.FieldError{
+					Field:   "url",
+					Message: "URL must not point at an internal endpoint of this service",
+				}))
+				Expect(stored).To(BeEmpty())
+			},
+			Entry("metrics", "https://sho.rt/metrics"),
+			Entry("API metrics", "https://sho.rt/api/metrics"),
+			Entry("admin API", "https://sho.rt/api/admin/export"),
+			Entry("extra short host", "https://go.example.com/admin"),
+			Entry("different case", "https://SHO.RT/API/Admin/stats"),
+			Entry("extra slashes", "https://sho.rt//api///admin/stats"),
+			Entry("dot segments", "https://sho.rt/abc/../api/admin/stats"),
+			Entry("encoded slash", "https://sho.rt/api%2Fadmin%2Fstats"),
+			Entry("trailing dot on the host", "https://sho.rt./api/admin/stats"),
+		)
+
+		It("should reject them when changing a destination", func() {
+			_, err := svc.UpdateURL(ctx, "url-1", "https://sho.rt/api/admin/stats")
+
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+		})
+
+		It("should allow the same paths on external hosts", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.org/api/admin/stats"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(Equal([]string{"https://example.org/api/admin/stats"}))
+		})
+
+		It("should only match whole path segments", func() {
+			_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://sho.rt/administer"})
+
+			var verr *domain.ValidationError
+			Expect(errors.As(err, &verr)).To(BeTrue())
+			Expect(verr.Fields).To(ConsistOf(domain.FieldError{
+				Field:   "url",
+				Message: "destination is not an existing short link",
+			}))
+		})
+
+		It("should use the configured paths instead of the defaults", func() {
+			custom := service.NewURLShortenerServiceWithOptions(
+				&mocks.MockURLRepository{},
+				&mocks.MockShortLinkRepository{},
+				&mocks.MockLinkClickRepository{},
+				zaptest.NewLogger(GinkgoT()),
+				service.Options{BaseURL: "https://sho.rt", InternalPaths: []string{"/v2/admin/"}},
+			)
+
+			_, err := custom.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://sho.rt/v2/admin/export"})
+			Expect(err).To(MatchError(ContainSubstring("internal endpoint")))
+		})
+	})
+})
@@ -3,6 +3,8 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -163,7 +165,7 @@ var _ = Describe("Service Suite", func() {
 					Expect(link.UserID).To(Equal("user-123"))
 					Expect(link.OriginalURL).To(Equal("https://example.com"))
 					Expect(link.ShortURL).To(Equal("mylink"))
-					Expect(link.Visits).To(Equal(10))
+					Expect(link.Visits).To(Equal(int64(10)))
 				})
 			})
 
@@ -207,7 +209,7 @@ var _ = Describe("Service Suite", func() {
 					Expect(link.UserID).To(Equal("user-123"))
 					Expect(link.OriginalURL).To(Equal("https://example.com"))
 					Expect(link.ShortURL).To(Equal("mylink"))
-					Expect(link.Visits).To(Equal(10))
+					Expect(link.Visits).To(Equal(int64(10)))
 				})
 			})
 
@@ -452,6 +454,34 @@ var _ = Describe("Service Suite", func() {
 					Expect(err.Error()).To(ContainSubstring("database error"))
 				})
 			})
+
+			Context("when the visit and click are recorded together", func() {
+				It("passes the click to a single repository call", func() {
+					var recorded *domain.Click
+					mockRepo.RecordVisitFunc = func(click *domain.Click) error {
+						recorded = click
+						return nil
+					}
+
+					err := srv.RecordClick("link-123", "Mozilla/5.0", "https://referrer.com", "127.0.0.1")
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(recorded.LinkID).To(Equal("link-123"))
+					Expect(recorded.UserAgent).To(Equal("Mozilla/5.0"))
+					Expect(recorded.Referer).To(Equal("https://referrer.com"))
+					Expect(recorded.IPAddress).To(Equal("127.0.0.1"))
+				})
+
+				It("returns the error when the transaction fails", func() {
+					mockRepo.RecordVisitFunc = func(click *domain.Click) error {
+						return errors.New("transaction rolled back")
+					}
+
+					err := srv.RecordClick("link-123", "Mozilla/5.0", "https://referrer.com", "127.0.0.1")
+
+					Expect(err).To(MatchError("transaction rolled back"))
+				})
+			})
 		})
 
 		Describe("GetClicks", func() {
@@ -677,8 +707,30 @@ var _ = Describe("Service Suite", func() {
 				It("should return an error", func() {
 					link, err := svc.CreateShortLink(ctx, req)
 
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("invalid URL"))
+					var verr *domain.ValidationError
+					Expect(errors.As(err, &verr)).To(BeTrue())
+					Expect(verr.Fields).To(ConsistOf(HaveField("Field", "url")))
+					Expect(link).To(BeNil())
+				})
+			})
+
+			Context("when several fields are invalid", func() {
+				BeforeEach(func() {
+					customAlias := "swagger"
+					req.URL = "ftp://example.com"
+					req.CustomAlias = &customAlias
+				})
+
+				It("should report every invalid field together", func() {
+					link, err := svc.CreateShortLink(ctx, req)
+
+					Expect(err).To(MatchError(domain.ErrValidation))
+					var verr *domain.ValidationError
+					Expect(errors.As(err, &verr)).To(BeTrue())
+					Expect(verr.Fields).To(Equal([]domain.FieldError{
+						{Field: "url", Message: "URL must use HTTP or HTTPS protocol"},
+						{Field: "custom_alias", Message: "custom alias 'swagger' is reserved and cannot be used"},
+					}))
 					Expect(link).To(BeNil())
 				})
 			})
@@ -726,6 +778,153 @@ var _ = Describe("Service Suite", func() {
 					Expect(link.Code).NotTo(BeEmpty())
 				})
 			})
+
+			Context("when every generated code collides", func() {
+				var lookups int
+
+				BeforeEach(func() {
+					lookups = 0
+					mockShortLinkRepo.GetByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+						lookups++
+						return &domain.ShortLink{ID: "existing-id", Code: code}, nil
+					}
+				})
+
+				It("should return the typed exhaustion error after the default attempts", func() {
+					link, err := svc.CreateShortLink(ctx, req)
+
+					Expect(err).To(MatchError(domain.ErrCodeGenerationExhausted))
+					Expect(link).To(BeNil())
+					Expect(lookups).To(Equal(5))
+				})
+
+				It("should honor the configured attempt bound", func() {
+					svc = service.NewURLShortenerServiceWithOptions(
+						mockURLRepo,
+						mockShortLinkRepo,
+						mockClickRepo,
+						logger,
+						service.Options{CodeAttempts: 2},
+					)
+
+					_, err := svc.CreateShortLink(ctx, req)
+
+					Expect(err).To(MatchError(domain.ErrCodeGenerationExhausted))
+					Expect(err.Error()).To(ContainSubstring("after 2 attempts"))
+					Expect(lookups).To(Equal(2))
+				})
+
+				It("should count insert conflicts against the same bound", func() {
+					mockShortLinkRepo.GetByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+						lookups++
+						return nil, errors.New("not found")
+					}
+					inserts := 0
+					mockShortLinkRepo.CreateFunc = func(ctx context.Context, link *domain.ShortLink) error {
+						inserts++
+						return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+					}
+					svc = service.NewURLShortenerServiceWithOptions(
+						mockURLRepo,
+						mockShortLinkRepo,
+						mockClickRepo,
+						logger,
+						service.Options{CodeAttempts: 3},
+					)
+
+					_, err := svc.CreateShortLink(ctx, req)
+
+					Expect(err).To(MatchError(domain.ErrCodeGenerationExhausted))
+					Expect(inserts).To(Equal(3))
+				})
+			})
+
+			Context("when the generated code is claimed between the check and the insert", func() {
+				var attemptedCodes []string
+
+				BeforeEach(func() {
+					attemptedCodes = nil
+					mockShortLinkRepo.CreateFunc = func(ctx context.Context, link *domain.ShortLink) error {
+						attemptedCodes = append(attemptedCodes, link.Code)
+						if len(attemptedCodes) == 1 {
+							return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+						}
+						return nil
+					}
+				})
+
+				It("should retry the insert with a new code", func() {
+					link, err := svc.CreateShortLink(ctx, req)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(attemptedCodes).To(HaveLen(2))
+					Expect(attemptedCodes[1]).NotTo(Equal(attemptedCodes[0]))
+					Expect(link.Code).To(Equal(attemptedCodes[1]))
+				})
+			})
+
+			Context("when two creates race for the same custom alias", func() {
+				var (
+					mu      sync.Mutex
+					aliases map[string]bool
+					checked sync.WaitGroup
+				)
+
+				BeforeEach(func() {
+					aliases = map[string]bool{}
+					checked = sync.WaitGroup{}
+					checked.Add(2)
+
+					// Both requests pass the lookup before either inserts
+					mockShortLinkRepo.GetByCustomAliasFunc = func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+						checked.Done()
+						checked.Wait()
+						return nil, errors.New("short link not found")
+					}
+
+					// Enforce the unique constraint like the database would
+					mockShortLinkRepo.CreateFunc = func(ctx context.Context, link *domain.ShortLink) error {
+						mu.Lock()
+						defer mu.Unlock()
+						if aliases[*link.CustomAlias] {
+							return fmt.Errorf("creating short link: %w", domain.ErrConflict)
+						}
+						aliases[*link.CustomAlias] = true
+						return nil
+					}
+				})
+
+				It("should let exactly one succeed and report a conflict for the other", func() {
+					alias := "race-alias"
+					errs := make([]error, 2)
+
+					var wg sync.WaitGroup
+					for i := range errs {
+						wg.Add(1)
+						go func(i int) {
+							defer GinkgoRecover()
+							defer wg.Done()
+							_, errs[i] = svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+								URL:         "https://example.com/some-long-url",
+								CustomAlias: &alias,
+							})
+						}(i)
+					}
+					wg.Wait()
+
+					succeeded, conflicted := 0, 0
+					for _, err := range errs {
+						switch {
+						case err == nil:
+							succeeded++
+						case errors.Is(err, domain.ErrConflict):
+							conflicted++
+						}
+					}
+					Expect(succeeded).To(Equal(1))
+					Expect(conflicted).To(Equal(1))
+				})
+			})
 		})
 
 		Describe("GetShortLink", func() {
@@ -1024,7 +1223,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should record the click with all fields", func() {
-					err := svc.RecordClick(ctx, "link-123", "https://referrer.com", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "127.0.0.1")
+					err := svc.RecordClick(ctx, "link-123", "https://referrer.com", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "127.0.0.1", "")
 
 					Expect(err).NotTo(HaveOccurred())
 					// Since click recording is asynchronous, we need to wait a bit
@@ -1032,7 +1231,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should handle empty optional fields", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "", "")
+					err := svc.RecordClick(ctx, "link-123", "", "", "", "")
 
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
@@ -1044,7 +1243,7 @@ var _ = Describe("Service Suite", func() {
 			Context("when getting stats successfully", func() {
 				BeforeEach(func() {
 					now := time.Now()
-					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
+					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
 						return &domain.LinkStats{
 							TotalClicks: 100,
 							LastClicked: &now,
@@ -1085,11 +1284,11 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should return link statistics", func() {
-					stats, err := svc.GetLinkStats(ctx, "link-123")
+					stats, err := svc.GetLinkStats(ctx, "link-123", nil)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(stats).NotTo(BeNil())
-					Expect(stats.TotalClicks).To(Equal(100))
+					Expect(stats.TotalClicks).To(Equal(int64(100)))
 					Expect(stats.LastClicked).NotTo(BeNil())
 					Expect(stats.TopReferrers).To(HaveLen(2))
 					Expect(stats.TopBrowsers).To(HaveLen(2))
@@ -1102,13 +1301,13 @@ var _ = Describe("Service Suite", func() {
 
 			Context("when there's an error getting stats", func() {
 				BeforeEach(func() {
-					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
+					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
 						return nil, errors.New("database error")
 					}
 				})
 
 				It("should return the error", func() {
-					stats, err := svc.GetLinkStats(ctx, "link-123")
+					stats, err := svc.GetLinkStats(ctx, "link-123", nil)
 
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("database error"))
@@ -1179,7 +1378,7 @@ var _ = Describe("Service Suite", func() {
 
 			Context("when parsing browser information", func() {
 				It("should detect Chrome", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond) // Wait for async processing
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1187,7 +1386,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Firefox", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1195,7 +1394,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Safari", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1203,7 +1402,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Edge", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1211,7 +1410,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Opera", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 OPR/77.0.4054.277", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 OPR/77.0.4054.277", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1219,7 +1418,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should mark unknown browsers as Other", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Unknown Browser", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Unknown Browser", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Browser).NotTo(BeNil())
@@ -1229,7 +1428,7 @@ var _ = Describe("Service Suite", func() {
 
 			Context("when parsing OS information", func() {
 				It("should detect Windows", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1237,7 +1436,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect macOS", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1245,7 +1444,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Linux", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1253,7 +1452,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect Android", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Linux; Android 11; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Mobile Safari/537.36", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Linux; Android 11; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Mobile Safari/537.36", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1261,7 +1460,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect iOS", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1269,7 +1468,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should mark unknown OS as Other", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Unknown OS", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Unknown OS", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.OS).NotTo(BeNil())
@@ -1279,7 +1478,7 @@ var _ = Describe("Service Suite", func() {
 
 			Context("when parsing device information", func() {
 				It("should detect mobile devices", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Device).NotTo(BeNil())
@@ -1287,7 +1486,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should detect tablets", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPad; CPU OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (iPad; CPU OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Device).NotTo(BeNil())
@@ -1295,7 +1494,7 @@ var _ = Describe("Service Suite", func() {
 				})
 
 				It("should mark other devices as Desktop", func() {
-					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "")
+					err := svc.RecordClick(ctx, "link-123", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36", "", "")
 					Expect(err).NotTo(HaveOccurred())
 					time.Sleep(100 * time.Millisecond)
 					Expect(capturedClick.Device).NotTo(BeNil())
@@ -1453,6 +1652,23 @@ var _ = Describe("Service Suite", func() {
 				})
 			})
 
+			Context("when the cache holds a value of the wrong type", func() {
+				It("should fall back to the database", func() {
+					dbLink := &domain.ShortLink{ID: "db-id", Code: "db-code", IsActive: true}
+					mockCache.GetFunc = func(key string) (interface{}, bool) {
+						return domain.ShortLink{ID: "stale"}, true
+					}
+					mockShortLinkRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.ShortLink, error) {
+						return dbLink, nil
+					}
+
+					link, err := svc.GetShortLink(ctx, "db-id")
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal(dbLink))
+				})
+			})
+
 			Context("when the link is not in cache", func() {
 				var dbLink *domain.ShortLink
 				var capturedCacheKeys []string
@@ -1569,6 +1785,47 @@ var _ = Describe("Service Suite", func() {
 					Expect(capturedCacheValues).To(ContainElements(dbLink, dbLink))
 				})
 			})
+
+			Context("when the cache holds a value of the wrong type", func() {
+				var dbLink *domain.ShortLink
+				var deletedKeys []string
+
+				BeforeEach(func() {
+					dbLink = &domain.ShortLink{
+						ID:        "db-id",
+						Code:      "db-code",
+						URLID:     "url-123",
+						IsActive:  true,
+						CreatedAt: time.Now(),
+					}
+
+					mockCache.GetFunc = func(key string) (interface{}, bool) {
+						return `{"id":"db-id","code":"db-code"}`, true
+					}
+
+					mockShortLinkRepo.GetByCodeFunc = func(ctx context.Context, code string) (*domain.ShortLink, error) {
+						return dbLink, nil
+					}
+
+					deletedKeys = nil
+					mockCache.DeleteFunc = func(key string) {
+						deletedKeys = append(deletedKeys, key)
+					}
+				})
+
+				It("should fall back to the database instead of panicking", func() {
+					var link *domain.ShortLink
+					var err error
+
+					Expect(func() {
+						link, err = svc.GetShortLinkByCode(ctx, "db-code")
+					}).NotTo(Panic())
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal(dbLink))
+					Expect(deletedKeys).To(ContainElement("db-code"))
+				})
+			})
 		})
 
 		Describe("UpdateShortLink", func() {
@@ -1757,7 +2014,7 @@ var _ = Describe("Service Suite", func() {
 						return nil, false
 					}
 
-					err := svc.RecordClick(ctx, "link-123", "referrer", "user-agent", "127.0.0.1")
+					err := svc.RecordClick(ctx, "link-123", "referrer", "user-agent", "127.0.0.1", "")
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(cacheWasUsed).To(BeFalse())
@@ -1777,7 +2034,7 @@ var _ = Describe("Service Suite", func() {
 						},
 					}
 
-					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
+					mockClickRepo.GetStatsByShortLinkIDFunc = func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
 						return stats, nil
 					}
 				})
@@ -1789,7 +2046,7 @@ var _ = Describe("Service Suite", func() {
 						return nil, false
 					}
 
-					result, err := svc.GetLinkStats(ctx, "link-123")
+					result, err := svc.GetLinkStats(ctx, "link-123", nil)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(result).To(Equal(stats))
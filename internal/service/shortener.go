@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/menezmethod/ref_go/internal/auth"
 	"github.com/menezmethod/ref_go/internal/domain"
 	"github.com/menezmethod/ref_go/internal/repository"
 )
@@ -28,6 +34,130 @@ var reservedAliases = []string{
 	"docs",    // Documentation
 	"admin",   // Admin panel if any
 	"status",  // Status information
+	"version", // Build info endpoint
+}
+
+// defaultAllowedSchemes are the destination schemes accepted when none are configured
+var defaultAllowedSchemes = []string{"http", "https"}
+
+// Options configures optional URL shortener behavior
+type Options struct {
+	BaseURL       string
+	DefaultExpiry time.Duration
+
+	// AllowedSchemes lists the lowercase destination URL schemes accepted on
+	// create; empty allows only http and https
+	AllowedSchemes []string
+
+	// RequireHTTPS rejects plain http destinations regardless of AllowedSchemes
+	RequireHTTPS bool
+
+	// CodeAttempts bounds how many generated code variations are tried;
+	// zero uses defaultCodeAttempts
+	CodeAttempts int
+
+	// MinAliasLength is the shortest custom alias accepted on create and
+	// update; generated codes are not affected. Zero allows any length.
+	MinAliasLength int
+
+	// IPAnonymization controls how click IPs are stored: IPAnonymizationNone,
+	// IPAnonymizationTruncate or IPAnonymizationHash
+	IPAnonymization string
+	IPHashSalt      string
+
+	// AuditLog records create, update and delete events; nil disables auditing
+	AuditLog repository.AuditLogRepository
+
+	// Collections lets links be filed in, and listed by, the caller's
+	// collections; nil rejects any collection ID
+	Collections repository.CollectionRepository
+
+	// ReachabilityCheck probes destinations on create: ReachabilityOff (the
+	// default), ReachabilityFlag to store the result or ReachabilityReject
+	// to refuse unreachable URLs
+	ReachabilityCheck string
+	// ReachabilityTimeout bounds each probe; zero uses defaultReachabilityTimeout
+	ReachabilityTimeout time.Duration
+	// ProbeAllowedAddresses are the private, loopback or link-local
+	// addresses reachability and health probes may still connect to, as
+	// CIDR prefixes or ip:port pairs; every other such address is refused
+	ProbeAllowedAddresses []string
+
+	// SelfLinks decides what happens to destinations on our own short link
+	// hosts: SelfLinkReject (the default) refuses them and SelfLinkResolve
+	// stores the destination of the short link they name instead.
+	// ShortHosts lists hosts serving our links besides the base URL's.
+	SelfLinks  string
+	ShortHosts []string
+
+	// ExportMaxRows caps the links a single export returns; zero uses
+	// defaultExportMaxRows. It also caps a health check of every link.
+	ExportMaxRows int
+
+	// HealthCheckConcurrency bounds how many destinations a health check
+	// probes at once; zero uses defaultHealthCheckConcurrency. Probes are
+	// bounded by ReachabilityTimeout.
+	HealthCheckConcurrency int
+
+	// ClickDedupeWindow drops repeat clicks on a link from the same IP within
+	// this window; zero records every click
+	ClickDedupeWindow time.Duration
+
+	// ClickRateThreshold is how many clicks on one link within
+	// ClickRateWindow trigger OnClickRateAlert; zero disables the detector
+	ClickRateThreshold int
+	ClickRateWindow    time.Duration
+	// OnClickRateAlert is called once each time a link crosses the
+	// threshold; when nil, alerts are only logged
+	OnClickRateAlert ClickRateAlertFunc
+
+	// ClickBeacon is sent every recorded click, sampled or not, for
+	// analytics elsewhere, e.g. ClickBeaconWebhook. It runs in the
+	// background within ClickBeaconTimeout, zero meaning the default, and
+	// its payload carries ClickBeaconFields, empty meaning the defaults.
+	ClickBeacon        ClickBeaconFunc
+	ClickBeaconTimeout time.Duration
+	ClickBeaconFields  []string
+
+	// ClickSampleRate stores only 1 in N clicks in detail for links without
+	// a rate of their own, each standing for N clicks in stats; the rest
+	// are only counted. Zero and 1 store every click.
+	ClickSampleRate int
+
+	// CampaignParams stores the utm_* params of the short link request
+	// with each click, so stats can be grouped by them
+	CampaignParams bool
+
+	// LastAccessedInterval is the least time between two writes of a link's
+	// last access time by this instance; zero uses defaultLastAccessedInterval
+	LastAccessedInterval time.Duration
+
+	// MaxLinksPerUser is how many active links one user may hold; creates
+	// past it fail with a *domain.QuotaError. Zero is unlimited.
+	// QuotaExemptUsers lists users the quota doesn't apply to.
+	MaxLinksPerUser  int
+	QuotaExemptUsers []string
+
+	// CodeCase is CodeCasePreserve (the default) or CodeCaseLower to store
+	// codes lowercased. CodeDisplayGroup splits generated codes into groups
+	// of that many characters for display, see DisplayCode; zero shows them
+	// as stored.
+	CodeCase         string
+	CodeDisplayGroup int
+
+	// InternalPaths are path prefixes on our own hosts, e.g. /api/admin,
+	// refused as destinations; empty uses the default metrics and admin paths
+	InternalPaths []string
+
+	// UniqueDestinations holds each user to one active link per normalized
+	// destination: UniqueDestinationsReuse returns the existing link instead
+	// of creating another and UniqueDestinationsReject refuses the create.
+	// Empty or UniqueDestinationsOff allows any number.
+	UniqueDestinations string
+
+	// ReservedAliases are refused as codes on top of the built-in reserved
+	// ones, e.g. the segment a custom API prefix takes at the root
+	ReservedAliases []string
 }
 
 // URLShortenerService handles URL shortening operations
@@ -38,6 +168,14 @@ type URLShortenerService struct {
 	logger        *zap.Logger
 	baseURL       string
 	defaultExpiry time.Duration
+	opts          Options
+	reachability  *reachabilityChecker
+	health        *reachabilityChecker
+	clickDedupe   *clickDeduper
+	clickRate     *clickRateDetector
+	clickSampler  *clickSampler
+	clickBeacon   *clickBeaconSender
+	lastAccessed  *accessThrottle
 }
 
 // NewURLShortenerService creates a new URL shortener service
@@ -49,62 +187,133 @@ func NewURLShortenerService(
 	baseURL string,
 	defaultExpiry time.Duration,
 ) *URLShortenerService {
-	return &URLShortenerService{
+	return NewURLShortenerServiceWithOptions(urlRepo, linkRepo, clickRepo, logger, Options{
+		BaseURL:       baseURL,
+		DefaultExpiry: defaultExpiry,
+	})
+}
+
+// NewURLShortenerServiceWithOptions creates a new URL shortener service with optional behavior
+func NewURLShortenerServiceWithOptions(
+	urlRepo repository.URLRepository,
+	linkRepo repository.ShortLinkRepository,
+	clickRepo repository.LinkClickRepository,
+	logger *zap.Logger,
+	opts Options,
+) *URLShortenerService {
+	s := &URLShortenerService{
 		urlRepo:       urlRepo,
 		linkRepo:      linkRepo,
 		clickRepo:     clickRepo,
 		logger:        logger,
-		baseURL:       baseURL,
-		defaultExpiry: defaultExpiry,
+		baseURL:       opts.BaseURL,
+		defaultExpiry: opts.DefaultExpiry,
+		opts:          opts,
+		health:        newReachabilityChecker(opts.ReachabilityTimeout, opts.ProbeAllowedAddresses),
+		clickSampler:  newClickSampler(opts.ClickSampleRate),
+		lastAccessed:  newAccessThrottle(opts.LastAccessedInterval),
+	}
+
+	if opts.ReachabilityCheck == ReachabilityFlag || opts.ReachabilityCheck == ReachabilityReject {
+		s.reachability = newReachabilityChecker(opts.ReachabilityTimeout, opts.ProbeAllowedAddresses)
 	}
+
+	if opts.ClickDedupeWindow > 0 {
+		s.clickDedupe = newClickDeduper(opts.ClickDedupeWindow)
+	}
+
+	if opts.ClickRateThreshold > 0 && opts.ClickRateWindow > 0 {
+		s.clickRate = newClickRateDetector(opts.ClickRateThreshold, opts.ClickRateWindow)
+	}
+
+	if opts.ClickBeacon != nil {
+		s.clickBeacon = newClickBeaconSender(opts.ClickBeacon, opts.ClickBeaconFields, opts.ClickBeaconTimeout, logger)
+	}
+
+	return s
 }
 
 // CreateShortLink creates a new short link
 func (s *URLShortenerService) CreateShortLink(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
-	// Validate URL
-	if err := s.validateURL(req.URL); err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+	// Check every field up front so all problems are reported together
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
 	}
 
-	// Generate hash for the URL
-	hash := s.generateHash(req.URL)
+	if err := s.checkCollection(ctx, req.CollectionID); err != nil {
+		return nil, err
+	}
 
-	// Check if URL already exists
-	existingURL, err := s.urlRepo.GetByHash(ctx, hash)
-	if err != nil && !strings.Contains(err.Error(), "not found") {
-		return nil, fmt.Errorf("checking existing URL: %w", err)
+	// A destination on our own host would redirect through us again
+	if dest, ok := s.ownShortURL(req.URL); ok {
+		resolved, err := s.resolveSelfLink(ctx, dest)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Debug("Resolved short link destination",
+			zap.String("url", req.URL),
+			zap.String("resolved", resolved),
+		)
+		collapsed := *req
+		collapsed.URL = resolved
+		req = &collapsed
 	}
 
-	var urlID string
-	if existingURL != nil {
-		// URL already exists, use existing URL ID
-		urlID = existingURL.ID
-	} else {
-		// Create new URL
-		urlID = uuid.New().String()
-		now := time.Now().UTC()
-		newURL := &domain.URL{
-			ID:          urlID,
-			OriginalURL: req.URL,
-			Hash:        hash,
-			CreatedAt:   now,
-			UpdatedAt:   now,
+	if req.ReuseExisting && (req.CustomAlias == nil || *req.CustomAlias == "") {
+		existing, err := s.existingShortLink(ctx, req.URL, req.IsPattern)
+		if err != nil {
+			return nil, err
 		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	userLink, err := s.userDestinationLink(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if userLink != nil {
+		return userLink, nil
+	}
 
-		if err := s.urlRepo.Create(ctx, newURL); err != nil {
-			return nil, fmt.Errorf("creating URL: %w", err)
+	// Only links actually created count, so a reused one passes regardless
+	if err := s.checkLinkQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	// Probe the destination before anything is stored; only web URLs can be probed
+	var reachable *bool
+	if s.reachability != nil && isWebURL(req.URL) {
+		ok := true
+		if err := s.reachability.Check(ctx, req.URL); err != nil {
+			if s.opts.ReachabilityCheck == ReachabilityReject {
+				return nil, fmt.Errorf("destination is unreachable: %v: %w", err, domain.ErrValidation)
+			}
+			s.logger.Info("Destination is unreachable", zap.String("url", req.URL), zap.Error(err))
+			ok = false
 		}
+		reachable = &ok
+	}
+
+	// Generate hash for the URL
+	hash := s.generateHash(req.URL)
+
+	urlID, err := s.findOrCreateURL(ctx, req.URL, hash)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate short code or use custom alias
-	var code string
-	if req.CustomAlias != nil && *req.CustomAlias != "" {
-		code = *req.CustomAlias
+	isCustom := req.CustomAlias != nil && *req.CustomAlias != ""
+	attempts := 0
 
-		// Check if custom alias is a reserved word
-		if s.isReservedAlias(code) {
-			return nil, fmt.Errorf("custom alias '%s' is reserved and cannot be used", code)
-		}
+	var code string
+	var customAlias *string
+	if isCustom {
+		code = s.canonicalCode(*req.CustomAlias)
+		customAlias = &code
 
 		// Check if custom alias is already in use
 		existingLink, err := s.linkRepo.GetByCustomAlias(ctx, code)
@@ -113,40 +322,12 @@ func (s *URLShortenerService) CreateShortLink(ctx context.Context, req *domain.C
 		}
 
 		if existingLink != nil {
-			return nil, fmt.Errorf("custom alias already in use")
+			return nil, fmt.Errorf("custom alias already in use: %w", domain.ErrConflict)
 		}
 	} else {
-		// Generate short code
-		code = s.generateCode(hash)
-
-		// Check for collisions and regenerate if necessary
-		attempts := 0
-		for attempts < 5 {
-			existingLink, err := s.linkRepo.GetByCode(ctx, code)
-			if err != nil && !strings.Contains(err.Error(), "not found") {
-				return nil, fmt.Errorf("checking existing code: %w", err)
-			}
-
-			if existingLink == nil {
-				// Code is available
-				break
-			}
-
-			// Ensure the generated code is not a reserved alias
-			if s.isReservedAlias(code) {
-				// Generate a different code
-				attempts++
-				code = s.generateCode(hash + fmt.Sprintf("-%d", attempts))
-				continue
-			}
-
-			// Code collision, try with a different variation
-			attempts++
-			code = s.generateCode(hash + fmt.Sprintf("-%d", attempts))
-		}
-
-		if attempts >= 5 {
-			return nil, fmt.Errorf("failed to generate unique code after %d attempts", attempts)
+		code, attempts, err = s.nextAvailableCode(ctx, hash, attempts)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -164,16 +345,52 @@ func (s *URLShortenerService) CreateShortLink(ctx context.Context, req *domain.C
 	shortLink := &domain.ShortLink{
 		ID:             uuid.New().String(),
 		Code:           code,
-		CustomAlias:    req.CustomAlias,
+		CustomAlias:    customAlias,
 		URLID:          urlID,
 		ExpirationDate: expirationDate,
 		IsActive:       true,
 		CreatedAt:      now,
 		UpdatedAt:      now,
+		Reachable:      reachable,
+		IsPattern:      req.IsPattern,
+		AllowedUsers:   normalizeAllowedUsers(req.AllowedUsers),
+	}
+
+	if req.CollectionID != nil && *req.CollectionID != "" {
+		shortLink.CollectionID = req.CollectionID
+	}
+
+	shortLink.ClickSampleRate = req.ClickSampleRate
+
+	trackClicks := req.TrackClicks == nil || *req.TrackClicks
+	shortLink.TrackClicks = &trackClicks
+
+	if userID := auth.UserIDFromContext(ctx); userID != "" {
+		shortLink.OwnerID = &userID
 	}
 
-	if err := s.linkRepo.Create(ctx, shortLink); err != nil {
-		return nil, fmt.Errorf("creating short link: %w", err)
+	// The checks above can race with concurrent creates, so the unique
+	// constraints on insert have the final say
+	for {
+		err := s.linkRepo.Create(ctx, shortLink)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("creating short link: %w", err)
+		}
+
+		if isCustom {
+			return nil, fmt.Errorf("custom alias already in use: %w", domain.ErrConflict)
+		}
+
+		// Another request claimed the generated code, try the next variation
+		attempts++
+		shortLink.Code, attempts, err = s.nextAvailableCode(ctx, hash, attempts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Retrieve URL data to include in response
@@ -183,9 +400,80 @@ func (s *URLShortenerService) CreateShortLink(ctx context.Context, req *domain.C
 	}
 
 	shortLink.URL = url
+	s.recordAudit(ctx, domain.AuditActionCreate, shortLink.ID, nil, shortLink)
 	return shortLink, nil
 }
 
+// existingShortLink returns the newest active, unexpired link to
+// originalURL matching isPattern, marked as reused, or nil when there is none
+func (s *URLShortenerService) existingShortLink(ctx context.Context, originalURL string, isPattern bool) (*domain.ShortLink, error) {
+	url, err := s.urlRepo.GetByHash(ctx, s.generateHash(originalURL))
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, fmt.Errorf("checking existing URL: %w", err)
+	}
+	if url == nil {
+		return nil, nil
+	}
+
+	links, err := s.linkRepo.GetAllByURLID(ctx, url.ID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving existing short links: %w", err)
+	}
+
+	now := time.Now()
+	var newest *domain.ShortLink
+	for _, link := range links {
+		if !link.IsActive || link.IsPattern != isPattern {
+			continue
+		}
+		if link.ExpirationDate != nil && link.ExpirationDate.Before(now) {
+			continue
+		}
+		if newest == nil || link.CreatedAt.After(newest.CreatedAt) {
+			newest = link
+		}
+	}
+
+	if newest == nil {
+		return nil, nil
+	}
+
+	newest.URL = url
+	newest.Reused = true
+	return newest, nil
+}
+
+// findOrCreateURL returns the ID of the stored URL with the given hash,
+// creating it first when it does not exist yet
+func (s *URLShortenerService) findOrCreateURL(ctx context.Context, originalURL, hash string) (string, error) {
+	// Check if URL already exists
+	existingURL, err := s.urlRepo.GetByHash(ctx, hash)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return "", fmt.Errorf("checking existing URL: %w", err)
+	}
+
+	if existingURL != nil {
+		// URL already exists, use existing URL ID
+		return existingURL.ID, nil
+	}
+
+	// Create new URL
+	now := time.Now().UTC()
+	newURL := &domain.URL{
+		ID:          uuid.New().String(),
+		OriginalURL: originalURL,
+		Hash:        hash,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.urlRepo.Create(ctx, newURL); err != nil {
+		return "", fmt.Errorf("creating URL: %w", err)
+	}
+
+	return newURL.ID, nil
+}
+
 // GetShortLink retrieves a short link by ID
 func (s *URLShortenerService) GetShortLink(ctx context.Context, id string) (*domain.ShortLink, error) {
 	link, err := s.linkRepo.GetByID(ctx, id)
@@ -203,8 +491,34 @@ func (s *URLShortenerService) GetShortLink(ctx context.Context, id string) (*dom
 	return link, nil
 }
 
-// GetShortLinkByCode retrieves a short link by code
+// GetShortLinkByCode retrieves a short link by code, or by the display
+// form of its code
 func (s *URLShortenerService) GetShortLinkByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	var link *domain.ShortLink
+	var err error
+	for _, candidate := range s.codeCandidates(code) {
+		link, err = s.findByCode(ctx, candidate)
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch URL data
+	url, err := s.urlRepo.GetByID(ctx, link.URLID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving URL data: %w", err)
+	}
+
+	link.URL = url
+	return link, nil
+}
+
+// findByCode retrieves the short link stored under the custom alias or
+// code exactly equal to code
+func (s *URLShortenerService) findByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
 	// Try to find by custom alias first
 	link, err := s.linkRepo.GetByCustomAlias(ctx, code)
 	if err != nil && !strings.Contains(err.Error(), "not found") {
@@ -219,13 +533,6 @@ func (s *URLShortenerService) GetShortLinkByCode(ctx context.Context, code strin
 		}
 	}
 
-	// Fetch URL data
-	url, err := s.urlRepo.GetByID(ctx, link.URLID)
-	if err != nil {
-		return nil, fmt.Errorf("retrieving URL data: %w", err)
-	}
-
-	link.URL = url
 	return link, nil
 }
 
@@ -237,20 +544,33 @@ func (s *URLShortenerService) UpdateShortLink(ctx context.Context, id string, re
 		return nil, fmt.Errorf("retrieving short link: %w", err)
 	}
 
+	if err := s.validateUpdateRequest(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCollection(ctx, req.CollectionID); err != nil {
+		return nil, err
+	}
+
+	// Keep a snapshot of the link as it was for the audit log
+	before := *link
+
 	// Update fields if provided
 	if req.CustomAlias != nil {
+		alias := s.canonicalCode(*req.CustomAlias)
+
 		// Check if custom alias is already in use by another link
-		if *req.CustomAlias != "" {
-			existingLink, err := s.linkRepo.GetByCustomAlias(ctx, *req.CustomAlias)
+		if alias != "" {
+			existingLink, err := s.linkRepo.GetByCustomAlias(ctx, alias)
 			if err != nil && !strings.Contains(err.Error(), "not found") {
 				return nil, fmt.Errorf("checking existing custom alias: %w", err)
 			}
 
 			if existingLink != nil && existingLink.ID != id {
-				return nil, fmt.Errorf("custom alias already in use")
+				return nil, fmt.Errorf("custom alias already in use: %w", domain.ErrConflict)
 			}
 		}
-		link.CustomAlias = req.CustomAlias
+		link.CustomAlias = &alias
 	}
 
 	if req.ExpirationDate != nil {
@@ -261,13 +581,41 @@ func (s *URLShortenerService) UpdateShortLink(ctx context.Context, id string, re
 		link.IsActive = *req.IsActive
 	}
 
+	if req.AllowedUsers != nil {
+		link.AllowedUsers = normalizeAllowedUsers(*req.AllowedUsers)
+	}
+
+	if req.CollectionID != nil {
+		link.CollectionID = nil
+		if *req.CollectionID != "" {
+			link.CollectionID = req.CollectionID
+		}
+	}
+
+	if req.ClickSampleRate != nil {
+		link.ClickSampleRate = nil
+		if *req.ClickSampleRate > 0 {
+			link.ClickSampleRate = req.ClickSampleRate
+		}
+	}
+
+	if req.TrackClicks != nil {
+		link.TrackClicks = req.TrackClicks
+	}
+
 	link.UpdatedAt = time.Now().UTC()
 
 	// Save updates
 	if err := s.linkRepo.Update(ctx, link); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, fmt.Errorf("custom alias already in use: %w", domain.ErrConflict)
+		}
 		return nil, fmt.Errorf("updating short link: %w", err)
 	}
 
+	// Pick up a changed sample rate on the next click
+	s.clickSampler.forget(link.ID)
+
 	// Retrieve URL data
 	url, err := s.urlRepo.GetByID(ctx, link.URLID)
 	if err != nil {
@@ -275,12 +623,52 @@ func (s *URLShortenerService) UpdateShortLink(ctx context.Context, id string, re
 	}
 
 	link.URL = url
+	s.recordAudit(ctx, domain.AuditActionUpdate, link.ID, &before, link)
 	return link, nil
 }
 
+// UpdateURL points an existing URL, and so every short link referencing it,
+// at a new destination
+func (s *URLShortenerService) UpdateURL(ctx context.Context, id, originalURL string) (*domain.URL, error) {
+	if err := s.validateURL(originalURL); err != nil {
+		verr := &domain.ValidationError{}
+		verr.Add("url", err.Error())
+		return nil, verr.Err()
+	}
+
+	url, err := s.urlRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving URL data: %w", err)
+	}
+
+	url.OriginalURL = originalURL
+	url.Hash = s.generateHash(originalURL)
+	url.UpdatedAt = time.Now().UTC()
+
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		return nil, fmt.Errorf("updating URL: %w", err)
+	}
+
+	return url, nil
+}
+
 // DeleteShortLink deletes a short link
 func (s *URLShortenerService) DeleteShortLink(ctx context.Context, id string) error {
-	return s.linkRepo.Delete(ctx, id)
+	var before *domain.ShortLink
+	if s.opts.AuditLog != nil {
+		link, err := s.linkRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("retrieving short link: %w", err)
+		}
+		before = link
+	}
+
+	if err := s.linkRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, domain.AuditActionDelete, id, before, nil)
+	return nil
 }
 
 // ListShortLinks lists all short links with pagination
@@ -310,16 +698,194 @@ func (s *URLShortenerService) ListShortLinks(ctx context.Context, page, pageSize
 	return links, total, nil
 }
 
-// RecordClick records a click on a short link
-func (s *URLShortenerService) RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress string) error {
+// StreamShortLinks calls fn for each link of the page ListShortLinks would
+// return, as rows are read from the database rather than after collecting
+// the page. begin is called with the total number of links first, so a
+// response can be started before the first link arrives.
+func (s *URLShortenerService) StreamShortLinks(ctx context.Context, page, pageSize int, begin func(total int) error, fn func(link *domain.ShortLink) error) error {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	total, err := s.linkRepo.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("counting short links: %w", err)
+	}
+
+	if err := begin(total); err != nil {
+		return err
+	}
+
+	return s.linkRepo.StreamPage(ctx, (page-1)*pageSize, pageSize, fn)
+}
+
+// ListShortLinksFiltered lists the short links matching filter with
+// pagination; an unknown status is a validation error, and a collection
+// the caller doesn't own is not found
+func (s *URLShortenerService) ListShortLinksFiltered(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	switch filter.Status {
+	case "", domain.LinkStatusActive, domain.LinkStatusExpired, domain.LinkStatusInactive:
+	default:
+		return nil, 0, fmt.Errorf("status must be active, expired or inactive: %w", domain.ErrValidation)
+	}
+
+	if filter.CollectionID != "" {
+		if s.opts.Collections == nil {
+			return nil, 0, fmt.Errorf("collection not found: %w", domain.ErrNotFound)
+		}
+		if _, err := ownedCollection(ctx, s.opts.Collections, filter.CollectionID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	now := time.Now().UTC()
+
+	total, err := s.linkRepo.CountFiltered(ctx, filter, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("counting short links: %w", err)
+	}
+
+	links, err := s.linkRepo.ListFiltered(ctx, filter, offset, pageSize, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing short links: %w", err)
+	}
+
+	return links, total, nil
+}
+
+// ListShortLinksAfter lists short links newest first using an opaque cursor
+// from a previous page; an empty cursor starts from the newest link. The
+// returned cursor is empty once there are no more links.
+func (s *URLShortenerService) ListShortLinksAfter(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	var after *domain.LinkCursor
+	if cursor != "" {
+		decoded, err := decodeLinkCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = decoded
+	}
+
+	// Fetch one extra row to know whether another page follows
+	links, err := s.linkRepo.ListAfter(ctx, after, pageSize+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("listing short links: %w", err)
+	}
+
+	if len(links) <= pageSize {
+		return links, "", nil
+	}
+
+	links = links[:pageSize]
+	last := links[len(links)-1]
+
+	return links, encodeLinkCursor(&domain.LinkCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+// encodeLinkCursor serializes a cursor into an opaque URL-safe token
+func encodeLinkCursor(cursor *domain.LinkCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeLinkCursor parses a token produced by encodeLinkCursor
+func decodeLinkCursor(token string) (*domain.LinkCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", domain.ErrValidation)
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, fmt.Errorf("invalid cursor: %w", domain.ErrValidation)
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", domain.ErrValidation)
+	}
+
+	return &domain.LinkCursor{CreatedAt: parsed, ID: id}, nil
+}
+
+// RecordClick records a click on a short link. rawQuery is the query string
+// of the short link request, whose campaign params are stored when enabled.
+func (s *URLShortenerService) RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
+	now := time.Now().UTC()
+
+	// Every click counts as an access, even one that isn't stored
+	s.touchLastAccessed(shortLinkID, now)
+
+	// Skip repeats of a click that was just recorded
+	if s.clickDedupe != nil && !s.clickDedupe.Allow(shortLinkID, ipAddress, now) {
+		s.logger.Debug("Dropped duplicate click",
+			zap.String("short_link_id", shortLinkID),
+		)
+		return nil
+	}
+
+	// Alert on links getting clicked unusually fast
+	if s.clickRate != nil {
+		if alert, ok := s.clickRate.Record(shortLinkID, now); ok {
+			s.logger.Warn("Click rate threshold crossed",
+				zap.String("short_link_id", shortLinkID),
+				zap.Int("clicks", alert.Clicks),
+				zap.Duration("window", alert.Window),
+			)
+			if s.opts.OnClickRateAlert != nil {
+				go s.opts.OnClickRateAlert(alert)
+			}
+		}
+	}
+
+	// Beacons go out for sampled out clicks too, the endpoint sees them all
+	if s.clickBeacon != nil {
+		s.sendClickBeacon(shortLinkID, referrer, userAgent, ipAddress, rawQuery, now)
+	}
+
+	// Only count clicks that sampling leaves out, keeping the total exact
+	store, weight := s.sampleClick(ctx, shortLinkID, now)
+	if !store {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := s.clickRepo.AddSampledOut(ctx, shortLinkID, 1); err != nil {
+				s.logger.Error("Failed to count sampled out click",
+					zap.String("short_link_id", shortLinkID),
+					zap.Error(err),
+				)
+			}
+		}()
+		return nil
+	}
+
 	// Extract useful information from user agent
 	browser, os, device := parseUserAgent(userAgent)
 
 	// Create click record
 	click := &domain.LinkClick{
-		ID:          uuid.New().String(),
-		ShortLinkID: shortLinkID,
-		CreatedAt:   time.Now().UTC(),
+		ID:           uuid.New().String(),
+		ShortLinkID:  shortLinkID,
+		CreatedAt:    now,
+		SampleWeight: weight,
 	}
 
 	// Set optional fields
@@ -331,7 +897,8 @@ func (s *URLShortenerService) RecordClick(ctx context.Context, shortLinkID strin
 		click.UserAgent = &userAgent
 	}
 
-	if ipAddress != "" {
+	// Anonymize the IP before it is persisted
+	if ipAddress = anonymizeIP(ipAddress, s.opts.IPAnonymization, s.opts.IPHashSalt); ipAddress != "" {
 		click.IPAddress = &ipAddress
 	}
 
@@ -347,6 +914,10 @@ func (s *URLShortenerService) RecordClick(ctx context.Context, shortLinkID strin
 		click.Device = &device
 	}
 
+	if s.opts.CampaignParams {
+		setCampaign(click, rawQuery)
+	}
+
 	// Save click asynchronously to not block redirection
 	go func() {
 		// Create a new context with timeout
@@ -364,9 +935,31 @@ func (s *URLShortenerService) RecordClick(ctx context.Context, shortLinkID strin
 	return nil
 }
 
-// GetLinkStats gets statistics for a short link
-func (s *URLShortenerService) GetLinkStats(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
-	return s.clickRepo.GetStatsByShortLinkID(ctx, shortLinkID)
+// GetLinkClick gets a single click of a short link. A click recorded for a
+// different link is reported as not found so IDs can't be probed across links.
+func (s *URLShortenerService) GetLinkClick(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+	click, err := s.clickRepo.GetByID(ctx, clickID)
+	if err != nil {
+		return nil, err
+	}
+
+	if click.ShortLinkID != shortLinkID {
+		return nil, fmt.Errorf("click %s does not belong to short link %s: %w", clickID, shortLinkID, domain.ErrNotFound)
+	}
+
+	return click, nil
+}
+
+// GetLinkStats gets statistics for a short link with clicks bucketed by
+// calendar day in loc; nil means UTC
+func (s *URLShortenerService) GetLinkStats(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+	return s.clickRepo.GetStatsByShortLinkID(ctx, shortLinkID, loc)
+}
+
+// GetLinkStatsSummaries gets summary statistics keyed by short link ID for
+// several links at once
+func (s *URLShortenerService) GetLinkStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+	return s.clickRepo.GetStatsSummaries(ctx, shortLinkIDs)
 }
 
 // generateHash creates a hash for a URL
@@ -405,18 +998,151 @@ func (s *URLShortenerService) validateURL(rawURL string) error {
 	}
 
 	// Check scheme
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return fmt.Errorf("URL must use HTTP or HTTPS protocol")
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if !slices.Contains(s.allowedSchemes(), scheme) {
+		return fmt.Errorf("URL must use %s protocol", describeSchemes(s.allowedSchemes()))
+	}
+
+	if s.opts.RequireHTTPS && scheme == "http" {
+		return fmt.Errorf("URL must use HTTPS, plain HTTP is not allowed")
 	}
 
-	// Check host
-	if parsedURL.Host == "" {
+	// Check host, except for opaque URLs such as mailto: or tel: that have none
+	if (isWebScheme(scheme) || parsedURL.Opaque == "") && parsedURL.Host == "" {
 		return fmt.Errorf("URL must have a host")
 	}
 
+	if s.internalDestination(rawURL) {
+		return fmt.Errorf("URL must not point at an internal endpoint of this service")
+	}
+
 	return nil
 }
 
+// validateCreateRequest checks every field of a create request and reports
+// all rejected fields in one domain.ValidationError
+func (s *URLShortenerService) validateCreateRequest(req *domain.CreateShortLinkRequest) error {
+	verr := &domain.ValidationError{}
+
+	if err := s.validateURL(req.URL); err != nil {
+		verr.Add("url", err.Error())
+	}
+
+	if req.CustomAlias != nil {
+		s.validateCustomAlias(verr, *req.CustomAlias)
+	}
+
+	// Path suffixes can only be appended to web URLs
+	if req.IsPattern && !isWebURL(req.URL) {
+		verr.Add("is_pattern", "pattern links need an HTTP or HTTPS destination")
+	}
+
+	validateExpirationDate(verr, req.ExpirationDate)
+	validateAllowedUsers(verr, req.AllowedUsers)
+
+	if req.ClickSampleRate != nil && *req.ClickSampleRate < 1 {
+		verr.Add("click_sample_rate", "click sample rate must be at least 1")
+	}
+
+	return verr.Err()
+}
+
+// validateUpdateRequest checks every field of an update request and reports
+// all rejected fields in one domain.ValidationError
+func (s *URLShortenerService) validateUpdateRequest(req *domain.UpdateShortLinkRequest) error {
+	verr := &domain.ValidationError{}
+
+	if req.CustomAlias != nil {
+		s.validateCustomAlias(verr, *req.CustomAlias)
+	}
+
+	validateExpirationDate(verr, req.ExpirationDate)
+	if req.AllowedUsers != nil {
+		validateAllowedUsers(verr, *req.AllowedUsers)
+	}
+
+	if req.ClickSampleRate != nil && *req.ClickSampleRate < 0 {
+		verr.Add("click_sample_rate", "click sample rate must not be negative")
+	}
+
+	return verr.Err()
+}
+
+// validateAllowedUsers rejects blank entries in a link's allowed users
+func validateAllowedUsers(verr *domain.ValidationError, users []string) {
+	for _, user := range users {
+		if strings.TrimSpace(user) == "" {
+			verr.Add("allowed_users", "allowed users must not be blank")
+			return
+		}
+	}
+}
+
+// normalizeAllowedUsers trims allowed users and drops repeats, which are
+// matched case-insensitively
+func normalizeAllowedUsers(users []string) []string {
+	var normalized []string
+	for _, user := range users {
+		user = strings.TrimSpace(user)
+		if !slices.ContainsFunc(normalized, func(u string) bool { return strings.EqualFold(u, user) }) {
+			normalized = append(normalized, user)
+		}
+	}
+	return normalized
+}
+
+// validateCustomAlias rejects reserved aliases and aliases shorter than
+// MinAliasLength. An empty alias means none was given.
+func (s *URLShortenerService) validateCustomAlias(verr *domain.ValidationError, alias string) {
+	if s.isReservedAlias(alias) {
+		verr.Add("custom_alias", fmt.Sprintf("custom alias '%s' is reserved and cannot be used", alias))
+	}
+
+	if alias != "" && utf8.RuneCountInString(alias) < s.opts.MinAliasLength {
+		verr.Add("custom_alias", fmt.Sprintf("custom alias must be at least %d characters", s.opts.MinAliasLength))
+	}
+}
+
+// validateExpirationDate rejects an expiration date that is not in the
+// future, since the link would be expired as soon as it is saved
+func validateExpirationDate(verr *domain.ValidationError, expirationDate *time.Time) {
+	if expirationDate != nil && !expirationDate.After(time.Now()) {
+		verr.Add("expiration_date", "expiration date must be in the future")
+	}
+}
+
+// allowedSchemes returns the configured destination schemes, defaulting to HTTP and HTTPS
+func (s *URLShortenerService) allowedSchemes() []string {
+	if len(s.opts.AllowedSchemes) == 0 {
+		return defaultAllowedSchemes
+	}
+	return s.opts.AllowedSchemes
+}
+
+// isWebScheme reports whether the scheme is http or https
+func isWebScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+// isWebURL reports whether rawURL uses http or https
+func isWebURL(rawURL string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	return err == nil && isWebScheme(strings.ToLower(parsedURL.Scheme))
+}
+
+// describeSchemes formats schemes for error messages, e.g. "HTTP, HTTPS or FTP"
+func describeSchemes(schemes []string) string {
+	upper := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		upper[i] = strings.ToUpper(scheme)
+	}
+
+	if len(upper) == 1 {
+		return upper[0]
+	}
+	return strings.Join(upper[:len(upper)-1], ", ") + " or " + upper[len(upper)-1]
+}
+
 // parseUserAgent extracts browser, OS and device information from user agent
 func parseUserAgent(userAgent string) (browser, os, device string) {
 	// This is a simple implementation - in a real project, you might use a proper
@@ -469,6 +1195,58 @@ func parseUserAgent(userAgent string) (browser, os, device string) {
 	return browser, os, device
 }
 
+// defaultCodeAttempts is the number of code variations tried when none is configured
+const defaultCodeAttempts = 5
+
+// nextAvailableCode returns the first generated code variation, starting at
+// attempt, that is neither reserved nor already taken. The first attempt is
+// derived from the URL hash alone; later ones add a random salt, since a
+// fixed sequence would make the Nth link to a popular URL probe N codes
+// that earlier links, or concurrent creates, already claimed.
+func (s *URLShortenerService) nextAvailableCode(ctx context.Context, hash string, attempt int) (string, int, error) {
+	maxAttempts := s.opts.CodeAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultCodeAttempts
+	}
+
+	for ; attempt < maxAttempts; attempt++ {
+		// Variations are re-hashed since generateCode only uses the leading bytes
+		code := s.generateCode(hash)
+		if attempt > 0 {
+			salt, err := codeSalt()
+			if err != nil {
+				return "", attempt, err
+			}
+			code = s.generateCode(s.generateHash(hash + "-" + salt))
+		}
+		code = s.canonicalCode(code)
+
+		if s.isReservedAlias(code) {
+			continue
+		}
+
+		existingLink, err := s.linkRepo.GetByCode(ctx, code)
+		if err != nil && !strings.Contains(err.Error(), "not found") {
+			return "", attempt, fmt.Errorf("checking existing code: %w", err)
+		}
+
+		if existingLink == nil {
+			return code, attempt, nil
+		}
+	}
+
+	return "", attempt, fmt.Errorf("%w after %d attempts", domain.ErrCodeGenerationExhausted, attempt)
+}
+
+// codeSalt returns random hex to vary a generated code with
+func codeSalt() (string, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generating code salt: %w", err)
+	}
+	return hex.EncodeToString(salt), nil
+}
+
 // isReservedAlias checks if a custom alias is in the list of reserved aliases
 func (s *URLShortenerService) isReservedAlias(alias string) bool {
 	// Convert alias to lowercase for case-insensitive comparison
@@ -480,5 +1258,11 @@ func (s *URLShortenerService) isReservedAlias(alias string) bool {
 		}
 	}
 
+	for _, reserved := range s.opts.ReservedAliases {
+		if lowercaseAlias == strings.ToLower(reserved) {
+			return true
+		}
+	}
+
 	return false
 }
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// statsShareKeyLabel separates the share token key from other uses of the
+// same secret, so a share token can never pass for an API token
+const statsShareKeyLabel = "stats-share:v1"
+
+// StatsShareService mints and checks share tokens for link stats. A token
+// names one link and an expiry, signed with a key derived from the secret,
+// so it can be handed to anyone without granting access to the account or
+// to the stats of any other link.
+type StatsShareService struct {
+	linkRepo  repository.ShortLinkRepository
+	clickRepo repository.LinkClickRepository
+	key       []byte
+	maxTTL    time.Duration
+}
+
+// NewStatsShareService creates a stats share service signing tokens with
+// secret; tokens live at most maxTTL
+func NewStatsShareService(
+	linkRepo repository.ShortLinkRepository,
+	clickRepo repository.LinkClickRepository,
+	secret string,
+	maxTTL time.Duration,
+) *StatsShareService {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(statsShareKeyLabel))
+
+	return &StatsShareService{
+		linkRepo:  linkRepo,
+		clickRepo: clickRepo,
+		key:       mac.Sum(nil),
+		maxTTL:    maxTTL,
+	}
+}
+
+// CreateToken mints a token for the stats of a link that expires after
+// ttl; zero uses the longest lifetime allowed
+func (s *StatsShareService) CreateToken(ctx context.Context, shortLinkID string, ttl time.Duration) (*domain.StatsShare, error) {
+	if ttl == 0 {
+		ttl = s.maxTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		verr := &domain.ValidationError{}
+		verr.Add("ttl", fmt.Sprintf("ttl must not be negative or longer than %s", s.maxTTL))
+		return nil, verr
+	}
+
+	if _, err := s.linkRepo.GetByID(ctx, shortLinkID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get short link: %w", err)
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Millisecond)
+	payload := shortLinkID + "." + strconv.FormatInt(expiresAt.UnixMilli(), 10)
+
+	return &domain.StatsShare{
+		Token:     encodeShareSegment([]byte(payload)) + "." + encodeShareSegment(s.sign(payload)),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// GetLinkStats returns the stats of the link a token was minted for, with
+// days bucketed in loc. Malformed, tampered and expired tokens are refused
+// with domain.ErrForbidden.
+func (s *StatsShareService) GetLinkStats(ctx context.Context, token string, loc *time.Location) (*domain.LinkStats, error) {
+	shortLinkID, err := s.verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.clickRepo.GetStatsByShortLinkID(ctx, shortLinkID, loc)
+}
+
+// verify checks the token's signature and expiry and returns the link it names
+func (s *StatsShareService) verify(token string, now time.Time) (string, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", fmt.Errorf("%w: malformed share token", domain.ErrForbidden)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed share token", domain.ErrForbidden)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed share token", domain.ErrForbidden)
+	}
+
+	// The signature is checked before anything in the payload is trusted
+	if !hmac.Equal(sig, s.sign(string(payload))) {
+		return "", fmt.Errorf("%w: invalid share token signature", domain.ErrForbidden)
+	}
+
+	dot := strings.LastIndexByte(string(payload), '.')
+	if dot <= 0 {
+		return "", fmt.Errorf("%w: malformed share token", domain.ErrForbidden)
+	}
+	expiresAt, err := strconv.ParseInt(string(payload[dot+1:]), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed share token", domain.ErrForbidden)
+	}
+	if now.UnixMilli() >= expiresAt {
+		return "", fmt.Errorf("%w: share token expired", domain.ErrForbidden)
+	}
+
+	return string(payload[:dot]), nil
+}
+
+// sign returns the HMAC of a token payload
+func (s *StatsShareService) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func encodeShareSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
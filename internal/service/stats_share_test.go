@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("StatsShareService", func() {
+	var (
+		ctx       context.Context
+		svc       *service.StatsShareService
+		statsRead []string
+	)
+
+	newService := func(secret string) *service.StatsShareService {
+		return service.NewStatsShareService(
+			&mocks.MockShortLinkRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					if id == "missing" {
+						return nil, errors.New("short link not found")
+					}
+					return &domain.ShortLink{ID: id}, nil
+				},
+			},
+			&mocks.MockLinkClickRepository{
+				GetStatsByShortLinkIDFunc: func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
+					statsRead = append(statsRead, shortLinkID)
+					return &domain.LinkStats{TotalClicks: int64(len(shortLinkID))}, nil
+				},
+			},
+			secret,
+			24*time.Hour,
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		statsRead = nil
+		svc = newService("share-test-secret")
+	})
+
+	It("should return the stats of the link a valid token names", func() {
+		share, err := svc.CreateToken(ctx, "link-1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(share.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+
+		stats, err := svc.GetLinkStats(ctx, share.Token, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalClicks).To(Equal(int64(len("link-1"))))
+		Expect(statsRead).To(Equal([]string{"link-1"}))
+	})
+
+	It("should default to the longest lifetime", func() {
+		share, err := svc.CreateToken(ctx, "link-1", 0)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(share.ExpiresAt).To(BeTemporally("~", time.Now().Add(24*time.Hour), time.Second))
+	})
+
+	It("should refuse an expired token", func() {
+		share, err := svc.CreateToken(ctx, "link-1", time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		time.Sleep(5 * time.Millisecond)
+
+		_, err = svc.GetLinkStats(ctx, share.Token, nil)
+
+		Expect(errors.Is(err, domain.ErrForbidden)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("expired")))
+		Expect(statsRead).To(BeEmpty())
+	})
+
+	It("should refuse a token rewritten to name a different link", func() {
+		share, err := svc.CreateToken(ctx, "link-1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		payload, sig, _ := strings.Cut(share.Token, ".")
+		decoded, err := base64.RawURLEncoding.DecodeString(payload)
+		Expect(err).NotTo(HaveOccurred())
+		forged := strings.Replace(string(decoded), "link-1", "link-2", 1)
+		token := base64.RawURLEncoding.EncodeToString([]byte(forged)) + "." + sig
+
+		_, err = svc.GetLinkStats(ctx, token, nil)
+
+		Expect(errors.Is(err, domain.ErrForbidden)).To(BeTrue())
+		Expect(statsRead).To(BeEmpty())
+	})
+
+	It("should only ever serve the link a token was minted for", func() {
+		first, err := svc.CreateToken(ctx, "link-1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := svc.CreateToken(ctx, "link-22", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Token).NotTo(Equal(second.Token))
+
+		_, err = svc.GetLinkStats(ctx, second.Token, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statsRead).To(Equal([]string{"link-22"}))
+	})
+
+	It("should refuse a token signed with another secret", func() {
+		share, err := newService("another-secret").CreateToken(ctx, "link-1", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.GetLinkStats(ctx, share.Token, nil)
+
+		Expect(errors.Is(err, domain.ErrForbidden)).To(BeTrue())
+	})
+
+	DescribeTable("should refuse malformed tokens",
+		func(token string) {
+			_, err := svc.GetLinkStats(ctx, token, nil)
+
+			Expect(errors.Is(err, domain.ErrForbidden)).To(BeTrue())
+		},
+		Entry("no signature", "bGluay0x"),
+		Entry("not base64", "!!!.???"),
+		Entry("empty", ""),
+	)
+
+	It("should reject a lifetime beyond the longest allowed", func() {
+		_, err := svc.CreateToken(ctx, "link-1", 48*time.Hour)
+
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields[0].Field).To(Equal("ttl"))
+	})
+
+	It("should report an unknown link as not found", func() {
+		_, err := svc.CreateToken(ctx, "missing", time.Hour)
+
+		Expect(err).To(MatchError(domain.ErrNotFound))
+	})
+})
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/repository"
+)
+
+// SystemStatsService computes aggregate statistics across all short links.
+// Results are cached for a short time so dashboards polling the endpoint
+// do not run the count queries on every request.
+type SystemStatsService struct {
+	linkRepo  repository.ShortLinkRepository
+	clickRepo repository.LinkClickRepository
+	ttl       time.Duration
+
+	mu        sync.Mutex
+	cached    *domain.SystemStats
+	expiresAt time.Time
+}
+
+// NewSystemStatsService creates a new system stats service
+func NewSystemStatsService(
+	linkRepo repository.ShortLinkRepository,
+	clickRepo repository.LinkClickRepository,
+	ttl time.Duration,
+) *SystemStatsService {
+	return &SystemStatsService{
+		linkRepo:  linkRepo,
+		clickRepo: clickRepo,
+		ttl:       ttl,
+	}
+}
+
+// GetSystemStats returns the aggregate statistics, served from cache when fresh
+func (s *SystemStatsService) GetSystemStats(ctx context.Context) (*domain.SystemStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if s.cached != nil && now.Before(s.expiresAt) {
+		stats := *s.cached
+		return &stats, nil
+	}
+
+	stats, err := s.linkRepo.CountStats(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("counting links: %w", err)
+	}
+
+	totalClicks, err := s.clickRepo.CountAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting clicks: %w", err)
+	}
+
+	stats.TotalClicks = totalClicks
+	stats.GeneratedAt = now
+
+	if s.ttl > 0 {
+		cached := *stats
+		s.cached = &cached
+		s.expiresAt = now.Add(s.ttl)
+	}
+
+	return stats, nil
+}
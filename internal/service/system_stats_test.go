@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("SystemStatsService", func() {
+	var (
+		mockShortLinkRepo *mocks.MockShortLinkRepository
+		mockClickRepo     *mocks.MockLinkClickRepository
+		links             []*domain.ShortLink
+		clicks            int64
+		countQueries      int
+		ctx               context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now := time.Now().UTC()
+		past := now.Add(-time.Hour)
+		future := now.Add(time.Hour)
+		yesterday := now.AddDate(0, 0, -1)
+
+		links = []*domain.ShortLink{
+			{ID: "active-today", IsActive: true, CreatedAt: now},
+			{ID: "active-no-expiry", IsActive: true, CreatedAt: yesterday},
+			{ID: "active-future-expiry", IsActive: true, ExpirationDate: &future, CreatedAt: yesterday},
+			{ID: "expired", IsActive: true, ExpirationDate: &past, CreatedAt: yesterday},
+			{ID: "deactivated", IsActive: false, CreatedAt: yesterday},
+		}
+		clicks = 17
+		countQueries = 0
+
+		// In-memory equivalent of the short_links count query
+		mockShortLinkRepo = &mocks.MockShortLinkRepository{
+			CountStatsFunc: func(ctx context.Context, now time.Time) (*domain.SystemStats, error) {
+				countQueries++
+				dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+				stats := &domain.SystemStats{}
+				for _, link := range links {
+					stats.TotalLinks++
+					if !link.CreatedAt.Before(dayStart) {
+						stats.LinksCreatedToday++
+					}
+					expired := link.ExpirationDate != nil && !link.ExpirationDate.After(now)
+					switch {
+					case expired:
+						stats.ExpiredLinks++
+					case link.IsActive:
+						stats.ActiveLinks++
+					default:
+						stats.InactiveLinks++
+					}
+				}
+				return stats, nil
+			},
+		}
+		mockClickRepo = &mocks.MockLinkClickRepository{
+			CountAllFunc: func(ctx context.Context) (int64, error) {
+				return clicks, nil
+			},
+		}
+	})
+
+	It("reports counts matching the seeded data", func() {
+		svc := service.NewSystemStatsService(mockShortLinkRepo, mockClickRepo, time.Minute)
+
+		stats, err := svc.GetSystemStats(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalLinks).To(Equal(5))
+		Expect(stats.TotalClicks).To(Equal(int64(17)))
+		Expect(stats.LinksCreatedToday).To(Equal(1))
+		Expect(stats.ActiveLinks).To(Equal(3))
+		Expect(stats.ExpiredLinks).To(Equal(1))
+		Expect(stats.InactiveLinks).To(Equal(1))
+		Expect(stats.GeneratedAt).To(BeTemporally("~", time.Now(), time.Second))
+	})
+
+	It("serves repeated calls from the cache within the TTL", func() {
+		svc := service.NewSystemStatsService(mockShortLinkRepo, mockClickRepo, time.Minute)
+
+		_, err := svc.GetSystemStats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		clicks = 100
+		stats, err := svc.GetSystemStats(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalClicks).To(Equal(int64(17)))
+		Expect(countQueries).To(Equal(1))
+	})
+
+	It("queries every time when caching is disabled", func() {
+		svc := service.NewSystemStatsService(mockShortLinkRepo, mockClickRepo, 0)
+
+		_, err := svc.GetSystemStats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		clicks = 100
+		stats, err := svc.GetSystemStats(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalClicks).To(Equal(int64(100)))
+		Expect(countQueries).To(Equal(2))
+	})
+
+	It("returns repository errors without caching them", func() {
+		mockClickRepo.CountAllFunc = func(ctx context.Context) (int64, error) {
+			return 0, errors.New("database error")
+		}
+		svc := service.NewSystemStatsService(mockShortLinkRepo, mockClickRepo, time.Minute)
+
+		_, err := svc.GetSystemStats(ctx)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("counting clicks"))
+	})
+})
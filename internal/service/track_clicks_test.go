@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService click tracking setting", func() {
+	var (
+		svc     *service.URLShortenerService
+		ctx     context.Context
+		stored  *domain.ShortLink
+		updated *domain.ShortLink
+	)
+
+	boolPtr := func(b bool) *bool { return &b }
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored, updated = nil, nil
+
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.ShortLink, error) {
+					return &domain.ShortLink{ID: id, Code: "abc123", IsActive: true}, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					stored = link
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					updated = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://short.example.com"},
+		)
+	})
+
+	It("should track clicks of new links by default", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.TrackClicks).To(HaveValue(BeTrue()))
+	})
+
+	It("should create a link with tracking off", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{
+			URL:         "https://example.com",
+			TrackClicks: boolPtr(false),
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.TracksClicks()).To(BeFalse())
+	})
+
+	It("should turn tracking off on update and leave it alone otherwise", func() {
+		_, err := svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{TrackClicks: boolPtr(false)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.TracksClicks()).To(BeFalse())
+
+		_, err = svc.UpdateShortLink(ctx, "link-1", &domain.UpdateShortLinkRequest{IsActive: boolPtr(true)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.TrackClicks).To(BeNil())
+	})
+})
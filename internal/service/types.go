@@ -7,6 +7,7 @@ import (
 // LinkService handles business logic related to links
 type LinkService struct {
 	linkRepo LinkRepository
+	visits   *VisitBatcher
 }
 
 // LinkRepository is an interface for link data access
@@ -19,7 +20,9 @@ type LinkRepository interface {
 	List(userID string, limit, offset int) ([]*domain.Link, error)
 	Count(userID string) (int, error)
 	IncrementVisits(id string) error
+	AddVisits(deltas map[string]int64) error
 	CreateClick(click *domain.Click) error
+	RecordVisit(click *domain.Click) error
 	GetClicks(linkID string, limit, offset int) ([]*domain.Click, error)
 	CountClicks(linkID string) (int, error)
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+)
+
+// How creating a second link to a destination a user already shortened
+// is handled
+const (
+	UniqueDestinationsOff    = "off"
+	UniqueDestinationsReuse  = "reuse"
+	UniqueDestinationsReject = "reject"
+)
+
+// userDestinationLink enforces UniqueDestinations for a create. It returns
+// the caller's active link to the same normalized destination, marked as
+// reused, in reuse mode, and a domain.ErrConflict in reject mode or when a
+// custom alias was asked for, since the existing link's code differs.
+// Requests made without a user and links to other destinations pass with
+// neither.
+func (s *URLShortenerService) userDestinationLink(ctx context.Context, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+	mode := s.opts.UniqueDestinations
+	if mode != UniqueDestinationsReuse && mode != UniqueDestinationsReject {
+		return nil, nil
+	}
+
+	userID := auth.UserIDFromContext(ctx)
+	if userID == "" {
+		return nil, nil
+	}
+
+	// Only links to the same host can match, which keeps the lookup small
+	links, err := s.linkRepo.ListActiveByOwnerAndHost(ctx, userID, destinationHost(req.URL), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("checking user's links to the destination: %w", err)
+	}
+
+	destination := normalizeDestinationURL(req.URL)
+	for _, link := range links {
+		if link.IsPattern != req.IsPattern || link.URL == nil || normalizeDestinationURL(link.URL.OriginalURL) != destination {
+			continue
+		}
+
+		if mode == UniqueDestinationsReject || (req.CustomAlias != nil && *req.CustomAlias != "") {
+			return nil, fmt.Errorf("destination already shortened as %s: %w", link.Code, domain.ErrConflict)
+		}
+
+		link.Reused = true
+		return link, nil
+	}
+
+	return nil, nil
+}
+
+// normalizeDestinationURL returns the form two spellings of the same web
+// destination share: scheme and host lowercased, the default port dropped
+// and an empty path made /. Other URLs are compared as given.
+func normalizeDestinationURL(rawURL string) string {
+	dest, err := url.Parse(rawURL)
+	if err != nil || dest.Host == "" || !isWebScheme(strings.ToLower(dest.Scheme)) {
+		return rawURL
+	}
+
+	dest.Scheme = strings.ToLower(dest.Scheme)
+	dest.Host = strings.ToLower(dest.Host)
+	if port := dest.Port(); (dest.Scheme == "http" && port == "80") || (dest.Scheme == "https" && port == "443") {
+		dest.Host = strings.TrimSuffix(dest.Host, ":"+port)
+	}
+	if dest.Path == "" && dest.RawPath == "" {
+		dest.Path = "/"
+	}
+	dest.ForceQuery = false
+
+	return dest.String()
+}
+
+// destinationHost returns the host of rawURL the way the urls.host column
+// stores it: lowercased, without user info or port and with IPv6 addresses
+// kept in brackets; empty when it has none
+func destinationHost(rawURL string) string {
+	dest, err := url.Parse(rawURL)
+	if err != nil || dest.Host == "" {
+		return ""
+	}
+
+	host := strings.ToLower(dest.Host)
+	if strings.HasPrefix(host, "[") {
+		if end := strings.Index(host, "]"); end >= 0 {
+			return host[:end+1]
+		}
+		return host
+	}
+
+	host, _, _ = strings.Cut(host, ":")
+	return host
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/auth"
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService unique destinations", func() {
+	var (
+		existing *domain.ShortLink
+		lookups  []string
+		created  *domain.ShortLink
+	)
+
+	newService := func(mode string) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				GetByCustomAliasFunc: func(ctx context.Context, alias string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+				ListActiveByOwnerAndHostFunc: func(ctx context.Context, ownerID, host string, now time.Time) ([]*domain.ShortLink, error) {
+					lookups = append(lookups, ownerID+"@"+host)
+					if ownerID != "alice" {
+						return nil, nil
+					}
+					return []*domain.ShortLink{existing}, nil
+				},
+				CreateFunc: func(ctx context.Context, link *domain.ShortLink) error {
+					created = link
+					return nil
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				BaseURL:            "https://short.example.com",
+				UniqueDestinations: mode,
+			},
+		)
+	}
+
+	create := func(svc *service.URLShortenerService, userID string, req *domain.CreateShortLinkRequest) (*domain.ShortLink, error) {
+		ctx := context.Background()
+		if userID != "" {
+			ctx = auth.WithUserID(ctx, userID)
+		}
+		return svc.CreateShortLink(ctx, req)
+	}
+
+	BeforeEach(func() {
+		lookups = nil
+		created = nil
+
+		owner := "alice"
+		existing = &domain.ShortLink{
+			ID:       "link-1",
+			Code:     "abc123",
+			URLID:    "url-1",
+			IsActive: true,
+			OwnerID:  &owner,
+			URL:      &domain.URL{ID: "url-1", OriginalURL: "https://example.com/"},
+		}
+	})
+
+	It("should return the user's existing link to the same normalized destination", func() {
+		svc := newService(service.UniqueDestinationsReuse)
+
+		link, err := create(svc, "alice", &domain.CreateShortLinkRequest{URL: "HTTPS://Example.COM:443"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.ID).To(Equal("link-1"))
+		Expect(link.Reused).To(BeTrue())
+		Expect(lookups).To(Equal([]string{"alice@example.com"}))
+		Expect(created).To(BeNil())
+	})
+
+	It("should create a new link when the path differs", func() {
+		svc := newService(service.UniqueDestinationsReuse)
+
+		link, err := create(svc, "alice", &domain.CreateShortLinkRequest{URL: "https://example.com/pricing"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Reused).To(BeFalse())
+		Expect(created).NotTo(BeNil())
+	})
+
+	It("should create a new link for another user", func() {
+		svc := newService(service.UniqueDestinationsReuse)
+
+		link, err := create(svc, "bob", &domain.CreateShortLinkRequest{URL: "https://example.com/"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Reused).To(BeFalse())
+		Expect(link.OwnerID).To(HaveValue(Equal("bob")))
+		Expect(created).NotTo(BeNil())
+	})
+
+	It("should leave requests without a user alone", func() {
+		svc := newService(service.UniqueDestinationsReuse)
+
+		_, err := create(svc, "", &domain.CreateShortLinkRequest{URL: "https://example.com/"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lookups).To(BeEmpty())
+		Expect(created).NotTo(BeNil())
+	})
+
+	It("should refuse a custom alias for a destination the user already shortened", func() {
+		svc := newService(service.UniqueDestinationsReuse)
+		alias := "launch"
+
+		_, err := create(svc, "alice", &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: &alias})
+
+		Expect(errors.Is(err, domain.ErrConflict)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("abc123"))
+		Expect(created).To(BeNil())
+	})
+
+	It("should refuse the duplicate in reject mode", func() {
+		svc := newService(service.UniqueDestinationsReject)
+
+		_, err := create(svc, "alice", &domain.CreateShortLinkRequest{URL: "https://example.com/"})
+
+		Expect(errors.Is(err, domain.ErrConflict)).To(BeTrue())
+		Expect(created).To(BeNil())
+	})
+
+	It("should create a new link when the option is disabled", func() {
+		svc := newService(service.UniqueDestinationsOff)
+
+		link, err := create(svc, "alice", &domain.CreateShortLinkRequest{URL: "https://example.com/"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Reused).To(BeFalse())
+		Expect(lookups).To(BeEmpty())
+		Expect(created).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService allowed schemes", func() {
+	var (
+		svc *service.URLShortenerService
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{AllowedSchemes: []string{"http", "https", "mailto", "ftp"}},
+		)
+	})
+
+	It("should accept an allowed scheme without a host", func() {
+		link, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "mailto:team@example.com"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).NotTo(BeNil())
+	})
+
+	It("should accept an allowed scheme with a host", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "ftp://files.example.com/report.pdf"})
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should still require a host for hierarchical URLs", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "ftp:///report.pdf"})
+
+		Expect(err).To(MatchError(ContainSubstring("must have a host")))
+	})
+
+	It("should still require a host for web URLs", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https:example.com"})
+
+		Expect(err).To(MatchError(ContainSubstring("must have a host")))
+	})
+
+	It("should reject a scheme that is not allowed", func() {
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "tel:+15555550100"})
+
+		Expect(err).To(MatchError(ContainSubstring("must use HTTP, HTTPS, MAILTO or FTP protocol")))
+	})
+})
+
+var _ = Describe("URLShortenerService HTTPS enforcement", func() {
+	var ctx context.Context
+
+	newService := func(requireHTTPS bool) *service.URLShortenerService {
+		return service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{
+				AllowedSchemes: []string{"http", "https", "mailto"},
+				RequireHTTPS:   requireHTTPS,
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("when enabled", func() {
+		It("should reject http destinations", func() {
+			_, err := newService(true).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "http://example.com"})
+
+			Expect(err).To(MatchError(ContainSubstring("must use HTTPS")))
+		})
+
+		It("should accept https destinations", func() {
+			_, err := newService(true).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com"})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should leave other allowed schemes alone", func() {
+			_, err := newService(true).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "mailto:team@example.com"})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when disabled", func() {
+		It("should accept http destinations", func() {
+			_, err := newService(false).CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "http://example.com"})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
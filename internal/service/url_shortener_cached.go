@@ -2,27 +2,147 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/menezmethod/ref_go/internal/auth"
 	"github.com/menezmethod/ref_go/internal/cache"
 	"github.com/menezmethod/ref_go/internal/domain"
 )
 
 // CachedURLShortenerService wraps the base URL shortener service with caching
 type CachedURLShortenerService struct {
-	base   *URLShortenerService
-	cache  cache.CacheInterface
-	logger *zap.Logger
+	base      *URLShortenerService
+	cache     cache.CacheInterface
+	logger    *zap.Logger
+	namespace string // Prefixed to every cache key
+
+	// listTTL is how many seconds list pages are cached; zero disables it.
+	// listGeneration is part of every list key, so bumping it on a write
+	// orphans all cached pages at once.
+	listTTL        int
+	listGeneration atomic.Int64
+}
+
+// linkPage is a cached page of a link listing
+type linkPage struct {
+	links      []*domain.ShortLink
+	total      int
+	nextCursor string
 }
 
 // NewCachedURLShortenerService creates a new cached URL shortener service
 func NewCachedURLShortenerService(base *URLShortenerService, cache cache.CacheInterface, logger *zap.Logger) *CachedURLShortenerService {
+	return NewCachedURLShortenerServiceWithNamespace(base, cache, logger, "")
+}
+
+// NewCachedURLShortenerServiceWithNamespace creates a cached URL shortener service
+// whose keys are prefixed with namespace (e.g. "prod:shortener:"), so several
+// environments can share one cache without colliding
+func NewCachedURLShortenerServiceWithNamespace(
+	base *URLShortenerService,
+	cache cache.CacheInterface,
+	logger *zap.Logger,
+	namespace string,
+) *CachedURLShortenerService {
 	return &CachedURLShortenerService{
-		base:   base,
-		cache:  cache,
-		logger: logger,
+		base:      base,
+		cache:     cache,
+		logger:    logger,
+		namespace: namespace,
+	}
+}
+
+// codeKey returns the cache key for a link looked up by code
+func (s *CachedURLShortenerService) codeKey(code string) string {
+	return s.namespace + code
+}
+
+// idKey returns the cache key for a link looked up by ID
+func (s *CachedURLShortenerService) idKey(id string) string {
+	return s.namespace + "id:" + id
+}
+
+// CacheLists caches list pages for ttl, rounded up to whole seconds. Any
+// create, update or delete through this service drops every cached page.
+// Writes made elsewhere, such as imports, show up once pages expire.
+func (s *CachedURLShortenerService) CacheLists(ttl time.Duration) {
+	s.listTTL = int((ttl + time.Second - 1) / time.Second)
+}
+
+// listKey returns the cache key for a list page in the current generation
+func (s *CachedURLShortenerService) listKey(params string) string {
+	return s.namespace + "list:" + strconv.FormatInt(s.listGeneration.Load(), 10) + ":" + params
+}
+
+// invalidateLists drops every cached list page
+func (s *CachedURLShortenerService) invalidateLists() {
+	s.listGeneration.Add(1)
+}
+
+// cachedPage reads a list page from the cache
+func (s *CachedURLShortenerService) cachedPage(key string) (*linkPage, bool) {
+	value, found := s.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	page, ok := value.(*linkPage)
+	if !ok || page == nil {
+		s.cache.Delete(key)
+		return nil, false
+	}
+
+	return page, true
+}
+
+// filterParams encodes a link filter for a list cache key. Collections are
+// only visible to their owner, so the caller is part of the key for those.
+func filterParams(ctx context.Context, filter domain.LinkFilter) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	params := "created_after=" + formatTime(filter.CreatedAfter) +
+		"&expires_before=" + formatTime(filter.ExpiresBefore) +
+		"&status=" + filter.Status
+	if filter.CollectionID != "" {
+		params += "&collection=" + url.QueryEscape(filter.CollectionID) +
+			"&owner=" + url.QueryEscape(auth.UserIDFromContext(ctx))
+	}
+
+	return params
+}
+
+// cachedLink reads a short link from the cache. An entry of any other type
+// is treated as a miss and evicted so the caller falls back to the database.
+func (s *CachedURLShortenerService) cachedLink(key string) (*domain.ShortLink, bool) {
+	value, found := s.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	link, ok := value.(*domain.ShortLink)
+	if !ok || link == nil {
+		s.logger.Warn("Unexpected value type in cache, falling back to database",
+			zap.String("key", key),
+			zap.String("type", fmt.Sprintf("%T", value)),
+		)
+		s.cache.Delete(key)
+		return nil, false
 	}
+
+	return link, true
 }
 
 // CreateShortLink creates a new short link (delegated to base service, updates cache)
@@ -33,8 +153,15 @@ func (s *CachedURLShortenerService) CreateShortLink(ctx context.Context, req *do
 		return nil, err
 	}
 
+	// A reused link changed nothing, and caching it would keep the flag
+	// on later lookups
+	if link.Reused {
+		return link, nil
+	}
+
 	// Add link to cache
-	s.cache.Set(link.Code, link, 0)
+	s.cache.Set(s.codeKey(link.Code), link, 0)
+	s.invalidateLists()
 
 	return link, nil
 }
@@ -42,9 +169,9 @@ func (s *CachedURLShortenerService) CreateShortLink(ctx context.Context, req *do
 // GetShortLink gets a short link by ID (with caching)
 func (s *CachedURLShortenerService) GetShortLink(ctx context.Context, id string) (*domain.ShortLink, error) {
 	// Try to get link from cache by ID
-	if cachedLink, found := s.cache.Get("id:" + id); found {
+	if cachedLink, found := s.cachedLink(s.idKey(id)); found {
 		s.logger.Debug("Cache hit for link ID", zap.String("id", id))
-		return cachedLink.(*domain.ShortLink), nil
+		return cachedLink, nil
 	}
 
 	// Get link from the base service
@@ -54,8 +181,8 @@ func (s *CachedURLShortenerService) GetShortLink(ctx context.Context, id string)
 	}
 
 	// Add link to cache
-	s.cache.Set("id:"+id, link, 0)
-	s.cache.Set(link.Code, link, 0)
+	s.cache.Set(s.idKey(id), link, 0)
+	s.cache.Set(s.codeKey(link.Code), link, 0)
 
 	return link, nil
 }
@@ -63,9 +190,9 @@ func (s *CachedURLShortenerService) GetShortLink(ctx context.Context, id string)
 // GetShortLinkByCode gets a short link by code (with caching)
 func (s *CachedURLShortenerService) GetShortLinkByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
 	// Try to get link from cache by code
-	if cachedLink, found := s.cache.Get(code); found {
+	if cachedLink, found := s.cachedLink(s.codeKey(code)); found {
 		s.logger.Debug("Cache hit for link code", zap.String("code", code))
-		return cachedLink.(*domain.ShortLink), nil
+		return cachedLink, nil
 	}
 
 	// Get link from the base service
@@ -74,9 +201,13 @@ func (s *CachedURLShortenerService) GetShortLinkByCode(ctx context.Context, code
 		return nil, err
 	}
 
-	// Add link to cache
-	s.cache.Set(code, link, 0)
-	s.cache.Set("id:"+link.ID, link, 0)
+	// Add link to cache. Other spellings of the code, such as another case,
+	// are not cached since evictLink, which updates and deletes use, could
+	// not find them again.
+	if s.evictsCode(link, code) {
+		s.cache.Set(s.codeKey(code), link, 0)
+	}
+	s.cache.Set(s.idKey(link.ID), link, 0)
 
 	return link, nil
 }
@@ -86,8 +217,8 @@ func (s *CachedURLShortenerService) UpdateShortLink(ctx context.Context, id stri
 	// Get the current link to know what to invalidate
 	oldLink, err := s.base.GetShortLink(ctx, id)
 	if err == nil {
-		// Invalidate the old code in the cache
-		s.cache.Delete(oldLink.Code)
+		// Invalidate the old code and alias in the cache
+		s.evictLink(oldLink)
 	}
 
 	// Update link using the base service
@@ -96,12 +227,10 @@ func (s *CachedURLShortenerService) UpdateShortLink(ctx context.Context, id stri
 		return nil, err
 	}
 
-	// Invalidate cache entries
-	s.cache.Delete("id:" + id)
-
 	// Add updated link to cache
-	s.cache.Set("id:"+id, link, 0)
-	s.cache.Set(link.Code, link, 0)
+	s.cache.Set(s.idKey(id), link, 0)
+	s.cache.Set(s.codeKey(link.Code), link, 0)
+	s.invalidateLists()
 
 	return link, nil
 }
@@ -110,9 +239,10 @@ func (s *CachedURLShortenerService) UpdateShortLink(ctx context.Context, id stri
 func (s *CachedURLShortenerService) DeleteShortLink(ctx context.Context, id string) error {
 	// Get the current link to know what to invalidate
 	oldLink, err := s.base.GetShortLink(ctx, id)
-	if err == nil {
-		// Invalidate the old code in the cache
-		s.cache.Delete(oldLink.Code)
+	evicted := err == nil
+	if evicted {
+		// Invalidate the old code and alias in the cache
+		s.evictLink(oldLink)
 	}
 
 	// Delete link using the base service
@@ -121,28 +251,213 @@ func (s *CachedURLShortenerService) DeleteShortLink(ctx context.Context, id stri
 		return err
 	}
 
-	// Invalidate cache entry
-	s.cache.Delete("id:" + id)
+	// Invalidate the ID entry evictLink did not get to
+	if !evicted {
+		s.cache.Delete(s.idKey(id))
+	}
+	s.invalidateLists()
 
 	return nil
 }
 
-// ListShortLinks lists short links (not cached)
+// UpdateURL changes a URL's destination and evicts every cached short link
+// pointing at it, since each of them embeds the old destination
+func (s *CachedURLShortenerService) UpdateURL(ctx context.Context, id, originalURL string) (*domain.URL, error) {
+	url, err := s.base.UpdateURL(ctx, id, originalURL)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateLists()
+
+	links, err := s.base.linkRepo.GetAllByURLID(ctx, id)
+	if err != nil {
+		// The update itself succeeded, so log rather than fail the request
+		s.logger.Warn("Failed to list short links for cache invalidation",
+			zap.String("url_id", id),
+			zap.Error(err),
+		)
+		return url, nil
+	}
+
+	for _, link := range links {
+		s.evictLink(link)
+	}
+
+	return url, nil
+}
+
+// evictLink drops the entries of a link cached under its ID, code, alias
+// and display code
+func (s *CachedURLShortenerService) evictLink(link *domain.ShortLink) {
+	s.evictCode(link)
+	s.cache.Delete(s.idKey(link.ID))
+	if link.CustomAlias != nil && *link.CustomAlias != "" {
+		s.cache.Delete(s.codeKey(*link.CustomAlias))
+	}
+}
+
+// evictCode drops the entries of a link cached under its code and display code
+func (s *CachedURLShortenerService) evictCode(link *domain.ShortLink) {
+	s.cache.Delete(s.codeKey(link.Code))
+	if display := s.base.DisplayCode(link); display != link.Code {
+		s.cache.Delete(s.codeKey(display))
+	}
+}
+
+// evictsCode reports whether evictLink drops the entry of link cached under code
+func (s *CachedURLShortenerService) evictsCode(link *domain.ShortLink, code string) bool {
+	return code == link.Code ||
+		(link.CustomAlias != nil && code == *link.CustomAlias) ||
+		code == s.base.DisplayCode(link)
+}
+
+// InvalidateLink drops every cached entry of the link with code or alias
+// code, for when the database was changed behind the service's back. The
+// link is read from the database rather than the cache, so entries keyed by
+// its ID go too; whatever is cached under code is dropped even if the link
+// is gone, and only when neither has it is domain.ErrNotFound returned.
+func (s *CachedURLShortenerService) InvalidateLink(ctx context.Context, code string) error {
+	cached, wasCached := s.cachedLink(s.codeKey(code))
+	if wasCached {
+		s.evictLink(cached)
+	}
+	s.cache.Delete(s.codeKey(code))
+	s.invalidateLists()
+
+	link, err := s.base.linkRepo.GetByCustomAlias(ctx, code)
+	if err != nil || link == nil {
+		link, err = s.base.linkRepo.GetByCode(ctx, code)
+	}
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) && !strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("retrieving short link: %w", err)
+		}
+		if wasCached {
+			return nil
+		}
+		return fmt.Errorf("short link %s: %w", code, domain.ErrNotFound)
+	}
+
+	s.evictLink(link)
+	s.logger.Info("Invalidated cached link",
+		zap.String("code", code),
+		zap.String("id", link.ID),
+	)
+
+	return nil
+}
+
+// WarmUp preloads the limit most clicked active links so the common redirects
+// are served from cache right after startup. It returns how many were loaded.
+func (s *CachedURLShortenerService) WarmUp(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	links, err := s.base.linkRepo.ListMostClicked(ctx, limit, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("listing most clicked links: %w", err)
+	}
+
+	for _, link := range links {
+		s.cache.Set(s.codeKey(link.Code), link, 0)
+		s.cache.Set(s.idKey(link.ID), link, 0)
+		if link.CustomAlias != nil && *link.CustomAlias != "" {
+			s.cache.Set(s.codeKey(*link.CustomAlias), link, 0)
+		}
+	}
+
+	s.logger.Info("Warmed up link cache", zap.Int("links", len(links)))
+
+	return len(links), nil
+}
+
+// ListShortLinks lists short links (cached briefly when CacheLists is set)
 func (s *CachedURLShortenerService) ListShortLinks(ctx context.Context, page, pageSize int) ([]*domain.ShortLink, int, error) {
-	// List links using the base service (not cached due to pagination)
-	return s.base.ListShortLinks(ctx, page, pageSize)
+	if s.listTTL == 0 {
+		return s.base.ListShortLinks(ctx, page, pageSize)
+	}
+
+	key := s.listKey(fmt.Sprintf("page=%d&page_size=%d", page, pageSize))
+	if cached, found := s.cachedPage(key); found {
+		s.logger.Debug("Cache hit for link list", zap.String("key", key))
+		return cached.links, cached.total, nil
+	}
+
+	links, total, err := s.base.ListShortLinks(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.cache.Set(key, &linkPage{links: links, total: total}, s.listTTL)
+
+	return links, total, nil
+}
+
+// ListShortLinksFiltered lists filtered short links (cached briefly when CacheLists is set)
+func (s *CachedURLShortenerService) ListShortLinksFiltered(ctx context.Context, filter domain.LinkFilter, page, pageSize int) ([]*domain.ShortLink, int, error) {
+	if s.listTTL == 0 {
+		return s.base.ListShortLinksFiltered(ctx, filter, page, pageSize)
+	}
+
+	key := s.listKey(fmt.Sprintf("page=%d&page_size=%d&%s", page, pageSize, filterParams(ctx, filter)))
+	if cached, found := s.cachedPage(key); found {
+		s.logger.Debug("Cache hit for link list", zap.String("key", key))
+		return cached.links, cached.total, nil
+	}
+
+	links, total, err := s.base.ListShortLinksFiltered(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.cache.Set(key, &linkPage{links: links, total: total}, s.listTTL)
+
+	return links, total, nil
+}
+
+// ListShortLinksAfter lists short links by cursor (cached briefly when CacheLists is set)
+func (s *CachedURLShortenerService) ListShortLinksAfter(ctx context.Context, cursor string, pageSize int) ([]*domain.ShortLink, string, error) {
+	if s.listTTL == 0 {
+		return s.base.ListShortLinksAfter(ctx, cursor, pageSize)
+	}
+
+	key := s.listKey(fmt.Sprintf("page_size=%d&cursor=%s", pageSize, cursor))
+	if cached, found := s.cachedPage(key); found {
+		s.logger.Debug("Cache hit for link list", zap.String("key", key))
+		return cached.links, cached.nextCursor, nil
+	}
+
+	links, nextCursor, err := s.base.ListShortLinksAfter(ctx, cursor, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.cache.Set(key, &linkPage{links: links, nextCursor: nextCursor}, s.listTTL)
+
+	return links, nextCursor, nil
 }
 
 // RecordClick records a click on a short link
-func (s *CachedURLShortenerService) RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress string) error {
+func (s *CachedURLShortenerService) RecordClick(ctx context.Context, shortLinkID string, referrer, userAgent, ipAddress, rawQuery string) error {
 	// Record click using the base service
-	return s.base.RecordClick(ctx, shortLinkID, referrer, userAgent, ipAddress)
+	return s.base.RecordClick(ctx, shortLinkID, referrer, userAgent, ipAddress, rawQuery)
+}
+
+// GetLinkClick gets a single click of a short link
+func (s *CachedURLShortenerService) GetLinkClick(ctx context.Context, shortLinkID, clickID string) (*domain.LinkClick, error) {
+	return s.base.GetLinkClick(ctx, shortLinkID, clickID)
 }
 
 // GetLinkStats gets statistics for a short link
-func (s *CachedURLShortenerService) GetLinkStats(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
+func (s *CachedURLShortenerService) GetLinkStats(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
 	// Get stats using the base service (not cached as they change frequently)
-	return s.base.GetLinkStats(ctx, shortLinkID)
+	return s.base.GetLinkStats(ctx, shortLinkID, loc)
+}
+
+// GetLinkStatsSummaries gets summary statistics for several links
+func (s *CachedURLShortenerService) GetLinkStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+	return s.base.GetLinkStatsSummaries(ctx, shortLinkIDs)
 }
 
 // GetCacheStats gets statistics about the cache
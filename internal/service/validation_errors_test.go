@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("URLShortenerService validation errors", func() {
+	var (
+		ctx     context.Context
+		repoErr error
+		svc     *service.URLShortenerService
+	)
+
+	strPtr := func(s string) *string { return &s }
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repoErr = nil
+
+		svc = service.NewURLShortenerService(
+			&mocks.MockURLRepository{
+				GetByHashFunc: func(ctx context.Context, hash string) (*domain.URL, error) {
+					return nil, repoErr
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*domain.URL, error) {
+					return &domain.URL{ID: id, OriginalURL: "https://example.com"}, nil
+				},
+			},
+			&mocks.MockShortLinkRepository{
+				GetByCodeFunc: func(ctx context.Context, code string) (*domain.ShortLink, error) {
+					return nil, domain.ErrNotFound
+				},
+			},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			"https://sho.rt",
+			0,
+		)
+	})
+
+	DescribeTable("should wrap ErrValidation for a rejected URL or alias",
+		func(req *domain.CreateShortLinkRequest) {
+			_, err := svc.CreateShortLink(ctx, req)
+
+			Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+		},
+		Entry("empty URL", &domain.CreateShortLinkRequest{URL: ""}),
+		Entry("unsupported scheme", &domain.CreateShortLinkRequest{URL: "ftp://example.com/file"}),
+		Entry("missing host", &domain.CreateShortLinkRequest{URL: "https://"}),
+		Entry("reserved alias", &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: strPtr("api")}),
+	)
+
+	It("should wrap ErrValidation when a URL is updated to an invalid one", func() {
+		_, err := svc.UpdateURL(ctx, "url-1", "javascript:alert(1)")
+
+		Expect(errors.Is(err, domain.ErrValidation)).To(BeTrue())
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields[0].Field).To(Equal("url"))
+	})
+
+	It("should not report a repository failure as a validation error", func() {
+		repoErr = errors.New("pq: connection refused")
+
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com"})
+
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, domain.ErrValidation)).To(BeFalse())
+	})
+
+	It("should refuse configured reserved aliases, such as a custom API prefix", func() {
+		svc = service.NewURLShortenerServiceWithOptions(
+			&mocks.MockURLRepository{},
+			&mocks.MockShortLinkRepository{},
+			&mocks.MockLinkClickRepository{},
+			zaptest.NewLogger(GinkgoT()),
+			service.Options{BaseURL: "https://sho.rt", ReservedAliases: []string{"v2"}},
+		)
+
+		_, err := svc.CreateShortLink(ctx, &domain.CreateShortLinkRequest{URL: "https://example.com", CustomAlias: strPtr("V2")})
+
+		var verr *domain.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields).To(ConsistOf(domain.FieldError{
+			Field:   "custom_alias",
+			Message: "custom alias 'V2' is reserved and cannot be used",
+		}))
+	})
+})
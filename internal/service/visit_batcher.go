@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// VisitAdder stores coalesced visit increments
+type VisitAdder interface {
+	AddVisits(deltas map[string]int64) error
+}
+
+// VisitBatcher coalesces visit increments per link in memory and stores
+// them in one batch per flush, instead of one UPDATE per click. Increments
+// not yet flushed are lost if the process dies; the visit reconciliation
+// job recounts them from the click records.
+type VisitBatcher struct {
+	adder  VisitAdder
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]int64
+}
+
+// NewVisitBatcher creates a new visit batcher
+func NewVisitBatcher(adder VisitAdder, logger *zap.Logger) *VisitBatcher {
+	return &VisitBatcher{
+		adder:   adder,
+		logger:  logger,
+		pending: make(map[string]int64),
+	}
+}
+
+// Increment counts one visit of a link until the next flush
+func (b *VisitBatcher) Increment(linkID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending[linkID]++
+}
+
+// Flush stores the pending increments and returns how many links they
+// touched. After a failed flush the increments are kept for the next one.
+func (b *VisitBatcher) Flush(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	deltas := b.pending
+	b.pending = make(map[string]int64)
+	b.mu.Unlock()
+
+	if len(deltas) == 0 {
+		return 0, nil
+	}
+
+	if err := b.adder.AddVisits(deltas); err != nil {
+		// Put them back, merged with whatever arrived meanwhile
+		b.mu.Lock()
+		for linkID, delta := range deltas {
+			b.pending[linkID] += delta
+		}
+		b.mu.Unlock()
+		return 0, fmt.Errorf("flushing visit increments: %w", err)
+	}
+
+	return len(deltas), nil
+}
+
+// Start flushes the pending increments periodically until the context is
+// cancelled, then flushes once more before returning, so callers waiting
+// for Start on shutdown lose no counted visits
+func (b *VisitBatcher) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The context is done, the final flush must not depend on it
+			if _, err := b.Flush(context.Background()); err != nil {
+				b.logger.Error("Final visit increment flush failed", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if _, err := b.Flush(ctx); err != nil {
+				b.logger.Error("Visit increment flush failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/menezmethod/ref_go/internal/domain"
+	"github.com/menezmethod/ref_go/internal/service"
+	"github.com/menezmethod/ref_go/internal/testutils/mocks"
+)
+
+var _ = Describe("VisitBatcher", func() {
+	var (
+		mu       sync.Mutex
+		visits   map[string]int64
+		batches  int
+		addErr   error
+		linkRepo *mocks.MockLinkRepository
+		batcher  *service.VisitBatcher
+		ctx      context.Context
+	)
+
+	// stored reads a link's visits count from the stand-in table
+	stored := func(linkID string) int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return visits[linkID]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		visits = map[string]int64{}
+		batches = 0
+		addErr = nil
+
+		linkRepo = &mocks.MockLinkRepository{
+			AddVisitsFunc: func(deltas map[string]int64) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if addErr != nil {
+					return addErr
+				}
+				batches++
+				for id, delta := range deltas {
+					visits[id] += delta
+				}
+				return nil
+			},
+			IncrementVisitsFunc: func(id string) error {
+				Fail("visits must not be incremented one click at a time")
+				return nil
+			},
+		}
+		batcher = service.NewVisitBatcher(linkRepo, zaptest.NewLogger(GinkgoT()))
+	})
+
+	It("should coalesce concurrent increments into one batch with the correct totals", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				batcher.Increment("link-1")
+				if i%5 == 0 {
+					batcher.Increment("link-2")
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		links, err := batcher.Flush(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(links).To(Equal(2))
+		Expect(batches).To(Equal(1))
+		Expect(stored("link-1")).To(Equal(int64(50)))
+		Expect(stored("link-2")).To(Equal(int64(10)))
+	})
+
+	It("should only store what arrived since the last flush", func() {
+		batcher.Increment("link-1")
+		batcher.Increment("link-1")
+		Expect(batcher.Flush(ctx)).To(Equal(1))
+
+		batcher.Increment("link-1")
+		Expect(batcher.Flush(ctx)).To(Equal(1))
+		Expect(batcher.Flush(ctx)).To(Equal(0))
+
+		Expect(stored("link-1")).To(Equal(int64(3)))
+		Expect(batches).To(Equal(2))
+	})
+
+	It("should keep the increments of a failed flush for the next one", func() {
+		batcher.Increment("link-1")
+		addErr = errors.New("connection refused")
+
+		_, err := batcher.Flush(ctx)
+		Expect(err).To(MatchError(ContainSubstring("connection refused")))
+
+		batcher.Increment("link-1")
+		addErr = nil
+		Expect(batcher.Flush(ctx)).To(Equal(1))
+
+		Expect(stored("link-1")).To(Equal(int64(2)))
+	})
+
+	It("should flush periodically and once more when stopped", func() {
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			batcher.Start(runCtx, 10*time.Millisecond)
+		}()
+
+		batcher.Increment("link-1")
+		Eventually(func() int64 { return stored("link-1") }).Should(Equal(int64(1)))
+
+		// Counted after the last tick, stored by the final flush
+		batcher.Increment("link-1")
+		stop()
+		Eventually(done).Should(BeClosed())
+
+		Expect(stored("link-1")).To(Equal(int64(2)))
+	})
+
+	Context("behind LinkService", func() {
+		var srv *service.LinkService
+
+		BeforeEach(func() {
+			srv = service.NewLinkService(linkRepo)
+			srv.BatchVisits(batcher)
+		})
+
+		It("should store every click but add the visits in one batch", func() {
+			var clicks []string
+			linkRepo.CreateClickFunc = func(click *domain.Click) error {
+				clicks = append(clicks, click.LinkID)
+				return nil
+			}
+			linkRepo.RecordVisitFunc = func(click *domain.Click) error {
+				Fail("batched clicks must not increment visits per click")
+				return nil
+			}
+
+			for i := 0; i < 3; i++ {
+				Expect(srv.RecordClick("link-1", "Mozilla/5.0", "", "127.0.0.1")).To(Succeed())
+			}
+			Expect(srv.RecordClick("link-2", "Mozilla/5.0", "", "127.0.0.1")).To(Succeed())
+			Expect(batcher.Flush(ctx)).To(Equal(2))
+
+			Expect(clicks).To(HaveLen(4))
+			Expect(stored("link-1")).To(Equal(int64(3)))
+			Expect(stored("link-2")).To(Equal(int64(1)))
+			Expect(batches).To(Equal(1))
+		})
+
+		It("should not count a visit whose click could not be stored", func() {
+			linkRepo.CreateClickFunc = func(click *domain.Click) error {
+				return errors.New("database error")
+			}
+
+			Expect(srv.RecordClick("link-1", "Mozilla/5.0", "", "127.0.0.1")).To(MatchError("database error"))
+			Expect(batcher.Flush(ctx)).To(Equal(0))
+		})
+	})
+})
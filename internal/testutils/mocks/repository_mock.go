@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/menezmethod/ref_go/internal/domain"
 )
@@ -16,7 +17,9 @@ type MockLinkRepository struct {
 	ListFunc            func(userID string, limit, offset int) ([]*domain.Link, error)
 	CountFunc           func(userID string) (int, error)
 	IncrementVisitsFunc func(id string) error
+	AddVisitsFunc       func(deltas map[string]int64) error
 	CreateClickFunc     func(click *domain.Click) error
+	RecordVisitFunc     func(click *domain.Click) error
 	GetClicksFunc       func(linkID string, limit, offset int) ([]*domain.Click, error)
 	CountClicksFunc     func(linkID string) (int, error)
 }
@@ -93,6 +96,26 @@ func (m *MockLinkRepository) CreateClick(click *domain.Click) error {
 	return nil
 }
 
+// AddVisits mocks the AddVisits method
+func (m *MockLinkRepository) AddVisits(deltas map[string]int64) error {
+	if m.AddVisitsFunc != nil {
+		return m.AddVisitsFunc(deltas)
+	}
+	return nil
+}
+
+// RecordVisit mocks the RecordVisit method. Without a RecordVisitFunc it
+// calls IncrementVisits and then CreateClick, stopping at the first error.
+func (m *MockLinkRepository) RecordVisit(click *domain.Click) error {
+	if m.RecordVisitFunc != nil {
+		return m.RecordVisitFunc(click)
+	}
+	if err := m.IncrementVisits(click.LinkID); err != nil {
+		return err
+	}
+	return m.CreateClick(click)
+}
+
 // GetClicks mocks the GetClicks method
 func (m *MockLinkRepository) GetClicks(linkID string, limit, offset int) ([]*domain.Click, error) {
 	if m.GetClicksFunc != nil {
@@ -163,6 +186,7 @@ type MockURLRepository struct {
 	CreateFunc    func(ctx context.Context, url *domain.URL) error
 	GetByIDFunc   func(ctx context.Context, id string) (*domain.URL, error)
 	GetByHashFunc func(ctx context.Context, hash string) (*domain.URL, error)
+	UpdateFunc    func(ctx context.Context, url *domain.URL) error
 }
 
 // Create mocks the Create method
@@ -189,17 +213,38 @@ func (m *MockURLRepository) GetByHash(ctx context.Context, hash string) (*domain
 	return nil, nil
 }
 
+// Update mocks the Update method
+func (m *MockURLRepository) Update(ctx context.Context, url *domain.URL) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, url)
+	}
+	return nil
+}
+
 // MockShortLinkRepository mocks the ShortLinkRepository interface
 type MockShortLinkRepository struct {
-	CreateFunc           func(ctx context.Context, link *domain.ShortLink) error
-	GetByIDFunc          func(ctx context.Context, id string) (*domain.ShortLink, error)
-	GetByCodeFunc        func(ctx context.Context, code string) (*domain.ShortLink, error)
-	GetByCustomAliasFunc func(ctx context.Context, alias string) (*domain.ShortLink, error)
-	GetAllByURLIDFunc    func(ctx context.Context, urlID string) ([]*domain.ShortLink, error)
-	UpdateFunc           func(ctx context.Context, link *domain.ShortLink) error
-	DeleteFunc           func(ctx context.Context, id string) error
-	ListFunc             func(ctx context.Context, offset, limit int) ([]*domain.ShortLink, error)
-	CountFunc            func(ctx context.Context) (int, error)
+	CreateFunc                   func(ctx context.Context, link *domain.ShortLink) error
+	GetByIDFunc                  func(ctx context.Context, id string) (*domain.ShortLink, error)
+	GetByCodeFunc                func(ctx context.Context, code string) (*domain.ShortLink, error)
+	GetByCustomAliasFunc         func(ctx context.Context, alias string) (*domain.ShortLink, error)
+	GetAllByURLIDFunc            func(ctx context.Context, urlID string) ([]*domain.ShortLink, error)
+	UpdateFunc                   func(ctx context.Context, link *domain.ShortLink) error
+	UpdateHealthFunc             func(ctx context.Context, id string, health *domain.LinkHealth) error
+	TouchLastAccessedFunc        func(ctx context.Context, id string, at time.Time) error
+	DeleteFunc                   func(ctx context.Context, id string) error
+	ListFunc                     func(ctx context.Context, offset, limit int) ([]*domain.ShortLink, error)
+	CountFunc                    func(ctx context.Context) (int, error)
+	CountStatsFunc               func(ctx context.Context, now time.Time) (*domain.SystemStats, error)
+	CountActiveByOwnerFunc       func(ctx context.Context, ownerID string, now time.Time) (int, error)
+	ListActiveByOwnerAndHostFunc func(ctx context.Context, ownerID, host string, now time.Time) ([]*domain.ShortLink, error)
+	ListByDestinationHostFunc    func(ctx context.Context, host string, offset, limit int) ([]*domain.ShortLink, error)
+	CountByDestinationHostFunc   func(ctx context.Context, host string) (int, error)
+	ListMostClickedFunc          func(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error)
+	ListAfterFunc                func(ctx context.Context, cursor *domain.LinkCursor, limit int) ([]*domain.ShortLink, error)
+	ListFilteredFunc             func(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error)
+	CountFilteredFunc            func(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error)
+	StreamFunc                   func(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error
+	StreamPageFunc               func(ctx context.Context, offset, limit int, fn func(link *domain.ShortLink) error) error
 }
 
 // Create mocks the Create method
@@ -250,6 +295,22 @@ func (m *MockShortLinkRepository) Update(ctx context.Context, link *domain.Short
 	return nil
 }
 
+// TouchLastAccessed mocks the TouchLastAccessed method
+func (m *MockShortLinkRepository) TouchLastAccessed(ctx context.Context, id string, at time.Time) error {
+	if m.TouchLastAccessedFunc != nil {
+		return m.TouchLastAccessedFunc(ctx, id, at)
+	}
+	return nil
+}
+
+// UpdateHealth mocks the UpdateHealth method
+func (m *MockShortLinkRepository) UpdateHealth(ctx context.Context, id string, health *domain.LinkHealth) error {
+	if m.UpdateHealthFunc != nil {
+		return m.UpdateHealthFunc(ctx, id, health)
+	}
+	return nil
+}
+
 // Delete mocks the Delete method
 func (m *MockShortLinkRepository) Delete(ctx context.Context, id string) error {
 	if m.DeleteFunc != nil {
@@ -266,6 +327,38 @@ func (m *MockShortLinkRepository) List(ctx context.Context, offset, limit int) (
 	return nil, nil
 }
 
+// StreamPage mocks the StreamPage method
+func (m *MockShortLinkRepository) StreamPage(ctx context.Context, offset, limit int, fn func(link *domain.ShortLink) error) error {
+	if m.StreamPageFunc != nil {
+		return m.StreamPageFunc(ctx, offset, limit, fn)
+	}
+	return nil
+}
+
+// ListAfter mocks the ListAfter method
+func (m *MockShortLinkRepository) ListAfter(ctx context.Context, cursor *domain.LinkCursor, limit int) ([]*domain.ShortLink, error) {
+	if m.ListAfterFunc != nil {
+		return m.ListAfterFunc(ctx, cursor, limit)
+	}
+	return nil, nil
+}
+
+// ListFiltered mocks the ListFiltered method
+func (m *MockShortLinkRepository) ListFiltered(ctx context.Context, filter domain.LinkFilter, offset, limit int, now time.Time) ([]*domain.ShortLink, error) {
+	if m.ListFilteredFunc != nil {
+		return m.ListFilteredFunc(ctx, filter, offset, limit, now)
+	}
+	return nil, nil
+}
+
+// CountFiltered mocks the CountFiltered method
+func (m *MockShortLinkRepository) CountFiltered(ctx context.Context, filter domain.LinkFilter, now time.Time) (int, error) {
+	if m.CountFilteredFunc != nil {
+		return m.CountFilteredFunc(ctx, filter, now)
+	}
+	return 0, nil
+}
+
 // Count mocks the Count method
 func (m *MockShortLinkRepository) Count(ctx context.Context) (int, error) {
 	if m.CountFunc != nil {
@@ -274,11 +367,76 @@ func (m *MockShortLinkRepository) Count(ctx context.Context) (int, error) {
 	return 0, nil
 }
 
+// CountStats mocks the CountStats method
+func (m *MockShortLinkRepository) CountStats(ctx context.Context, now time.Time) (*domain.SystemStats, error) {
+	if m.CountStatsFunc != nil {
+		return m.CountStatsFunc(ctx, now)
+	}
+	return &domain.SystemStats{}, nil
+}
+
+// CountActiveByOwner mocks the CountActiveByOwner method
+func (m *MockShortLinkRepository) CountActiveByOwner(ctx context.Context, ownerID string, now time.Time) (int, error) {
+	if m.CountActiveByOwnerFunc != nil {
+		return m.CountActiveByOwnerFunc(ctx, ownerID, now)
+	}
+	return 0, nil
+}
+
+// ListActiveByOwnerAndHost mocks the ListActiveByOwnerAndHost method
+func (m *MockShortLinkRepository) ListActiveByOwnerAndHost(ctx context.Context, ownerID, host string, now time.Time) ([]*domain.ShortLink, error) {
+	if m.ListActiveByOwnerAndHostFunc != nil {
+		return m.ListActiveByOwnerAndHostFunc(ctx, ownerID, host, now)
+	}
+	return nil, nil
+}
+
+// ListByDestinationHost mocks the ListByDestinationHost method
+func (m *MockShortLinkRepository) ListByDestinationHost(ctx context.Context, host string, offset, limit int) ([]*domain.ShortLink, error) {
+	if m.ListByDestinationHostFunc != nil {
+		return m.ListByDestinationHostFunc(ctx, host, offset, limit)
+	}
+	return []*domain.ShortLink{}, nil
+}
+
+// CountByDestinationHost mocks the CountByDestinationHost method
+func (m *MockShortLinkRepository) CountByDestinationHost(ctx context.Context, host string) (int, error) {
+	if m.CountByDestinationHostFunc != nil {
+		return m.CountByDestinationHostFunc(ctx, host)
+	}
+	return 0, nil
+}
+
+// Stream mocks the Stream method
+func (m *MockShortLinkRepository) Stream(ctx context.Context, limit int, fn func(link *domain.ShortLink) error) error {
+	if m.StreamFunc != nil {
+		return m.StreamFunc(ctx, limit, fn)
+	}
+	return nil
+}
+
+// ListMostClicked mocks the ListMostClicked method
+func (m *MockShortLinkRepository) ListMostClicked(ctx context.Context, limit int, now time.Time) ([]*domain.ShortLink, error) {
+	if m.ListMostClickedFunc != nil {
+		return m.ListMostClickedFunc(ctx, limit, now)
+	}
+	return nil, nil
+}
+
 // MockLinkClickRepository mocks the LinkClickRepository interface
 type MockLinkClickRepository struct {
 	CreateFunc                func(ctx context.Context, click *domain.LinkClick) error
+	GetByIDFunc               func(ctx context.Context, id string) (*domain.LinkClick, error)
 	GetByShortLinkIDFunc      func(ctx context.Context, shortLinkID string, offset, limit int) ([]*domain.LinkClick, error)
-	GetStatsByShortLinkIDFunc func(ctx context.Context, shortLinkID string) (*domain.LinkStats, error)
+	GetStatsByShortLinkIDFunc func(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error)
+	GetStatsSummariesFunc     func(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error)
+	DeleteOlderThanFunc       func(ctx context.Context, cutoff time.Time, archive bool) (int64, error)
+	StreamByCreatedRangeFunc  func(ctx context.Context, start, end time.Time, fn func(click *domain.LinkClick) error) error
+	GetEarliestCreatedAtFunc  func(ctx context.Context) (*time.Time, error)
+	GetRollupWatermarkFunc    func(ctx context.Context) (*time.Time, error)
+	SaveDailyRollupFunc       func(ctx context.Context, day time.Time, rollups []*domain.DailyClickRollup) error
+	CountAllFunc              func(ctx context.Context) (int64, error)
+	AddSampledOutFunc         func(ctx context.Context, shortLinkID string, clicks int) error
 }
 
 // Create mocks the Create method
@@ -289,6 +447,14 @@ func (m *MockLinkClickRepository) Create(ctx context.Context, click *domain.Link
 	return nil
 }
 
+// GetByID mocks the GetByID method
+func (m *MockLinkClickRepository) GetByID(ctx context.Context, id string) (*domain.LinkClick, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, domain.ErrNotFound
+}
+
 // GetByShortLinkID mocks the GetByShortLinkID method
 func (m *MockLinkClickRepository) GetByShortLinkID(ctx context.Context, shortLinkID string, offset, limit int) ([]*domain.LinkClick, error) {
 	if m.GetByShortLinkIDFunc != nil {
@@ -298,9 +464,166 @@ func (m *MockLinkClickRepository) GetByShortLinkID(ctx context.Context, shortLin
 }
 
 // GetStatsByShortLinkID mocks the GetStatsByShortLinkID method
-func (m *MockLinkClickRepository) GetStatsByShortLinkID(ctx context.Context, shortLinkID string) (*domain.LinkStats, error) {
+func (m *MockLinkClickRepository) GetStatsByShortLinkID(ctx context.Context, shortLinkID string, loc *time.Location) (*domain.LinkStats, error) {
 	if m.GetStatsByShortLinkIDFunc != nil {
-		return m.GetStatsByShortLinkIDFunc(ctx, shortLinkID)
+		return m.GetStatsByShortLinkIDFunc(ctx, shortLinkID, loc)
+	}
+	return nil, nil
+}
+
+// GetStatsSummaries mocks the GetStatsSummaries method
+func (m *MockLinkClickRepository) GetStatsSummaries(ctx context.Context, shortLinkIDs []string) (map[string]*domain.LinkStatsSummary, error) {
+	if m.GetStatsSummariesFunc != nil {
+		return m.GetStatsSummariesFunc(ctx, shortLinkIDs)
+	}
+	return map[string]*domain.LinkStatsSummary{}, nil
+}
+
+// DeleteOlderThan mocks the DeleteOlderThan method
+func (m *MockLinkClickRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, archive bool) (int64, error) {
+	if m.DeleteOlderThanFunc != nil {
+		return m.DeleteOlderThanFunc(ctx, cutoff, archive)
+	}
+	return 0, nil
+}
+
+// StreamByCreatedRange mocks the StreamByCreatedRange method
+func (m *MockLinkClickRepository) StreamByCreatedRange(ctx context.Context, start, end time.Time, fn func(click *domain.LinkClick) error) error {
+	if m.StreamByCreatedRangeFunc != nil {
+		return m.StreamByCreatedRangeFunc(ctx, start, end, fn)
+	}
+	return nil
+}
+
+// GetEarliestCreatedAt mocks the GetEarliestCreatedAt method
+func (m *MockLinkClickRepository) GetEarliestCreatedAt(ctx context.Context) (*time.Time, error) {
+	if m.GetEarliestCreatedAtFunc != nil {
+		return m.GetEarliestCreatedAtFunc(ctx)
+	}
+	return nil, nil
+}
+
+// GetRollupWatermark mocks the GetRollupWatermark method
+func (m *MockLinkClickRepository) GetRollupWatermark(ctx context.Context) (*time.Time, error) {
+	if m.GetRollupWatermarkFunc != nil {
+		return m.GetRollupWatermarkFunc(ctx)
 	}
 	return nil, nil
 }
+
+// SaveDailyRollup mocks the SaveDailyRollup method
+func (m *MockLinkClickRepository) SaveDailyRollup(ctx context.Context, day time.Time, rollups []*domain.DailyClickRollup) error {
+	if m.SaveDailyRollupFunc != nil {
+		return m.SaveDailyRollupFunc(ctx, day, rollups)
+	}
+	return nil
+}
+
+// CountAll mocks the CountAll method
+func (m *MockLinkClickRepository) CountAll(ctx context.Context) (int64, error) {
+	if m.CountAllFunc != nil {
+		return m.CountAllFunc(ctx)
+	}
+	return 0, nil
+}
+
+// AddSampledOut mocks the AddSampledOut method
+func (m *MockLinkClickRepository) AddSampledOut(ctx context.Context, shortLinkID string, clicks int) error {
+	if m.AddSampledOutFunc != nil {
+		return m.AddSampledOutFunc(ctx, shortLinkID, clicks)
+	}
+	return nil
+}
+
+// MockAuditLogRepository mocks the AuditLogRepository interface
+type MockAuditLogRepository struct {
+	CreateFunc       func(ctx context.Context, entry *domain.AuditEntry) error
+	ListByEntityFunc func(ctx context.Context, entityType, entityID string, offset, limit int) ([]*domain.AuditEntry, error)
+}
+
+// Create mocks the Create method
+func (m *MockAuditLogRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, entry)
+	}
+	return nil
+}
+
+// ListByEntity mocks the ListByEntity method
+func (m *MockAuditLogRepository) ListByEntity(ctx context.Context, entityType, entityID string, offset, limit int) ([]*domain.AuditEntry, error) {
+	if m.ListByEntityFunc != nil {
+		return m.ListByEntityFunc(ctx, entityType, entityID, offset, limit)
+	}
+	return nil, nil
+}
+
+// MockCollectionRepository mocks the CollectionRepository interface
+type MockCollectionRepository struct {
+	CreateFunc      func(ctx context.Context, collection *domain.Collection) error
+	GetByIDFunc     func(ctx context.Context, owner, id string) (*domain.Collection, error)
+	ListByOwnerFunc func(ctx context.Context, owner string) ([]*domain.Collection, error)
+	UpdateFunc      func(ctx context.Context, collection *domain.Collection) error
+	DeleteFunc      func(ctx context.Context, owner, id string) error
+}
+
+// Create mocks the Create method
+func (m *MockCollectionRepository) Create(ctx context.Context, collection *domain.Collection) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, collection)
+	}
+	return nil
+}
+
+// GetByID mocks the GetByID method
+func (m *MockCollectionRepository) GetByID(ctx context.Context, owner, id string) (*domain.Collection, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, owner, id)
+	}
+	return nil, nil
+}
+
+// ListByOwner mocks the ListByOwner method
+func (m *MockCollectionRepository) ListByOwner(ctx context.Context, owner string) ([]*domain.Collection, error) {
+	if m.ListByOwnerFunc != nil {
+		return m.ListByOwnerFunc(ctx, owner)
+	}
+	return []*domain.Collection{}, nil
+}
+
+// Update mocks the Update method
+func (m *MockCollectionRepository) Update(ctx context.Context, collection *domain.Collection) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, collection)
+	}
+	return nil
+}
+
+// Delete mocks the Delete method
+func (m *MockCollectionRepository) Delete(ctx context.Context, owner, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, owner, id)
+	}
+	return nil
+}
+
+// MockMetricCounterRepository is a mock implementation of repository.MetricCounterRepository
+type MockMetricCounterRepository struct {
+	ListFunc func(ctx context.Context) ([]domain.MetricCounter, error)
+	AddFunc  func(ctx context.Context, counters []domain.MetricCounter) error
+}
+
+// List mocks the List method
+func (m *MockMetricCounterRepository) List(ctx context.Context) ([]domain.MetricCounter, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+	return nil, nil
+}
+
+// Add mocks the Add method
+func (m *MockMetricCounterRepository) Add(ctx context.Context, counters []domain.MetricCounter) error {
+	if m.AddFunc != nil {
+		return m.AddFunc(ctx, counters)
+	}
+	return nil
+}
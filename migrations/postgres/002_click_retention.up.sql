@@ -0,0 +1,7 @@
+-- Per-link totals for clicks removed by the retention job
+CREATE TABLE IF NOT EXISTS link_click_summaries (
+    short_link_id UUID PRIMARY KEY REFERENCES short_links(id) ON DELETE CASCADE,
+    archived_clicks BIGINT NOT NULL DEFAULT 0,
+    last_archived_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS link_click_rollup_state;
+DROP INDEX IF EXISTS idx_link_click_daily_day;
+DROP TABLE IF EXISTS link_click_daily;
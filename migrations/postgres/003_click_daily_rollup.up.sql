@@ -0,0 +1,18 @@
+-- Per-day click aggregates by dimension (total, referrer, browser, os, device)
+CREATE TABLE IF NOT EXISTS link_click_daily (
+    short_link_id UUID NOT NULL REFERENCES short_links(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    dimension TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_link_id, day, dimension, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_click_daily_day ON link_click_daily(day);
+
+-- Tracks the last UTC day that has been fully rolled up
+CREATE TABLE IF NOT EXISTS link_click_rollup_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    rolled_through DATE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Links re-coded by the up migration keep their new codes
+DROP INDEX IF EXISTS idx_short_links_code;
+CREATE INDEX IF NOT EXISTS idx_short_links_code ON short_links(code);
@@ -0,0 +1,33 @@
+-- Generated codes must be unique so concurrent creates are rejected by the database.
+--
+-- Codes created before this constraint may already be duplicated. Redirects
+-- only ever resolved one link per code, so the oldest link keeps the code and
+-- every newer duplicate is re-coded to <code>-<first 8 hex digits of its id>
+-- and deactivated. Each re-coded link is reported as a NOTICE; their owners can
+-- find them by the new code and reactivate them. To pick which link keeps a
+-- code instead, re-code or delete the others before running this migration:
+--
+--   SELECT code, array_agg(id ORDER BY created_at, id)
+--   FROM short_links GROUP BY code HAVING COUNT(*) > 1;
+DO $$
+DECLARE
+    dup RECORD;
+BEGIN
+    FOR dup IN
+        SELECT id, code, code || '-' || left(replace(id::text, '-', ''), 8) AS new_code
+        FROM (
+            SELECT id, code, ROW_NUMBER() OVER (PARTITION BY code ORDER BY created_at, id) AS position
+            FROM short_links
+        ) ranked
+        WHERE position > 1
+    LOOP
+        UPDATE short_links
+        SET code = dup.new_code, is_active = FALSE, updated_at = NOW()
+        WHERE id = dup.id;
+
+        RAISE NOTICE 'short link % duplicated code %; re-coded to % and deactivated', dup.id, dup.code, dup.new_code;
+    END LOOP;
+END $$;
+
+DROP INDEX IF EXISTS idx_short_links_code;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_short_links_code ON short_links(code);
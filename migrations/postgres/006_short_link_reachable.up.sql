@@ -0,0 +1,2 @@
+-- Result of the optional destination probe at creation time; NULL when not checked
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS reachable BOOLEAN;
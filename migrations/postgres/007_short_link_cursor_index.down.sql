@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_short_links_created_at_id;
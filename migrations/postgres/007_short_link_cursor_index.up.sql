@@ -0,0 +1,2 @@
+-- Supports keyset pagination of links, newest first
+CREATE INDEX IF NOT EXISTS idx_short_links_created_at_id ON short_links(created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+-- Pattern links match their code as a path prefix and forward the rest of the path
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS is_pattern BOOLEAN NOT NULL DEFAULT FALSE;
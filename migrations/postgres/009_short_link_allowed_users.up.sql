@@ -0,0 +1,2 @@
+-- Restricted links only redirect for these users; NULL leaves a link open to everyone
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS allowed_users TEXT[];
@@ -0,0 +1,3 @@
+ALTER TABLE short_links DROP COLUMN IF EXISTS health_checked_at;
+ALTER TABLE short_links DROP COLUMN IF EXISTS health_error;
+ALTER TABLE short_links DROP COLUMN IF EXISTS health_status_code;
@@ -0,0 +1,4 @@
+-- Result of the last destination health check; health_checked_at is NULL until one has run
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS health_status_code INTEGER;
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS health_error TEXT;
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS health_checked_at TIMESTAMP WITH TIME ZONE;
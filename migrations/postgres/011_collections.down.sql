@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_short_links_collection_id;
+ALTER TABLE short_links DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,13 @@
+-- Named folders grouping a user's short links; names are unique per owner
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_id, name)
+);
+
+-- Deleting a collection leaves its links in place, outside any collection
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS collection_id UUID REFERENCES collections(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_short_links_collection_id ON short_links (collection_id);
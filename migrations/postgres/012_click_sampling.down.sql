@@ -0,0 +1,3 @@
+ALTER TABLE link_click_summaries DROP COLUMN IF EXISTS sampled_out_clicks;
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS sample_weight;
+ALTER TABLE short_links DROP COLUMN IF EXISTS click_sample_rate;
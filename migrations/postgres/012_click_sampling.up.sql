@@ -0,0 +1,9 @@
+-- Per-link click sampling: only 1 in click_sample_rate clicks of a link is
+-- stored, NULL leaving it to the global rate
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS click_sample_rate INTEGER CHECK (click_sample_rate >= 1);
+
+-- How many clicks each stored click stands for, so sampled stats can be scaled back up
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS sample_weight INTEGER NOT NULL DEFAULT 1;
+
+-- Exact count of the clicks that were sampled out and never stored
+ALTER TABLE link_click_summaries ADD COLUMN IF NOT EXISTS sampled_out_clicks BIGINT NOT NULL DEFAULT 0;
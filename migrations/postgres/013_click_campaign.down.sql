@@ -0,0 +1,7 @@
+DELETE FROM link_click_daily WHERE dimension IN ('utm_source', 'utm_medium', 'utm_campaign');
+
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS utm_content;
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS utm_term;
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS utm_source;
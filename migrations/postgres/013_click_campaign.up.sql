@@ -0,0 +1,6 @@
+-- utm_* campaign params of the short link request a click came from
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS utm_source TEXT;
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS utm_medium TEXT;
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS utm_campaign TEXT;
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS utm_term TEXT;
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS utm_content TEXT;
@@ -0,0 +1,9 @@
+-- Persisted totals of in-memory metric counters (redirects_total,
+-- redirects_by_link), so they survive restarts
+CREATE TABLE IF NOT EXISTS metric_counters (
+    name TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (name, label)
+);
@@ -0,0 +1 @@
+DELETE FROM link_click_daily WHERE dimension IN ('country', 'city');
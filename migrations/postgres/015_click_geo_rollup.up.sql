@@ -0,0 +1,10 @@
+-- Days rolled up before countries and cities were aggregated get them from
+-- the raw clicks still stored
+INSERT INTO link_click_daily (short_link_id, day, dimension, value, clicks)
+SELECT c.short_link_id, (c.created_at AT TIME ZONE 'UTC')::date, d.dimension, d.value, SUM(c.sample_weight)
+FROM link_clicks c
+CROSS JOIN LATERAL (VALUES ('country', c.country), ('city', c.city)) AS d(dimension, value)
+JOIN link_click_rollup_state s ON (c.created_at AT TIME ZONE 'UTC')::date <= s.rolled_through
+WHERE d.value IS NOT NULL
+GROUP BY c.short_link_id, (c.created_at AT TIME ZONE 'UTC')::date, d.dimension, d.value
+ON CONFLICT (short_link_id, day, dimension, value) DO NOTHING;
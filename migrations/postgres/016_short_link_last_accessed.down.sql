@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_short_links_last_accessed_at;
+ALTER TABLE short_links DROP COLUMN IF EXISTS last_accessed_at;
@@ -0,0 +1,4 @@
+-- When the link was last followed, written at most once per throttle window;
+-- NULL until its first click. Indexed for finding dormant links.
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_short_links_last_accessed_at ON short_links(last_accessed_at);
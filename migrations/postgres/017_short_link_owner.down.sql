@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_short_links_owner_id;
+ALTER TABLE short_links DROP COLUMN IF EXISTS owner_id;
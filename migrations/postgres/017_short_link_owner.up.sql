@@ -0,0 +1,4 @@
+-- The user whose token created the link, for per-user link quotas; NULL for
+-- links created without one or before owners were recorded
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS owner_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_short_links_owner_id ON short_links(owner_id);
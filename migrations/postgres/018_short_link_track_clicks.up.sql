@@ -0,0 +1,2 @@
+-- Links with track_clicks off redirect without recording any clicks
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS track_clicks BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_urls_host_reversed;
+ALTER TABLE urls DROP COLUMN IF EXISTS host;
@@ -0,0 +1,7 @@
+-- The lowercase host of each destination, derived from original_url by
+-- Postgres so it never drifts, for finding every link pointing at a domain.
+-- Indexed reversed so both exact and subdomain matches are prefix scans.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS host TEXT GENERATED ALWAYS AS (
+    lower(substring(original_url FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/?#]*@)?(\[[^]]*\]|[^:/?#]*)'))
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_urls_host_reversed ON urls (reverse(host) text_pattern_ops);